@@ -3,12 +3,24 @@ package config
 import (
 	"fmt"
 	"os"
+	"sync"
+	"time"
 
 	"github.com/casbin/casbin/v2"
 	gormadapter "github.com/casbin/gorm-adapter/v3"
+	"github.com/pwnthemall/pwnthemall/backend/logger"
 )
 
-var CEF *casbin.Enforcer
+// policyCheckInterval is how often the stored policy is compared with the loaded one
+const policyCheckInterval = 5 * time.Second
+
+var (
+	CEF *casbin.Enforcer
+
+	policyMu          sync.RWMutex
+	policyFingerprint string
+	policyCheckedAt   time.Time
+)
 
 func InitCasbin() *casbin.Enforcer {
 	adapter, err := gormadapter.NewAdapterByDB(DB)
@@ -31,5 +43,79 @@ func InitCasbin() *casbin.Enforcer {
 		SeedCasbinFromCsv(CEF)
 	}
 
+	// Seeding writes to storage, pick it up once before serving requests
+	if err := CEF.LoadPolicy(); err != nil {
+		logger.Errorf("Casbin error: %v", err)
+	}
+	policyFingerprint = currentPolicyFingerprint()
+	policyCheckedAt = time.Now()
+
 	return enforcer
 }
+
+// Enforce checks a permission, the policy is reloaded first if another instance changed it
+func Enforce(sub, obj, act string) (bool, error) {
+	reloadPolicyIfChanged()
+	policyMu.RLock()
+	defer policyMu.RUnlock()
+	return CEF.Enforce(sub, obj, act)
+}
+
+// UpdatePolicy applies a change to the enforcer, the adapter saves it so other instances reload it
+func UpdatePolicy(change func(e *casbin.Enforcer) error) error {
+	policyMu.Lock()
+	defer policyMu.Unlock()
+	if err := change(CEF); err != nil {
+		return err
+	}
+	policyFingerprint = currentPolicyFingerprint()
+	return nil
+}
+
+// ReadPolicy gives read access to the loaded policy
+func ReadPolicy(read func(e *casbin.Enforcer)) {
+	reloadPolicyIfChanged()
+	policyMu.RLock()
+	defer policyMu.RUnlock()
+	read(CEF)
+}
+
+// currentPolicyFingerprint changes whenever a rule is added or removed
+func currentPolicyFingerprint() string {
+	var row struct {
+		Count int64
+		MaxID int64
+	}
+	if err := DB.Raw("SELECT COUNT(*) AS count, COALESCE(MAX(id), 0) AS max_id FROM casbin_rule").Scan(&row).Error; err != nil {
+		logger.Errorf("Casbin error: %v", err)
+		return ""
+	}
+	return fmt.Sprintf("%d:%d", row.Count, row.MaxID)
+}
+
+func reloadPolicyIfChanged() {
+	policyMu.RLock()
+	recent := time.Since(policyCheckedAt) < policyCheckInterval
+	policyMu.RUnlock()
+	if recent {
+		return
+	}
+
+	policyMu.Lock()
+	defer policyMu.Unlock()
+	if time.Since(policyCheckedAt) < policyCheckInterval {
+		return
+	}
+	policyCheckedAt = time.Now()
+
+	fingerprint := currentPolicyFingerprint()
+	if fingerprint == "" || fingerprint == policyFingerprint {
+		return
+	}
+	if err := CEF.LoadPolicy(); err != nil {
+		logger.Errorf("Casbin error: %v", err)
+		return
+	}
+	policyFingerprint = fingerprint
+	logger.Debug("Casbin policy reloaded")
+}
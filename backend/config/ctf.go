@@ -4,7 +4,7 @@ import (
 	"os"
 	"time"
 
-	"github.com/pwnthemall/pwnthemall/backend/debug"
+	"github.com/pwnthemall/pwnthemall/backend/logger"
 	"github.com/pwnthemall/pwnthemall/backend/models"
 	"gorm.io/gorm"
 )
@@ -41,11 +41,11 @@ func GetCTFStatus() CTFStatus {
 				Public: true,
 			}
 			if createErr := DB.Create(&startConfig).Error; createErr != nil {
-				debug.Log("Failed to create CTF_START_TIME config: %v", createErr)
+				logger.Errorf("Failed to create CTF_START_TIME config: %v", createErr)
 				return CTFNoTiming
 			}
 		} else {
-			debug.Log("Database error getting CTF_START_TIME: %v", err)
+			logger.Errorf("Database error getting CTF_START_TIME: %v", err)
 			return CTFNoTiming
 		}
 	}
@@ -60,11 +60,11 @@ func GetCTFStatus() CTFStatus {
 				Public: true,
 			}
 			if createErr := DB.Create(&endConfig).Error; createErr != nil {
-				debug.Log("Failed to create CTF_END_TIME config: %v", createErr)
+				logger.Errorf("Failed to create CTF_END_TIME config: %v", createErr)
 				return CTFNoTiming
 			}
 		} else {
-			debug.Log("Database error getting CTF_END_TIME: %v", err)
+			logger.Errorf("Database error getting CTF_END_TIME: %v", err)
 			return CTFNoTiming
 		}
 	}
@@ -77,13 +77,13 @@ func GetCTFStatus() CTFStatus {
 	// Parse times (expecting RFC3339 format: 2006-01-02T15:04:05Z07:00)
 	startTime, err := time.Parse(time.RFC3339, startConfig.Value)
 	if err != nil {
-		debug.Log("Failed to parse CTF start time")
+		logger.Error("Failed to parse CTF start time")
 		return CTFNoTiming
 	}
 
 	endTime, err := time.Parse(time.RFC3339, endConfig.Value)
 	if err != nil {
-		debug.Log("Failed to parse CTF end time: %v", err)
+		logger.Errorf("Failed to parse CTF end time: %v", err)
 		return CTFNoTiming
 	}
 
@@ -3,7 +3,7 @@ package config
 import (
 	"os"
 
-	"github.com/pwnthemall/pwnthemall/backend/debug"
+	"github.com/pwnthemall/pwnthemall/backend/logger"
 	"github.com/pwnthemall/pwnthemall/backend/models"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
@@ -11,28 +11,36 @@ import (
 
 var DB *gorm.DB
 
+// ChallengeSearchVector is the text search document of a challenge, it must match idx_challenges_search
+const ChallengeSearchVector = `to_tsvector('simple', coalesce(name, '') || ' ' || coalesce(description, ''))`
+
 func ConnectDB() *gorm.DB {
 	dsn := os.Getenv("DATABASE_URL")
 	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
 	if err != nil {
-		debug.Log("Failed to connect to database: %v", err)
+		logger.Errorf("Failed to connect to database: %v", err)
 		os.Exit(1)
 	}
 
+	// Accounts created before email verification existed are considered verified
+	markUsersVerified := !db.Migrator().HasColumn(&models.User{}, "email_verified")
+
 	err = db.AutoMigrate(
 		&models.Config{}, &models.DockerConfig{},
 		&models.Team{}, &models.Solve{},
-		&models.User{}, &models.ChallengeCategory{},
+		&models.User{}, &models.UserIdentity{}, &models.UserToken{}, &models.APIToken{}, &models.UserSession{}, &models.RevokedToken{}, &models.Role{}, &models.Bracket{}, &models.ChallengeCategory{},
 		&models.ChallengeType{}, &models.ChallengeDifficulty{},
-		&models.DecayFormula{}, &models.Challenge{}, &models.Flag{},
-		&models.Hint{}, &models.HintPurchase{}, &models.FirstBlood{},
+		&models.DecayFormula{}, &models.Tag{}, &models.Challenge{}, &models.Flag{},
+		&models.ChallengeAuthor{}, &models.Hint{}, &models.HintPurchase{}, &models.FirstBlood{}, &models.ChallengeRating{},
 		&models.Submission{}, &models.Instance{}, &models.InstanceCooldown{}, &models.DynamicFlag{}, &models.GeoSpec{},
-		&models.Notification{},
-		&models.Ticket{}, &models.TicketMessage{},
-		&models.Page{},
+		&models.Notification{}, &models.NotificationRecipient{}, &models.NotificationRead{}, &models.NotificationRevision{},
+		&models.Ticket{}, &models.TicketMessage{}, &models.TicketCannedResponse{},
+		&models.Page{}, &models.PageRevision{},
+		&models.AuditLog{}, &models.RateLimitBucket{}, &models.PubSubMessage{},
+		&models.WebhookTarget{}, &models.WebhookDelivery{},
 	)
 	if err != nil {
-		debug.Log("Failed to migrate database: %v", err)
+		logger.Errorf("Failed to migrate database: %v", err)
 		os.Exit(1)
 	}
 
@@ -41,6 +49,17 @@ func ConnectDB() *gorm.DB {
 	// Migrate existing pages to have is_in_sidebar = true
 	migrateExistingPages()
 
+	if markUsersVerified {
+		if err := DB.Model(&models.User{}).Where("1 = 1").Update("email_verified", true).Error; err != nil {
+			logger.Warnf("Failed to mark existing users as verified: %v", err)
+		}
+	}
+
+	createChallengeSearchIndex()
+	protectAuditLog()
+	backfillTicketFirstResponses()
+	migrateNotificationReads()
+
 	// fixInstanceUserForeignKey()
 	if os.Getenv("PTA_SEED_DATABASE") == "true" {
 		SeedDatabase()
@@ -54,7 +73,7 @@ func migrateExistingPages() {
 	DB.Model(&models.Page{}).Where("is_in_sidebar = ?", false).Count(&count)
 
 	if count > 0 {
-		debug.Log("Migrating %d existing pages to set is_in_sidebar = true", count)
+		logger.Debugf("Migrating %d existing pages to set is_in_sidebar = true", count)
 		result := DB.Model(&models.Page{}).
 			Where("is_in_sidebar = ?", false).
 			Updates(map[string]interface{}{
@@ -62,9 +81,65 @@ func migrateExistingPages() {
 				"source":        "ui",
 			})
 		if result.Error != nil {
-			debug.Log("Warning: Failed to migrate existing pages: %v", result.Error)
+			logger.Warnf("Failed to migrate existing pages: %v", result.Error)
 		} else {
-			debug.Log("Successfully migrated %d pages", result.RowsAffected)
+			logger.Infof("Successfully migrated %d pages", result.RowsAffected)
+		}
+	}
+}
+
+// createChallengeSearchIndex adds the GIN index used by the challenge full-text search
+func createChallengeSearchIndex() {
+	if err := DB.Exec(`CREATE INDEX IF NOT EXISTS idx_challenges_search ON challenges USING GIN (` + ChallengeSearchVector + `)`).Error; err != nil {
+		logger.Warnf("Failed to create challenge search index: %v", err)
+	}
+}
+
+// backfillTicketFirstResponses sets the first admin reply of tickets answered before it was tracked
+func backfillTicketFirstResponses() {
+	if err := DB.Exec(`UPDATE tickets SET first_response_at = (
+		SELECT MIN(m.created_at) FROM ticket_messages m WHERE m.ticket_id = tickets.id AND m.is_admin
+	) WHERE first_response_at IS NULL AND EXISTS (
+		SELECT 1 FROM ticket_messages m WHERE m.ticket_id = tickets.id AND m.is_admin
+	)`).Error; err != nil {
+		logger.Warnf("Failed to backfill ticket first responses: %v", err)
+	}
+}
+
+// migrateNotificationReads moves the read state of direct notifications from the old shared column to the per-user table
+// The column is dropped afterwards, global and team notifications start unread since it could not tell who read them
+func migrateNotificationReads() {
+	if !DB.Migrator().HasColumn("notifications", "read_at") {
+		return
+	}
+	if err := DB.Exec(`INSERT INTO notification_reads (notification_id, user_id, read_at)
+		SELECT id, user_id, read_at FROM notifications WHERE user_id IS NOT NULL AND read_at IS NOT NULL
+		ON CONFLICT DO NOTHING`).Error; err != nil {
+		logger.Warnf("Failed to migrate notification reads: %v", err)
+		return
+	}
+	if err := DB.Migrator().DropColumn("notifications", "read_at"); err != nil {
+		logger.Warnf("Failed to drop notifications.read_at: %v", err)
+	}
+}
+
+// protectAuditLog makes the database refuse any update, delete or truncate of the audit log
+func protectAuditLog() {
+	statements := []string{
+		`CREATE OR REPLACE FUNCTION audit_logs_append_only() RETURNS trigger AS $$
+		BEGIN
+			RAISE EXCEPTION 'audit_logs is append-only';
+		END;
+		$$ LANGUAGE plpgsql`,
+		`DROP TRIGGER IF EXISTS audit_logs_append_only ON audit_logs`,
+		`CREATE TRIGGER audit_logs_append_only BEFORE UPDATE OR DELETE ON audit_logs FOR EACH ROW EXECUTE FUNCTION audit_logs_append_only()`,
+		`DROP TRIGGER IF EXISTS audit_logs_no_truncate ON audit_logs`,
+		`CREATE TRIGGER audit_logs_no_truncate BEFORE TRUNCATE ON audit_logs FOR EACH STATEMENT EXECUTE FUNCTION audit_logs_append_only()`,
+	}
+	for _, statement := range statements {
+		if err := DB.Exec(statement).Error; err != nil {
+			logger.Warnf("Failed to protect audit log: %v", err)
+			return
 		}
 	}
 }
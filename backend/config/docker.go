@@ -4,14 +4,14 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	
+
 	"net/http"
 
 	"strings"
 
 	"github.com/docker/cli/cli/connhelper"
 	"github.com/docker/docker/client"
-	"github.com/pwnthemall/pwnthemall/backend/debug"
+	"github.com/pwnthemall/pwnthemall/backend/logger"
 	"github.com/pwnthemall/pwnthemall/backend/models"
 )
 
@@ -20,15 +20,15 @@ var DockerClient *client.Client
 func ConnectDocker() error {
 	var dockerCfg models.DockerConfig
 	if err := DB.First(&dockerCfg).Error; err != nil {
-		debug.Println("Unable to load DockerConfig from DB:", err)
-		debug.Println("This might be due to missing environment variables or database seeding issues")
+		logger.Errorf("Unable to load DockerConfig from DB: %v", err)
+		logger.Debug("This might be due to missing environment variables or database seeding issues")
 		return err
 	}
 
-	debug.Log("Docker config loaded from DB - Host: %s, ImagePrefix: %s", dockerCfg.Host, dockerCfg.ImagePrefix)
+	logger.Debugf("Docker config loaded from DB - Host: %s, ImagePrefix: %s", dockerCfg.Host, dockerCfg.ImagePrefix)
 
 	if dockerCfg.Host == "" {
-		debug.Println("ERROR: DockerConfig.Host is empty in DB")
+		logger.Error("DockerConfig.Host is empty in DB")
 		return errors.New("DockerConfig.Host is empty in DB")
 	}
 
@@ -37,7 +37,7 @@ func ConnectDocker() error {
 
 	// Handle Unix socket directly (local Docker daemon)
 	if dockerCfg.Host == "/var/run/docker.sock" || strings.HasPrefix(dockerCfg.Host, "unix://") || strings.HasPrefix(dockerCfg.Host, "/") {
-		debug.Log("DEBUG: Using local Unix socket: %s", dockerCfg.Host)
+		logger.Debugf("DEBUG: Using local Unix socket: %s", dockerCfg.Host)
 
 		// For Unix sockets, use a simple client configuration
 		clientOpts := []client.Opt{
@@ -47,12 +47,12 @@ func ConnectDocker() error {
 
 		cl, err = client.NewClientWithOpts(clientOpts...)
 		if err != nil {
-			debug.Println("Unable to create docker client for Unix socket:", err)
+			logger.Errorf("Unable to create docker client for Unix socket: %v", err)
 			return fmt.Errorf("unable to create docker client for Unix socket: %w", err)
 		}
 	} else {
 		// Handle remote Docker daemon (SSH, TCP, etc.)
-		debug.Log("DEBUG: Using remote Docker daemon: %s", dockerCfg.Host)
+		logger.Debugf("DEBUG: Using remote Docker daemon: %s", dockerCfg.Host)
 		var helper *connhelper.ConnectionHelper
 		if strings.HasPrefix(dockerCfg.Host, "ssh://") {
 			sshOpts := []string{
@@ -61,22 +61,22 @@ func ConnectDocker() error {
 
 			helper, err = connhelper.GetConnectionHelperWithSSHOpts(dockerCfg.Host, sshOpts)
 			if err != nil {
-				debug.Println("Failed to create connection helper:", err)
+				logger.Errorf("Failed to create connection helper: %v", err)
 				return err
 			}
 			if helper == nil {
-				debug.Println("Unable to create connection helper (nil)")
+				logger.Error("Unable to create connection helper (nil)")
 				return errors.New("unable to create connection helper")
 			}
 
 		} else {
 			helper, err = connhelper.GetConnectionHelper(dockerCfg.Host)
 			if err != nil {
-				debug.Println("Failed to create connection helper:", err)
+				logger.Errorf("Failed to create connection helper: %v", err)
 				return err
 			}
 			if helper == nil {
-				debug.Println("Unable to create connection helper (nil)")
+				logger.Error("Unable to create connection helper (nil)")
 				return errors.New("unable to create connection helper")
 			}
 		}
@@ -96,22 +96,22 @@ func ConnectDocker() error {
 
 		cl, err = client.NewClientWithOpts(clientOpts...)
 		if err != nil {
-			debug.Println("Unable to create docker client:", err)
+			logger.Errorf("Unable to create docker client: %v", err)
 			return errors.New("unable to create docker client")
 		}
 	}
 
 	if cl == nil {
-		debug.Println("Unable to create docker client")
+		logger.Error("Unable to create docker client")
 		return errors.New("unable to create docker client")
 	}
 
 	ver, err := cl.ServerVersion(context.Background())
 	if err != nil {
-		debug.Println("Unable to connect to docker daemon:", err)
+		logger.Errorf("Unable to connect to docker daemon: %v", err)
 		return fmt.Errorf("unable to connect to docker daemon: %s", err.Error())
 	}
-	debug.Log("Connected to %s | Docker Version: %s", dockerCfg.Host, ver.Version)
+	logger.Debugf("Connected to %s | Docker Version: %s", dockerCfg.Host, ver.Version)
 
 	DockerClient = cl
 	return nil
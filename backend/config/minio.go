@@ -5,7 +5,7 @@ import (
 
 	"github.com/minio/minio-go/v7"
 	"github.com/minio/minio-go/v7/pkg/credentials"
-	"github.com/pwnthemall/pwnthemall/backend/debug"
+	"github.com/pwnthemall/pwnthemall/backend/logger"
 )
 
 var FS *minio.Client
@@ -19,7 +19,7 @@ func ConnectMinio() *minio.Client {
 		Secure: useSSL,
 	})
 	if err != nil {
-		debug.Log("Failed to connect to MinIO: %v", err)
+		logger.Errorf("Failed to connect to MinIO: %v", err)
 		os.Exit(1)
 	}
 	FS = minioClient
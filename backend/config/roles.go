@@ -0,0 +1,83 @@
+package config
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/casbin/casbin/v2"
+	"github.com/pwnthemall/pwnthemall/backend/models"
+)
+
+// BuiltinRoles cannot be renamed or deleted
+var BuiltinRoles = []string{"anonymous", "member", "author", "admin"}
+
+var roleNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{1,49}$`)
+
+// ValidRoleName reports whether a name can be used for a custom role
+func ValidRoleName(name string) bool {
+	return roleNamePattern.MatchString(name) && !slices.Contains(BuiltinRoles, name)
+}
+
+// RoleExists reports whether a role is built in or was created by an admin
+func RoleExists(name string) bool {
+	if slices.Contains(BuiltinRoles, name) {
+		return true
+	}
+	var count int64
+	DB.Model(&models.Role{}).Where("name = ?", name).Count(&count)
+	return count > 0
+}
+
+// AllRoleNames returns the built-in roles followed by the custom ones
+func AllRoleNames() []string {
+	var custom []string
+	DB.Model(&models.Role{}).Order("name").Pluck("name", &custom)
+	return append(slices.Clone(BuiltinRoles), custom...)
+}
+
+// RolesWithPermission returns the roles allowed to perform act on obj
+func RolesWithPermission(obj, act string) []string {
+	var roles []string
+	for _, role := range AllRoleNames() {
+		if ok, err := Enforce(role, obj, act); err == nil && ok {
+			roles = append(roles, role)
+		}
+	}
+	return roles
+}
+
+// RolePermissions returns the obj and act pairs a role holds, inherited ones included
+func RolePermissions(role string) [][2]string {
+	var rules [][]string
+	ReadPolicy(func(e *casbin.Enforcer) {
+		rules, _ = e.GetImplicitPermissionsForUser(role)
+	})
+	permissions := make([][2]string, 0, len(rules))
+	for _, rule := range rules {
+		if len(rule) >= 3 {
+			permissions = append(permissions, [2]string{rule[1], rule[2]})
+		}
+	}
+	return permissions
+}
+
+// HasAdminAccess reports whether a role holds any permission on the admin routes
+func HasAdminAccess(role string) bool {
+	for _, p := range RolePermissions(role) {
+		if p[0] == "/*" || strings.HasPrefix(p[0], "/admin") {
+			return true
+		}
+	}
+	return false
+}
+
+// RoleCovers reports whether sub holds every permission of role, so granting role to someone adds nothing sub can't already do
+func RoleCovers(sub, role string) bool {
+	for _, p := range RolePermissions(role) {
+		if ok, err := Enforce(sub, p[0], p[1]); err != nil || !ok {
+			return false
+		}
+	}
+	return true
+}
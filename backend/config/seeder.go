@@ -8,11 +8,11 @@ import (
 	"time"
 
 	"github.com/casbin/casbin/v2"
-	"github.com/pwnthemall/pwnthemall/backend/debug"
+	"github.com/pwnthemall/pwnthemall/backend/logger"
 	"github.com/pwnthemall/pwnthemall/backend/models"
 	"golang.org/x/crypto/bcrypt"
 	"gorm.io/gorm"
-	"gorm.io/gorm/logger"
+	gormlogger "gorm.io/gorm/logger"
 )
 
 // Constants for demo data queries
@@ -28,9 +28,14 @@ func seedConfig() {
 		{Key: "SITE_NAME", Value: os.Getenv("PTA_SITE_NAME"), Public: true},
 		{Key: "REGISTRATION_ENABLED", Value: GetEnvWithDefault("PTA_REGISTRATION_ENABLED", "false"), Public: true},
 		{Key: "TICKETS_ENABLED", Value: GetEnvWithDefault("PTA_TICKETS_ENABLED", "true"), Public: true},
+		{Key: "TICKET_SLA_MINUTES", Value: "30", Public: false},
+		{Key: "TICKET_REOPEN_WINDOW_HOURS", Value: "48", Public: true},
 		{Key: "CTF_START_TIME", Value: GetEnvWithDefault("PTA_CTF_START_TIME", ""), Public: true},
 		{Key: "CTF_END_TIME", Value: GetEnvWithDefault("PTA_CTF_END_TIME", ""), Public: true},
 		{Key: "DEMO", Value: GetEnvWithDefault("PTA_DEMO", "false"), Public: true, SyncWithEnv: false},
+		{Key: "REQUIRE_EMAIL_VERIFICATION", Value: GetEnvWithDefault("PTA_REQUIRE_EMAIL_VERIFICATION", "false"), Public: true},
+		{Key: "REQUIRE_ADMIN_2FA", Value: GetEnvWithDefault("PTA_REQUIRE_ADMIN_2FA", "false"), Public: false},
+		{Key: "PUBLIC_CHALLENGE_RATINGS", Value: GetEnvWithDefault("PTA_PUBLIC_CHALLENGE_RATINGS", "false"), Public: true},
 	}
 
 	for _, item := range config {
@@ -43,17 +48,17 @@ func seedConfig() {
 			continue
 		}
 		if err := DB.Create(&item).Error; err != nil {
-			debug.Log("Failed to seed config %s: %v\n", item.Key, err)
+			logger.Errorf("Failed to seed config %s: %v", item.Key, err)
 		}
 	}
-	debug.Println("Seeding: config finished")
+	logger.Debug("Seeding: config finished")
 }
 
 func seedDockerConfig() {
 	var existing models.DockerConfig
 	err := DB.First(&existing).Error
 	if err == nil {
-		debug.Println("Seeding: docker config already exists, skipping")
+		logger.Debug("Seeding: docker config already exists, skipping")
 		return
 	}
 
@@ -101,10 +106,10 @@ func seedDockerConfig() {
 	}
 
 	if err := DB.Create(&config).Error; err != nil {
-		debug.Log("Failed to seed docker config: %s", err.Error())
+		logger.Errorf("Failed to seed docker config: %s", err.Error())
 		return
 	}
-	debug.Println("Seeding: docker config finished")
+	logger.Debug("Seeding: docker config finished")
 }
 
 func seedChallengeCategory() {
@@ -116,17 +121,17 @@ func seedChallengeCategory() {
 		var existing models.ChallengeCategory
 		err := DB.Where("name = ?", challengeCategory.Name).First(&existing).Error
 		if err != nil && err != gorm.ErrRecordNotFound {
-			debug.Log("Failed to check challengeCategory %s: %v\n", challengeCategory.Name, err)
+			logger.Errorf("Failed to check challengeCategory %s: %v", challengeCategory.Name, err)
 			continue
 		}
 		if err == nil {
 			continue
 		}
 		if err := DB.Create(&challengeCategory).Error; err != nil {
-			debug.Log("Failed to seed challengeCategory %s: %v\n", challengeCategory.Name, err)
+			logger.Errorf("Failed to seed challengeCategory %s: %v", challengeCategory.Name, err)
 		}
 	}
-	debug.Println("Seeding: challengeCategories finished")
+	logger.Debug("Seeding: challengeCategories finished")
 }
 
 func seedChallengeType() {
@@ -140,17 +145,17 @@ func seedChallengeType() {
 		var existing models.ChallengeType
 		err := DB.Where("name = ?", challengeType.Name).First(&existing).Error
 		if err != nil && err != gorm.ErrRecordNotFound {
-			debug.Log("Failed to check challengeType %s: %v\n", challengeType.Name, err)
+			logger.Errorf("Failed to check challengeType %s: %v", challengeType.Name, err)
 			continue
 		}
 		if err == nil {
 			continue
 		}
 		if err := DB.Create(&challengeType).Error; err != nil {
-			debug.Log("Failed to seed challengeType %s: %v\n", challengeType.Name, err)
+			logger.Errorf("Failed to seed challengeType %s: %v", challengeType.Name, err)
 		}
 	}
-	debug.Println("Seeding: challengeTypes finished")
+	logger.Debug("Seeding: challengeTypes finished")
 }
 
 func seedChallengeDifficulty() {
@@ -165,7 +170,7 @@ func seedChallengeDifficulty() {
 		var existing models.ChallengeDifficulty
 		err := DB.Where("name = ?", challengeDifficulty.Name).First(&existing).Error
 		if err != nil && err != gorm.ErrRecordNotFound {
-			debug.Log("Failed to check challengeDifficulty %s: %v\n", challengeDifficulty.Name, err)
+			logger.Errorf("Failed to check challengeDifficulty %s: %v", challengeDifficulty.Name, err)
 			continue
 		}
 		if err == nil {
@@ -176,10 +181,10 @@ func seedChallengeDifficulty() {
 			continue
 		}
 		if err := DB.Create(&challengeDifficulty).Error; err != nil {
-			debug.Log("Failed to seed challengeDifficulty %s: %v\n", challengeDifficulty.Name, err)
+			logger.Errorf("Failed to seed challengeDifficulty %s: %v", challengeDifficulty.Name, err)
 		}
 	}
-	debug.Println("Seeding: challengeDifficulties finished")
+	logger.Debug("Seeding: challengeDifficulties finished")
 }
 
 func seedDecayFormulas() {
@@ -226,35 +231,35 @@ func seedDecayFormulas() {
 		var existing models.DecayFormula
 		err := DB.Where("name = ?", formula.Name).First(&existing).Error
 		if err != nil && err != gorm.ErrRecordNotFound {
-			debug.Log("Failed to check decay formula %s: %v\n", formula.Name, err)
+			logger.Errorf("Failed to check decay formula %s: %v", formula.Name, err)
 			continue
 		}
 		if err == nil {
 			continue
 		}
 		if err := DB.Create(&formula).Error; err != nil {
-			debug.Log("Failed to seed decay formula %s: %v\n", formula.Name, err)
+			logger.Errorf("Failed to seed decay formula %s: %v", formula.Name, err)
 		}
 	}
-	debug.Println("Seeding: decay formulas finished")
+	logger.Debug("Seeding: decay formulas finished")
 }
 
 func seedDefaultUsers() {
 	users := []models.User{
-		{Username: "admin", Email: "admin@admin.admin", Password: "admin", Role: "admin"},
-		{Username: "user", Email: "user@user.user", Password: "user", Role: "member"},
-		{Username: "user1", Email: "user1@user.user", Password: "user1", Role: "member"},
-		{Username: "user2", Email: "user2@user.user", Password: "user2", Role: "member"},
-		{Username: "user3", Email: "user3@user.user", Password: "user3", Role: "member"},
-		{Username: "user4", Email: "user4@user.user", Password: "user4", Role: "member"},
-		{Username: "user5", Email: "user5@user.user", Password: "user5", Role: "member"},
+		{Username: "admin", Email: "admin@admin.admin", Password: "admin", Role: "admin", EmailVerified: true},
+		{Username: "user", Email: "user@user.user", Password: "user", Role: "member", EmailVerified: true},
+		{Username: "user1", Email: "user1@user.user", Password: "user1", Role: "member", EmailVerified: true},
+		{Username: "user2", Email: "user2@user.user", Password: "user2", Role: "member", EmailVerified: true},
+		{Username: "user3", Email: "user3@user.user", Password: "user3", Role: "member", EmailVerified: true},
+		{Username: "user4", Email: "user4@user.user", Password: "user4", Role: "member", EmailVerified: true},
+		{Username: "user5", Email: "user5@user.user", Password: "user5", Role: "member", EmailVerified: true},
 	}
 
 	for _, user := range users {
 		var existing models.User
 		err := DB.Where("username = ? OR email = ?", user.Username, user.Email).First(&existing).Error
 		if err != nil && err != gorm.ErrRecordNotFound {
-			debug.Log("Failed to check user %s: %v\n", user.Username, err)
+			logger.Errorf("Failed to check user %s: %v", user.Username, err)
 			continue
 		}
 		if err == nil {
@@ -263,19 +268,19 @@ func seedDefaultUsers() {
 
 		hashedPassword, err := bcrypt.GenerateFromPassword([]byte(user.Password), bcrypt.DefaultCost)
 		if err != nil {
-			debug.Log("Failed to hash password for user %s: %v\n", user.Username, err)
+			logger.Errorf("Failed to hash password for user %s: %v", user.Username, err)
 			continue
 		}
 		user.Password = string(hashedPassword)
 		if err := DB.Create(&user).Error; err != nil {
-			debug.Log("Failed to seed user %s: %v\n", user.Username, err)
+			logger.Errorf("Failed to seed user %s: %v", user.Username, err)
 		}
 	}
-	debug.Println("Seeding: users finished")
+	logger.Debug("Seeding: users finished")
 }
 
 func SeedCasbin(enforcer *casbin.Enforcer) {
-	debug.Println("Seeding: Casbin rules..")
+	logger.Debug("Seeding: Casbin rules..")
 	if hasPolicy, _ := enforcer.HasPolicy("anonymous", "/login", "*"); !hasPolicy {
 		enforcer.AddPolicy("anonymous", "/login", "*")
 	}
@@ -294,24 +299,42 @@ func SeedCasbin(enforcer *casbin.Enforcer) {
 		enforcer.AddPolicy("admin", "/*", "*")
 	}
 	enforcer.SavePolicy()
-	debug.Println("Seeding: Casbin finished")
+	logger.Debug("Seeding: Casbin finished")
 
 }
 
 func SeedCasbinFromCsv(enforcer *casbin.Enforcer) {
-	debug.Println("Seeding: Casbin rules from CSV..")
+	logger.Debug("Seeding: Casbin rules from CSV..")
 	e, err := casbin.NewEnforcer("config/casbin_model.conf", "config/casbin_policies.csv")
 	if err != nil {
-		debug.Println(err.Error())
+		logger.Errorf("Failed to load Casbin policies from CSV: %v", err)
+		return
+	}
+	policies, _ := e.GetPolicy()
+	groupings, _ := e.GetGroupingPolicy()
+
+	// Built-in roles follow the CSV, custom roles created from the admin panel are kept
+	for _, role := range BuiltinRoles {
+		if _, err := enforcer.RemoveFilteredPolicy(0, role); err != nil {
+			logger.Errorf("Failed to reset policies of role %s: %v", role, err)
+		}
+		if _, err := enforcer.RemoveFilteredGroupingPolicy(0, role); err != nil {
+			logger.Errorf("Failed to reset parents of role %s: %v", role, err)
+		}
+	}
+	if _, err := enforcer.AddPolicies(policies); err != nil {
+		logger.Errorf("Failed to seed Casbin rules from CSV: %v", err)
+	}
+	if len(groupings) > 0 {
+		if _, err := enforcer.AddGroupingPolicies(groupings); err != nil {
+			logger.Errorf("Failed to seed Casbin role inheritance from CSV: %v", err)
+		}
 	}
-	e.LoadPolicy()
-	e.SetAdapter(enforcer.GetAdapter())
-	e.SavePolicy()
-	debug.Println("Seeding: Casbin from CSV finished")
+	logger.Debug("Seeding: Casbin from CSV finished")
 }
 
 func SeedDatabase() {
-	debug.Println("Seeding: Database..")
+	logger.Debug("Seeding: Database..")
 	seedConfig()
 	seedDockerConfig()
 	seedChallengeDifficulty()
@@ -325,7 +348,7 @@ func SeedDatabase() {
 // SeedDemoData creates demo teams, users, and solves with timestamps spread over a time range
 // This is useful for testing the scoreboard timeline without using Playwright tests
 func SeedDemoData(teamCount int, timeRangeHours int) error {
-	debug.Log("Seeding: Demo data with %d teams over %d hours...\n", teamCount, timeRangeHours)
+	logger.Debugf("Seeding: Demo data with %d teams over %d hours...", teamCount, timeRangeHours)
 
 	// Check if challenges exist
 	var challengeCount int64
@@ -335,7 +358,7 @@ func SeedDemoData(teamCount int, timeRangeHours int) error {
 	if challengeCount == 0 {
 		return fmt.Errorf("no challenges found - please sync challenges from MinIO first")
 	}
-	debug.Log("Found %d challenges to use for demo data\n", challengeCount)
+	logger.Debugf("Found %d challenges to use for demo data", challengeCount)
 
 	// Get all visible challenges
 	var challenges []models.Challenge
@@ -357,7 +380,7 @@ func SeedDemoData(teamCount int, timeRangeHours int) error {
 	var createdTeams []models.Team
 
 	if len(existingDemoTeams) > 0 {
-		debug.Log("Found %d existing demo teams - using existing teams\n", len(existingDemoTeams))
+		logger.Debugf("Found %d existing demo teams - using existing teams", len(existingDemoTeams))
 		createdTeams = existingDemoTeams
 	} else {
 		// Create teams and users
@@ -374,13 +397,14 @@ func SeedDemoData(teamCount int, timeRangeHours int) error {
 
 			// Create first user (team creator)
 			creator := models.User{
-				Username: fmt.Sprintf("demo-user-%d", userCounter),
-				Email:    fmt.Sprintf("demo%d@demo.local", userCounter),
-				Password: string(hashedPassword),
-				Role:     "member",
+				Username:      fmt.Sprintf("demo-user-%d", userCounter),
+				Email:         fmt.Sprintf("demo%d@demo.local", userCounter),
+				Password:      string(hashedPassword),
+				Role:          "member",
+				EmailVerified: true,
 			}
 			if err := DB.Create(&creator).Error; err != nil {
-				debug.Log("Failed to create demo user %d: %v\n", userCounter, err)
+				logger.Errorf("Failed to create demo user %d: %v", userCounter, err)
 				userCounter++
 				continue
 			}
@@ -393,34 +417,35 @@ func SeedDemoData(teamCount int, timeRangeHours int) error {
 				CreatorID: creator.ID,
 			}
 			if err := DB.Create(&team).Error; err != nil {
-				debug.Log("Failed to create demo team %d: %v\n", i, err)
+				logger.Errorf("Failed to create demo team %d: %v", i, err)
 				continue
 			}
 
 			// Assign creator to team
 			creator.TeamID = &team.ID
 			if err := DB.Save(&creator).Error; err != nil {
-				debug.Log("Failed to assign creator to team %d: %v\n", i, err)
+				logger.Errorf("Failed to assign creator to team %d: %v", i, err)
 				continue
 			}
 
 			// Create additional team members
 			for j := 1; j < memberCount; j++ {
 				member := models.User{
-					Username: fmt.Sprintf("demo-user-%d", userCounter),
-					Email:    fmt.Sprintf("demo%d@demo.local", userCounter),
-					Password: string(hashedPassword),
-					Role:     "member",
-					TeamID:   &team.ID,
+					Username:      fmt.Sprintf("demo-user-%d", userCounter),
+					Email:         fmt.Sprintf("demo%d@demo.local", userCounter),
+					Password:      string(hashedPassword),
+					Role:          "member",
+					TeamID:        &team.ID,
+					EmailVerified: true,
 				}
 				if err := DB.Create(&member).Error; err != nil {
-					debug.Log("Failed to create team member %d: %v\n", userCounter, err)
+					logger.Errorf("Failed to create team member %d: %v", userCounter, err)
 				}
 				userCounter++
 			}
 
 			createdTeams = append(createdTeams, team)
-			debug.Log("Created Demo Team %d with %d members\n", i, memberCount)
+			logger.Debugf("Created Demo Team %d with %d members", i, memberCount)
 		}
 	}
 
@@ -439,7 +464,7 @@ func SeedDemoData(teamCount int, timeRangeHours int) error {
 		// Get team's user
 		var user models.User
 		if err := DB.Where("team_id = ?", team.ID).First(&user).Error; err != nil {
-			debug.Log("Failed to find user for team %d: %v\n", team.ID, err)
+			logger.Errorf("Failed to find user for team %d: %v", team.ID, err)
 			continue
 		}
 
@@ -453,7 +478,7 @@ func SeedDemoData(teamCount int, timeRangeHours int) error {
 
 			// Check if team already solved this challenge
 			var existingSolve models.Solve
-			result := DB.Session(&gorm.Session{Logger: DB.Logger.LogMode(logger.Silent)}).
+			result := DB.Session(&gorm.Session{Logger: DB.Logger.LogMode(gormlogger.Silent)}).
 				Where("team_id = ? AND challenge_id = ?", team.ID, challenge.ID).First(&existingSolve)
 			if result.Error == nil {
 				continue // Already solved
@@ -496,13 +521,13 @@ func SeedDemoData(teamCount int, timeRangeHours int) error {
 
 			// Set CreatedAt manually for the spread effect
 			if err := DB.Create(&solve).Error; err != nil {
-				debug.Log("Failed to create solve for team %d, challenge %d: %v\n", team.ID, challenge.ID, err)
+				logger.Errorf("Failed to create solve for team %d, challenge %d: %v", team.ID, challenge.ID, err)
 				continue
 			}
 
 			// Update the timestamp directly (GORM auto-sets CreatedAt)
 			if err := DB.Model(&solve).Update("created_at", solveTime).Error; err != nil {
-				debug.Log("Failed to update solve timestamp: %v\n", err)
+				logger.Errorf("Failed to update solve timestamp: %v", err)
 			}
 
 			// Create first blood entry if applicable
@@ -525,14 +550,14 @@ func SeedDemoData(teamCount int, timeRangeHours int) error {
 		}
 	}
 
-	debug.Log("Seeding: Demo data complete - created %d teams and %d solves\n", len(createdTeams), totalSolves)
-	debug.Log("Solve timestamps spread from %s to %s\n", startTime.Format(time.RFC3339), now.Format(time.RFC3339))
+	logger.Debugf("Seeding: Demo data complete - created %d teams and %d solves", len(createdTeams), totalSolves)
+	logger.Debugf("Solve timestamps spread from %s to %s", startTime.Format(time.RFC3339), now.Format(time.RFC3339))
 	return nil
 }
 
 // CleanDemoData removes all demo teams, users, and their associated data
 func CleanDemoData() error {
-	debug.Println("Cleaning: Demo data...")
+	logger.Debug("Cleaning: Demo data...")
 
 	// Get demo team IDs
 	var demoTeams []models.Team
@@ -541,7 +566,7 @@ func CleanDemoData() error {
 	}
 
 	if len(demoTeams) == 0 {
-		debug.Println("No demo teams found to clean")
+		logger.Debug("No demo teams found to clean")
 		return nil
 	}
 
@@ -552,22 +577,22 @@ func CleanDemoData() error {
 
 	// Delete solves for demo teams
 	if err := DB.Where(queryTeamIDIn, teamIDs).Delete(&models.Solve{}).Error; err != nil {
-		debug.Log("Failed to delete demo solves: %v\n", err)
+		logger.Errorf("Failed to delete demo solves: %v", err)
 	}
 
 	// Delete first bloods for demo teams
 	if err := DB.Where(queryTeamIDIn, teamIDs).Delete(&models.FirstBlood{}).Error; err != nil {
-		debug.Log("Failed to delete demo first bloods: %v\n", err)
+		logger.Errorf("Failed to delete demo first bloods: %v", err)
 	}
 
 	// Delete hint purchases for demo teams
 	if err := DB.Where(queryTeamIDIn, teamIDs).Delete(&models.HintPurchase{}).Error; err != nil {
-		debug.Log("Failed to delete demo hint purchases: %v\n", err)
+		logger.Errorf("Failed to delete demo hint purchases: %v", err)
 	}
 
 	// Delete demo users
 	if err := DB.Where("username LIKE ?", queryDemoUserPattern).Delete(&models.User{}).Error; err != nil {
-		debug.Log("Failed to delete demo users: %v\n", err)
+		logger.Errorf("Failed to delete demo users: %v", err)
 	}
 
 	// Delete demo teams
@@ -575,7 +600,7 @@ func CleanDemoData() error {
 		return fmt.Errorf("failed to delete demo teams: %w", err)
 	}
 
-	debug.Log("Cleaning: Removed %d demo teams and associated data\n", len(demoTeams))
+	logger.Debugf("Cleaning: Removed %d demo teams and associated data", len(demoTeams))
 	return nil
 }
 
@@ -0,0 +1,23 @@
+package config
+
+import (
+	"github.com/pwnthemall/pwnthemall/backend/models"
+)
+
+// GetConfigValue returns the value of a config key, or defaultValue when it is missing or empty
+func GetConfigValue(key, defaultValue string) string {
+	var cfg models.Config
+	if err := DB.Where("key = ?", key).First(&cfg).Error; err != nil || cfg.Value == "" {
+		return defaultValue
+	}
+	return cfg.Value
+}
+
+// GetConfigBool returns whether a config key is set to "true", or defaultValue when it is missing
+func GetConfigBool(key string, defaultValue bool) bool {
+	value := GetConfigValue(key, "")
+	if value == "" {
+		return defaultValue
+	}
+	return value == "true"
+}
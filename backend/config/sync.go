@@ -3,7 +3,7 @@ package config
 import (
 	"os"
 
-	"github.com/pwnthemall/pwnthemall/backend/debug"
+	"github.com/pwnthemall/pwnthemall/backend/logger"
 	"github.com/pwnthemall/pwnthemall/backend/models"
 )
 
@@ -27,12 +27,12 @@ func SynchronizeEnvWithDb() {
 	// }
 	var dockerConfig models.DockerConfig
 	if err := DB.Select("host").Find(&dockerConfig).Error; err != nil {
-		debug.Log("Failed to retrieve host from docker config: %s", err.Error())
+		logger.Errorf("Failed to retrieve host from docker config: %s", err.Error())
 	} else {
 		if err := os.Setenv("DOCKER_HOST", dockerConfig.Host); err != nil {
-			debug.Log("Failed to set env variable DOCKER_HOST: %v", err)
+			logger.Errorf("Failed to set env variable DOCKER_HOST: %v", err)
 		} else {
-			debug.Log("Env variable set from DB: DOCKER_HOST=%s", dockerConfig.Host)
+			logger.Debugf("Env variable set from DB: DOCKER_HOST=%s", dockerConfig.Host)
 		}
 	}
 
@@ -0,0 +1,150 @@
+package controllers
+
+import (
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pwnthemall/pwnthemall/backend/config"
+	"github.com/pwnthemall/pwnthemall/backend/dto"
+	"github.com/pwnthemall/pwnthemall/backend/logger"
+	"github.com/pwnthemall/pwnthemall/backend/models"
+	"github.com/pwnthemall/pwnthemall/backend/utils"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	verifyEmailTokenTTL   = 48 * time.Hour
+	resetPasswordTokenTTL = 1 * time.Hour
+)
+
+// publicLink builds a frontend link from PTA_PUBLIC_DOMAIN, never from the request Host header
+func publicLink(path, token string) string {
+	domain := utils.GetEnvWithDefault("PTA_PUBLIC_DOMAIN", "localhost")
+	return fmt.Sprintf("https://%s%s?token=%s", domain, path, url.QueryEscape(token))
+}
+
+func sendVerificationEmail(user models.User) {
+	token, err := utils.IssueEmailToken(user.ID, utils.EmailTokenVerify, verifyEmailTokenTTL)
+	if err != nil {
+		logger.Errorf("Failed to issue verification token for user %d: %v", user.ID, err)
+		return
+	}
+	siteName := config.GetConfigValue("SITE_NAME", "pwnthemall")
+	err = utils.GetMailer().Send(utils.MailMessage{
+		To:      user.Email,
+		Subject: fmt.Sprintf("[%s] Verify your email address", siteName),
+		Body: fmt.Sprintf("Hello %s,\n\nPlease confirm your email address by opening the link below:\n\n%s\n\nThe link expires in 48 hours.\n",
+			user.Username, publicLink("/verify-email", token)),
+	})
+	if err != nil {
+		logger.Errorf("Failed to send verification email to user %d: %v", user.ID, err)
+	}
+}
+
+func sendPasswordResetEmail(user models.User) {
+	token, err := utils.IssueEmailToken(user.ID, utils.EmailTokenReset, resetPasswordTokenTTL)
+	if err != nil {
+		logger.Errorf("Failed to issue reset token for user %d: %v", user.ID, err)
+		return
+	}
+	siteName := config.GetConfigValue("SITE_NAME", "pwnthemall")
+	err = utils.GetMailer().Send(utils.MailMessage{
+		To:      user.Email,
+		Subject: fmt.Sprintf("[%s] Reset your password", siteName),
+		Body: fmt.Sprintf("Hello %s,\n\nA password reset was requested for your account. Open the link below to choose a new password:\n\n%s\n\nThe link expires in 1 hour. If you did not ask for it, you can ignore this email.\n",
+			user.Username, publicLink("/reset-password", token)),
+	})
+	if err != nil {
+		logger.Errorf("Failed to send reset email to user %d: %v", user.ID, err)
+	}
+}
+
+// VerifyEmail marks the email of the token owner as verified
+func VerifyEmail(c *gin.Context) {
+	var input dto.EmailTokenInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.BadRequestError(c, "invalid_input")
+		return
+	}
+
+	userID, err := utils.ConsumeEmailToken(input.Token, utils.EmailTokenVerify)
+	if err != nil {
+		utils.BadRequestError(c, "invalid_or_expired_token")
+		return
+	}
+
+	if err := config.DB.Model(&models.User{}).Where("id = ?", userID).Update("email_verified", true).Error; err != nil {
+		utils.InternalServerError(c, "email_verification_failed")
+		return
+	}
+	utils.OKResponse(c, gin.H{"message": "email_verified"})
+}
+
+// ResendVerificationEmail sends a new verification link to the current user
+func ResendVerificationEmail(c *gin.Context) {
+	user, ok := utils.GetAuthenticatedUser(c)
+	if !ok {
+		utils.UnauthorizedError(c, "unauthorized")
+		return
+	}
+	if user.EmailVerified {
+		utils.ConflictError(c, "email_already_verified")
+		return
+	}
+
+	go sendVerificationEmail(*user)
+	utils.OKResponse(c, gin.H{"message": "verification_email_sent"})
+}
+
+// ForgotPassword emails a reset link, the answer is the same whether the account exists or not
+func ForgotPassword(c *gin.Context) {
+	var input dto.ForgotPasswordInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.BadRequestError(c, "invalid_input")
+		return
+	}
+
+	var user models.User
+	if err := config.DB.Where("LOWER(email) = LOWER(?)", input.Email).First(&user).Error; err == nil && !user.Banned {
+		go sendPasswordResetEmail(user)
+	}
+
+	utils.OKResponse(c, gin.H{"message": "password_reset_email_sent"})
+}
+
+// ResetPassword sets a new password using a token from ForgotPassword
+func ResetPassword(c *gin.Context) {
+	var input dto.ResetPasswordInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.BadRequestError(c, "Password must be 8-72 characters.")
+		return
+	}
+
+	userID, err := utils.ConsumeEmailToken(input.Token, utils.EmailTokenReset)
+	if err != nil {
+		utils.BadRequestError(c, "invalid_or_expired_token")
+		return
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(input.Password), bcrypt.DefaultCost)
+	if err != nil {
+		utils.InternalServerError(c, "Failed to hash new password")
+		return
+	}
+
+	// Receiving the link also proves the mailbox belongs to the user
+	if err := config.DB.Model(&models.User{}).Where("id = ?", userID).Updates(map[string]interface{}{
+		"password":       string(hashedPassword),
+		"email_verified": true,
+	}).Error; err != nil {
+		utils.InternalServerError(c, "Failed to update password")
+		return
+	}
+	if _, err := utils.RevokeUserSessions(userID, ""); err != nil {
+		utils.InternalServerError(c, "Failed to revoke sessions")
+		return
+	}
+	utils.OKResponse(c, gin.H{"message": "Password updated"})
+}
@@ -0,0 +1,116 @@
+package controllers
+
+import (
+	"slices"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pwnthemall/pwnthemall/backend/config"
+	"github.com/pwnthemall/pwnthemall/backend/dto"
+	"github.com/pwnthemall/pwnthemall/backend/models"
+	"github.com/pwnthemall/pwnthemall/backend/utils"
+)
+
+const maxAPITokensPerUser = 20
+
+// GetAPITokens lists the API tokens of the current user
+func GetAPITokens(c *gin.Context) {
+	user, ok := utils.GetAuthenticatedUser(c)
+	if !ok {
+		utils.UnauthorizedError(c, "unauthorized")
+		return
+	}
+
+	var tokens []models.APIToken
+	if err := config.DB.Where("user_id = ?", user.ID).Order("created_at DESC").Find(&tokens).Error; err != nil {
+		utils.InternalServerError(c, "Failed to fetch API tokens")
+		return
+	}
+	utils.OKResponse(c, gin.H{"tokens": tokens, "availableScopes": availableAPITokenScopes(user)})
+}
+
+// CreateAPIToken creates a token, the raw value is only returned once
+func CreateAPIToken(c *gin.Context) {
+	user, ok := utils.GetAuthenticatedUser(c)
+	if !ok {
+		utils.UnauthorizedError(c, "unauthorized")
+		return
+	}
+
+	var input dto.APITokenInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.BadRequestError(c, "invalid_input")
+		return
+	}
+
+	allowed := availableAPITokenScopes(user)
+	scopes := make([]string, 0, len(input.Scopes))
+	for _, scope := range input.Scopes {
+		if !slices.Contains(allowed, scope) {
+			utils.BadRequestError(c, "invalid_scope")
+			return
+		}
+		if !slices.Contains(scopes, scope) {
+			scopes = append(scopes, scope)
+		}
+	}
+
+	var count int64
+	config.DB.Model(&models.APIToken{}).Where("user_id = ?", user.ID).Count(&count)
+	if count >= maxAPITokensPerUser {
+		utils.BadRequestError(c, "too_many_api_tokens")
+		return
+	}
+
+	raw, hash, err := utils.GenerateAPIToken()
+	if err != nil {
+		utils.InternalServerError(c, "Failed to generate API token")
+		return
+	}
+
+	token := models.APIToken{
+		UserID:    user.ID,
+		Name:      input.Name,
+		Prefix:    raw[:len(utils.APITokenPrefix)+8],
+		TokenHash: hash,
+		Scopes:    scopes,
+	}
+	if input.ExpiresInDays > 0 {
+		expiresAt := time.Now().AddDate(0, 0, input.ExpiresInDays)
+		token.ExpiresAt = &expiresAt
+	}
+	if err := config.DB.Create(&token).Error; err != nil {
+		utils.InternalServerError(c, "Failed to create API token")
+		return
+	}
+
+	utils.CreatedResponse(c, gin.H{"token": raw, "apiToken": token})
+}
+
+// RevokeAPIToken deletes one of the current user's tokens
+func RevokeAPIToken(c *gin.Context) {
+	user, ok := utils.GetAuthenticatedUser(c)
+	if !ok {
+		utils.UnauthorizedError(c, "unauthorized")
+		return
+	}
+
+	result := config.DB.Where("id = ? AND user_id = ?", c.Param("id"), user.ID).Delete(&models.APIToken{})
+	if result.Error != nil {
+		utils.InternalServerError(c, "Failed to revoke API token")
+		return
+	}
+	if result.RowsAffected == 0 {
+		utils.NotFoundError(c, "api_token_not_found")
+		return
+	}
+	utils.OKResponse(c, gin.H{"message": "api_token_revoked"})
+}
+
+// availableAPITokenScopes returns the scopes the user may grant, admin:read is for roles with admin permissions
+func availableAPITokenScopes(user *models.User) []string {
+	if config.HasAdminAccess(user.Role) {
+		return utils.APITokenScopes
+	}
+	return []string{utils.ScopeSubmit, utils.ScopeInstances}
+}
@@ -0,0 +1,156 @@
+package controllers
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pwnthemall/pwnthemall/backend/config"
+	"github.com/pwnthemall/pwnthemall/backend/dto"
+	"github.com/pwnthemall/pwnthemall/backend/logger"
+	"github.com/pwnthemall/pwnthemall/backend/models"
+	"github.com/pwnthemall/pwnthemall/backend/utils"
+	"gorm.io/gorm"
+)
+
+// auditExportBatch is the number of entries loaded at once while streaming the CSV export
+const auditExportBatch = 500
+
+// auditLogQuery applies the filters of the query string to the audit log
+func auditLogQuery(c *gin.Context) (*gorm.DB, string) {
+	query := config.DB.Model(&models.AuditLog{})
+
+	if actorID := c.Query("actorId"); actorID != "" {
+		id, err := strconv.ParseUint(actorID, 10, 64)
+		if err != nil {
+			return nil, "invalid_actor_id"
+		}
+		query = query.Where("actor_id = ?", id)
+	}
+	if actor := c.Query("actor"); actor != "" {
+		query = query.Where("actor_name = ?", actor)
+	}
+	if action := c.Query("action"); action != "" {
+		query = query.Where("action = ?", action)
+	}
+	if targetType := c.Query("targetType"); targetType != "" {
+		query = query.Where("target_type = ?", targetType)
+	}
+	if targetID := c.Query("targetId"); targetID != "" {
+		query = query.Where("target_id = ?", targetID)
+	}
+	if ip := c.Query("ip"); ip != "" {
+		query = query.Where("ip_address = ?", ip)
+	}
+	if from := c.Query("from"); from != "" {
+		t, ok := parseAuditTime(from)
+		if !ok {
+			return nil, "invalid_from"
+		}
+		query = query.Where("created_at >= ?", t)
+	}
+	if to := c.Query("to"); to != "" {
+		t, ok := parseAuditTime(to)
+		if !ok {
+			return nil, "invalid_to"
+		}
+		query = query.Where("created_at <= ?", t)
+	}
+	return query, ""
+}
+
+// parseAuditTime accepts RFC 3339 timestamps and plain dates
+func parseAuditTime(value string) (time.Time, bool) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, true
+	}
+	if t, err := time.Parse("2006-01-02", value); err == nil {
+		return t, true
+	}
+	return time.Time{}, false
+}
+
+// GetAuditLogs lists audit entries, newest first
+func GetAuditLogs(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("pageSize", "50"))
+
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 200 {
+		pageSize = 50
+	}
+
+	query, errKey := auditLogQuery(c)
+	if errKey != "" {
+		utils.BadRequestError(c, errKey)
+		return
+	}
+
+	var total int64
+	query.Count(&total)
+
+	entries := []models.AuditLog{}
+	if err := query.Order("created_at DESC, id DESC").Offset((page - 1) * pageSize).Limit(pageSize).Find(&entries).Error; err != nil {
+		utils.InternalServerError(c, "failed_to_fetch_audit_logs")
+		return
+	}
+
+	totalPages := int(total) / pageSize
+	if int(total)%pageSize > 0 {
+		totalPages++
+	}
+
+	utils.OKResponse(c, dto.AuditLogListResponse{
+		Entries:    entries,
+		Total:      total,
+		Page:       page,
+		PageSize:   pageSize,
+		TotalPages: totalPages,
+	})
+}
+
+// ExportAuditLogs streams the filtered audit log as CSV
+func ExportAuditLogs(c *gin.Context) {
+	query, errKey := auditLogQuery(c)
+	if errKey != "" {
+		utils.BadRequestError(c, errKey)
+		return
+	}
+
+	filename := fmt.Sprintf("pwnthemall-audit-%s.csv", time.Now().UTC().Format("20060102-150405"))
+	c.Header("Content-Type", "text/csv")
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s\"", filename))
+
+	writer := csv.NewWriter(c.Writer)
+	writer.Write([]string{"id", "created_at", "actor_id", "actor_name", "actor_role", "ip_address", "action", "target_type", "target_id", "changes"})
+
+	var entries []models.AuditLog
+	err := query.Order("id").FindInBatches(&entries, auditExportBatch, func(tx *gorm.DB, batch int) error {
+		for _, entry := range entries {
+			changes, _ := json.Marshal(entry.Changes)
+			writer.Write([]string{
+				strconv.FormatUint(uint64(entry.ID), 10),
+				entry.CreatedAt.UTC().Format(time.RFC3339),
+				strconv.FormatUint(uint64(entry.ActorID), 10),
+				utils.SanitizeCSVField(entry.ActorName),
+				entry.ActorRole,
+				entry.IPAddress,
+				entry.Action,
+				entry.TargetType,
+				utils.SanitizeCSVField(entry.TargetID),
+				utils.SanitizeCSVField(string(changes)),
+			})
+		}
+		writer.Flush()
+		return writer.Error()
+	}).Error
+	if err != nil {
+		logger.Ctx(c).Errorf("Failed to export audit log: %v", err)
+	}
+	writer.Flush()
+}
@@ -5,11 +5,11 @@ import (
 	"net/http"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/gin-contrib/sessions"
 	"github.com/gin-gonic/gin"
 	"github.com/go-playground/validator/v10"
-	"github.com/golang-jwt/jwt/v5"
 	"github.com/jinzhu/copier"
 	"github.com/pwnthemall/pwnthemall/backend/config"
 	"github.com/pwnthemall/pwnthemall/backend/dto"
@@ -97,10 +97,13 @@ func Register(c *gin.Context) {
 		return
 	}
 
+	go sendVerificationEmail(user)
+
 	utils.CreatedResponse(c, gin.H{
-		"id":       user.ID,
-		"username": user.Username,
-		"email":    user.Email,
+		"id":                        user.ID,
+		"username":                  user.Username,
+		"email":                     user.Email,
+		"emailVerificationRequired": config.GetConfigBool("REQUIRE_EMAIL_VERIFICATION", false),
 	})
 }
 
@@ -136,14 +139,9 @@ func authenticateUser(usernameOrEmail, password string) (*models.User, error) {
 
 // generateAndSetTokens creates tokens and sets them as cookies
 func generateAndSetTokens(c *gin.Context, userID uint, role string) error {
-	accessToken, err := utils.GenerateAccessToken(userID, role)
-	if err != nil {
-		return fmt.Errorf("could not create access token")
-	}
-
-	refreshToken, err := utils.GenerateRefreshToken(userID)
+	accessToken, refreshToken, err := utils.CreateSession(userID, role, c.Request.UserAgent(), c.ClientIP())
 	if err != nil {
-		return fmt.Errorf("could not create refresh token")
+		return fmt.Errorf("could not create session")
 	}
 
 	c.SetSameSite(http.SameSiteStrictMode)
@@ -178,6 +176,17 @@ func Login(c *gin.Context) {
 		return
 	}
 
+	// Second step required: the session is only issued by LoginTwoFactor
+	if user.TOTPEnabled {
+		preAuthToken, err := utils.GeneratePreAuthToken(user.ID)
+		if err != nil {
+			utils.InternalServerError(c, "could not create pre-auth token")
+			return
+		}
+		utils.OKResponse(c, gin.H{"twoFactorRequired": true, "preAuthToken": preAuthToken})
+		return
+	}
+
 	// Generate and set tokens
 	if err := generateAndSetTokens(c, user.ID, user.Role); err != nil {
 		utils.InternalServerError(c, err.Error())
@@ -194,21 +203,18 @@ func Refresh(c *gin.Context) {
 		return
 	}
 
-	// Check if refresh token is blacklisted
-	if _, blacklisted := utils.TokenBlacklist.Load(tokenStr); blacklisted {
-		utils.UnauthorizedError(c, "token invalidated")
+	claims, err := utils.ParseRefreshToken(tokenStr)
+	if err != nil {
+		utils.UnauthorizedError(c, "invalid refresh token")
 		return
 	}
 
-	token, err := jwt.ParseWithClaims(tokenStr, &jwt.RegisteredClaims{}, func(t *jwt.Token) (interface{}, error) {
-		return utils.RefreshSecret, nil
-	})
-	if err != nil || !token.Valid {
-		utils.UnauthorizedError(c, "invalid refresh token")
+	var session models.UserSession
+	if err := config.DB.Where("jti = ? AND revoked_at IS NULL", claims.ID).First(&session).Error; err != nil {
+		utils.UnauthorizedError(c, "token invalidated")
 		return
 	}
 
-	claims := token.Claims.(*jwt.RegisteredClaims)
 	userID, err := strconv.Atoi(claims.Subject)
 	if err != nil {
 		utils.InternalServerError(c, "can't read subject")
@@ -226,45 +232,28 @@ func Refresh(c *gin.Context) {
 		return
 	}
 
-	accessToken, err := utils.GenerateAccessToken(user.ID, user.Role)
+	accessToken, err := utils.GenerateAccessToken(user.ID, user.Role, session.JTI)
 	if err != nil {
 		utils.InternalServerError(c, "failed to generate access token")
 		return
 	}
 
+	config.DB.Model(&session).Updates(map[string]interface{}{"last_seen_at": time.Now(), "ip_address": c.ClientIP()})
+
 	// Set the new access token as a secure HTTP-only cookie
 	c.SetCookie("access_token", accessToken, 3600, "/", "", true, true) // 1 hour, secure, httpOnly
 
 	utils.OKResponse(c, gin.H{"message": "Token refreshed"})
 }
 
-// Logout clears the user session and blacklists JWT tokens
+// Logout clears the user session and revokes its JWT tokens
 func Logout(c *gin.Context) {
-	// Get tokens before clearing cookies
-	accessToken, _ := c.Cookie("access_token")
-	refreshToken, _ := c.Cookie("refresh_token")
-
-	// Blacklist access token if present
-	if accessToken != "" {
-		token, err := jwt.ParseWithClaims(accessToken, &jwt.RegisteredClaims{}, func(t *jwt.Token) (interface{}, error) {
-			return utils.AccessSecret, nil
-		})
-		if err == nil && token.Valid {
-			if claims, ok := token.Claims.(*jwt.RegisteredClaims); ok && claims.ExpiresAt != nil {
-				utils.BlacklistToken(accessToken, claims.ExpiresAt.Time)
-			}
-		}
-	}
-
-	// Blacklist refresh token if present
-	if refreshToken != "" {
-		token, err := jwt.ParseWithClaims(refreshToken, &jwt.RegisteredClaims{}, func(t *jwt.Token) (interface{}, error) {
-			return utils.RefreshSecret, nil
-		})
-		if err == nil && token.Valid {
-			if claims, ok := token.Claims.(*jwt.RegisteredClaims); ok && claims.ExpiresAt != nil {
-				utils.BlacklistToken(refreshToken, claims.ExpiresAt.Time)
-			}
+	// Revoking the session also refuses every access token issued from it
+	if claims, err := utils.GetClaimsFromCookie(c); err == nil {
+		utils.RevokeToken(claims.ID, claims.ExpiresAt.Time)
+		var session models.UserSession
+		if err := config.DB.Where("jti = ?", claims.SessionID).First(&session).Error; err == nil {
+			utils.RevokeSession(&session)
 		}
 	}
 
@@ -422,6 +411,16 @@ func UpdateCurrentUserPassword(c *gin.Context) {
 		utils.InternalServerError(c, "Failed to update password")
 		return
 	}
+
+	// Other devices are logged out, the current one keeps a fresh session
+	if _, err := utils.RevokeUserSessions(user.ID, ""); err != nil {
+		utils.InternalServerError(c, "Failed to revoke sessions")
+		return
+	}
+	if err := generateAndSetTokens(c, user.ID, user.Role); err != nil {
+		utils.InternalServerError(c, err.Error())
+		return
+	}
 	utils.OKResponse(c, gin.H{"message": "Password updated"})
 }
 
@@ -0,0 +1,68 @@
+package controllers
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pwnthemall/pwnthemall/backend/logger"
+	"github.com/pwnthemall/pwnthemall/backend/utils"
+)
+
+// maxBackupSize limits the size of uploaded event archives (1GB)
+const maxBackupSize = 1 << 30
+
+// ExportEventBackup returns a full event archive as ZIP
+func ExportEventBackup(c *gin.Context) {
+	var buf bytes.Buffer
+	if _, err := utils.ExportEventArchive(&buf); err != nil {
+		logger.Ctx(c).Errorf("Failed to export event archive: %v", err)
+		utils.InternalServerError(c, "backup_export_failed")
+		return
+	}
+
+	utils.RecordAudit(c, "backup.export", "event", nil, nil, nil)
+
+	filename := fmt.Sprintf("pwnthemall-backup-%s.zip", time.Now().UTC().Format("20060102-150405"))
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s\"", filename))
+	c.Data(200, "application/zip", buf.Bytes())
+}
+
+// RestoreEventBackup restores an uploaded event archive into this instance
+func RestoreEventBackup(c *gin.Context) {
+	file, header, err := c.Request.FormFile("file")
+	if err != nil {
+		utils.BadRequestError(c, "file_required")
+		return
+	}
+	defer file.Close()
+
+	if header.Size > maxBackupSize {
+		utils.BadRequestError(c, "file_too_large")
+		return
+	}
+
+	data, err := io.ReadAll(file)
+	if err != nil {
+		utils.BadRequestError(c, "invalid_file")
+		return
+	}
+
+	report, err := utils.RestoreEventArchive(data)
+	if err != nil {
+		logger.Ctx(c).Errorf("Failed to restore event archive: %v", err)
+		if errors.Is(err, utils.ErrEventArchiveTargetNotEmpty) {
+			utils.ConflictError(c, "backup_target_not_empty")
+			return
+		}
+		utils.BadRequestError(c, err.Error())
+		return
+	}
+
+	utils.RecordAudit(c, "backup.restore", "event", nil, nil, gin.H{"file": header.Filename, "counts": report.Counts})
+
+	utils.OKResponse(c, report)
+}
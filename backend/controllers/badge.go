@@ -62,6 +62,8 @@ func CreateBadge(c *gin.Context) {
 		return
 	}
 
+	utils.RecordAudit(c, "badge.create", "badge", badge.ID, nil, badge)
+
 	utils.CreatedResponse(c, badge)
 }
 
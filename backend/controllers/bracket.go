@@ -0,0 +1,163 @@
+package controllers
+
+import (
+	"errors"
+	"slices"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pwnthemall/pwnthemall/backend/config"
+	"github.com/pwnthemall/pwnthemall/backend/dto"
+	"github.com/pwnthemall/pwnthemall/backend/models"
+	"github.com/pwnthemall/pwnthemall/backend/utils"
+	"gorm.io/gorm"
+)
+
+// GetBrackets lists the brackets with the teams they contain
+func GetBrackets(c *gin.Context) {
+	var brackets []models.Bracket
+	if err := config.DB.Preload("Teams").Order("name").Find(&brackets).Error; err != nil {
+		utils.InternalServerError(c, "failed_to_fetch_brackets")
+		return
+	}
+
+	response := make([]dto.BracketResponse, 0, len(brackets))
+	for _, b := range brackets {
+		response = append(response, toBracketResponse(b))
+	}
+	utils.OKResponse(c, response)
+}
+
+// CreateBracket adds an empty bracket
+func CreateBracket(c *gin.Context) {
+	var input dto.BracketInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.BadRequestError(c, "invalid_input")
+		return
+	}
+	input.Name = strings.TrimSpace(input.Name)
+	if input.Name == "" {
+		utils.BadRequestError(c, "invalid_bracket_name")
+		return
+	}
+
+	bracket := models.Bracket{Name: input.Name, Description: input.Description}
+	if err := config.DB.Create(&bracket).Error; err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) || strings.Contains(err.Error(), "duplicate key") {
+			utils.ConflictError(c, "bracket_already_exists")
+			return
+		}
+		utils.InternalServerError(c, "failed_to_create_bracket")
+		return
+	}
+	utils.RecordAudit(c, "bracket.create", "bracket", bracket.ID, nil, bracket)
+	utils.CreatedResponse(c, toBracketResponse(bracket))
+}
+
+// UpdateBracket renames a bracket or changes its description
+func UpdateBracket(c *gin.Context) {
+	var bracket models.Bracket
+	if err := config.DB.Preload("Teams").First(&bracket, c.Param("id")).Error; err != nil {
+		utils.NotFoundError(c, "bracket_not_found")
+		return
+	}
+
+	var input dto.BracketInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.BadRequestError(c, "invalid_input")
+		return
+	}
+	input.Name = strings.TrimSpace(input.Name)
+	if input.Name == "" {
+		utils.BadRequestError(c, "invalid_bracket_name")
+		return
+	}
+
+	before := toBracketResponse(bracket)
+	bracket.Name = input.Name
+	bracket.Description = input.Description
+	if err := config.DB.Omit("Teams").Save(&bracket).Error; err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) || strings.Contains(err.Error(), "duplicate key") {
+			utils.ConflictError(c, "bracket_already_exists")
+			return
+		}
+		utils.InternalServerError(c, "failed_to_update_bracket")
+		return
+	}
+	utils.RecordAudit(c, "bracket.update", "bracket", bracket.ID, before, toBracketResponse(bracket))
+	utils.OKResponse(c, toBracketResponse(bracket))
+}
+
+// SetBracketTeams replaces the teams of a bracket, a team belongs to one bracket at most
+func SetBracketTeams(c *gin.Context) {
+	var bracket models.Bracket
+	if err := config.DB.Preload("Teams").First(&bracket, c.Param("id")).Error; err != nil {
+		utils.NotFoundError(c, "bracket_not_found")
+		return
+	}
+
+	var input dto.BracketTeamsInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.BadRequestError(c, "invalid_input")
+		return
+	}
+	slices.Sort(input.TeamIDs)
+	input.TeamIDs = slices.Compact(input.TeamIDs)
+	if len(input.TeamIDs) > 0 {
+		var count int64
+		config.DB.Model(&models.Team{}).Where("id IN ?", input.TeamIDs).Count(&count)
+		if count != int64(len(input.TeamIDs)) {
+			utils.BadRequestError(c, "team_not_found")
+			return
+		}
+	}
+
+	before := toBracketResponse(bracket)
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.Team{}).Where("bracket_id = ?", bracket.ID).Update("bracket_id", nil).Error; err != nil {
+			return err
+		}
+		if len(input.TeamIDs) == 0 {
+			return nil
+		}
+		return tx.Model(&models.Team{}).Where("id IN ?", input.TeamIDs).Update("bracket_id", bracket.ID).Error
+	})
+	if err != nil {
+		utils.InternalServerError(c, "failed_to_update_bracket")
+		return
+	}
+
+	config.DB.Preload("Teams").First(&bracket, bracket.ID)
+	utils.RecordAudit(c, "bracket.teams", "bracket", bracket.ID, before, toBracketResponse(bracket))
+	utils.OKResponse(c, toBracketResponse(bracket))
+}
+
+// DeleteBracket removes a bracket, its teams are left without one
+func DeleteBracket(c *gin.Context) {
+	var bracket models.Bracket
+	if err := config.DB.Preload("Teams").First(&bracket, c.Param("id")).Error; err != nil {
+		utils.NotFoundError(c, "bracket_not_found")
+		return
+	}
+
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.Team{}).Where("bracket_id = ?", bracket.ID).Update("bracket_id", nil).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&bracket).Error
+	})
+	if err != nil {
+		utils.InternalServerError(c, "failed_to_delete_bracket")
+		return
+	}
+	utils.RecordAudit(c, "bracket.delete", "bracket", bracket.ID, toBracketResponse(bracket), nil)
+	utils.OKResponse(c, gin.H{"message": "bracket_deleted"})
+}
+
+func toBracketResponse(b models.Bracket) dto.BracketResponse {
+	teamIDs := make([]uint, 0, len(b.Teams))
+	for _, t := range b.Teams {
+		teamIDs = append(teamIDs, t.ID)
+	}
+	return dto.BracketResponse{ID: b.ID, Name: b.Name, Description: b.Description, TeamIDs: teamIDs, CreatedAt: b.CreatedAt}
+}
@@ -7,14 +7,16 @@ import (
 	"fmt"
 	"io"
 	"mime/multipart"
+	"net/http"
 	"strconv"
 	"strings"
 
 	"github.com/gin-gonic/gin"
 	"github.com/minio/minio-go/v7"
 	"github.com/pwnthemall/pwnthemall/backend/config"
-	"github.com/pwnthemall/pwnthemall/backend/debug"
 	"github.com/pwnthemall/pwnthemall/backend/dto"
+	"github.com/pwnthemall/pwnthemall/backend/events"
+	"github.com/pwnthemall/pwnthemall/backend/logger"
 	"github.com/pwnthemall/pwnthemall/backend/models"
 	"github.com/pwnthemall/pwnthemall/backend/utils"
 	"gorm.io/gorm/clause"
@@ -24,6 +26,7 @@ const (
 	queryChallengeIDAdmin   = "challenge_id = ?"
 	errChallengeNotFoundMsg = "Challenge not found"
 	queryNameEquals         = "name = ?"
+	maxChallengeImportSize  = 500 << 20 // 500MB
 )
 
 // ExportChallenge downloads the challenge ZIP (files, cover, scripts) from MinIO
@@ -36,25 +39,28 @@ func ExportChallenge(c *gin.Context) {
 		utils.NotFoundError(c, errChallengeNotFoundMsg)
 		return
 	}
+	if _, ok := authorizeChallenge(c, challenge.ID); !ok {
+		return
+	}
 
 	objectName := fmt.Sprintf("challenges/%s.zip", challenge.Slug)
 	obj, err := config.FS.GetObject(context.Background(), bucketChallengeFiles, objectName, minio.GetObjectOptions{})
 
 	// If object doesn't exist, generate it on-the-fly
 	if err != nil || obj == nil {
-		debug.Log("Challenge export not found, generating on-the-fly for %s", challenge.Slug)
+		logger.Ctx(c).Debugf("Challenge export not found, generating on-the-fly for %s", challenge.Slug)
 
 		// Generate ZIP
 		zipBytes, err := createChallengeZipFromDB(challenge.ID)
 		if err != nil {
-			debug.Log("Failed to generate challenge export: %v", err)
+			logger.Ctx(c).Errorf("Failed to generate challenge export: %v", err)
 			utils.InternalServerError(c, "Failed to generate challenge export")
 			return
 		}
 
 		// Upload to MinIO for future use
 		if err := uploadFilesToMinIO(challenge.Slug, zipBytes); err != nil {
-			debug.Log("Failed to cache challenge export: %v", err)
+			logger.Ctx(c).Errorf("Failed to cache challenge export: %v", err)
 		}
 
 		// Stream directly to client
@@ -66,19 +72,19 @@ func ExportChallenge(c *gin.Context) {
 	defer obj.Close()
 
 	if _, err := obj.Stat(); err != nil {
-		debug.Log("Challenge export stat failed %s, regenerating: %v", objectName, err)
+		logger.Ctx(c).Errorf("Challenge export stat failed %s, regenerating: %v", objectName, err)
 
 		// Generate ZIP on-the-fly
 		zipBytes, err := createChallengeZipFromDB(challenge.ID)
 		if err != nil {
-			debug.Log("Failed to generate challenge export: %v", err)
+			logger.Ctx(c).Errorf("Failed to generate challenge export: %v", err)
 			utils.NotFoundError(c, "Challenge export not found")
 			return
 		}
 
 		// Upload to MinIO for future use
 		if err := uploadFilesToMinIO(challenge.Slug, zipBytes); err != nil {
-			debug.Log("Failed to cache challenge export: %v", err)
+			logger.Ctx(c).Errorf("Failed to cache challenge export: %v", err)
 		}
 
 		// Stream directly to client
@@ -92,7 +98,7 @@ func ExportChallenge(c *gin.Context) {
 	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s.zip\"", challenge.Slug))
 
 	if _, err := io.Copy(c.Writer, obj); err != nil {
-		debug.Log("Failed to stream challenge export %s: %v", objectName, err)
+		logger.Ctx(c).Errorf("Failed to stream challenge export %s: %v", objectName, err)
 	}
 }
 
@@ -237,7 +243,7 @@ func CreateChallengeAdmin(c *gin.Context) {
 			utils.BadRequestError(c, "invalid decay formula ID")
 			return
 		}
-		debug.Log("Decay formula '%s' (ID: %d) will be applied to challenge", decayFormula.Name, decayFormula.ID)
+		logger.Ctx(c).Debugf("Decay formula '%s' (ID: %d) will be applied to challenge", decayFormula.Name, decayFormula.ID)
 	}
 
 	// Create challenge
@@ -267,11 +273,16 @@ func CreateChallengeAdmin(c *gin.Context) {
 		return
 	}
 
-	// Create flags (hashed)
+	// Create flags (hashed, regex flags are kept in clear)
 	for _, flagValue := range req.Flags {
-		hashed := utils.HashFlag(flagValue)
+		stored, err := utils.StoredFlagValue(flagValue)
+		if err != nil {
+			tx.Rollback()
+			utils.BadRequestError(c, err.Error())
+			return
+		}
 		flag := models.Flag{
-			Value:       hashed,
+			Value:       stored,
 			ChallengeID: challenge.ID,
 		}
 		if err := tx.Create(&flag).Error; err != nil {
@@ -298,6 +309,12 @@ func CreateChallengeAdmin(c *gin.Context) {
 		}
 	}
 
+	if err := utils.SetChallengeTags(tx, &challenge, req.Tags); err != nil {
+		tx.Rollback()
+		utils.InternalServerError(c, "Failed to set tags")
+		return
+	}
+
 	// Create GeoSpec if geo challenge
 	if req.Type == "geo" {
 		geoSpec := models.GeoSpec{
@@ -321,19 +338,19 @@ func CreateChallengeAdmin(c *gin.Context) {
 
 	// Process cover image if provided
 	if coverFile != nil {
-		debug.Log("Processing cover image for challenge %d: %s", challenge.ID, coverFile.Filename)
+		logger.Ctx(c).Debugf("Processing cover image for challenge %d: %s", challenge.ID, coverFile.Filename)
 
 		// Open the uploaded file
 		file, err := coverFile.Open()
 		if err != nil {
-			debug.Log("Failed to open cover file: %v", err)
+			logger.Ctx(c).Errorf("Failed to open cover file: %v", err)
 		} else {
 			defer file.Close()
 
 			// Read file data
 			fileData, err := io.ReadAll(file)
 			if err != nil {
-				debug.Log("Failed to read cover file: %v", err)
+				logger.Ctx(c).Errorf("Failed to read cover file: %v", err)
 			} else {
 				// Store original in MinIO
 				bucketName := "challenges"
@@ -362,17 +379,17 @@ func CreateChallengeAdmin(c *gin.Context) {
 				)
 
 				if err != nil {
-					debug.Log("Failed to store cover image: %v", err)
+					logger.Ctx(c).Errorf("Failed to store cover image: %v", err)
 				} else {
 					// Process image (resize, validate, etc)
 					processedPath, err := utils.ProcessChallengeCoverImage(context.Background(), challenge.Slug, coverFile.Filename)
 					if err != nil {
-						debug.Log("Failed to process cover image: %v", err)
+						logger.Ctx(c).Errorf("Failed to process cover image: %v", err)
 					} else {
 						// Update challenge with cover path
 						challenge.CoverImg = processedPath
 						config.DB.Model(&challenge).Update("cover_img", processedPath)
-						debug.Log("Cover image processed successfully: %s", processedPath)
+						logger.Ctx(c).Infof("Cover image processed successfully: %s", processedPath)
 					}
 				}
 			}
@@ -381,22 +398,28 @@ func CreateChallengeAdmin(c *gin.Context) {
 
 	// Create and upload challenge ZIP to MinIO for export functionality
 	if zipBytes, err := createChallengeZipFromDB(challenge.ID); err != nil {
-		debug.Log("Failed to create challenge ZIP for export: %v", err)
+		logger.Ctx(c).Errorf("Failed to create challenge ZIP for export: %v", err)
 	} else {
 		if err := uploadFilesToMinIO(challenge.Slug, zipBytes); err != nil {
-			debug.Log("Failed to upload challenge ZIP to MinIO: %v", err)
+			logger.Ctx(c).Errorf("Failed to upload challenge ZIP to MinIO: %v", err)
 		} else {
-			debug.Log("Successfully created and uploaded challenge ZIP for export")
+			logger.Ctx(c).Info("Successfully created and uploaded challenge ZIP for export")
 		}
 	}
 
+	utils.RecordAudit(c, "challenge.create", "challenge", challenge.ID, nil, challenge)
+
 	// Broadcast challenge creation
 	broadcastChallengeUpdate()
 
 	// Reload challenge with associations
 	config.DB.Preload("ChallengeCategory").Preload("ChallengeDifficulty").Preload("ChallengeType").First(&challenge, challenge.ID)
 
-	debug.Log("Created challenge: ID=%d, Slug=%s, Name=%s, Type=%s", challenge.ID, challenge.Slug, challenge.Name, req.Type)
+	if !challenge.Hidden {
+		publishChallengeRelease(challenge)
+	}
+
+	logger.Ctx(c).Debugf("Created challenge: ID=%d, Slug=%s, Name=%s, Type=%s", challenge.ID, challenge.Slug, challenge.Name, req.Type)
 
 	utils.CreatedResponse(c, challenge)
 }
@@ -426,16 +449,22 @@ func updateChallengeFields(challenge *models.Challenge, req *dto.ChallengeAdminU
 	}
 }
 
-// broadcastChallengeUpdate sends WebSocket notification for challenge update
+// publishChallengeRelease publishes a challenge that became visible to players
+func publishChallengeRelease(challenge models.Challenge) {
+	var category models.ChallengeCategory
+	config.DB.First(&category, challenge.ChallengeCategoryID)
+	events.Publish(events.ChallengeReleased{
+		ChallengeID: challenge.ID,
+		Name:        challenge.Name,
+		Slug:        challenge.Slug,
+		Category:    category.Name,
+		Points:      challenge.Points,
+	})
+}
+
+// broadcastChallengeUpdate publishes a challenge update
 func broadcastChallengeUpdate() {
-	if utils.UpdatesHub != nil {
-		if payload, err := json.Marshal(gin.H{
-			"event":  "challenge-category",
-			"action": "challenge_update",
-		}); err == nil {
-			utils.UpdatesHub.SendToAll(payload)
-		}
-	}
+	events.Publish(events.ChallengesChanged{Action: "challenge_update"})
 }
 
 // processHintsFromRequest creates or updates hints based on the request
@@ -445,21 +474,21 @@ func processHintsFromRequest(challengeID uint, hints *[]models.Hint) {
 	}
 
 	for _, hintReq := range *hints {
-		debug.Log("Processing hint: ID=%d, Title=%s, Content=%s, Cost=%d", hintReq.ID, hintReq.Title, hintReq.Content, hintReq.Cost)
+		logger.Debugf("Processing hint: ID=%d, Title=%s, Content=%s, Cost=%d", hintReq.ID, hintReq.Title, hintReq.Content, hintReq.Cost)
 
 		if hintReq.ID > 0 {
 			// Update existing hint
 			var hint models.Hint
-			if err := config.DB.First(&hint, hintReq.ID).Error; err == nil {
+			if err := config.DB.Where(queryChallengeIDAdmin, challengeID).First(&hint, hintReq.ID).Error; err == nil {
 				hint.Title = hintReq.Title
 				hint.Content = hintReq.Content
 				hint.Cost = hintReq.Cost
 				hint.IsActive = hintReq.IsActive
 				hint.AutoActiveAt = hintReq.AutoActiveAt
 				if err := config.DB.Save(&hint).Error; err != nil {
-					debug.Log("Failed to update hint %d: %v", hint.ID, err)
+					logger.Errorf("Failed to update hint %d: %v", hint.ID, err)
 				} else {
-					debug.Log("Successfully updated hint %d", hint.ID)
+					logger.Debugf("Successfully updated hint %d", hint.ID)
 				}
 			}
 		} else if hintReq.Content != "" {
@@ -473,9 +502,9 @@ func processHintsFromRequest(challengeID uint, hints *[]models.Hint) {
 				AutoActiveAt: hintReq.AutoActiveAt,
 			}
 			if err := config.DB.Create(&hint).Error; err != nil {
-				debug.Log("Failed to create hint: %v", err)
+				logger.Errorf("Failed to create hint: %v", err)
 			} else {
-				debug.Log("Successfully created hint: ID=%d, Title=%s", hint.ID, hint.Title)
+				logger.Debugf("Successfully created hint: ID=%d, Title=%s", hint.ID, hint.Title)
 			}
 		}
 	}
@@ -496,6 +525,10 @@ func UpdateChallengeAdmin(c *gin.Context) {
 		utils.NotFoundError(c, errChallengeNotFoundMsg)
 		return
 	}
+	user, ok := authorizeChallenge(c, challenge.ID)
+	if !ok {
+		return
+	}
 
 	var req dto.ChallengeAdminUpdateRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -503,12 +536,14 @@ func UpdateChallengeAdmin(c *gin.Context) {
 		return
 	}
 
+	before := challenge
 	updateChallengeFields(&challenge, &req)
 
 	if err := config.DB.Save(&challenge).Error; err != nil {
 		utils.InternalServerError(c, "Failed to update challenge")
 		return
 	}
+	utils.RecordAudit(c, "challenge.update", "challenge", challenge.ID, before, challenge)
 
 	broadcastChallengeUpdate()
 
@@ -517,28 +552,36 @@ func UpdateChallengeAdmin(c *gin.Context) {
 	cleanupFirstBloodIfDisabled(&req, &challenge)
 
 	processHintsFromRequest(challenge.ID, req.Hints)
+	if req.Hints != nil {
+		utils.RecordAudit(c, "challenge.hints_update", "challenge", challenge.ID, nil, gin.H{"hints": req.Hints})
+	}
 
 	// Reload challenge with associations
 	if err := config.DB.Preload("DecayFormula").Preload("Hints").Preload("FirstBlood").First(&challenge, challenge.ID).Error; err != nil {
-		debug.Log("Failed to reload challenge: %v", err)
+		logger.Ctx(c).Errorf("Failed to reload challenge: %v", err)
 	} else {
-		debug.Log("Reloaded challenge %d with %d hints", challenge.ID, len(challenge.Hints))
+		logger.Ctx(c).Debugf("Reloaded challenge %d with %d hints", challenge.ID, len(challenge.Hints))
 		for i, hint := range challenge.Hints {
-			debug.Log("Hint %d: ID=%d, Title=%s, Content=%s", i, hint.ID, hint.Title, hint.Content)
+			logger.Ctx(c).Debugf("Hint %d: ID=%d, Title=%s, Content=%s", i, hint.ID, hint.Title, hint.Content)
 		}
 	}
 
 	// Create and upload updated challenge ZIP to MinIO for export
 	if zipBytes, err := createChallengeZipFromDB(challenge.ID); err != nil {
-		debug.Log("Failed to create challenge ZIP for export: %v", err)
+		logger.Ctx(c).Errorf("Failed to create challenge ZIP for export: %v", err)
 	} else {
 		if err := uploadFilesToMinIO(challenge.Slug, zipBytes); err != nil {
-			debug.Log("Failed to upload challenge ZIP to MinIO: %v", err)
+			logger.Ctx(c).Errorf("Failed to upload challenge ZIP to MinIO: %v", err)
 		} else {
-			debug.Log("Successfully created and uploaded updated challenge ZIP for export")
+			logger.Ctx(c).Info("Successfully created and uploaded updated challenge ZIP for export")
 		}
 	}
 
+	// First blood identifies a player, only admins see it
+	if !managesAllChallenges(user) {
+		challenge.FirstBlood = nil
+	}
+
 	utils.OKResponse(c, challenge)
 }
 
@@ -550,6 +593,9 @@ func UpdateChallengeGeneralAdmin(c *gin.Context) {
 		utils.NotFoundError(c, errChallengeNotFoundMsg)
 		return
 	}
+	if _, ok := authorizeChallenge(c, challenge.ID); !ok {
+		return
+	}
 
 	var req dto.ChallengeGeneralUpdateRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -557,6 +603,8 @@ func UpdateChallengeGeneralAdmin(c *gin.Context) {
 		return
 	}
 
+	before := challenge
+
 	// Update challenge general fields
 	challenge.Name = req.Name
 	challenge.Description = req.Description
@@ -581,24 +629,28 @@ func UpdateChallengeGeneralAdmin(c *gin.Context) {
 		return
 	}
 
-	// Broadcast category update (challenge modified affects category)
-	if utils.UpdatesHub != nil {
-		if payload, err := json.Marshal(gin.H{
-			"event":  "challenge-category",
-			"action": "challenge_update",
-		}); err == nil {
-			utils.UpdatesHub.SendToAll(payload)
+	if req.Tags != nil {
+		if err := utils.SetChallengeTags(config.DB, &challenge, req.Tags); err != nil {
+			utils.InternalServerError(c, "Failed to update tags")
+			return
 		}
 	}
+	utils.RecordAudit(c, "challenge.update", "challenge", challenge.ID, before, challenge)
+
+	// Challenge modified affects category
+	broadcastChallengeUpdate()
+	if before.Hidden && !challenge.Hidden {
+		publishChallengeRelease(challenge)
+	}
 
 	// Create and upload updated challenge ZIP to MinIO for export functionality
 	if zipBytes, err := createChallengeZipFromDB(challenge.ID); err != nil {
-		debug.Log("Failed to create challenge ZIP for export: %v", err)
+		logger.Ctx(c).Errorf("Failed to create challenge ZIP for export: %v", err)
 	} else {
 		if err := uploadFilesToMinIO(challenge.Slug, zipBytes); err != nil {
-			debug.Log("Failed to upload challenge ZIP to MinIO: %v", err)
+			logger.Ctx(c).Errorf("Failed to upload challenge ZIP to MinIO: %v", err)
 		} else {
-			debug.Log("Successfully created and uploaded updated challenge ZIP for export")
+			logger.Ctx(c).Info("Successfully created and uploaded updated challenge ZIP for export")
 		}
 	}
 
@@ -609,14 +661,21 @@ func GetChallengeAdmin(c *gin.Context) {
 	var challenge models.Challenge
 	id := c.Param("id")
 
-	if err := config.DB.Preload("DecayFormula").Preload("Hints").Preload("FirstBlood").First(&challenge, id).Error; err != nil {
+	if err := config.DB.Preload("DecayFormula").Preload("Hints").Preload("Tags").Preload("FirstBlood").First(&challenge, id).Error; err != nil {
 		utils.NotFoundError(c, errChallengeNotFoundMsg)
 		return
 	}
+	user, ok := authorizeChallenge(c, challenge.ID)
+	if !ok {
+		return
+	}
+	if !managesAllChallenges(user) {
+		challenge.FirstBlood = nil
+	}
 
-	debug.Log("GetChallengeAdmin: Challenge %d has %d hints", challenge.ID, len(challenge.Hints))
+	logger.Ctx(c).Debugf("GetChallengeAdmin: Challenge %d has %d hints", challenge.ID, len(challenge.Hints))
 	for i, hint := range challenge.Hints {
-		debug.Log("Hint %d: ID=%d, Title=%s, Content=%s", i, hint.ID, hint.Title, hint.Content)
+		logger.Ctx(c).Debugf("Hint %d: ID=%d, Title=%s, Content=%s", i, hint.ID, hint.Title, hint.Content)
 	}
 
 	var decayFormulas []models.DecayFormula
@@ -629,14 +688,21 @@ func GetChallengeAdmin(c *gin.Context) {
 		"challenge":             challenge,
 		"decayFormulas":         decayFormulas,
 		"challengeDifficulties": challengeDifficulties,
+		"ratings":               getChallengeRatingSummary(challenge.ID),
 	}
 
 	utils.OKResponse(c, response)
 }
 
 func GetAllChallengesAdmin(c *gin.Context) {
+	user, ok := utils.GetAuthenticatedUser(c)
+	if !ok {
+		utils.UnauthorizedError(c, errUnauthorized)
+		return
+	}
+
 	var challenges []models.Challenge
-	if err := config.DB.Preload("ChallengeCategory").Preload("ChallengeType").Preload("ChallengeDifficulty").Preload("Hints").Find(&challenges).Error; err != nil {
+	if err := config.DB.Scopes(managedChallengesScope(user)).Preload("ChallengeCategory").Preload("ChallengeType").Preload("ChallengeDifficulty").Preload("Hints").Preload("Tags").Find(&challenges).Error; err != nil {
 		utils.InternalServerError(c, err.Error())
 		return
 	}
@@ -645,13 +711,22 @@ func GetAllChallengesAdmin(c *gin.Context) {
 }
 
 func DeleteHint(c *gin.Context) {
-	hintID := c.Param("hintId")
+	var hint models.Hint
+	if err := config.DB.First(&hint, c.Param("hintId")).Error; err != nil {
+		utils.NotFoundError(c, "hint_not_found")
+		return
+	}
+	if _, ok := authorizeChallenge(c, hint.ChallengeID); !ok {
+		return
+	}
 
-	if err := config.DB.Delete(&models.Hint{}, hintID).Error; err != nil {
+	if err := config.DB.Delete(&hint).Error; err != nil {
 		utils.InternalServerError(c, "Failed to delete hint")
 		return
 	}
 
+	utils.RecordAudit(c, "challenge.hint_delete", "hint", hint.ID, hint, nil)
+
 	utils.OKResponse(c, gin.H{"message": "Hint deleted successfully"})
 }
 
@@ -662,19 +737,19 @@ func recalculateChallengePoints(challengeID uint) {
 	// Get the challenge details
 	var challenge models.Challenge
 	if err := config.DB.First(&challenge, challengeID).Error; err != nil {
-		debug.Log("Failed to fetch challenge %d for recalculation: %v", challengeID, err)
+		logger.Errorf("Failed to fetch challenge %d for recalculation: %v", challengeID, err)
 		return
 	}
 
 	// Delete existing FirstBlood entries for this challenge and recreate them
 	if err := config.DB.Where("challenge_id = ?", challengeID).Delete(&models.FirstBlood{}).Error; err != nil {
-		debug.Log("Failed to delete existing FirstBlood entries: %v", err)
+		logger.Errorf("Failed to delete existing FirstBlood entries: %v", err)
 	}
 
 	// Get all solves for this challenge, ordered by creation time
 	var solves []models.Solve
 	if err := config.DB.Where("challenge_id = ?", challengeID).Order("created_at ASC").Find(&solves).Error; err != nil {
-		debug.Log("Failed to fetch solves for challenge %d: %v", challengeID, err)
+		logger.Errorf("Failed to fetch solves for challenge %d: %v", challengeID, err)
 		return
 	}
 
@@ -704,7 +779,7 @@ func recalculateChallengePoints(challengeID uint) {
 				}
 
 				if err := config.DB.Create(&firstBlood).Error; err != nil {
-					debug.Log("Failed to recreate FirstBlood entry: %v", err)
+					logger.Errorf("Failed to recreate FirstBlood entry: %v", err)
 				}
 			}
 		}
@@ -714,11 +789,89 @@ func recalculateChallengePoints(challengeID uint) {
 		if solve.Points != newPointsWithBonus {
 			solve.Points = newPointsWithBonus
 			if err := config.DB.Save(&solve).Error; err != nil {
-				debug.Log("Failed to update solve for team %d, challenge %d: %v", solve.TeamID, solve.ChallengeID, err)
+				logger.Errorf("Failed to update solve for team %d, challenge %d: %v", solve.TeamID, solve.ChallengeID, err)
 			} else {
-				debug.Log("Updated solve points for team %d, challenge %d: %d -> %d (decay: %d, firstblood: %d)",
+				logger.Debugf("Updated solve points for team %d, challenge %d: %d -> %d (decay: %d, firstblood: %d)",
 					solve.TeamID, solve.ChallengeID, solve.Points, newPointsWithBonus, newPoints, firstBloodBonus)
 			}
 		}
 	}
 }
+
+// ImportChallengesAdmin imports many challenges from a ZIP of slug/chall.yml folders
+func ImportChallengesAdmin(c *gin.Context) {
+	file, header, err := c.Request.FormFile("file")
+	if err != nil {
+		utils.BadRequestError(c, "file_required")
+		return
+	}
+	defer file.Close()
+
+	if header.Size > maxChallengeImportSize {
+		utils.BadRequestError(c, "file_too_large")
+		return
+	}
+
+	data, err := io.ReadAll(file)
+	if err != nil {
+		utils.BadRequestError(c, "invalid_file")
+		return
+	}
+
+	dryRun := c.Query("dryRun") == "true"
+	results, valid, err := utils.ImportChallengesFromZip(c.Request.Context(), data, dryRun, utils.UpdatesHub)
+	if err != nil {
+		utils.BadRequestError(c, err.Error())
+		return
+	}
+
+	if !valid {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "import_validation_failed", "results": results})
+		return
+	}
+
+	if !dryRun {
+		utils.RecordAudit(c, "challenge.import", "challenge", nil, nil, gin.H{"file": header.Filename, "results": results})
+	}
+
+	utils.OKResponse(c, gin.H{"dryRun": dryRun, "results": results})
+}
+
+// ImportCTFdAdmin imports challenges, users, teams and solves from a CTFd export ZIP
+// CTFd admins only keep their role with ?keepAdmins=true
+func ImportCTFdAdmin(c *gin.Context) {
+	file, header, err := c.Request.FormFile("file")
+	if err != nil {
+		utils.BadRequestError(c, "file_required")
+		return
+	}
+	defer file.Close()
+
+	if header.Size > maxChallengeImportSize {
+		utils.BadRequestError(c, "file_too_large")
+		return
+	}
+
+	data, err := io.ReadAll(file)
+	if err != nil {
+		utils.BadRequestError(c, "invalid_file")
+		return
+	}
+
+	keepAdmins := c.Query("keepAdmins") == "true"
+	report, err := utils.ImportCTFdExport(c.Request.Context(), data, keepAdmins, utils.UpdatesHub)
+	if report != nil {
+		utils.RecordAudit(c, "challenge.import_ctfd", "challenge", nil, nil, gin.H{"file": header.Filename, "keepAdmins": keepAdmins, "counts": report.Counts})
+	}
+	if err != nil {
+		logger.Ctx(c).Errorf("CTFd import failed: %v", err)
+		if report == nil {
+			utils.BadRequestError(c, err.Error())
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "ctfd_import_failed", "report": report})
+		return
+	}
+
+	utils.OKResponse(c, report)
+}
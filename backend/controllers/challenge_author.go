@@ -0,0 +1,194 @@
+package controllers
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/pwnthemall/pwnthemall/backend/config"
+	"github.com/pwnthemall/pwnthemall/backend/dto"
+	"github.com/pwnthemall/pwnthemall/backend/models"
+	"github.com/pwnthemall/pwnthemall/backend/utils"
+	"gorm.io/gorm"
+)
+
+// managesAllChallenges reports whether the role of the user manages every challenge, not only the ones they author
+func managesAllChallenges(user *models.User) bool {
+	ok, err := config.Enforce(user.Role, "/admin/challenges", "write")
+	return err == nil && ok
+}
+
+// canManageChallenge reports whether the user may manage the challenge, admins manage all of them
+func canManageChallenge(user *models.User, challengeID uint) bool {
+	if managesAllChallenges(user) {
+		return true
+	}
+	var count int64
+	config.DB.Model(&models.ChallengeAuthor{}).Where("challenge_id = ? AND user_id = ?", challengeID, user.ID).Count(&count)
+	return count > 0
+}
+
+// authorizeChallenge answers 404 when the current user cannot manage the challenge
+// so authors cannot probe which other challenges exist
+func authorizeChallenge(c *gin.Context, challengeID uint) (*models.User, bool) {
+	user, ok := utils.GetAuthenticatedUser(c)
+	if !ok || !canManageChallenge(user, challengeID) {
+		utils.NotFoundError(c, errChallengeNotFoundMsg)
+		return nil, false
+	}
+	return user, true
+}
+
+// managedChallengesScope restricts a challenge query to the ones the user manages
+func managedChallengesScope(user *models.User) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if managesAllChallenges(user) {
+			return db
+		}
+		owned := config.DB.Model(&models.ChallengeAuthor{}).Select("challenge_id").Where("user_id = ?", user.ID)
+		return db.Where("challenges.id IN (?)", owned)
+	}
+}
+
+// GetChallengeAuthors lists the users allowed to manage a challenge
+func GetChallengeAuthors(c *gin.Context) {
+	var challenge models.Challenge
+	if err := config.DB.First(&challenge, c.Param("id")).Error; err != nil {
+		utils.NotFoundError(c, errChallengeNotFoundMsg)
+		return
+	}
+
+	authors := []dto.ChallengeAuthorUser{}
+	config.DB.Table("challenge_authors").
+		Select("users.id, users.username, users.role").
+		Joins("JOIN users ON users.id = challenge_authors.user_id").
+		Where("challenge_authors.challenge_id = ?", challenge.ID).
+		Order("users.username").
+		Scan(&authors)
+
+	utils.OKResponse(c, authors)
+}
+
+// SetChallengeAuthors replaces the users allowed to manage a challenge
+func SetChallengeAuthors(c *gin.Context) {
+	var challenge models.Challenge
+	if err := config.DB.First(&challenge, c.Param("id")).Error; err != nil {
+		utils.NotFoundError(c, errChallengeNotFoundMsg)
+		return
+	}
+
+	var input dto.ChallengeAuthorsInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.BadRequestError(c, err.Error())
+		return
+	}
+
+	var before []uint
+	config.DB.Model(&models.ChallengeAuthor{}).Where("challenge_id = ?", challenge.ID).Order("user_id").Pluck("user_id", &before)
+
+	var users []models.User
+	if len(input.UserIDs) > 0 {
+		if err := config.DB.Where("id IN ?", input.UserIDs).Find(&users).Error; err != nil {
+			utils.InternalServerError(c, "challenge_authors_update_failed")
+			return
+		}
+	}
+	if len(users) != len(uniqueIDs(input.UserIDs)) {
+		utils.BadRequestError(c, "user_not_found")
+		return
+	}
+
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("challenge_id = ?", challenge.ID).Delete(&models.ChallengeAuthor{}).Error; err != nil {
+			return err
+		}
+		for _, user := range users {
+			if err := tx.Create(&models.ChallengeAuthor{ChallengeID: challenge.ID, UserID: user.ID}).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		utils.InternalServerError(c, "challenge_authors_update_failed")
+		return
+	}
+
+	after := make([]uint, 0, len(users))
+	for _, user := range users {
+		after = append(after, user.ID)
+	}
+	utils.RecordAudit(c, "challenge.authors_update", "challenge", challenge.ID, gin.H{"authors": before}, gin.H{"authors": after})
+
+	GetChallengeAuthors(c)
+}
+
+// TestChallengeFlag checks a flag against a challenge without recording a submission or a solve
+func TestChallengeFlag(c *gin.Context) {
+	var challenge models.Challenge
+	if err := config.DB.Preload("Flags").Preload("ChallengeType").First(&challenge, c.Param("id")).Error; err != nil {
+		utils.NotFoundError(c, errChallengeNotFoundMsg)
+		return
+	}
+	if _, ok := authorizeChallenge(c, challenge.ID); !ok {
+		return
+	}
+
+	var inputRaw map[string]interface{}
+	if err := c.ShouldBindJSON(&inputRaw); err != nil {
+		utils.BadRequestError(c, errInvalidInput)
+		return
+	}
+
+	submittedValue := extractSubmittedValue(inputRaw)
+	utils.OKResponse(c, gin.H{"correct": validateFlagSubmission(inputRaw, challenge, submittedValue)})
+}
+
+// GetAuthorDashboard returns solve statistics of the challenges managed by the current user
+func GetAuthorDashboard(c *gin.Context) {
+	user, ok := utils.GetAuthenticatedUser(c)
+	if !ok {
+		utils.UnauthorizedError(c, errUnauthorized)
+		return
+	}
+
+	var challenges []models.Challenge
+	if err := config.DB.Scopes(managedChallengesScope(user)).Preload("ChallengeCategory").Order("challenges.name").Find(&challenges).Error; err != nil {
+		utils.InternalServerError(c, "author_dashboard_failed")
+		return
+	}
+
+	stats := make([]dto.AuthorChallengeStats, 0, len(challenges))
+	for _, challenge := range challenges {
+		entry := dto.AuthorChallengeStats{
+			ID:      challenge.ID,
+			Name:    challenge.Name,
+			Hidden:  challenge.Hidden,
+			Points:  challenge.Points,
+			Ratings: getChallengeRatingSummary(challenge.ID),
+		}
+		if challenge.ChallengeCategory != nil {
+			entry.Category = challenge.ChallengeCategory.Name
+		}
+
+		config.DB.Model(&models.Solve{}).Where("challenge_id = ?", challenge.ID).Count(&entry.Solves)
+		var firstSolve models.Solve
+		if entry.Solves > 0 && config.DB.Where("challenge_id = ?", challenge.ID).Order("created_at").First(&firstSolve).Error == nil {
+			entry.FirstSolveAt = &firstSolve.CreatedAt
+		}
+		config.DB.Model(&models.Submission{}).Where("challenge_id = ?", challenge.ID).Count(&entry.Attempts)
+
+		stats = append(stats, entry)
+	}
+
+	utils.OKResponse(c, stats)
+}
+
+func uniqueIDs(ids []uint) []uint {
+	seen := make(map[uint]bool, len(ids))
+	unique := make([]uint, 0, len(ids))
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+	return unique
+}
@@ -16,11 +16,12 @@ import (
 	"github.com/jinzhu/copier"
 	"github.com/minio/minio-go/v7"
 	"github.com/pwnthemall/pwnthemall/backend/config"
-	"github.com/pwnthemall/pwnthemall/backend/debug"
 	"github.com/pwnthemall/pwnthemall/backend/dto"
+	"github.com/pwnthemall/pwnthemall/backend/logger"
 	"github.com/pwnthemall/pwnthemall/backend/models"
 	"github.com/pwnthemall/pwnthemall/backend/utils"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // getSolvedChallengeIds retrieves all challenge IDs solved by a team
@@ -75,7 +76,7 @@ func processHintsWithPurchaseStatus(hints []models.Hint, purchasedHintIds []uint
 	var hintsWithPurchased []dto.HintWithPurchased
 	for _, hint := range hints {
 		if !hint.IsActive && userRole != "admin" {
-			debug.Log("Skipping inactive hint ID %d for non-admin user", hint.ID)
+			logger.Debugf("Skipping inactive hint ID %d for non-admin user", hint.ID)
 			continue
 		}
 		var hintWithPurchased dto.HintWithPurchased
@@ -167,7 +168,41 @@ func CheckChallengeDependancies(c *gin.Context, challenge models.Challenge) bool
 	return true
 }
 
-// GetChallenges returns all visible challenges
+// applyChallengeFilters narrows a challenge query with the tag, author, category, difficulty, solved and q parameters
+func applyChallengeFilters(c *gin.Context, query *gorm.DB, user *models.User) *gorm.DB {
+	for _, tag := range utils.NormalizeTagNames(strings.Split(strings.Join(c.QueryArray("tag"), ","), ",")) {
+		query = query.Where("challenges.id IN (?)", config.DB.Table("challenge_tags").
+			Select("challenge_tags.challenge_id").
+			Joins("JOIN tags ON tags.id = challenge_tags.tag_id").
+			Where("tags.name = ?", tag))
+	}
+	if author := strings.TrimSpace(c.Query("author")); author != "" {
+		query = query.Where("LOWER(challenges.author) = LOWER(?)", author)
+	}
+	if category := strings.TrimSpace(c.Query("category")); category != "" {
+		query = query.Where("challenges.challenge_category_id IN (?)", config.DB.Model(&models.ChallengeCategory{}).
+			Select("id").Where("LOWER(name) = LOWER(?)", category))
+	}
+	if difficulty := strings.TrimSpace(c.Query("difficulty")); difficulty != "" {
+		query = query.Where("challenges.challenge_difficulty_id IN (?)", config.DB.Model(&models.ChallengeDifficulty{}).
+			Select("id").Where("LOWER(name) = LOWER(?)", difficulty))
+	}
+	if solved := c.Query("solved"); (solved == "true" || solved == "false") && user.Team != nil {
+		solvedIds := config.DB.Model(&models.Solve{}).Select("challenge_id").Where("team_id = ?", user.Team.ID)
+		if solved == "true" {
+			query = query.Where("challenges.id IN (?)", solvedIds)
+		} else {
+			query = query.Where("challenges.id NOT IN (?)", solvedIds)
+		}
+	}
+	if search := strings.TrimSpace(c.Query("q")); search != "" {
+		query = query.Where(config.ChallengeSearchVector+" @@ websearch_to_tsquery('simple', ?)", search).
+			Order(clause.Expr{SQL: "ts_rank(" + config.ChallengeSearchVector + ", websearch_to_tsquery('simple', ?)) DESC", Vars: []interface{}{search}})
+	}
+	return query.Order("challenges.\"order\" ASC, challenges.id ASC")
+}
+
+// GetChallenges returns all visible challenges, optionally filtered by tag, author, difficulty, solved state or search query
 func GetChallenges(c *gin.Context) {
 	userI, _ := c.Get("user")
 	user, ok := userI.(*models.User)
@@ -183,7 +218,9 @@ func GetChallenges(c *gin.Context) {
 		Preload("ChallengeType").
 		Preload("DecayFormula").
 		Preload("Hints").
-		Where("hidden = false").
+		Preload("Tags")
+	result = applyChallengeFilters(c, result, user).
+		Where("challenges.hidden = false").
 		Find(&challenges)
 	if result.Error != nil {
 		utils.InternalServerError(c, result.Error.Error())
@@ -218,6 +255,45 @@ func GetChallenges(c *gin.Context) {
 	utils.OKResponse(c, challengesWithSolved)
 }
 
+// GetChallengeTags returns the tags used by visible challenges, locked ones are left out like in CheckChallengeDependancies
+func GetChallengeTags(c *gin.Context) {
+	userI, _ := c.Get("user")
+	user, ok := userI.(*models.User)
+	if !ok {
+		utils.InternalServerError(c, "user_wrong_type")
+		return
+	}
+
+	visible := config.DB.Table("challenge_tags").
+		Select("challenge_tags.tag_id").
+		Joins("JOIN challenges ON challenges.id = challenge_tags.challenge_id").
+		Where("challenges.hidden = false")
+	if user.Role != "admin" {
+		var solvedNames []string
+		if user.Team != nil {
+			config.DB.Table("challenges").
+				Joins("JOIN solves ON solves.challenge_id = challenges.id").
+				Where("solves.team_id = ?", user.Team.ID).
+				Pluck("challenges.name", &solvedNames)
+		}
+		if len(solvedNames) > 0 {
+			visible = visible.Where("COALESCE(challenges.depends_on, '') = '' OR challenges.depends_on IN ?", solvedNames)
+		} else {
+			visible = visible.Where("COALESCE(challenges.depends_on, '') = ''")
+		}
+	}
+
+	var tags []models.Tag
+	if err := config.DB.
+		Where("id IN (?)", visible).
+		Order("name ASC").
+		Find(&tags).Error; err != nil {
+		utils.InternalServerError(c, err.Error())
+		return
+	}
+	utils.OKResponse(c, tags)
+}
+
 // GetChallenge returns a single challenge by ID
 func GetChallenge(c *gin.Context) {
 	var challenge models.Challenge
@@ -229,6 +305,7 @@ func GetChallenge(c *gin.Context) {
 		Preload("ChallengeType").
 		Preload("DecayFormula").
 		Preload("Hints").
+		Preload("Tags").
 		First(&challenge, id)
 	if result.Error != nil {
 		utils.NotFoundError(c, "challenge_not_found")
@@ -294,6 +371,7 @@ func GetChallengesByCategoryName(c *gin.Context) {
 		Preload("ChallengeDifficulty").
 		Preload("DecayFormula").
 		Preload("Hints").
+		Preload("Tags").
 		Joins("JOIN challenge_categories ON challenge_categories.id = challenges.challenge_category_id").
 		Where("challenge_categories.name = ? and hidden = false", categoryName).
 		Order("challenges.\"order\" ASC, challenges.id ASC").
@@ -465,9 +543,6 @@ func parseAndValidateMeta(metaStr string) (*models.Challenge, error) {
 
 // createChallengeZipFromDB exports challenge as ZIP (chall.yml + files, flags excluded)
 func createChallengeZipFromDB(challengeID uint) ([]byte, error) {
-	var buf bytes.Buffer
-	zipWriter := zip.NewWriter(&buf)
-
 	// Fetch challenge with all associations
 	var challenge models.Challenge
 	if err := config.DB.
@@ -475,53 +550,13 @@ func createChallengeZipFromDB(challengeID uint) ([]byte, error) {
 		Preload("ChallengeDifficulty").
 		Preload("ChallengeType").
 		Preload("Hints").
+		Preload("Tags").
 		Preload("DecayFormula").
 		First(&challenge, challengeID).Error; err != nil {
-		zipWriter.Close()
 		return nil, fmt.Errorf("failed to fetch challenge: %w", err)
 	}
 
-	// Create chall.yml file (without flags for security)
-	challYml, err := zipWriter.Create("chall.yml")
-	if err != nil {
-		zipWriter.Close()
-		return nil, fmt.Errorf("failed to create chall.yml: %w", err)
-	}
-
-	// Generate YAML content
-	yamlContent := utils.GenerateChallengeYAML(challenge)
-	if _, err := challYml.Write([]byte(yamlContent)); err != nil {
-		zipWriter.Close()
-		return nil, fmt.Errorf("failed to write chall.yml: %w", err)
-	}
-
-	// Try to get files from the challenge ZIP in MinIO (new system)
-	filesAdded := 0
-	zipData, err := utils.GetChallengeZipFromMinIO(bucketChallengeFiles, challenge.Slug)
-	if err == nil {
-		// Extract and copy files from ZIP
-		count, err := utils.CopyZipFilesToWriter(zipData, zipWriter)
-		if err != nil {
-			debug.Log("Failed to copy files from ZIP: %v", err)
-		} else {
-			filesAdded = count
-		}
-	} else {
-		debug.Log("Challenge ZIP not found: %v", err)
-	}
-
-	// Fallback: Try to copy individual files from old system
-	if filesAdded == 0 {
-		debug.Log("Trying to get files from old system for challenge %s", challenge.Slug)
-		filesAdded = utils.CopyIndividualFilesFromMinIO("challenges", challenge.Slug, challenge.Files, zipWriter)
-	}
-
-	// Finalize ZIP
-	if err := zipWriter.Close(); err != nil {
-		return nil, fmt.Errorf("failed to close zip writer: %w", err)
-	}
-
-	return buf.Bytes(), nil
+	return utils.BuildChallengeBundle(challenge)
 }
 
 func uploadFilesToMinIO(challengeSlug string, zipBytes []byte) error {
@@ -597,6 +632,8 @@ func CreateChallenge(c *gin.Context) {
 		}
 	}
 
+	utils.RecordAudit(c, "challenge.create", "challenge", challenge.ID, nil, *challenge)
+
 	utils.CreatedResponse(c, *challenge)
 }
 
@@ -1,12 +1,11 @@
 package controllers
 
 import (
-	"encoding/json"
-
 	"github.com/gin-gonic/gin"
 	"github.com/jinzhu/copier"
 	"github.com/pwnthemall/pwnthemall/backend/config"
 	"github.com/pwnthemall/pwnthemall/backend/dto"
+	"github.com/pwnthemall/pwnthemall/backend/events"
 	"github.com/pwnthemall/pwnthemall/backend/models"
 	"github.com/pwnthemall/pwnthemall/backend/utils"
 )
@@ -63,15 +62,9 @@ func CreateChallengeCategory(c *gin.Context) {
 		return
 	}
 
-	// Broadcast category update to all connected clients
-	if utils.UpdatesHub != nil {
-		if payload, err := json.Marshal(gin.H{
-			"event":  "challenge-category",
-			"action": "create",
-		}); err == nil {
-			utils.UpdatesHub.SendToAll(payload)
-		}
-	}
+	utils.RecordAudit(c, "category.create", "challenge_category", challengeCategory.ID, nil, challengeCategory)
+
+	events.Publish(events.ChallengesChanged{Action: "create"})
 
 	utils.CreatedResponse(c, gin.H{
 		"id":   challengeCategory.ID,
@@ -95,18 +88,12 @@ func UpdateChallengeCategory(c *gin.Context) {
 		return
 	}
 
+	before := challengeCategory
 	challengeCategory.Name = input.Name
 	config.DB.Save(&challengeCategory)
+	utils.RecordAudit(c, "category.update", "challenge_category", challengeCategory.ID, before, challengeCategory)
 
-	// Broadcast category update to all connected clients
-	if utils.UpdatesHub != nil {
-		if payload, err := json.Marshal(gin.H{
-			"event":  "challenge-category",
-			"action": "update",
-		}); err == nil {
-			utils.UpdatesHub.SendToAll(payload)
-		}
-	}
+	events.Publish(events.ChallengesChanged{Action: "update"})
 	var safeChallengeCategory dto.ChallengeCategory
 	copier.Copy(&safeChallengeCategory, &challengeCategory)
 	utils.OKResponse(c, safeChallengeCategory)
@@ -122,16 +109,9 @@ func DeleteChallengeCategory(c *gin.Context) {
 	}
 
 	config.DB.Delete(&challengeCategory)
+	utils.RecordAudit(c, "category.delete", "challenge_category", challengeCategory.ID, challengeCategory, nil)
 
-	// Broadcast category update to all connected clients
-	if utils.UpdatesHub != nil {
-		if payload, err := json.Marshal(gin.H{
-			"event":  "challenge-category",
-			"action": "delete",
-		}); err == nil {
-			utils.UpdatesHub.SendToAll(payload)
-		}
-	}
+	events.Publish(events.ChallengesChanged{Action: "delete"})
 
 	utils.OKResponse(c, gin.H{"message": "challenge_category_deleted"})
 }
@@ -169,5 +149,7 @@ func ReorderChallenges(c *gin.Context) {
 		}
 	}
 
+	utils.RecordAudit(c, "category.reorder", "challenge_category", category.ID, nil, gin.H{"challengeIds": req.ChallengeIDs})
+
 	utils.OKResponse(c, gin.H{"message": "challenges_reordered_successfully"})
 }
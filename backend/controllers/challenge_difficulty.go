@@ -7,6 +7,7 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/pwnthemall/pwnthemall/backend/config"
 	"github.com/pwnthemall/pwnthemall/backend/models"
+	"github.com/pwnthemall/pwnthemall/backend/utils"
 )
 
 // GetChallengeDifficulties returns all challenge difficulties
@@ -41,6 +42,8 @@ func CreateChallengeDifficulty(c *gin.Context) {
 		return
 	}
 
+	utils.RecordAudit(c, "difficulty.create", "challenge_difficulty", difficulty.ID, nil, difficulty)
+
 	c.JSON(http.StatusCreated, difficulty)
 }
 
@@ -68,6 +71,7 @@ func UpdateChallengeDifficulty(c *gin.Context) {
 		return
 	}
 
+	before := difficulty
 	difficulty.Name = input.Name
 	difficulty.Color = input.Color
 
@@ -76,6 +80,8 @@ func UpdateChallengeDifficulty(c *gin.Context) {
 		return
 	}
 
+	utils.RecordAudit(c, "difficulty.update", "challenge_difficulty", difficulty.ID, before, difficulty)
+
 	c.JSON(http.StatusOK, difficulty)
 }
 
@@ -98,5 +104,7 @@ func DeleteChallengeDifficulty(c *gin.Context) {
 		return
 	}
 
+	utils.RecordAudit(c, "difficulty.delete", "challenge_difficulty", difficulty.ID, difficulty, nil)
+
 	c.JSON(http.StatusOK, gin.H{"message": "Difficulty deleted"})
 }
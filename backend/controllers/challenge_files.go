@@ -11,7 +11,7 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/minio/minio-go/v7"
 	"github.com/pwnthemall/pwnthemall/backend/config"
-	"github.com/pwnthemall/pwnthemall/backend/debug"
+	"github.com/pwnthemall/pwnthemall/backend/logger"
 	"github.com/pwnthemall/pwnthemall/backend/meta"
 	"github.com/pwnthemall/pwnthemall/backend/models"
 	"github.com/pwnthemall/pwnthemall/backend/utils"
@@ -22,7 +22,7 @@ func GetChallengeFiles(c *gin.Context) {
 	challengeID := c.Param("id")
 	var challenge models.Challenge
 	if err := config.DB.First(&challenge, challengeID).Error; err != nil {
-		debug.Log("Challenge not found: %v", err)
+		logger.Ctx(c).Debugf("Challenge not found: %v", err)
 		utils.NotFoundError(c, "challenge_not_found")
 		return
 	}
@@ -42,14 +42,14 @@ func GetChallengeFiles(c *gin.Context) {
 	for _, fileName := range challenge.Files {
 		cleanPath := filepath.Clean(fileName)
 		if strings.HasPrefix(cleanPath, "..") || filepath.IsAbs(cleanPath) {
-			debug.Log("Invalid file path skipped: %s", fileName)
+			logger.Ctx(c).Debugf("Invalid file path skipped: %s", fileName)
 			continue
 		}
 
 		objectPath := fmt.Sprintf("%s/%s", challenge.Slug, cleanPath)
 		obj, err := config.FS.StatObject(context.Background(), bucketName, objectPath, minio.StatObjectOptions{})
 		if err != nil {
-			debug.Log("File not found in MinIO: %s, error: %v", objectPath, err)
+			logger.Ctx(c).Errorf("File not found in MinIO: %s, error: %v", objectPath, err)
 			continue
 		}
 
@@ -73,7 +73,7 @@ func DownloadChallengeFile(c *gin.Context) {
 	filename := c.Param("filename")
 	var challenge models.Challenge
 	if err := config.DB.First(&challenge, challengeID).Error; err != nil {
-		debug.Log("Challenge not found: %v", err)
+		logger.Ctx(c).Debugf("Challenge not found: %v", err)
 		utils.NotFoundError(c, "challenge_not_found")
 		return
 	}
@@ -92,14 +92,14 @@ func DownloadChallengeFile(c *gin.Context) {
 	}
 
 	if !fileFound {
-		debug.Log("File %s not found in challenge %s file list", filename, challengeID)
+		logger.Ctx(c).Debugf("File %s not found in challenge %s file list", filename, challengeID)
 		utils.NotFoundError(c, "file_not_found")
 		return
 	}
 
 	cleanPath := filepath.Clean(matchedFile)
 	if strings.HasPrefix(cleanPath, "..") || filepath.IsAbs(cleanPath) {
-		debug.Log("Invalid file path: %s", matchedFile)
+		logger.Ctx(c).Debugf("Invalid file path: %s", matchedFile)
 		utils.BadRequestError(c, "invalid_file_path")
 		return
 	}
@@ -109,7 +109,7 @@ func DownloadChallengeFile(c *gin.Context) {
 
 	object, err := config.FS.GetObject(context.Background(), bucketName, objectPath, minio.GetObjectOptions{})
 	if err != nil {
-		debug.Log("Failed to get object from MinIO: %s, error: %v", objectPath, err)
+		logger.Ctx(c).Errorf("Failed to get object from MinIO: %s, error: %v", objectPath, err)
 		utils.InternalServerError(c, "file_download_failed")
 		return
 	}
@@ -117,7 +117,7 @@ func DownloadChallengeFile(c *gin.Context) {
 
 	objInfo, err := object.Stat()
 	if err != nil {
-		debug.Log("Failed to stat object: %s, error: %v", objectPath, err)
+		logger.Ctx(c).Errorf("Failed to stat object: %s, error: %v", objectPath, err)
 		utils.InternalServerError(c, "file_download_failed")
 		return
 	}
@@ -132,7 +132,7 @@ func DownloadChallengeFile(c *gin.Context) {
 	c.Header("Content-Length", fmt.Sprintf("%d", objInfo.Size))
 
 	if _, err := io.Copy(c.Writer, object); err != nil {
-		debug.Log("Failed to stream file: %v", err)
+		logger.Ctx(c).Errorf("Failed to stream file: %v", err)
 		return
 	}
 }
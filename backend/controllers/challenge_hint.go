@@ -1,12 +1,9 @@
 package controllers
 
 import (
-	"encoding/json"
-	"time"
-
 	"github.com/gin-gonic/gin"
 	"github.com/pwnthemall/pwnthemall/backend/config"
-	"github.com/pwnthemall/pwnthemall/backend/dto"
+	"github.com/pwnthemall/pwnthemall/backend/events"
 	"github.com/pwnthemall/pwnthemall/backend/models"
 	"github.com/pwnthemall/pwnthemall/backend/utils"
 )
@@ -120,8 +117,7 @@ func PurchaseHint(c *gin.Context) {
 		return
 	}
 
-	// Broadcast hint purchase to team members via WebSocket
-	broadcastHintPurchase(user, hint)
+	publishHintPurchase(user, hint)
 
 	utils.OKResponse(c, gin.H{
 		"message": "hint_purchased",
@@ -130,26 +126,16 @@ func PurchaseHint(c *gin.Context) {
 	})
 }
 
-// broadcastHintPurchase sends WebSocket notification for hint purchase
-func broadcastHintPurchase(user *models.User, hint models.Hint) {
-	if utils.WebSocketHub == nil {
-		return
-	}
-
-	event := dto.HintPurchaseEvent{
-		Event:       EventHintPurchase,
+// publishHintPurchase publishes the hint purchase of a team
+func publishHintPurchase(user *models.User, hint models.Hint) {
+	events.Publish(events.HintPurchased{
 		TeamID:      *user.TeamID,
 		ChallengeID: hint.ChallengeID,
 		HintID:      hint.ID,
-		UserID:      user.ID,
-		Username:    user.Username,
 		HintTitle:   hint.Title,
 		HintContent: hint.Content,
 		Cost:        hint.Cost,
-		Timestamp:   time.Now().UTC().Unix(),
-	}
-
-	if payload, err := json.Marshal(event); err == nil {
-		utils.WebSocketHub.SendToTeam(*user.TeamID, payload)
-	}
+		UserID:      user.ID,
+		Username:    user.Username,
+	})
 }
@@ -8,7 +8,7 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/pwnthemall/pwnthemall/backend/config"
-	"github.com/pwnthemall/pwnthemall/backend/debug"
+	"github.com/pwnthemall/pwnthemall/backend/logger"
 	"github.com/pwnthemall/pwnthemall/backend/models"
 	"github.com/pwnthemall/pwnthemall/backend/shared"
 	"github.com/pwnthemall/pwnthemall/backend/utils"
@@ -21,7 +21,7 @@ func StartChallengeInstance(c *gin.Context) {
 	result := config.DB.Preload("ChallengeType").First(&challenge, id)
 
 	if result.Error != nil {
-		debug.Log("Challenge not found with ID %s: %v", id, result.Error)
+		logger.Ctx(c).Debugf("Challenge not found with ID %s: %v", id, result.Error)
 		c.JSON(http.StatusNotFound, gin.H{"error": "challenge_not_found"})
 		return
 	}
@@ -33,13 +33,13 @@ func StartChallengeInstance(c *gin.Context) {
 
 	handler, ok := shared.GetChallengeHandler(challenge.GetType())
 	if !ok {
-		debug.Log("No handler registered for challenge type: %s", challenge.GetType())
+		logger.Ctx(c).Debugf("No handler registered for challenge type: %s", challenge.GetType())
 		c.JSON(http.StatusBadRequest, gin.H{"error": "unsupported_challenge_type"})
 		return
 	}
 
 	if err := handler.Start(c, &challenge); err != nil {
-		debug.Log("Failed to start challenge: %v", err)
+		logger.Ctx(c).Errorf("Failed to start challenge: %v", err)
 		// Error response already sent by handler
 		return
 	}
@@ -51,7 +51,7 @@ func StopChallengeInstance(c *gin.Context) {
 	result := config.DB.Preload("ChallengeType").First(&challenge, id)
 
 	if result.Error != nil {
-		debug.Log("Challenge not found with ID %s: %v", id, result.Error)
+		logger.Ctx(c).Debugf("Challenge not found with ID %s: %v", id, result.Error)
 		c.JSON(http.StatusNotFound, gin.H{"error": "challenge_not_found"})
 		return
 	}
@@ -130,5 +130,7 @@ func BuildChallengeImage(c *gin.Context) {
 		return
 	}
 
+	utils.RecordAudit(c, "challenge.image_build", "challenge", challenge.ID, nil, nil)
+
 	utils.OKResponse(c, gin.H{"message": fmt.Sprintf("Successfully built image for challenge %s", challenge.Slug)})
 }
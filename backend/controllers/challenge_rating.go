@@ -0,0 +1,147 @@
+package controllers
+
+import (
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pwnthemall/pwnthemall/backend/config"
+	"github.com/pwnthemall/pwnthemall/backend/dto"
+	"github.com/pwnthemall/pwnthemall/backend/models"
+	"github.com/pwnthemall/pwnthemall/backend/utils"
+)
+
+// getChallengeRatingSummary aggregates the ratings of a challenge
+func getChallengeRatingSummary(challengeID uint) models.ChallengeRatingSummary {
+	summary := models.ChallengeRatingSummary{ChallengeID: challengeID}
+	config.DB.Model(&models.ChallengeRating{}).
+		Select("COALESCE(AVG(rating), 0) as average, COUNT(*) as count").
+		Where("challenge_id = ?", challengeID).
+		Scan(&summary)
+	return summary
+}
+
+// RateChallenge stores the rating and feedback of the current user for a solved challenge
+func RateChallenge(c *gin.Context) {
+	userI, _ := c.Get("user")
+	user, ok := userI.(*models.User)
+	if !ok {
+		utils.InternalServerError(c, "user_wrong_type")
+		return
+	}
+
+	var challenge models.Challenge
+	if err := config.DB.First(&challenge, c.Param("id")).Error; err != nil {
+		utils.NotFoundError(c, "challenge_not_found")
+		return
+	}
+
+	var req dto.ChallengeRatingRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequestError(c, "invalid_rating")
+		return
+	}
+
+	// Only players whose team solved the challenge can rate it
+	if user.TeamID == nil {
+		utils.ForbiddenError(c, "challenge_not_solved")
+		return
+	}
+	var solveCount int64
+	config.DB.Model(&models.Solve{}).
+		Where("team_id = ? AND challenge_id = ?", *user.TeamID, challenge.ID).
+		Count(&solveCount)
+	if solveCount == 0 {
+		utils.ForbiddenError(c, "challenge_not_solved")
+		return
+	}
+
+	var existing int64
+	config.DB.Model(&models.ChallengeRating{}).
+		Where("challenge_id = ? AND user_id = ?", challenge.ID, user.ID).
+		Count(&existing)
+	if existing > 0 {
+		utils.ConflictError(c, "challenge_already_rated")
+		return
+	}
+
+	rating := models.ChallengeRating{
+		ChallengeID: challenge.ID,
+		UserID:      user.ID,
+		Rating:      req.Rating,
+		Feedback:    strings.TrimSpace(req.Feedback),
+	}
+	if err := config.DB.Create(&rating).Error; err != nil {
+		utils.ConflictError(c, "challenge_already_rated")
+		return
+	}
+
+	utils.CreatedResponse(c, rating)
+}
+
+// GetChallengeRating returns the current user's rating and, when enabled, the public average
+func GetChallengeRating(c *gin.Context) {
+	userI, _ := c.Get("user")
+	user, ok := userI.(*models.User)
+	if !ok {
+		utils.InternalServerError(c, "user_wrong_type")
+		return
+	}
+
+	var challenge models.Challenge
+	if err := config.DB.First(&challenge, c.Param("id")).Error; err != nil {
+		utils.NotFoundError(c, "challenge_not_found")
+		return
+	}
+	if challenge.Hidden && !canManageChallenge(user, challenge.ID) {
+		utils.NotFoundError(c, "challenge_not_found")
+		return
+	}
+
+	response := gin.H{"rating": nil}
+	var rating models.ChallengeRating
+	if err := config.DB.Where("challenge_id = ? AND user_id = ?", challenge.ID, user.ID).First(&rating).Error; err == nil {
+		response["rating"] = rating
+	}
+	if canManageChallenge(user, challenge.ID) || config.GetConfigBool("PUBLIC_CHALLENGE_RATINGS", false) {
+		response["summary"] = getChallengeRatingSummary(challenge.ID)
+	}
+
+	utils.OKResponse(c, response)
+}
+
+// GetChallengeRatingsAdmin lists every rating and feedback of a challenge
+func GetChallengeRatingsAdmin(c *gin.Context) {
+	var challenge models.Challenge
+	if err := config.DB.First(&challenge, c.Param("id")).Error; err != nil {
+		utils.NotFoundError(c, errChallengeNotFoundMsg)
+		return
+	}
+	user, ok := authorizeChallenge(c, challenge.ID)
+	if !ok {
+		return
+	}
+
+	ratings := []dto.ChallengeRatingWithUser{}
+	if err := config.DB.Table("challenge_ratings").
+		Select("challenge_ratings.id, challenge_ratings.user_id, users.username, challenge_ratings.rating, challenge_ratings.feedback, challenge_ratings.created_at").
+		Joins("LEFT JOIN users ON users.id = challenge_ratings.user_id").
+		Where("challenge_ratings.challenge_id = ?", challenge.ID).
+		Order("challenge_ratings.created_at DESC").
+		Scan(&ratings).Error; err != nil {
+		utils.InternalServerError(c, err.Error())
+		return
+	}
+
+	// Authors get the feedback but not who wrote it
+	if !managesAllChallenges(user) {
+		for i := range ratings {
+			ratings[i].UserID = 0
+			ratings[i].Username = ""
+		}
+	}
+
+	utils.OKResponse(c, gin.H{
+		"summary": getChallengeRatingSummary(challenge.ID),
+		"ratings": ratings,
+	})
+}
@@ -1,16 +1,16 @@
 package controllers
 
 import (
-	"encoding/json"
 	"fmt"
 
 	"strings"
-	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/pwnthemall/pwnthemall/backend/config"
-	"github.com/pwnthemall/pwnthemall/backend/debug"
 	"github.com/pwnthemall/pwnthemall/backend/dto"
+	"github.com/pwnthemall/pwnthemall/backend/events"
+	"github.com/pwnthemall/pwnthemall/backend/logger"
+	"github.com/pwnthemall/pwnthemall/backend/metrics"
 	"github.com/pwnthemall/pwnthemall/backend/models"
 	"github.com/pwnthemall/pwnthemall/backend/utils"
 )
@@ -57,6 +57,12 @@ func validateStandardFlag(submittedValue string, flags []models.Flag) bool {
 	}
 
 	for _, flag := range flags {
+		if utils.IsRegexFlag(flag.Value) {
+			if utils.MatchRegexFlag(flag.Value, submittedValue) {
+				return true
+			}
+			continue
+		}
 		if !utils.IsGeoFlag(flag.Value) && flag.Value == utils.HashFlag(submittedValue) {
 			return true
 		}
@@ -91,25 +97,25 @@ func validateGeoSpec(inputRaw map[string]interface{}, challengeID uint) bool {
 	lng, ok2 := inputRaw["lng"].(float64)
 
 	if !ok1 || !ok2 {
-		debug.Log("GeoValidation: No lat/lng coordinates found in submission for challenge %d", challengeID)
+		logger.Debugf("GeoValidation: No lat/lng coordinates found in submission for challenge %d", challengeID)
 		return false
 	}
 
 	var spec models.GeoSpec
 	if err := config.DB.Where(queryChallengeID, challengeID).First(&spec).Error; err != nil {
-		debug.Log("GeoValidation: No GeoSpec found for challenge %d: %v", challengeID, err)
+		logger.Debugf("GeoValidation: No GeoSpec found for challenge %d: %v", challengeID, err)
 		return false
 	}
 
-	debug.Log("GeoValidation: Checking challenge %d submission (lat=%f,lng=%f) against target (lat=%f,lng=%f) radius=%fkm",
+	logger.Debugf("GeoValidation: Checking challenge %d submission (lat=%f,lng=%f) against target (lat=%f,lng=%f) radius=%fkm",
 		challengeID, lat, lng, spec.TargetLat, spec.TargetLng, spec.RadiusKm)
 
 	if utils.IsWithinRadiusKm(spec.TargetLat, spec.TargetLng, lat, lng, spec.RadiusKm) {
-		debug.Log("GeoValidation: CORRECT - Submission within radius for challenge %d", challengeID)
+		logger.Debugf("GeoValidation: CORRECT - Submission within radius for challenge %d", challengeID)
 		return true
 	}
 
-	debug.Log("GeoValidation: INCORRECT - Submission outside radius for challenge %d", challengeID)
+	logger.Debugf("GeoValidation: INCORRECT - Submission outside radius for challenge %d", challengeID)
 	return false
 }
 
@@ -147,18 +153,18 @@ func validateFlagSubmission(inputRaw map[string]interface{}, challenge models.Ch
 // calculateFirstBloodBonus determines the first blood bonus for a solve position
 func calculateFirstBloodBonus(challenge models.Challenge, position int64) int {
 	if !challenge.EnableFirstBlood || len(challenge.FirstBloodBonuses) == 0 {
-		debug.Log("FirstBlood: FirstBlood not enabled or no bonuses configured")
+		logger.Debug("FirstBlood: FirstBlood not enabled or no bonuses configured")
 		return 0
 	}
 
 	pos := int(position)
 	if pos >= len(challenge.FirstBloodBonuses) {
-		debug.Log("FirstBlood: Position %d beyond configured bonuses (%d available)", pos, len(challenge.FirstBloodBonuses))
+		logger.Debugf("FirstBlood: Position %d beyond configured bonuses (%d available)", pos, len(challenge.FirstBloodBonuses))
 		return 0
 	}
 
 	bonus := int(challenge.FirstBloodBonuses[pos])
-	debug.Log("FirstBlood: Position %d gets bonus %d points", pos, bonus)
+	logger.Debugf("FirstBlood: Position %d gets bonus %d points", pos, bonus)
 	return bonus
 }
 
@@ -182,35 +188,25 @@ func createFirstBloodEntry(challenge models.Challenge, user *models.User, positi
 	}
 
 	if err := config.DB.Create(&firstBlood).Error; err != nil {
-		debug.Log("Failed to create FirstBlood entry: %v", err)
+		logger.Errorf("Failed to create FirstBlood entry: %v", err)
 		return err
 	}
 
-	debug.Log("Created FirstBlood entry for user %d, challenge %d, position %d, bonus %d points",
+	logger.Debugf("Created FirstBlood entry for user %d, challenge %d, position %d, bonus %d points",
 		user.ID, challenge.ID, position, bonus)
-	return nil
-}
 
-// broadcastTeamSolve sends WebSocket event for team solve
-func broadcastTeamSolve(user *models.User, challenge models.Challenge, totalPoints int) {
-	if utils.WebSocketHub == nil {
-		return
-	}
-
-	event := dto.TeamSolveEvent{
-		Event:         "team_solve",
+	events.Publish(events.FirstBlood{
 		TeamID:        user.Team.ID,
+		TeamName:      user.Team.Name,
 		ChallengeID:   challenge.ID,
 		ChallengeName: challenge.Name,
-		Points:        totalPoints,
 		UserID:        user.ID,
 		Username:      user.Username,
-		Timestamp:     time.Now().UTC().Unix(),
-	}
-
-	if payload, err := json.Marshal(event); err == nil {
-		utils.WebSocketHub.SendToTeamExcept(user.Team.ID, user.ID, payload)
-	}
+		Position:      position + 1,
+		Bonus:         bonus,
+		Badge:         badge,
+	})
+	return nil
 }
 
 // stopInstanceOnSolve stops the running instance for a team when challenge is solved
@@ -223,30 +219,22 @@ func stopInstanceOnSolve(teamID uint, challengeID uint, actorID uint, actorName
 	// Try stopping the container
 	if instance.Name != "" {
 		if err := utils.StopDockerInstance(instance.Name); err != nil {
-			debug.Log("Failed to stop Docker instance on solve: %v", err)
+			logger.Errorf("Failed to stop Docker instance on solve: %v", err)
 		}
 	}
 
 	// Remove instance record to free the slot
 	if err := config.DB.Delete(&instance).Error; err != nil {
-		debug.Log("Failed to delete instance on solve: %v", err)
-	}
-
-	// Notify team listeners that instance stopped
-	if utils.WebSocketHub != nil {
-		evt := dto.InstanceEvent{
-			Event:       "instance_update",
-			TeamID:      teamID,
-			UserID:      actorID,
-			Username:    actorName,
-			ChallengeID: challengeID,
-			Status:      "stopped",
-			UpdatedAt:   time.Now().UTC().Unix(),
-		}
-		if payload, err := json.Marshal(evt); err == nil {
-			utils.WebSocketHub.SendToTeamExcept(teamID, actorID, payload)
-		}
+		logger.Errorf("Failed to delete instance on solve: %v", err)
 	}
+
+	events.Publish(events.InstanceStopped{
+		TeamID:      teamID,
+		UserID:      actorID,
+		Username:    actorName,
+		ChallengeID: challengeID,
+		Reason:      "solve",
+	})
 }
 
 // checkExistingSolve returns true if team has already solved the challenge
@@ -287,7 +275,7 @@ func checkDuplicateSubmission(userID uint, challengeID uint, submittedValue stri
 func handleCorrectSubmission(c *gin.Context, user *models.User, challenge models.Challenge) {
 	// Admin without team: return success without recording solve
 	if user.Role == "admin" && (user.Team == nil || user.TeamID == nil) {
-		debug.Log("AdminTest: Flag correct for challenge %d (admin: %s)", challenge.ID, user.Username)
+		logger.Ctx(c).Debugf("AdminTest: Flag correct for challenge %d (admin: %s)", challenge.ID, user.Username)
 		utils.OKResponse(c, gin.H{"message": msgChallengeSolved, "testMode": true})
 		return
 	}
@@ -296,7 +284,7 @@ func handleCorrectSubmission(c *gin.Context, user *models.User, challenge models
 	var position int64
 	config.DB.Model(&models.Solve{}).Where(queryChallengeID, challenge.ID).Count(&position)
 
-	debug.Log("FirstBlood: Challenge %d, Position %d, EnableFirstBlood: %v, Bonuses count: %d",
+	logger.Ctx(c).Debugf("FirstBlood: Challenge %d, Position %d, EnableFirstBlood: %v, Bonuses count: %d",
 		challenge.ID, position, challenge.EnableFirstBlood, len(challenge.FirstBloodBonuses))
 
 	// Calculate first blood bonus
@@ -319,8 +307,18 @@ func handleCorrectSubmission(c *gin.Context, user *models.User, challenge models
 	// Create FirstBlood entry if applicable
 	createFirstBloodEntry(challenge, user, position, firstBloodBonus)
 
-	// Broadcast team solve event
-	broadcastTeamSolve(user, challenge, totalPoints)
+	events.Publish(events.SolveCreated{
+		TeamID:        user.Team.ID,
+		TeamName:      user.Team.Name,
+		ChallengeID:   challenge.ID,
+		ChallengeName: challenge.Name,
+		ChallengeSlug: challenge.Slug,
+		UserID:        user.ID,
+		Username:      user.Username,
+		Points:        totalPoints,
+		Position:      position + 1,
+		SolvedAt:      solve.CreatedAt,
+	})
 
 	// Stop instance asynchronously
 	go stopInstanceOnSolve(user.Team.ID, challenge.ID, user.ID, user.Username)
@@ -438,6 +436,7 @@ func SubmitChallenge(c *gin.Context) {
 
 	// Validate flag
 	isCorrect := validateFlagSubmission(inputRaw, challenge, submittedValue)
+	metrics.ObserveSubmission(challenge.Slug, isCorrect)
 
 	if isCorrect {
 		submittedValue = utils.HashFlag(submittedValue)
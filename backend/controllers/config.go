@@ -1,7 +1,6 @@
 package controllers
 
 import (
-	"encoding/json"
 	"fmt"
 	"sync"
 	"time"
@@ -10,6 +9,7 @@ import (
 	"github.com/jinzhu/copier"
 	"github.com/pwnthemall/pwnthemall/backend/config"
 	"github.com/pwnthemall/pwnthemall/backend/dto"
+	"github.com/pwnthemall/pwnthemall/backend/events"
 	"github.com/pwnthemall/pwnthemall/backend/models"
 	"github.com/pwnthemall/pwnthemall/backend/utils"
 )
@@ -88,19 +88,12 @@ func CreateConfig(c *gin.Context) {
 		config.SynchronizeEnvWithDb()
 	}
 
-	// Broadcast CTF status update if timing-related config changed
 	if cfg.Key == "CTF_START_TIME" || cfg.Key == "CTF_END_TIME" {
 		invalidateCTFStatusCache()
-
-		if utils.UpdatesHub != nil {
-			if payload, err := json.Marshal(gin.H{
-				"event":  "ctf-status",
-				"action": "config_update",
-			}); err == nil {
-				utils.UpdatesHub.SendToAll(payload)
-			}
-		}
 	}
+	events.Publish(events.ConfigChanged{Key: cfg.Key, Value: cfg.Value, Public: cfg.Public})
+
+	utils.RecordAudit(c, "config.create", "config", cfg.Key, nil, cfg)
 
 	utils.OKResponse(c, cfg)
 }
@@ -135,6 +128,7 @@ func UpdateConfig(c *gin.Context) {
 
 	// Store old value for potential rollback
 	oldValue := cfg.Value
+	before := cfg
 
 	cfg.Value = input.Value
 	if input.Public != nil {
@@ -165,38 +159,12 @@ func UpdateConfig(c *gin.Context) {
 		config.SynchronizeEnvWithDb()
 	}
 
-	// Broadcast updates for specific config changes
-	if utils.UpdatesHub != nil {
-		var event string
-		var action string
-		
-		switch key {
-		case "CTF_START_TIME", "CTF_END_TIME":
-			invalidateCTFStatusCache()
-			event = "ctf-status"
-			action = "config_update"
-		case "TICKETS_ENABLED":
-			event = "config-update"
-			action = "tickets_enabled"
-		default:
-			// For other public configs, broadcast a general config update
-			if cfg.Public {
-				event = "config-update"
-				action = "public_config"
-			}
-		}
-		
-		if event != "" {
-			if payload, err := json.Marshal(gin.H{
-				"event":  event,
-				"action": action,
-				"key":    key,
-				"value":  cfg.Value,
-			}); err == nil {
-				utils.UpdatesHub.SendToAll(payload)
-			}
-		}
+	if key == "CTF_START_TIME" || key == "CTF_END_TIME" {
+		invalidateCTFStatusCache()
 	}
+	events.Publish(events.ConfigChanged{Key: key, Value: cfg.Value, Public: cfg.Public})
+
+	utils.RecordAudit(c, "config.update", "config", key, before, cfg)
 
 	utils.OKResponse(c, cfg)
 }
@@ -221,6 +189,8 @@ func DeleteConfig(c *gin.Context) {
 		config.SynchronizeEnvWithDb()
 	}
 
+	utils.RecordAudit(c, "config.delete", "config", key, cfg, nil)
+
 	utils.OKResponse(c, gin.H{"message": "Configuration deleted successfully"})
 }
 
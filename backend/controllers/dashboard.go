@@ -98,6 +98,18 @@ func GetDashboardStats(c *gin.Context) {
 	stats.Instances.Running = runningInstances
 	stats.Instances.Total = totalInstances
 
+	// Rating statistics
+	config.DB.Model(&models.ChallengeRating{}).
+		Select("COUNT(*) as total, COALESCE(AVG(rating), 0) as average").
+		Scan(&stats.Ratings)
+	stats.Ratings.Challenges = []models.ChallengeRatingSummary{}
+	config.DB.Table("challenge_ratings").
+		Select("challenge_ratings.challenge_id, challenges.name as challenge_name, AVG(challenge_ratings.rating) as average, COUNT(*) as count").
+		Joins("JOIN challenges ON challenges.id = challenge_ratings.challenge_id").
+		Group("challenge_ratings.challenge_id, challenges.name").
+		Order("average DESC, count DESC").
+		Scan(&stats.Ratings.Challenges)
+
 	utils.OKResponse(c, stats)
 }
 
@@ -145,16 +157,16 @@ func GetRunningInstances(c *gin.Context) {
 	for _, instance := range instances {
 		var instanceDTO dto.AdminInstanceDTO
 		copier.Copy(&instanceDTO, &instance)
-		
+
 		// Manually set nested fields that copier can't automatically map
 		instanceDTO.Username = instance.User.Username
 		instanceDTO.TeamName = instance.Team.Name
 		instanceDTO.ChallengeName = instance.Challenge.Name
-		
+
 		if instance.Challenge.ChallengeCategory != nil {
 			instanceDTO.Category = instance.Challenge.ChallengeCategory.Name
 		}
-		
+
 		runningInstances = append(runningInstances, instanceDTO)
 	}
 
@@ -35,6 +35,8 @@ func CreateDecayFormula(c *gin.Context) {
 		return
 	}
 
+	utils.RecordAudit(c, "decay_formula.create", "decay_formula", decayFormula.ID, nil, decayFormula)
+
 	utils.CreatedResponse(c, decayFormula)
 }
 
@@ -47,6 +49,7 @@ func UpdateDecayFormula(c *gin.Context) {
 		return
 	}
 
+	before := decayFormula
 	if err := c.ShouldBindJSON(&decayFormula); err != nil {
 		utils.BadRequestError(c, err.Error())
 		return
@@ -63,16 +66,26 @@ func UpdateDecayFormula(c *gin.Context) {
 		return
 	}
 
+	utils.RecordAudit(c, "decay_formula.update", "decay_formula", decayFormula.ID, before, decayFormula)
+
 	utils.OKResponse(c, decayFormula)
 }
 
 func DeleteDecayFormula(c *gin.Context) {
 	id := c.Param("id")
 
-	if err := config.DB.Delete(&models.DecayFormula{}, id).Error; err != nil {
+	var decayFormula models.DecayFormula
+	if err := config.DB.First(&decayFormula, id).Error; err != nil {
+		utils.NotFoundError(c, "Decay formula not found")
+		return
+	}
+
+	if err := config.DB.Delete(&decayFormula).Error; err != nil {
 		utils.InternalServerError(c, err.Error())
 		return
 	}
 
+	utils.RecordAudit(c, "decay_formula.delete", "decay_formula", decayFormula.ID, decayFormula, nil)
+
 	utils.OKResponse(c, gin.H{"message": "Decay formula deleted successfully"})
 }
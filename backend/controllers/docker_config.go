@@ -34,10 +34,12 @@ func UpdateDockerConfig(c *gin.Context) {
 			utils.InternalServerError(c, "Failed to create Docker Configuration")
 			return
 		}
+		utils.RecordAudit(c, "docker_config.create", "docker_config", newCfg.ID, nil, newCfg)
 		utils.CreatedResponse(c, newCfg)
 		return
 	}
 
+	before := existingCfg
 	existingCfg.Host = newCfg.Host
 	existingCfg.ImagePrefix = newCfg.ImagePrefix
 	existingCfg.InstancesByTeam = newCfg.InstancesByTeam
@@ -51,6 +53,7 @@ func UpdateDockerConfig(c *gin.Context) {
 		utils.InternalServerError(c, "Failed to update Docker Configuration")
 		return
 	}
+	utils.RecordAudit(c, "docker_config.update", "docker_config", existingCfg.ID, before, existingCfg)
 	if err := config.ConnectDocker(); err != nil {
 		utils.InternalServerError(c, "Config updated but connection to Docker Daemon isn't healthy")
 		return
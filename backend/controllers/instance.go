@@ -4,8 +4,8 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/jinzhu/copier"
 	"github.com/pwnthemall/pwnthemall/backend/config"
-	"github.com/pwnthemall/pwnthemall/backend/debug"
 	"github.com/pwnthemall/pwnthemall/backend/dto"
+	"github.com/pwnthemall/pwnthemall/backend/logger"
 	"github.com/pwnthemall/pwnthemall/backend/models"
 	"github.com/pwnthemall/pwnthemall/backend/utils"
 )
@@ -63,12 +63,12 @@ func GetAllInstancesAdmin(c *gin.Context) {
 	for _, instance := range instances {
 		var instanceDTO dto.AdminInstanceDTO
 		copier.Copy(&instanceDTO, &instance)
-		
+
 		// Manually set nested fields that copier can't automatically map
 		instanceDTO.Username = instance.User.Username
 		instanceDTO.TeamName = instance.Team.Name
 		instanceDTO.ChallengeName = instance.Challenge.Name
-		
+
 		if instance.Challenge.ChallengeCategory != nil {
 			instanceDTO.Category = instance.Challenge.ChallengeCategory.Name
 		}
@@ -103,23 +103,31 @@ func DeleteInstanceAdmin(c *gin.Context) {
 
 		go func() {
 			if isCompose {
-				debug.Log("Admin stopping Compose project asynchronously: %s", containerName)
+				logger.Debugf("Admin stopping Compose project asynchronously: %s", containerName)
 				if err := utils.StopComposeInstance(containerName); err != nil {
-					debug.Log("Warning: Error stopping Compose instance (may already be stopped): %v", err)
+					logger.Warnf("Error stopping Compose instance (may already be stopped): %v", err)
 				} else {
-					debug.Log("Compose project stopped successfully: %s", containerName)
+					logger.Infof("Compose project stopped successfully: %s", containerName)
 				}
 			} else {
-				debug.Log("Admin stopping Docker container asynchronously: %s", containerName)
+				logger.Debugf("Admin stopping Docker container asynchronously: %s", containerName)
 				if err := utils.StopDockerInstance(containerName); err != nil {
-					debug.Log("Warning: Error stopping Docker instance (may already be stopped): %v", err)
+					logger.Warnf("Error stopping Docker instance (may already be stopped): %v", err)
 				} else {
-					debug.Log("Docker container stopped successfully: %s", containerName)
+					logger.Infof("Docker container stopped successfully: %s", containerName)
 				}
 			}
 		}()
 	}
 
+	utils.RecordAudit(c, "instance.delete", "instance", instance.ID, gin.H{
+		"name":        instance.Name,
+		"challengeId": instance.ChallengeID,
+		"teamId":      instance.TeamID,
+		"userId":      instance.UserID,
+		"status":      instance.Status,
+	}, nil)
+
 	utils.OKResponse(c, gin.H{"message": "Instance deleted successfully"})
 }
 
@@ -148,7 +156,8 @@ func StopAllInstancesAdmin(c *gin.Context) {
 	}
 
 	count := len(instances)
-	debug.Log("Admin stopping all instances: %d total", count)
+	utils.RecordAudit(c, "instance.stop_all", "instance", nil, gin.H{"count": count}, gin.H{"count": 0})
+	logger.Ctx(c).Debugf("Admin stopping all instances: %d total", count)
 
 	// Stop all Docker/Compose containers asynchronously with rate limiting (3 at a time)
 	const maxConcurrent = 3
@@ -166,18 +175,18 @@ func StopAllInstancesAdmin(c *gin.Context) {
 				defer func() { <-semaphore }() // Release semaphore slot when done
 
 				if compose {
-					debug.Log("Admin stopping Compose project asynchronously: %s", name)
+					logger.Debugf("Admin stopping Compose project asynchronously: %s", name)
 					if err := utils.StopComposeInstance(name); err != nil {
-						debug.Log("Warning: Error stopping Compose instance (may already be stopped): %v", err)
+						logger.Warnf("Error stopping Compose instance (may already be stopped): %v", err)
 					} else {
-						debug.Log("Compose project stopped successfully: %s", name)
+						logger.Infof("Compose project stopped successfully: %s", name)
 					}
 				} else {
-					debug.Log("Admin stopping Docker container asynchronously: %s", name)
+					logger.Debugf("Admin stopping Docker container asynchronously: %s", name)
 					if err := utils.StopDockerInstance(name); err != nil {
-						debug.Log("Warning: Error stopping Docker instance (may already be stopped): %v", err)
+						logger.Warnf("Error stopping Docker instance (may already be stopped): %v", err)
 					} else {
-						debug.Log("Docker container stopped successfully: %s", name)
+						logger.Infof("Docker container stopped successfully: %s", name)
 					}
 				}
 			}(containerName, isCompose)
@@ -1,9 +1,7 @@
 package controllers
 
 import (
-	"github.com/pwnthemall/pwnthemall/backend/debug"
-	"encoding/json"
-	
+	"github.com/pwnthemall/pwnthemall/backend/logger"
 
 	"time"
 
@@ -11,11 +9,14 @@ import (
 	"github.com/jinzhu/copier"
 	"github.com/pwnthemall/pwnthemall/backend/config"
 	"github.com/pwnthemall/pwnthemall/backend/dto"
+	"github.com/pwnthemall/pwnthemall/backend/events"
 	"github.com/pwnthemall/pwnthemall/backend/models"
 	"github.com/pwnthemall/pwnthemall/backend/utils"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
-// SendNotification sends a notification to users
+// SendNotification sends a notification to users, right away or at its scheduled time
 func SendNotification(c *gin.Context) {
 	var input dto.NotificationInput
 	if err := c.ShouldBindJSON(&input); err != nil {
@@ -29,36 +30,222 @@ func SendNotification(c *gin.Context) {
 	// Create notification in database
 	var notification models.Notification
 	copier.Copy(&notification, &input)
+	notification.SenderID = &senderID
+
+	if errKey := validateNotificationTarget(notification); errKey != "" {
+		utils.BadRequestError(c, errKey)
+		return
+	}
+
+	now := time.Now()
+	if notification.SendAt == nil || !notification.SendAt.After(now) {
+		notification.SendAt = &now
+	} else {
+		notification.Pending = true
+	}
 
 	if err := config.DB.Create(&notification).Error; err != nil {
 		utils.InternalServerError(c, "Failed to create notification")
 		return
 	}
 
-	// Prepare notification message for WebSocket
-	var notificationMsg dto.NotificationResponse
+	if !notification.Pending {
+		if err := utils.DeliverNotification(&notification); err != nil {
+			logger.Ctx(c).Errorf("Failed to deliver notification %d: %v", notification.ID, err)
+			utils.InternalServerError(c, "failed_to_deliver_notification")
+			return
+		}
+	}
+
+	var notificationMsg dto.SentNotificationResponse
 	copier.Copy(&notificationMsg, &notification)
 
-	// Send notification via WebSocket if available
-	messageBytes, err := json.Marshal(notificationMsg)
+	utils.RecordAudit(c, "notification.send", "notification", notification.ID, nil, notificationMsg)
+
+	utils.CreatedResponse(c, notificationMsg)
+}
+
+// UpdateNotification edits a notification, keeping the previous content and re-broadcasting it when already sent
+func UpdateNotification(c *gin.Context) {
+	var notification models.Notification
+	if err := config.DB.First(&notification, c.Param("id")).Error; err != nil {
+		utils.NotFoundError(c, "Notification not found")
+		return
+	}
+
+	var input dto.NotificationUpdateInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.BadRequestError(c, "Invalid input")
+		return
+	}
+
+	before := notification
+	editorID := c.GetUint("user_id")
+	contentChanged := input.Title != notification.Title || input.Message != notification.Message || input.Type != notification.Type
+
+	notification.Title = input.Title
+	notification.Message = input.Message
+	notification.Type = input.Type
+	if input.Pinned != nil {
+		notification.Pinned = *input.Pinned
+	}
+	if input.SendAt != nil {
+		if !notification.Pending {
+			utils.BadRequestError(c, "notification_already_sent")
+			return
+		}
+		notification.SendAt = input.SendAt
+	}
+	if errKey := validateNotificationTarget(notification); errKey != "" {
+		utils.BadRequestError(c, errKey)
+		return
+	}
+
+	now := time.Now()
+	if contentChanged && !notification.Pending {
+		notification.EditedAt = &now
+	}
+
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if contentChanged {
+			revision := models.NotificationRevision{
+				NotificationID: before.ID,
+				Title:          before.Title,
+				Message:        before.Message,
+				Type:           before.Type,
+				EditorID:       &editorID,
+			}
+			if err := tx.Create(&revision).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Omit(clause.Associations).Save(&notification).Error
+	})
 	if err != nil {
-		utils.InternalServerError(c, "Failed to marshal notification")
+		utils.InternalServerError(c, "failed_to_update_notification")
 		return
 	}
 
-	// Send via WebSocket
-	if input.UserID != nil {
-		// Send to specific user
-		utils.WebSocketHub.SendToUser(*input.UserID, messageBytes)
-	} else if input.TeamID != nil {
-		// Send to all users in the team
-		utils.WebSocketHub.SendToTeam(*input.TeamID, messageBytes)
-	} else {
-		// Send to all connected users except the sender
-		utils.WebSocketHub.SendToAllExcept(messageBytes, senderID)
+	if contentChanged && !notification.Pending {
+		var recipients []uint
+		if notification.HasAudienceFilter() {
+			recipients = utils.NotificationRecipientIDs(notification.ID)
+		}
+		events.Publish(events.NotificationUpdated{
+			NotificationID: notification.ID,
+			Title:          notification.Title,
+			Message:        notification.Message,
+			Type:           notification.Type,
+			UserID:         notification.UserID,
+			TeamID:         notification.TeamID,
+			Global:         notification.IsGlobal(),
+			Recipients:     recipients,
+			EditorID:       editorID,
+			CreatedAt:      notification.SentAt(),
+			EditedAt:       now,
+		})
 	}
 
-	utils.CreatedResponse(c, notificationMsg)
+	var response dto.SentNotificationResponse
+	copier.Copy(&response, &notification)
+
+	utils.RecordAudit(c, "notification.update", "notification", notification.ID, before, notification)
+
+	utils.OKResponse(c, response)
+}
+
+// GetNotificationRevisions lists the previous versions of a notification, newest first
+func GetNotificationRevisions(c *gin.Context) {
+	revisions := []models.NotificationRevision{}
+	if err := config.DB.Where("notification_id = ?", c.Param("id")).Order("created_at DESC, id DESC").Find(&revisions).Error; err != nil {
+		utils.InternalServerError(c, "failed_to_fetch_notification_revisions")
+		return
+	}
+	utils.OKResponse(c, revisions)
+}
+
+// GetAnnouncements returns the pinned global notifications, available without authentication
+func GetAnnouncements(c *gin.Context) {
+	var notifications []models.Notification
+	if err := config.DB.Where("pinned = ? AND pending = ?", true, false).
+		Where(models.NotificationGlobalCondition).
+		Order("COALESCE(send_at, created_at) DESC").Limit(20).Find(&notifications).Error; err != nil {
+		utils.InternalServerError(c, "failed_to_fetch_announcements")
+		return
+	}
+
+	response := []dto.AnnouncementResponse{}
+	for _, n := range notifications {
+		response = append(response, dto.AnnouncementResponse{
+			ID:        n.ID,
+			Title:     n.Title,
+			Message:   n.Message,
+			Type:      n.Type,
+			EditedAt:  n.EditedAt,
+			CreatedAt: n.SentAt(),
+		})
+	}
+	utils.OKResponse(c, response)
+}
+
+// validateNotificationTarget checks that role, challenge and bracket filters are not mixed with a user or team and that only global notifications are pinned
+func validateNotificationTarget(n models.Notification) string {
+	if n.UserID != nil && n.TeamID != nil {
+		return "conflicting_notification_target"
+	}
+	if (n.UserID != nil || n.TeamID != nil) && n.HasAudienceFilter() {
+		return "conflicting_notification_target"
+	}
+	if n.TargetRole != "" && !config.RoleExists(n.TargetRole) {
+		return "role_not_found"
+	}
+	if n.UnsolvedChallengeID != nil {
+		var count int64
+		config.DB.Model(&models.Challenge{}).Where("id = ?", *n.UnsolvedChallengeID).Count(&count)
+		if count == 0 {
+			return "challenge_not_found"
+		}
+	}
+	if n.TargetBracketID != nil {
+		var count int64
+		config.DB.Model(&models.Bracket{}).Where("id = ?", *n.TargetBracketID).Count(&count)
+		if count == 0 {
+			return "bracket_not_found"
+		}
+	}
+	if n.Pinned && !n.IsGlobal() {
+		return "pinned_requires_global"
+	}
+	return ""
+}
+
+// visibleNotifications scopes notifications to the delivered ones a user is part of the audience of
+func visibleNotifications(userID uint, teamID *uint) *gorm.DB {
+	audience := config.DB.Where("user_id = ?", userID).
+		Or(models.NotificationGlobalCondition).
+		Or("id IN (?)", config.DB.Model(&models.NotificationRecipient{}).Select("notification_id").Where("user_id = ?", userID))
+	if teamID != nil {
+		audience = audience.Or("team_id = ?", *teamID)
+	}
+	return config.DB.Model(&models.Notification{}).Where("pending = ?", false).Where(audience)
+}
+
+// readNotificationIDs selects the notifications a user has read
+func readNotificationIDs(userID uint) *gorm.DB {
+	return config.DB.Model(&models.NotificationRead{}).Select("notification_id").Where("user_id = ?", userID)
+}
+
+// markNotificationsRead stores the read state of a user, notifications already read keep their first read time
+func markNotificationsRead(userID uint, notificationIDs []uint) error {
+	if len(notificationIDs) == 0 {
+		return nil
+	}
+	now := time.Now()
+	reads := make([]models.NotificationRead, len(notificationIDs))
+	for i, id := range notificationIDs {
+		reads[i] = models.NotificationRead{NotificationID: id, UserID: userID, ReadAt: now}
+	}
+	return config.DB.Clauses(clause.OnConflict{DoNothing: true}).CreateInBatches(reads, 500).Error
 }
 
 // GetUserNotifications retrieves notifications for the current user
@@ -74,24 +261,35 @@ func GetUserNotifications(c *gin.Context) {
 		return
 	}
 
-	// Build query for notifications: user-specific, team-specific, or global
-	query := config.DB.Where("user_id = ? OR user_id IS NULL", userID)
-	if user.TeamID != nil {
-		query = query.Or("team_id = ?", *user.TeamID)
-	}
-
-	result := query.Order("created_at DESC").Limit(50).Find(&notifications)
+	result := visibleNotifications(userID, user.TeamID).Order("COALESCE(send_at, created_at) DESC").Limit(50).Find(&notifications)
 
 	if result.Error != nil {
 		utils.InternalServerError(c, "Failed to fetch notifications")
 		return
 	}
 
+	ids := make([]uint, len(notifications))
+	for i, notification := range notifications {
+		ids[i] = notification.ID
+	}
+	var reads []models.NotificationRead
+	if len(ids) > 0 {
+		config.DB.Where("user_id = ? AND notification_id IN ?", userID, ids).Find(&reads)
+	}
+	readAt := make(map[uint]time.Time, len(reads))
+	for _, read := range reads {
+		readAt[read.NotificationID] = read.ReadAt
+	}
+
 	// Convert to response format
 	var response []dto.NotificationResponse
 	for _, notification := range notifications {
 		var notifResp dto.NotificationResponse
 		copier.Copy(&notifResp, &notification)
+		notifResp.CreatedAt = notification.SentAt()
+		if t, ok := readAt[notification.ID]; ok {
+			notifResp.ReadAt = &t
+		}
 		response = append(response, notifResp)
 	}
 
@@ -101,7 +299,7 @@ func GetUserNotifications(c *gin.Context) {
 	}
 
 	// Log the response for debugging
-	debug.Log("User %d notifications: %+v", userID, response)
+	logger.Ctx(c).Debugf("User %d notifications: %+v", userID, response)
 
 	utils.OKResponse(c, response)
 }
@@ -120,23 +318,14 @@ func MarkNotificationAsRead(c *gin.Context) {
 		return
 	}
 
-	// Build query for notifications: user-specific, team-specific, or global
-	query := config.DB.Where("id = ? AND (user_id = ? OR user_id IS NULL)", notificationID, userID)
-	if user.TeamID != nil {
-		query = query.Or("id = ? AND team_id = ?", notificationID, *user.TeamID)
-	}
-
-	result := query.First(&notification)
+	result := visibleNotifications(userID, user.TeamID).Where("id = ?", notificationID).First(&notification)
 
 	if result.Error != nil {
 		utils.NotFoundError(c, "Notification not found")
 		return
 	}
 
-	now := time.Now()
-	notification.ReadAt = &now
-
-	if err := config.DB.Save(&notification).Error; err != nil {
+	if err := markNotificationsRead(userID, []uint{notification.ID}); err != nil {
 		utils.InternalServerError(c, "Failed to mark notification as read")
 		return
 	}
@@ -155,17 +344,12 @@ func MarkAllNotificationsAsRead(c *gin.Context) {
 		return
 	}
 
-	now := time.Now()
-	query := config.DB.Model(&models.Notification{}).
-		Where("(user_id = ? OR user_id IS NULL) AND read_at IS NULL", userID)
-
-	if user.TeamID != nil {
-		query = query.Or("team_id = ? AND read_at IS NULL", *user.TeamID)
+	var unreadIDs []uint
+	if err := visibleNotifications(userID, user.TeamID).Where("id NOT IN (?)", readNotificationIDs(userID)).Pluck("id", &unreadIDs).Error; err != nil {
+		utils.InternalServerError(c, "Failed to mark notifications as read")
+		return
 	}
-
-	result := query.Update("read_at", now)
-
-	if result.Error != nil {
+	if err := markNotificationsRead(userID, unreadIDs); err != nil {
 		utils.InternalServerError(c, "Failed to mark notifications as read")
 		return
 	}
@@ -185,12 +369,7 @@ func GetUnreadCount(c *gin.Context) {
 	}
 
 	var count int64
-	query := config.DB.Model(&models.Notification{}).
-		Where("(user_id = ? OR user_id IS NULL) AND read_at IS NULL", userID)
-
-	if user.TeamID != nil {
-		query = query.Or("team_id = ? AND read_at IS NULL", *user.TeamID)
-	}
+	query := visibleNotifications(userID, user.TeamID).Where("id NOT IN (?)", readNotificationIDs(userID))
 
 	result := query.Count(&count)
 
@@ -200,7 +379,7 @@ func GetUnreadCount(c *gin.Context) {
 	}
 
 	// Log the count for debugging
-	debug.Log("User %d unread count: %d", userID, count)
+	logger.Ctx(c).Debugf("User %d unread count: %d", userID, count)
 
 	utils.OKResponse(c, gin.H{"count": count})
 }
@@ -208,7 +387,7 @@ func GetUnreadCount(c *gin.Context) {
 // GetSentNotifications retrieves all sent notifications (admin only)
 func GetSentNotifications(c *gin.Context) {
 	var notifications []models.Notification
-	result := config.DB.Preload("User").Preload("Team").Order("created_at DESC").Limit(100).Find(&notifications)
+	result := config.DB.Preload("User").Preload("Team").Order("COALESCE(send_at, created_at) DESC").Limit(100).Find(&notifications)
 
 	if result.Error != nil {
 		utils.InternalServerError(c, "Failed to fetch notifications")
@@ -216,7 +395,7 @@ func GetSentNotifications(c *gin.Context) {
 	}
 
 	// Log the raw notifications for debugging
-	debug.Log("Raw notifications from DB: %+v", notifications)
+	logger.Ctx(c).Debugf("Raw notifications from DB: %+v", notifications)
 
 	// Convert to response format with user info
 
@@ -241,7 +420,7 @@ func GetSentNotifications(c *gin.Context) {
 		response = []dto.SentNotificationResponse{}
 	}
 
-	debug.Log("Final response: %+v", response)
+	logger.Ctx(c).Debugf("Final response: %+v", response)
 	utils.OKResponse(c, response)
 }
 
@@ -260,5 +439,7 @@ func DeleteNotification(c *gin.Context) {
 		return
 	}
 
+	utils.RecordAudit(c, "notification.delete", "notification", notification.ID, notification, nil)
+
 	utils.OKResponse(c, gin.H{"message": "Notification deleted"})
 }
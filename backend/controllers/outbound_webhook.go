@@ -0,0 +1,232 @@
+package controllers
+
+import (
+	"net/url"
+	"slices"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pwnthemall/pwnthemall/backend/config"
+	"github.com/pwnthemall/pwnthemall/backend/dto"
+	"github.com/pwnthemall/pwnthemall/backend/logger"
+	"github.com/pwnthemall/pwnthemall/backend/models"
+	"github.com/pwnthemall/pwnthemall/backend/utils"
+)
+
+// GetWebhookTargets lists the outbound webhooks with the events they can subscribe to
+func GetWebhookTargets(c *gin.Context) {
+	targets := []models.WebhookTarget{}
+	if err := config.DB.Order("id").Find(&targets).Error; err != nil {
+		utils.InternalServerError(c, "failed_to_fetch_webhooks")
+		return
+	}
+	utils.OKResponse(c, gin.H{"webhooks": targets, "events": utils.WebhookEvents, "formats": utils.WebhookFormats})
+}
+
+// CreateWebhookTarget registers an outbound webhook, the signing secret is only returned once
+func CreateWebhookTarget(c *gin.Context) {
+	var input dto.WebhookTargetInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.BadRequestError(c, "invalid_input")
+		return
+	}
+	if errKey := validateWebhookTargetInput(input); errKey != "" {
+		utils.BadRequestError(c, errKey)
+		return
+	}
+
+	secret, err := utils.GenerateRandomString(32)
+	if err != nil {
+		utils.InternalServerError(c, "failed_to_create_webhook")
+		return
+	}
+	target := models.WebhookTarget{
+		Name:    input.Name,
+		URL:     input.URL,
+		Format:  input.Format,
+		Secret:  secret,
+		Events:  input.Events,
+		Enabled: input.Enabled == nil || *input.Enabled,
+	}
+	if err := config.DB.Create(&target).Error; err != nil {
+		utils.InternalServerError(c, "failed_to_create_webhook")
+		return
+	}
+
+	utils.RecordAudit(c, "webhook.create", "webhook", target.ID, nil, target)
+	utils.CreatedResponse(c, gin.H{"webhook": target, "secret": secret})
+}
+
+// UpdateWebhookTarget changes the URL, format, events or state of an outbound webhook
+func UpdateWebhookTarget(c *gin.Context) {
+	target, ok := findWebhookTarget(c)
+	if !ok {
+		return
+	}
+
+	var input dto.WebhookTargetInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.BadRequestError(c, "invalid_input")
+		return
+	}
+	if errKey := validateWebhookTargetInput(input); errKey != "" {
+		utils.BadRequestError(c, errKey)
+		return
+	}
+
+	before := target
+	target.Name = input.Name
+	target.URL = input.URL
+	target.Format = input.Format
+	target.Events = input.Events
+	if input.Enabled != nil {
+		target.Enabled = *input.Enabled
+	}
+	if err := config.DB.Save(&target).Error; err != nil {
+		utils.InternalServerError(c, "failed_to_update_webhook")
+		return
+	}
+
+	utils.RecordAudit(c, "webhook.update", "webhook", target.ID, before, target)
+	utils.OKResponse(c, target)
+}
+
+// RotateWebhookSecret replaces the signing secret of an outbound webhook
+func RotateWebhookSecret(c *gin.Context) {
+	target, ok := findWebhookTarget(c)
+	if !ok {
+		return
+	}
+
+	secret, err := utils.GenerateRandomString(32)
+	if err != nil {
+		utils.InternalServerError(c, "failed_to_update_webhook")
+		return
+	}
+	if err := config.DB.Model(&target).Update("secret", secret).Error; err != nil {
+		utils.InternalServerError(c, "failed_to_update_webhook")
+		return
+	}
+
+	utils.RecordAudit(c, "webhook.rotate_secret", "webhook", target.ID, nil, nil)
+	utils.OKResponse(c, gin.H{"secret": secret})
+}
+
+// DeleteWebhookTarget removes an outbound webhook and its delivery log
+func DeleteWebhookTarget(c *gin.Context) {
+	target, ok := findWebhookTarget(c)
+	if !ok {
+		return
+	}
+	if err := config.DB.Delete(&target).Error; err != nil {
+		utils.InternalServerError(c, "failed_to_delete_webhook")
+		return
+	}
+
+	utils.RecordAudit(c, "webhook.delete", "webhook", target.ID, target, nil)
+	utils.OKResponse(c, gin.H{"message": "webhook_deleted"})
+}
+
+// TestWebhookTarget sends a test event right away and returns the delivery
+func TestWebhookTarget(c *gin.Context) {
+	target, ok := findWebhookTarget(c)
+	if !ok {
+		return
+	}
+
+	delivery, err := utils.SendTestWebhook(target)
+	if err != nil {
+		logger.Ctx(c).Errorf("Failed to send test webhook %d: %v", target.ID, err)
+		utils.InternalServerError(c, "failed_to_send_test_webhook")
+		return
+	}
+	utils.OKResponse(c, delivery)
+}
+
+// GetWebhookDeliveries lists the delivery log of an outbound webhook, newest first
+func GetWebhookDeliveries(c *gin.Context) {
+	target, ok := findWebhookTarget(c)
+	if !ok {
+		return
+	}
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("pageSize", "50"))
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 200 {
+		pageSize = 50
+	}
+
+	query := config.DB.Model(&models.WebhookDelivery{}).Where("target_id = ?", target.ID)
+	if status := c.Query("status"); status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var total int64
+	query.Count(&total)
+
+	deliveries := []models.WebhookDelivery{}
+	if err := query.Order("created_at DESC, id DESC").Offset((page - 1) * pageSize).Limit(pageSize).Find(&deliveries).Error; err != nil {
+		utils.InternalServerError(c, "failed_to_fetch_webhook_deliveries")
+		return
+	}
+
+	totalPages := int(total) / pageSize
+	if int(total)%pageSize > 0 {
+		totalPages++
+	}
+
+	utils.OKResponse(c, dto.WebhookDeliveryListResponse{
+		Deliveries: deliveries,
+		Total:      total,
+		Page:       page,
+		PageSize:   pageSize,
+		TotalPages: totalPages,
+	})
+}
+
+// RedeliverWebhookDelivery queues a past delivery again
+func RedeliverWebhookDelivery(c *gin.Context) {
+	target, ok := findWebhookTarget(c)
+	if !ok {
+		return
+	}
+
+	var original models.WebhookDelivery
+	if err := config.DB.Where("id = ? AND target_id = ?", c.Param("deliveryId"), target.ID).First(&original).Error; err != nil {
+		utils.NotFoundError(c, "webhook_delivery_not_found")
+		return
+	}
+
+	delivery, err := utils.RedeliverWebhook(original)
+	if err != nil {
+		utils.InternalServerError(c, "failed_to_redeliver_webhook")
+		return
+	}
+	utils.CreatedResponse(c, delivery)
+}
+
+func findWebhookTarget(c *gin.Context) (models.WebhookTarget, bool) {
+	var target models.WebhookTarget
+	if err := config.DB.First(&target, c.Param("id")).Error; err != nil {
+		utils.NotFoundError(c, "webhook_not_found")
+		return target, false
+	}
+	return target, true
+}
+
+// validateWebhookTargetInput checks the URL and the subscribed events
+func validateWebhookTargetInput(input dto.WebhookTargetInput) string {
+	u, err := url.Parse(input.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return "invalid_webhook_url"
+	}
+	for _, event := range input.Events {
+		if !slices.Contains(utils.WebhookEvents, event) {
+			return "invalid_webhook_event"
+		}
+	}
+	return ""
+}
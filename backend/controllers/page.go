@@ -5,23 +5,26 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/jinzhu/copier"
 	"github.com/minio/minio-go/v7"
 	"github.com/pwnthemall/pwnthemall/backend/config"
 	"github.com/pwnthemall/pwnthemall/backend/dto"
+	"github.com/pwnthemall/pwnthemall/backend/logger"
 	"github.com/pwnthemall/pwnthemall/backend/models"
 	"github.com/pwnthemall/pwnthemall/backend/utils"
+	"gorm.io/gorm"
 )
 
 const pagesBucket = "pages"
 
-// GetPages returns all pages
+// GetPages returns all published pages
 func GetPages(c *gin.Context) {
 	var pages []models.Page
 
-	if err := config.DB.Order("created_at DESC").Find(&pages).Error; err != nil {
+	if err := visiblePages().Order("created_at DESC").Find(&pages).Error; err != nil {
 		utils.InternalServerError(c, "Failed to fetch pages")
 		return
 	}
@@ -100,20 +103,25 @@ func GetAdminPage(c *gin.Context) {
 		return
 	}
 
+	// The editor works on the source of the latest revision, the stored HTML for older pages
+	format, source := page.Format, buf.String()
+	var revision models.PageRevision
+	if err := config.DB.Where("page_id = ?", page.ID).Order("number DESC").First(&revision).Error; err == nil {
+		format, source = revision.Format, revision.Content
+	}
+
 	utils.SuccessResponse(c, http.StatusOK, gin.H{
-		"page": page,
-		"html": buf.String(),
+		"page":     page,
+		"html":     buf.String(),
+		"format":   format,
+		"source":   source,
+		"revision": revision.Number,
 	})
 }
 
 // CreatePage creates a new custom page
 func CreatePage(c *gin.Context) {
-	var input struct {
-		Slug  string `json:"slug" binding:"required"`
-		Title string `json:"title" binding:"required"`
-		HTML  string `json:"html" binding:"required"`
-	}
-
+	var input dto.PageInput
 	if err := c.ShouldBindJSON(&input); err != nil {
 		utils.BadRequestError(c, "Invalid input: "+err.Error())
 		return
@@ -136,12 +144,12 @@ func CreatePage(c *gin.Context) {
 		return
 	}
 
-	// Sanitize HTML content
-	sanitizedHTML := utils.SanitizePageHTML(input.HTML)
-
-	// Validate content size (max 1MB)
-	if len(sanitizedHTML) > 1024*1024 {
-		utils.BadRequestError(c, "HTML content is too large (max 1MB)")
+	if !validPublishWindow(input.PublishAt.Value, input.UnpublishAt.Value) {
+		utils.BadRequestError(c, "invalid_publish_window")
+		return
+	}
+	format, source, html, ok := renderPageInput(c, input)
+	if !ok {
 		return
 	}
 
@@ -161,9 +169,13 @@ func CreatePage(c *gin.Context) {
 
 	// Create database record
 	page := models.Page{
-		Slug:     input.Slug,
-		Title:    input.Title,
-		MinioKey: fmt.Sprintf("%s.html", input.Slug),
+		Slug:        input.Slug,
+		Title:       input.Title,
+		MinioKey:    fmt.Sprintf("%s.html", input.Slug),
+		Format:      format,
+		Draft:       input.Draft != nil && *input.Draft,
+		PublishAt:   input.PublishAt.Value,
+		UnpublishAt: input.UnpublishAt.Value,
 	}
 
 	if err := config.DB.Create(&page).Error; err != nil {
@@ -172,29 +184,24 @@ func CreatePage(c *gin.Context) {
 	}
 
 	// Upload HTML to MinIO
-	objectKey := fmt.Sprintf("%s.html", page.Slug)
-	reader := bytes.NewReader([]byte(sanitizedHTML))
-
-	_, err = config.FS.PutObject(
-		ctx,
-		pagesBucket,
-		objectKey,
-		reader,
-		int64(len(sanitizedHTML)),
-		minio.PutObjectOptions{ContentType: "text/html; charset=utf-8"},
-	)
-
-	if err != nil {
+	if err := putPageObject(ctx, page.MinioKey, html); err != nil {
 		// Rollback database record
 		config.DB.Delete(&page)
 		utils.InternalServerError(c, "Failed to upload page content")
 		return
 	}
 
+	authorID := c.GetUint("user_id")
+	if _, err := createPageRevision(page, source, &authorID, nil); err != nil {
+		logger.Ctx(c).Errorf("Failed to store revision of page %d: %v", page.ID, err)
+	}
+
+	utils.RecordAudit(c, "page.create", "page", page.ID, nil, page)
+
 	utils.CreatedResponse(c, gin.H{"page": page})
 }
 
-// UpdatePage updates an existing custom page
+// UpdatePage updates an existing custom page and stores the new content as a revision
 func UpdatePage(c *gin.Context) {
 	id := c.Param("id")
 
@@ -204,12 +211,7 @@ func UpdatePage(c *gin.Context) {
 		return
 	}
 
-	var input struct {
-		Slug  string `json:"slug" binding:"required"`
-		Title string `json:"title" binding:"required"`
-		HTML  string `json:"html" binding:"required"`
-	}
-
+	var input dto.PageInput
 	if err := c.ShouldBindJSON(&input); err != nil {
 		utils.BadRequestError(c, "Invalid input: "+err.Error())
 		return
@@ -234,31 +236,34 @@ func UpdatePage(c *gin.Context) {
 		}
 	}
 
-	// Sanitize HTML content
-	sanitizedHTML := utils.SanitizePageHTML(input.HTML)
-
-	// Validate content size (max 1MB)
-	if len(sanitizedHTML) > 1024*1024 {
-		utils.BadRequestError(c, "HTML content is too large (max 1MB)")
+	// The schedule is kept when the fields are omitted, the editor does not send them
+	publishAt, unpublishAt := page.PublishAt, page.UnpublishAt
+	if input.PublishAt.Set {
+		publishAt = input.PublishAt.Value
+	}
+	if input.UnpublishAt.Set {
+		unpublishAt = input.UnpublishAt.Value
+	}
+	if !validPublishWindow(publishAt, unpublishAt) {
+		utils.BadRequestError(c, "invalid_publish_window")
 		return
 	}
+	format, source, html, ok := renderPageInput(c, input)
+	if !ok {
+		return
+	}
+
+	// Pages saved before revisions existed keep their current content as the first revision
+	if err := snapshotPage(page); err != nil {
+		logger.Ctx(c).Warnf("Failed to snapshot page %d: %v", page.ID, err)
+	}
 
 	ctx := context.Background()
 	oldObjectKey := page.MinioKey
 	newObjectKey := fmt.Sprintf("%s.html", input.Slug)
 
 	// Upload new HTML content
-	reader := bytes.NewReader([]byte(sanitizedHTML))
-	_, err := config.FS.PutObject(
-		ctx,
-		pagesBucket,
-		newObjectKey,
-		reader,
-		int64(len(sanitizedHTML)),
-		minio.PutObjectOptions{ContentType: "text/html; charset=utf-8"},
-	)
-
-	if err != nil {
+	if err := putPageObject(ctx, newObjectKey, html); err != nil {
 		utils.InternalServerError(c, "Failed to upload page content")
 		return
 	}
@@ -269,15 +274,29 @@ func UpdatePage(c *gin.Context) {
 	}
 
 	// Update database record
+	before := page
 	page.Slug = input.Slug
 	page.Title = input.Title
 	page.MinioKey = newObjectKey
+	page.Format = format
+	page.PublishAt = publishAt
+	page.UnpublishAt = unpublishAt
+	if input.Draft != nil {
+		page.Draft = *input.Draft
+	}
 
 	if err := config.DB.Save(&page).Error; err != nil {
 		utils.InternalServerError(c, "Failed to update page")
 		return
 	}
 
+	authorID := c.GetUint("user_id")
+	if _, err := createPageRevision(page, source, &authorID, nil); err != nil {
+		logger.Ctx(c).Errorf("Failed to store revision of page %d: %v", page.ID, err)
+	}
+
+	utils.RecordAudit(c, "page.update", "page", page.ID, before, page)
+
 	utils.SuccessResponse(c, http.StatusOK, gin.H{"page": page})
 }
 
@@ -304,6 +323,8 @@ func DeletePage(c *gin.Context) {
 		return
 	}
 
+	utils.RecordAudit(c, "page.delete", "page", page.ID, page, nil)
+
 	utils.SuccessResponse(c, http.StatusOK, gin.H{"message": "Page deleted successfully"})
 }
 
@@ -313,7 +334,7 @@ func ServePublicPage(c *gin.Context) {
 
 	// Lookup page in database
 	var page models.Page
-	if err := config.DB.Where("slug = ?", slug).First(&page).Error; err != nil {
+	if err := visiblePages().Where("slug = ?", slug).First(&page).Error; err != nil {
 		// Page not found, let Next.js handle 404
 		c.JSON(http.StatusNotFound, gin.H{"error": "Page not found"})
 		return
@@ -334,8 +355,8 @@ func ServePublicPage(c *gin.Context) {
 		return
 	}
 
-	// Sanitize HTML content before serving
-	sanitizedHTML := utils.SanitizePageHTML(buf.String())
+	// Fill in template variables and widgets, then sanitize HTML content before serving
+	sanitizedHTML := utils.SanitizePageHTML(renderPageTemplate(buf.String()))
 
 	// Set security headers
 	c.Header("Content-Type", "text/html; charset=utf-8")
@@ -354,7 +375,7 @@ func ServePublicPageAPI(c *gin.Context) {
 
 	// Lookup page in database
 	var page models.Page
-	if err := config.DB.Where("slug = ?", slug).First(&page).Error; err != nil {
+	if err := visiblePages().Where("slug = ?", slug).First(&page).Error; err != nil {
 		// Page not found
 		c.JSON(http.StatusNotFound, gin.H{"error": "Page not found"})
 		return
@@ -375,8 +396,8 @@ func ServePublicPageAPI(c *gin.Context) {
 		return
 	}
 
-	// Sanitize HTML content before serving
-	sanitizedHTML := utils.SanitizePageHTML(buf.String())
+	// Fill in template variables and widgets, then sanitize HTML content before serving
+	sanitizedHTML := utils.SanitizePageHTML(renderPageTemplate(buf.String()))
 
 	// Return JSON response with page metadata and sanitized HTML content
 	c.JSON(http.StatusOK, gin.H{
@@ -391,3 +412,9 @@ func ServePublicPageAPI(c *gin.Context) {
 		},
 	})
 }
+
+// visiblePages scopes a query to the pages that are not drafts and within their publish window
+func visiblePages() *gorm.DB {
+	now := time.Now()
+	return config.DB.Where(models.PageVisibleCondition, false, now, now)
+}
@@ -0,0 +1,258 @@
+package controllers
+
+import (
+	"bytes"
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/minio/minio-go/v7"
+	"github.com/pwnthemall/pwnthemall/backend/config"
+	"github.com/pwnthemall/pwnthemall/backend/dto"
+	"github.com/pwnthemall/pwnthemall/backend/logger"
+	"github.com/pwnthemall/pwnthemall/backend/models"
+	"github.com/pwnthemall/pwnthemall/backend/utils"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const maxPageSize = 1024 * 1024
+
+// GetPageRevisions lists the revisions of a page, newest first, without their content
+func GetPageRevisions(c *gin.Context) {
+	page, ok := findPage(c)
+	if !ok {
+		return
+	}
+
+	var revisions []models.PageRevision
+	if err := config.DB.Omit("content").Preload("Author", func(db *gorm.DB) *gorm.DB {
+		return db.Select("id", "username")
+	}).Where("page_id = ?", page.ID).Order("number DESC").Find(&revisions).Error; err != nil {
+		utils.InternalServerError(c, "failed_to_fetch_page_revisions")
+		return
+	}
+
+	response := make([]dto.PageRevisionDTO, len(revisions))
+	for i, revision := range revisions {
+		response[i] = pageRevisionToDTO(revision)
+	}
+	utils.OKResponse(c, response)
+}
+
+// GetPageRevision returns a single revision of a page with its source
+func GetPageRevision(c *gin.Context) {
+	page, ok := findPage(c)
+	if !ok {
+		return
+	}
+	revision, ok := findPageRevision(c, page.ID, c.Param("revision"))
+	if !ok {
+		return
+	}
+	utils.OKResponse(c, pageRevisionToDTO(revision))
+}
+
+// GetPageDiff returns the unified diff between two revisions, by default the latest one and the one before it
+func GetPageDiff(c *gin.Context) {
+	page, ok := findPage(c)
+	if !ok {
+		return
+	}
+
+	to, ok := findPageRevision(c, page.ID, c.DefaultQuery("to", "latest"))
+	if !ok {
+		return
+	}
+	// Revision 0 is the empty page, so the first revision diffs against nothing
+	var from models.PageRevision
+	if fromParam := c.DefaultQuery("from", strconv.Itoa(to.Number-1)); fromParam != "0" {
+		if from, ok = findPageRevision(c, page.ID, fromParam); !ok {
+			return
+		}
+	}
+
+	diff, err := utils.DiffPageRevisions(from, to)
+	if err != nil {
+		logger.Ctx(c).Errorf("Failed to diff revisions of page %d: %v", page.ID, err)
+		utils.InternalServerError(c, "failed_to_diff_page_revisions")
+		return
+	}
+	utils.OKResponse(c, dto.PageDiffResponse{From: from.Number, To: to.Number, Diff: diff})
+}
+
+// RollbackPage republishes the content of an older revision as a new revision
+func RollbackPage(c *gin.Context) {
+	page, ok := findPage(c)
+	if !ok {
+		return
+	}
+	target, ok := findPageRevision(c, page.ID, c.Param("revision"))
+	if !ok {
+		return
+	}
+
+	html, err := utils.RenderPageContent(target.Format, target.Content)
+	if err != nil {
+		logger.Ctx(c).Errorf("Failed to render revision %d of page %d: %v", target.Number, page.ID, err)
+		utils.InternalServerError(c, "failed_to_render_page")
+		return
+	}
+	if err := putPageObject(context.Background(), page.MinioKey, html); err != nil {
+		utils.InternalServerError(c, "failed_to_upload_page_content")
+		return
+	}
+
+	before := page
+	page.Title = target.Title
+	page.Format = target.Format
+	if err := config.DB.Save(&page).Error; err != nil {
+		utils.InternalServerError(c, "failed_to_update_page")
+		return
+	}
+
+	authorID := c.GetUint("user_id")
+	revision, err := createPageRevision(page, target.Content, &authorID, &target.Number)
+	if err != nil {
+		logger.Ctx(c).Errorf("Failed to store revision of page %d: %v", page.ID, err)
+		utils.InternalServerError(c, "failed_to_create_page_revision")
+		return
+	}
+
+	utils.RecordAudit(c, "page.rollback", "page", page.ID, before, gin.H{"revision": revision.Number, "restoredFrom": target.Number})
+	utils.OKResponse(c, gin.H{"page": page, "revision": pageRevisionToDTO(revision)})
+}
+
+// validPublishWindow reports whether a page is unpublished after it is published
+func validPublishWindow(publishAt, unpublishAt *time.Time) bool {
+	return publishAt == nil || unpublishAt == nil || unpublishAt.After(*publishAt)
+}
+
+// renderPageInput picks the source matching the format of the input and renders it, the response is sent on failure
+func renderPageInput(c *gin.Context, input dto.PageInput) (format, source, html string, ok bool) {
+	format, source = models.PageFormatHTML, input.HTML
+	if input.Format == models.PageFormatMarkdown {
+		format, source = models.PageFormatMarkdown, input.Markdown
+	}
+	if source == "" {
+		utils.BadRequestError(c, "content_required")
+		return
+	}
+	if len(source) > maxPageSize {
+		utils.BadRequestError(c, "HTML content is too large (max 1MB)")
+		return
+	}
+
+	html, err := utils.RenderPageContent(format, source)
+	if err != nil {
+		logger.Ctx(c).Errorf("Failed to render page %s: %v", input.Slug, err)
+		utils.BadRequestError(c, "failed_to_render_page")
+		return
+	}
+	if len(html) > maxPageSize {
+		utils.BadRequestError(c, "HTML content is too large (max 1MB)")
+		return
+	}
+	return format, source, html, true
+}
+
+// putPageObject uploads the rendered HTML of a page
+func putPageObject(ctx context.Context, key, html string) error {
+	_, err := config.FS.PutObject(ctx, pagesBucket, key, bytes.NewReader([]byte(html)), int64(len(html)),
+		minio.PutObjectOptions{ContentType: "text/html; charset=utf-8"})
+	return err
+}
+
+// createPageRevision stores the source of a page as its next revision
+func createPageRevision(page models.Page, source string, authorID *uint, restoredFrom *int) (models.PageRevision, error) {
+	revision := models.PageRevision{
+		PageID:       page.ID,
+		Title:        page.Title,
+		Format:       page.Format,
+		Content:      source,
+		AuthorID:     authorID,
+		RestoredFrom: restoredFrom,
+	}
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		// Lock the page so concurrent saves don't pick the same number
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").First(&models.Page{}, page.ID).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.PageRevision{}).Where("page_id = ?", page.ID).
+			Select("COALESCE(MAX(number), 0) + 1").Scan(&revision.Number).Error; err != nil {
+			return err
+		}
+		return tx.Create(&revision).Error
+	})
+	return revision, err
+}
+
+// snapshotPage stores the current content of a page without revisions as its first revision
+func snapshotPage(page models.Page) error {
+	var count int64
+	if err := config.DB.Model(&models.PageRevision{}).Where("page_id = ?", page.ID).Count(&count).Error; err != nil || count > 0 {
+		return err
+	}
+
+	obj, err := config.FS.GetObject(context.Background(), pagesBucket, page.MinioKey, minio.GetObjectOptions{})
+	if err != nil {
+		return err
+	}
+	defer obj.Close()
+	buf := new(bytes.Buffer)
+	if _, err := buf.ReadFrom(obj); err != nil {
+		return err
+	}
+
+	page.Format = models.PageFormatHTML
+	_, err = createPageRevision(page, buf.String(), nil, nil)
+	return err
+}
+
+func findPage(c *gin.Context) (models.Page, bool) {
+	var page models.Page
+	if err := config.DB.First(&page, c.Param("id")).Error; err != nil {
+		utils.NotFoundError(c, "page_not_found")
+		return page, false
+	}
+	return page, true
+}
+
+// findPageRevision loads a revision of a page by number, "latest" for the newest one
+func findPageRevision(c *gin.Context, pageID uint, number string) (models.PageRevision, bool) {
+	var revision models.PageRevision
+	query := config.DB.Preload("Author", func(db *gorm.DB) *gorm.DB {
+		return db.Select("id", "username")
+	}).Where("page_id = ?", pageID)
+	if number == "latest" {
+		query = query.Order("number DESC")
+	} else if n, err := strconv.Atoi(number); err == nil {
+		query = query.Where("number = ?", n)
+	} else {
+		utils.BadRequestError(c, "invalid_revision")
+		return revision, false
+	}
+	if err := query.First(&revision).Error; err != nil {
+		utils.NotFoundError(c, "page_revision_not_found")
+		return revision, false
+	}
+	return revision, true
+}
+
+func pageRevisionToDTO(revision models.PageRevision) dto.PageRevisionDTO {
+	response := dto.PageRevisionDTO{
+		ID:           revision.ID,
+		Number:       revision.Number,
+		Title:        revision.Title,
+		Format:       revision.Format,
+		Content:      revision.Content,
+		AuthorID:     revision.AuthorID,
+		RestoredFrom: revision.RestoredFrom,
+		CreatedAt:    revision.CreatedAt,
+	}
+	if revision.Author != nil {
+		response.AuthorName = revision.Author.Username
+	}
+	return response
+}
@@ -0,0 +1,178 @@
+package controllers
+
+import (
+	"fmt"
+	"html"
+	"html/template"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pwnthemall/pwnthemall/backend/config"
+	"github.com/pwnthemall/pwnthemall/backend/dto"
+	"github.com/pwnthemall/pwnthemall/backend/logger"
+	"github.com/pwnthemall/pwnthemall/backend/models"
+)
+
+const (
+	pageTemplateCacheDuration = 30 * time.Second
+	pageScoreboardSize        = 10
+)
+
+// pagePlaceholderRegex matches {{ctf.start}} style variables and {{widget:scoreboard}} style widgets
+var pagePlaceholderRegex = regexp.MustCompile(`\{\{\s*([a-z]+(?:\.[a-z_]+|:[a-z_]+))\s*\}\}`)
+
+// html/template escapes team names, the result goes through SanitizePageHTML afterwards anyway
+var pageWidgetTemplates = template.Must(template.New("widgets").Parse(
+	`{{define "scoreboard"}}<table class="pta-scoreboard"><thead><tr><th>#</th><th>Team</th><th>Points</th><th>Solves</th></tr></thead><tbody>` +
+		`{{range .}}<tr><td>{{.Rank}}</td><td>{{.Team.Name}}</td><td>{{.TotalScore}}</td><td>{{.SolveCount}}</td></tr>{{end}}` +
+		`</tbody></table>{{end}}` +
+		`{{define "countdown"}}<time class="pta-countdown pta-countdown-{{.Status}}" datetime="{{.Target}}">{{.Remaining}}</time>{{end}}`,
+))
+
+// pageTemplateData holds the values placeholders are replaced with
+type pageTemplateData struct {
+	vars       map[string]string
+	start, end *time.Time
+	scoreboard []dto.TeamScore
+}
+
+// Cache of the page template data, shared by every page
+var (
+	pageTemplateCache      *pageTemplateData
+	pageTemplateCacheTime  time.Time
+	pageTemplateCacheMutex sync.Mutex
+)
+
+// renderPageTemplate replaces the placeholders of a page, unknown placeholders are left untouched
+func renderPageTemplate(content string) string {
+	if !strings.Contains(content, "{{") {
+		return content
+	}
+
+	data := getPageTemplateData()
+	now := time.Now()
+	return pagePlaceholderRegex.ReplaceAllStringFunc(content, func(match string) string {
+		name := pagePlaceholderRegex.FindStringSubmatch(match)[1]
+		switch name {
+		case "ctf.status":
+			return string(data.status(now))
+		case "widget:countdown":
+			if countdown := data.countdown(now); countdown != nil {
+				return renderPageWidget("countdown", countdown)
+			}
+			return ""
+		case "widget:scoreboard":
+			return renderPageWidget("scoreboard", data.scoreboard)
+		}
+		if value, ok := data.vars[name]; ok {
+			return html.EscapeString(value)
+		}
+		return match
+	})
+}
+
+// getPageTemplateData returns the cached template data, rebuilt at most every 30 seconds
+func getPageTemplateData() *pageTemplateData {
+	pageTemplateCacheMutex.Lock()
+	defer pageTemplateCacheMutex.Unlock()
+
+	if pageTemplateCache != nil && time.Since(pageTemplateCacheTime) < pageTemplateCacheDuration {
+		return pageTemplateCache
+	}
+
+	data := &pageTemplateData{
+		vars:  map[string]string{"site.name": config.GetConfigValue("SITE_NAME", "pwnthemall")},
+		start: parseCTFTime(config.GetConfigValue("CTF_START_TIME", "")),
+		end:   parseCTFTime(config.GetConfigValue("CTF_END_TIME", "")),
+	}
+	data.vars["ctf.start"] = formatCTFTime(data.start)
+	data.vars["ctf.end"] = formatCTFTime(data.end)
+
+	var teams, users, solves, challenges int64
+	config.DB.Model(&models.Team{}).Count(&teams)
+	config.DB.Model(&models.User{}).Where("banned = ?", false).Count(&users)
+	config.DB.Model(&models.Solve{}).Count(&solves)
+	config.DB.Model(&models.Challenge{}).Where("hidden = ?", false).Count(&challenges)
+	data.vars["stats.teams"] = strconv.FormatInt(teams, 10)
+	data.vars["stats.users"] = strconv.FormatInt(users, 10)
+	data.vars["stats.solves"] = strconv.FormatInt(solves, 10)
+	data.vars["stats.challenges"] = strconv.FormatInt(challenges, 10)
+
+	leaderboard, err := buildLeaderboard()
+	if err != nil {
+		logger.Errorf("Failed to build page scoreboard: %v", err)
+	}
+	data.scoreboard = leaderboard[:min(len(leaderboard), pageScoreboardSize)]
+
+	pageTemplateCache = data
+	pageTemplateCacheTime = time.Now()
+	return data
+}
+
+// status mirrors config.GetCTFStatus with the cached times
+func (d *pageTemplateData) status(now time.Time) config.CTFStatus {
+	switch {
+	case d.start == nil || d.end == nil:
+		return config.CTFNoTiming
+	case now.Before(*d.start):
+		return config.CTFNotStarted
+	case now.After(*d.end):
+		return config.CTFEnded
+	default:
+		return config.CTFActive
+	}
+}
+
+type pageCountdown struct {
+	Status    config.CTFStatus
+	Target    string
+	Remaining string
+}
+
+// countdown counts down to the start of the CTF, then to its end, nil without timing
+func (d *pageTemplateData) countdown(now time.Time) *pageCountdown {
+	status := d.status(now)
+	target := d.end
+	switch status {
+	case config.CTFNoTiming:
+		return nil
+	case config.CTFNotStarted:
+		target = d.start
+	}
+
+	remaining := max(target.Sub(now), 0).Truncate(time.Second)
+	days := int(remaining.Hours()) / 24
+	return &pageCountdown{
+		Status: status,
+		Target: target.UTC().Format(time.RFC3339),
+		Remaining: fmt.Sprintf("%dd %02d:%02d:%02d", days, int(remaining.Hours())%24,
+			int(remaining.Minutes())%60, int(remaining.Seconds())%60),
+	}
+}
+
+func renderPageWidget(name string, data any) string {
+	var buf strings.Builder
+	if err := pageWidgetTemplates.ExecuteTemplate(&buf, name, data); err != nil {
+		logger.Errorf("Failed to render %s page widget: %v", name, err)
+		return ""
+	}
+	return buf.String()
+}
+
+func parseCTFTime(value string) *time.Time {
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil
+	}
+	return &t
+}
+
+func formatCTFTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.UTC().Format("2006-01-02 15:04 UTC")
+}
@@ -0,0 +1,341 @@
+package controllers
+
+import (
+	"errors"
+	"slices"
+	"strings"
+
+	"github.com/casbin/casbin/v2"
+	"github.com/gin-gonic/gin"
+	"github.com/pwnthemall/pwnthemall/backend/config"
+	"github.com/pwnthemall/pwnthemall/backend/dto"
+	"github.com/pwnthemall/pwnthemall/backend/logger"
+	"github.com/pwnthemall/pwnthemall/backend/models"
+	"github.com/pwnthemall/pwnthemall/backend/utils"
+	"gorm.io/gorm"
+)
+
+// GetRoles lists built-in and custom roles with their permissions
+func GetRoles(c *gin.Context) {
+	var custom []models.Role
+	if err := config.DB.Order("name").Find(&custom).Error; err != nil {
+		utils.InternalServerError(c, "Failed to fetch roles")
+		return
+	}
+	descriptions := make(map[string]string, len(custom))
+	for _, role := range custom {
+		descriptions[role.Name] = role.Description
+	}
+
+	roles := make([]dto.RoleWithPermissions, 0, len(config.BuiltinRoles)+len(custom))
+	config.ReadPolicy(func(e *casbin.Enforcer) {
+		for _, name := range config.AllRoleNames() {
+			permissions, _ := e.GetFilteredPolicy(0, name)
+			inherits, _ := e.GetRolesForUser(name)
+			role := dto.RoleWithPermissions{
+				Name:        name,
+				Description: descriptions[name],
+				BuiltIn:     slices.Contains(config.BuiltinRoles, name),
+				Inherits:    inherits,
+				Permissions: make([][]string, 0, len(permissions)),
+			}
+			for _, p := range permissions {
+				role.Permissions = append(role.Permissions, p[1:])
+			}
+			roles = append(roles, role)
+		}
+	})
+	for i := range roles {
+		config.DB.Model(&models.User{}).Where("role = ?", roles[i].Name).Count(&roles[i].UserCount)
+	}
+
+	utils.OKResponse(c, roles)
+}
+
+// CreateRole adds a custom role, optionally inheriting the permissions of another one
+func CreateRole(c *gin.Context) {
+	var input dto.RoleInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.BadRequestError(c, "invalid_input")
+		return
+	}
+	input.Name = strings.ToLower(strings.TrimSpace(input.Name))
+	if !config.ValidRoleName(input.Name) {
+		utils.BadRequestError(c, "invalid_role_name")
+		return
+	}
+	if errKey := validateRoleParent(input.Name, input.Inherits); errKey != "" {
+		utils.BadRequestError(c, errKey)
+		return
+	}
+	if input.Inherits != "" && !callerCoversRole(c, input.Inherits) {
+		utils.ForbiddenError(c, "role_not_held")
+		return
+	}
+
+	role := models.Role{Name: input.Name, Description: input.Description}
+	if err := config.DB.Create(&role).Error; err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) || strings.Contains(err.Error(), "duplicate key") {
+			utils.ConflictError(c, "role_already_exists")
+			return
+		}
+		utils.InternalServerError(c, "Failed to create role")
+		return
+	}
+
+	if err := setRoleParent(role.Name, input.Inherits); err != nil {
+		utils.InternalServerError(c, "Failed to update role")
+		return
+	}
+	utils.RecordAudit(c, "role.create", "role", role.Name, nil, gin.H{"description": role.Description, "inherits": input.Inherits})
+	utils.CreatedResponse(c, role)
+}
+
+// UpdateRole changes the description and parent of a custom role
+func UpdateRole(c *gin.Context) {
+	var role models.Role
+	if err := config.DB.Where("name = ?", c.Param("name")).First(&role).Error; err != nil {
+		utils.NotFoundError(c, "role_not_found")
+		return
+	}
+
+	var input dto.RoleInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.BadRequestError(c, "invalid_input")
+		return
+	}
+	if errKey := validateRoleParent(role.Name, input.Inherits); errKey != "" {
+		utils.BadRequestError(c, errKey)
+		return
+	}
+	if input.Inherits != "" && !callerCoversRole(c, input.Inherits) {
+		utils.ForbiddenError(c, "role_not_held")
+		return
+	}
+
+	before := gin.H{"description": role.Description, "inherits": roleParents(role.Name)}
+	role.Description = input.Description
+	if err := config.DB.Save(&role).Error; err != nil {
+		utils.InternalServerError(c, "Failed to update role")
+		return
+	}
+	if err := setRoleParent(role.Name, input.Inherits); err != nil {
+		utils.InternalServerError(c, "Failed to update role")
+		return
+	}
+	utils.RecordAudit(c, "role.update", "role", role.Name, before, gin.H{"description": role.Description, "inherits": roleParents(role.Name)})
+	utils.OKResponse(c, role)
+}
+
+// DeleteRole removes a custom role, its users fall back to member
+func DeleteRole(c *gin.Context) {
+	var role models.Role
+	if err := config.DB.Where("name = ?", c.Param("name")).First(&role).Error; err != nil {
+		utils.NotFoundError(c, "role_not_found")
+		return
+	}
+
+	var userIDs []uint
+	config.DB.Model(&models.User{}).Where("role = ?", role.Name).Pluck("id", &userIDs)
+	if err := config.DB.Model(&models.User{}).Where("role = ?", role.Name).Update("role", "member").Error; err != nil {
+		utils.InternalServerError(c, "Failed to update users")
+		return
+	}
+	for _, id := range userIDs {
+		if _, err := utils.RevokeUserSessions(id, ""); err != nil {
+			logger.Ctx(c).Errorf("Failed to revoke sessions of user %d: %v", id, err)
+		}
+	}
+
+	// DeleteRole drops both the role permissions and the inheritance rules mentioning it
+	err := config.UpdatePolicy(func(e *casbin.Enforcer) error {
+		_, err := e.DeleteRole(role.Name)
+		return err
+	})
+	if err != nil {
+		utils.InternalServerError(c, "Failed to delete role permissions")
+		return
+	}
+
+	if err := config.DB.Delete(&role).Error; err != nil {
+		utils.InternalServerError(c, "Failed to delete role")
+		return
+	}
+	utils.RecordAudit(c, "role.delete", "role", role.Name, gin.H{"description": role.Description, "users": userIDs}, nil)
+	utils.OKResponse(c, gin.H{"message": "role_deleted", "usersReset": len(userIDs)})
+}
+
+// GrantPermission allows a role to perform act on obj
+func GrantPermission(c *gin.Context) {
+	role, input, ok := bindPermission(c)
+	if !ok {
+		return
+	}
+	// A permission the caller lacks could be granted to their own role, so only held ones can be passed on
+	if !callerHolds(c, input.Obj, input.Act) {
+		utils.ForbiddenError(c, "permission_not_held")
+		return
+	}
+	err := config.UpdatePolicy(func(e *casbin.Enforcer) error {
+		_, err := e.AddPolicy(role, input.Obj, input.Act)
+		return err
+	})
+	if err != nil {
+		utils.InternalServerError(c, "Failed to grant permission")
+		return
+	}
+	utils.RecordAudit(c, "role.permission_grant", "role", role, nil, input)
+	utils.OKResponse(c, gin.H{"message": "permission_granted"})
+}
+
+// RevokePermission removes a permission from a role
+func RevokePermission(c *gin.Context) {
+	role, input, ok := bindPermission(c)
+	if !ok {
+		return
+	}
+	var removed bool
+	err := config.UpdatePolicy(func(e *casbin.Enforcer) error {
+		var err error
+		removed, err = e.RemovePolicy(role, input.Obj, input.Act)
+		return err
+	})
+	if err != nil {
+		utils.InternalServerError(c, "Failed to revoke permission")
+		return
+	}
+	if !removed {
+		utils.NotFoundError(c, "permission_not_found")
+		return
+	}
+	utils.RecordAudit(c, "role.permission_revoke", "role", role, input, nil)
+	utils.OKResponse(c, gin.H{"message": "permission_revoked"})
+}
+
+// SetUserRole assigns a role to a user, their sessions are revoked so the new role applies everywhere
+func SetUserRole(c *gin.Context) {
+	var user models.User
+	if err := config.DB.First(&user, c.Param("id")).Error; err != nil {
+		utils.NotFoundError(c, "User not found")
+		return
+	}
+
+	var input dto.UserRoleInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.BadRequestError(c, "invalid_input")
+		return
+	}
+	if currentID, ok := utils.GetAuthenticatedUserID(c); ok && currentID == user.ID && input.Role != user.Role {
+		utils.BadRequestError(c, "cannot_change_own_role")
+		return
+	}
+	if errKey := checkRoleAssignment(c, user.Role, input.Role); errKey != "" {
+		utils.ForbiddenError(c, errKey)
+		return
+	}
+
+	if input.Role != user.Role {
+		if err := config.DB.Model(&user).Update("role", input.Role).Error; err != nil {
+			utils.InternalServerError(c, "Failed to update role")
+			return
+		}
+		if _, err := utils.RevokeUserSessions(user.ID, ""); err != nil {
+			logger.Ctx(c).Errorf("Failed to revoke sessions of user %d: %v", user.ID, err)
+		}
+		utils.RecordAudit(c, "user.role_change", "user", user.ID, gin.H{"role": user.Role}, gin.H{"role": input.Role})
+	}
+	utils.OKResponse(c, gin.H{"id": user.ID, "role": input.Role})
+}
+
+// checkRoleAssignment returns an error key when the caller may not move a user from oldRole to newRole
+// The caller must hold every permission of both roles, so delegated user management cannot escalate
+func checkRoleAssignment(c *gin.Context, oldRole, newRole string) string {
+	if newRole == "anonymous" || !config.RoleExists(newRole) {
+		return "role_not_found"
+	}
+	if oldRole != newRole && (!callerCoversRole(c, oldRole) || !callerCoversRole(c, newRole)) {
+		return "role_not_held"
+	}
+	return ""
+}
+
+// callerHolds reports whether the current user may perform act on obj
+func callerHolds(c *gin.Context, obj, act string) bool {
+	caller, ok := utils.GetAuthenticatedUser(c)
+	if !ok {
+		return false
+	}
+	allowed, err := config.Enforce(caller.Role, obj, act)
+	return err == nil && allowed
+}
+
+// callerCoversRole reports whether the current user holds every permission of role
+func callerCoversRole(c *gin.Context, role string) bool {
+	caller, ok := utils.GetAuthenticatedUser(c)
+	return ok && config.RoleCovers(caller.Role, role)
+}
+
+// bindPermission reads a permission request for a custom role
+// Built-in roles are defined by casbin_policies.csv, which is reapplied on startup
+func bindPermission(c *gin.Context) (string, dto.PermissionInput, bool) {
+	var input dto.PermissionInput
+	role := c.Param("name")
+	if slices.Contains(config.BuiltinRoles, role) {
+		utils.ForbiddenError(c, "builtin_role_is_locked")
+		return "", input, false
+	}
+	if !config.RoleExists(role) {
+		utils.NotFoundError(c, "role_not_found")
+		return "", input, false
+	}
+	if err := c.ShouldBindJSON(&input); err != nil || !strings.HasPrefix(input.Obj, "/") {
+		utils.BadRequestError(c, "invalid_permission")
+		return "", input, false
+	}
+	return role, input, true
+}
+
+// validateRoleParent checks the role a custom role inherits from
+func validateRoleParent(name, parent string) string {
+	if parent == "" {
+		return ""
+	}
+	if parent == name || parent == "admin" || !config.RoleExists(parent) {
+		return "invalid_parent_role"
+	}
+	var inheritsBack bool
+	config.ReadPolicy(func(e *casbin.Enforcer) {
+		inheritsBack, _ = e.HasRoleForUser(parent, name)
+		if !inheritsBack {
+			implicit, _ := e.GetImplicitRolesForUser(parent)
+			inheritsBack = slices.Contains(implicit, name)
+		}
+	})
+	if inheritsBack {
+		return "invalid_parent_role"
+	}
+	return ""
+}
+
+// setRoleParent replaces the parent role of a custom role
+func setRoleParent(name, parent string) error {
+	return config.UpdatePolicy(func(e *casbin.Enforcer) error {
+		if _, err := e.DeleteRolesForUser(name); err != nil {
+			return err
+		}
+		if parent == "" {
+			return nil
+		}
+		_, err := e.AddRoleForUser(name, parent)
+		return err
+	})
+}
+
+// roleParents returns the roles a role inherits from
+func roleParents(name string) []string {
+	var parents []string
+	config.ReadPolicy(func(e *casbin.Enforcer) {
+		parents, _ = e.GetRolesForUser(name)
+	})
+	return parents
+}
@@ -0,0 +1,87 @@
+package controllers
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pwnthemall/pwnthemall/backend/config"
+	"github.com/pwnthemall/pwnthemall/backend/models"
+	"github.com/pwnthemall/pwnthemall/backend/utils"
+)
+
+// currentSessionJTI returns the refresh jti of the session making the request, if any
+func currentSessionJTI(c *gin.Context) string {
+	if claims, err := utils.GetClaimsFromCookie(c); err == nil {
+		return claims.SessionID
+	}
+	return ""
+}
+
+// GetSessions lists the active sessions of the current user
+func GetSessions(c *gin.Context) {
+	user, ok := utils.GetAuthenticatedUser(c)
+	if !ok {
+		utils.UnauthorizedError(c, "unauthorized")
+		return
+	}
+
+	var sessions []models.UserSession
+	if err := config.DB.
+		Where("user_id = ? AND revoked_at IS NULL AND expires_at > ?", user.ID, time.Now()).
+		Order("last_seen_at DESC").
+		Find(&sessions).Error; err != nil {
+		utils.InternalServerError(c, "Failed to fetch sessions")
+		return
+	}
+
+	current := currentSessionJTI(c)
+	result := make([]gin.H, 0, len(sessions))
+	for _, session := range sessions {
+		result = append(result, gin.H{
+			"id":         session.ID,
+			"userAgent":  session.UserAgent,
+			"ipAddress":  session.IPAddress,
+			"createdAt":  session.CreatedAt,
+			"lastSeenAt": session.LastSeenAt,
+			"expiresAt":  session.ExpiresAt,
+			"current":    session.JTI == current,
+		})
+	}
+	utils.OKResponse(c, result)
+}
+
+// RevokeSessionByID logs one of the current user's devices out
+func RevokeSessionByID(c *gin.Context) {
+	user, ok := utils.GetAuthenticatedUser(c)
+	if !ok {
+		utils.UnauthorizedError(c, "unauthorized")
+		return
+	}
+
+	var session models.UserSession
+	if err := config.DB.Where("id = ? AND user_id = ? AND revoked_at IS NULL", c.Param("id"), user.ID).First(&session).Error; err != nil {
+		utils.NotFoundError(c, "session_not_found")
+		return
+	}
+	if err := utils.RevokeSession(&session); err != nil {
+		utils.InternalServerError(c, "Failed to revoke session")
+		return
+	}
+	utils.OKResponse(c, gin.H{"message": "session_revoked"})
+}
+
+// RevokeOtherSessions logs out every device except the one making the request
+func RevokeOtherSessions(c *gin.Context) {
+	user, ok := utils.GetAuthenticatedUser(c)
+	if !ok {
+		utils.UnauthorizedError(c, "unauthorized")
+		return
+	}
+
+	count, err := utils.RevokeUserSessions(user.ID, currentSessionJTI(c))
+	if err != nil {
+		utils.InternalServerError(c, "Failed to revoke sessions")
+		return
+	}
+	utils.OKResponse(c, gin.H{"message": "sessions_revoked", "revoked": count})
+}
@@ -0,0 +1,331 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/pwnthemall/pwnthemall/backend/config"
+	"github.com/pwnthemall/pwnthemall/backend/logger"
+	"github.com/pwnthemall/pwnthemall/backend/models"
+	"github.com/pwnthemall/pwnthemall/backend/shared"
+	"github.com/pwnthemall/pwnthemall/backend/utils"
+	"golang.org/x/oauth2"
+	"gorm.io/gorm"
+)
+
+const (
+	ssoFlowCookie   = "sso_flow"
+	ssoFlowLifetime = 10 * time.Minute
+	// ssoPreAuthCookie carries the pre-auth token of SSO users with 2FA, so it never appears in a URL
+	ssoPreAuthCookie   = "sso_pre_auth"
+	ssoPreAuthLifetime = 5 * time.Minute
+)
+
+// ssoFlowClaims keeps the state of a pending SSO login in a signed cookie
+type ssoFlowClaims struct {
+	State    string `json:"state"`
+	Nonce    string `json:"nonce"`
+	Verifier string `json:"verifier"`
+	Redirect string `json:"redirect"`
+	jwt.RegisteredClaims
+}
+
+// GetSSOConfig tells the frontend whether SSO login is available
+func GetSSOConfig(c *gin.Context) {
+	provider, err := utils.GetOIDCProvider()
+	if err != nil {
+		utils.OKResponse(c, gin.H{"enabled": false})
+		return
+	}
+	utils.OKResponse(c, gin.H{"enabled": true, "name": provider.Config.Name})
+}
+
+// SSOLogin redirects the browser to the identity provider
+func SSOLogin(c *gin.Context) {
+	provider, err := utils.GetOIDCProvider()
+	if err != nil {
+		utils.NotFoundError(c, "sso_disabled")
+		return
+	}
+
+	state, err := utils.GenerateRandomString(16)
+	if err != nil {
+		utils.InternalServerError(c, "sso_state_failed")
+		return
+	}
+	nonce, err := utils.GenerateRandomString(16)
+	if err != nil {
+		utils.InternalServerError(c, "sso_state_failed")
+		return
+	}
+	verifier := oauth2.GenerateVerifier()
+
+	authURL, err := provider.AuthCodeURL(c.Request.Context(), state, nonce, verifier)
+	if err != nil {
+		logger.Ctx(c).Errorf("SSO login failed: %v", err)
+		utils.ErrorResponse(c, http.StatusBadGateway, "sso_provider_unavailable")
+		return
+	}
+
+	flow := ssoFlowClaims{
+		State:    state,
+		Nonce:    nonce,
+		Verifier: verifier,
+		Redirect: safeSSORedirect(c.Query("redirect"), provider.Config.PostLoginRedirect),
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(ssoFlowLifetime)),
+		},
+	}
+	flowToken, err := jwt.NewWithClaims(jwt.SigningMethodHS256, flow).SignedString(utils.DeriveSecret("sso-flow"))
+	if err != nil {
+		utils.InternalServerError(c, "sso_state_failed")
+		return
+	}
+
+	// Lax so the cookie comes back on the top-level redirect from the provider
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(ssoFlowCookie, flowToken, int(ssoFlowLifetime.Seconds()), "/", "", true, true)
+	c.Redirect(http.StatusFound, authURL)
+}
+
+// SSOCallback completes the authorization code flow and logs the user in
+func SSOCallback(c *gin.Context) {
+	provider, err := utils.GetOIDCProvider()
+	if err != nil {
+		utils.NotFoundError(c, "sso_disabled")
+		return
+	}
+
+	flowToken, _ := c.Cookie(ssoFlowCookie)
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(ssoFlowCookie, "", -1, "/", "", true, true)
+
+	var flow ssoFlowClaims
+	token, err := jwt.ParseWithClaims(flowToken, &flow, func(t *jwt.Token) (interface{}, error) {
+		return utils.DeriveSecret("sso-flow"), nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}))
+	if err != nil || !token.Valid || flow.State == "" || c.Query("state") != flow.State {
+		redirectSSOError(c, "sso_invalid_state")
+		return
+	}
+
+	if providerError := c.Query("error"); providerError != "" {
+		logger.Ctx(c).Errorf("SSO provider returned an error: %s (%s)", providerError, c.Query("error_description"))
+		redirectSSOError(c, "sso_provider_error")
+		return
+	}
+
+	identity, err := provider.Exchange(c.Request.Context(), c.Query("code"), flow.Verifier, flow.Nonce)
+	if err != nil {
+		logger.Ctx(c).Errorf("SSO callback failed: %v", err)
+		redirectSSOError(c, "sso_invalid_token")
+		return
+	}
+
+	user, errKey := resolveSSOUser(provider.Config, identity)
+	if errKey != "" {
+		redirectSSOError(c, errKey)
+		return
+	}
+
+	// Users with 2FA finish the login on the frontend with LoginTwoFactor
+	if user.TOTPEnabled {
+		preAuthToken, err := utils.GeneratePreAuthToken(user.ID)
+		if err != nil {
+			redirectSSOError(c, "sso_login_failed")
+			return
+		}
+		c.SetSameSite(http.SameSiteStrictMode)
+		c.SetCookie(ssoPreAuthCookie, preAuthToken, int(ssoPreAuthLifetime.Seconds()), "/", "", true, true)
+		c.Redirect(http.StatusFound, "/login?twoFactorRequired=true")
+		return
+	}
+
+	if err := generateAndSetTokens(c, user.ID, user.Role); err != nil {
+		redirectSSOError(c, "sso_login_failed")
+		return
+	}
+
+	c.Redirect(http.StatusFound, flow.Redirect)
+}
+
+// resolveSSOUser finds, links or provisions the user of an identity and applies role and team mapping
+// It returns an error key when the login must be refused
+func resolveSSOUser(cfg utils.OIDCConfig, identity *utils.OIDCIdentity) (*models.User, string) {
+	var user models.User
+	var link models.UserIdentity
+	err := config.DB.Where("provider = ? AND subject = ?", cfg.Issuer, identity.Subject).First(&link).Error
+	switch {
+	case err == nil:
+		if err := config.DB.First(&user, link.UserID).Error; err != nil {
+			return nil, "sso_login_failed"
+		}
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		found := false
+		// Only a verified email can be trusted to link an existing account
+		if identity.Email != "" && identity.EmailVerified {
+			found = config.DB.Where("LOWER(email) = ?", identity.Email).First(&user).Error == nil
+		}
+		// The local address must be verified too, else whoever registered it first keeps a password on the linked account
+		if found && !user.EmailVerified {
+			return nil, "sso_account_not_linked"
+		}
+		if !found {
+			if !cfg.AutoProvision {
+				return nil, "sso_account_not_linked"
+			}
+			if err := provisionSSOUser(identity, &user); err != nil {
+				logger.Errorf("SSO provisioning failed for %s: %v", identity.Subject, err)
+				return nil, "sso_login_failed"
+			}
+		}
+		link = models.UserIdentity{UserID: user.ID, Provider: cfg.Issuer, Subject: identity.Subject}
+	default:
+		return nil, "sso_login_failed"
+	}
+
+	if user.Banned {
+		return nil, "banned"
+	}
+
+	role := cfg.MapRole(identity.Groups)
+	teamName := identity.Team
+	for _, handler := range shared.ListSSOHandlers() {
+		mapping, err := handler.MapIdentity(toSharedSSOIdentity(cfg.Issuer, identity))
+		if err != nil {
+			logger.Errorf("SSO plugin mapping failed: %v", err)
+			continue
+		}
+		if mapping.Deny {
+			logger.Debugf("SSO login of %s denied by plugin: %s", identity.Subject, mapping.Reason)
+			return nil, "sso_access_denied"
+		}
+		if mapping.Role != "" {
+			role = mapping.Role
+		}
+		if mapping.Team != "" {
+			teamName = mapping.Team
+		}
+	}
+
+	if role != "" && role != user.Role {
+		user.Role = role
+		if err := config.DB.Model(&user).Update("role", role).Error; err != nil {
+			return nil, "sso_login_failed"
+		}
+	}
+	if teamName != "" && user.TeamID == nil {
+		if err := assignSSOTeam(&user, teamName); err != nil {
+			logger.Errorf("SSO team assignment failed for %s: %v", user.Username, err)
+		}
+	}
+
+	link.Email = identity.Email
+	link.LastLoginAt = time.Now()
+	if err := config.DB.Save(&link).Error; err != nil {
+		return nil, "sso_login_failed"
+	}
+	return &user, ""
+}
+
+// provisionSSOUser creates a member account for a new identity
+func provisionSSOUser(identity *utils.OIDCIdentity, user *models.User) error {
+	if identity.Email == "" {
+		return fmt.Errorf("identity has no email")
+	}
+	password, err := utils.RandomPasswordHash()
+	if err != nil {
+		return err
+	}
+	*user = models.User{
+		Username: uniqueSSOUsername(identity),
+		Email:    identity.Email,
+		Password: password,
+		Role:     "member",
+		// Only trust the provider when it says the address is verified
+		EmailVerified: identity.EmailVerified,
+	}
+	return config.DB.Create(user).Error
+}
+
+// uniqueSSOUsername derives a free, valid username from the identity claims
+func uniqueSSOUsername(identity *utils.OIDCIdentity) string {
+	base := identity.Username
+	if base == "" {
+		base, _, _ = strings.Cut(identity.Email, "@")
+	}
+	base = strings.Map(func(r rune) rune {
+		if (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') || r == '_' || r == '-' {
+			return r
+		}
+		return '_'
+	}, base)
+	if len(base) > 26 {
+		base = base[:26]
+	}
+	if utils.ValidateUsername(base) != "" {
+		base = "user"
+	}
+
+	candidate := base
+	for i := 2; ; i++ {
+		var count int64
+		config.DB.Model(&models.User{}).Where("username = ?", candidate).Count(&count)
+		if count == 0 {
+			return candidate
+		}
+		candidate = fmt.Sprintf("%s-%d", base, i)
+	}
+}
+
+// assignSSOTeam puts the user in the named team, creating it when needed
+func assignSSOTeam(user *models.User, teamName string) error {
+	var team models.Team
+	err := config.DB.Where("name = ?", teamName).First(&team).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		password, err := utils.RandomPasswordHash()
+		if err != nil {
+			return err
+		}
+		team = models.Team{Name: teamName, Password: password, CreatorID: user.ID}
+		if err := config.DB.Create(&team).Error; err != nil {
+			return err
+		}
+	} else if err != nil {
+		return err
+	}
+
+	user.TeamID = &team.ID
+	return config.DB.Model(user).Update("team_id", team.ID).Error
+}
+
+func toSharedSSOIdentity(providerName string, identity *utils.OIDCIdentity) shared.SSOIdentity {
+	claims, _ := json.Marshal(identity.Claims)
+	return shared.SSOIdentity{
+		Provider: providerName,
+		Subject:  identity.Subject,
+		Email:    identity.Email,
+		Username: identity.Username,
+		Groups:   identity.Groups,
+		Claims:   claims,
+	}
+}
+
+// safeSSORedirect only accepts local paths to avoid open redirects
+func safeSSORedirect(redirect, fallback string) string {
+	if strings.HasPrefix(redirect, "/") && !strings.HasPrefix(redirect, "//") && !strings.Contains(redirect, "\\") {
+		return redirect
+	}
+	return fallback
+}
+
+func redirectSSOError(c *gin.Context, errKey string) {
+	c.Redirect(http.StatusFound, "/login?sso_error="+url.QueryEscape(errKey))
+}
@@ -0,0 +1,281 @@
+package controllers
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/glebarez/sqlite"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/pwnthemall/pwnthemall/backend/config"
+	"github.com/pwnthemall/pwnthemall/backend/models"
+	"github.com/pwnthemall/pwnthemall/backend/utils"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+)
+
+const testOIDCClientID = "pwnthemall"
+
+// mockIdP is a minimal OpenID Connect provider serving discovery, JWKS and token endpoints
+// Each authorization code returns the ID token claims registered with issue
+type mockIdP struct {
+	server *httptest.Server
+	key    *rsa.PrivateKey
+
+	mu     sync.Mutex
+	claims map[string]jwt.MapClaims
+}
+
+func newMockIdP() *mockIdP {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		panic(err)
+	}
+	idp := &mockIdP{key: key, claims: make(map[string]jwt.MapClaims)}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		writeTestJSON(w, map[string]string{
+			"issuer":                 idp.server.URL,
+			"authorization_endpoint": idp.server.URL + "/authorize",
+			"token_endpoint":         idp.server.URL + "/token",
+			"jwks_uri":               idp.server.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		writeTestJSON(w, map[string]interface{}{
+			"keys": []map[string]string{{
+				"kty": "RSA",
+				"kid": "test",
+				"use": "sig",
+				"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+				"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+			}},
+		})
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil || r.PostForm.Get("code_verifier") == "" {
+			http.Error(w, `{"error":"invalid_request"}`, http.StatusBadRequest)
+			return
+		}
+		idp.mu.Lock()
+		claims, ok := idp.claims[r.PostForm.Get("code")]
+		idp.mu.Unlock()
+		if !ok {
+			http.Error(w, `{"error":"invalid_grant"}`, http.StatusBadRequest)
+			return
+		}
+		token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+		token.Header["kid"] = "test"
+		idToken, err := token.SignedString(key)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		writeTestJSON(w, map[string]interface{}{
+			"access_token": "access",
+			"token_type":   "Bearer",
+			"expires_in":   3600,
+			"id_token":     idToken,
+		})
+	})
+	idp.server = httptest.NewServer(mux)
+	return idp
+}
+
+// issue registers the claims returned for code, with valid defaults for the issuer, audience and lifetime
+func (idp *mockIdP) issue(code string, claims jwt.MapClaims) {
+	now := time.Now()
+	token := jwt.MapClaims{
+		"iss": idp.server.URL,
+		"aud": testOIDCClientID,
+		"iat": now.Unix(),
+		"exp": now.Add(time.Hour).Unix(),
+	}
+	for k, v := range claims {
+		token[k] = v
+	}
+	idp.mu.Lock()
+	idp.claims[code] = token
+	idp.mu.Unlock()
+}
+
+func writeTestJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
+
+var testIdP *mockIdP
+
+func TestMain(m *testing.M) {
+	gin.SetMode(gin.TestMode)
+	utils.AccessSecret = []byte("sso-test-access-secret")
+	utils.RefreshSecret = []byte("sso-test-refresh-secret")
+
+	testIdP = newMockIdP()
+	os.Setenv("PTA_OIDC_ENABLED", "true")
+	os.Setenv("PTA_OIDC_ISSUER", testIdP.server.URL)
+	os.Setenv("PTA_OIDC_CLIENT_ID", testOIDCClientID)
+	os.Setenv("PTA_OIDC_CLIENT_SECRET", "secret")
+	os.Setenv("PTA_OIDC_REDIRECT_URL", "http://ctf.test/api/auth/sso/callback")
+
+	db, err := gorm.Open(sqlite.Open("file::memory:?cache=shared"), &gorm.Config{
+		Logger:                                   gormlogger.Default.LogMode(gormlogger.Silent),
+		DisableForeignKeyConstraintWhenMigrating: true,
+	})
+	if err != nil {
+		panic(err)
+	}
+	if err := db.AutoMigrate(&models.Bracket{}, &models.Team{}, &models.User{}, &models.UserIdentity{}, &models.UserSession{}); err != nil {
+		panic(err)
+	}
+	config.DB = db
+
+	code := m.Run()
+	testIdP.server.Close()
+	os.Exit(code)
+}
+
+// ssoCallback runs SSOCallback with a flow cookie holding state and nonce, and returns the redirect location
+func ssoCallback(t *testing.T, state, nonce, queryState, code string) *url.URL {
+	t.Helper()
+	flow := ssoFlowClaims{
+		State:    state,
+		Nonce:    nonce,
+		Verifier: "verifier-verifier-verifier-verifier-verifier",
+		Redirect: "/challenges",
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(ssoFlowLifetime)),
+		},
+	}
+	flowToken, err := jwt.NewWithClaims(jwt.SigningMethodHS256, flow).SignedString(utils.DeriveSecret("sso-flow"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	router := gin.New()
+	router.GET("/api/auth/sso/callback", SSOCallback)
+	req := httptest.NewRequest(http.MethodGet, "/api/auth/sso/callback?"+url.Values{"state": {queryState}, "code": {code}}.Encode(), nil)
+	req.AddCookie(&http.Cookie{Name: ssoFlowCookie, Value: flowToken})
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusFound {
+		t.Fatalf("expected a redirect, got %d: %s", w.Code, w.Body.String())
+	}
+	location, err := url.Parse(w.Header().Get("Location"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return location
+}
+
+func createTestUser(t *testing.T, username, email string, emailVerified bool) models.User {
+	t.Helper()
+	user := models.User{Username: username, Email: email, Password: "x", Role: "member", EmailVerified: emailVerified}
+	if err := config.DB.Create(&user).Error; err != nil {
+		t.Fatal(err)
+	}
+	return user
+}
+
+func identityUserID(t *testing.T, subject string) (uint, bool) {
+	t.Helper()
+	var link models.UserIdentity
+	if err := config.DB.Where("provider = ? AND subject = ?", testIdP.server.URL, subject).First(&link).Error; err != nil {
+		return 0, false
+	}
+	return link.UserID, true
+}
+
+func TestSSOCallbackRejectsStateMismatch(t *testing.T) {
+	testIdP.issue("code-state", jwt.MapClaims{"sub": "state-user", "nonce": "nonce-state", "email": "state@example.com", "email_verified": true})
+
+	location := ssoCallback(t, "expected-state", "nonce-state", "other-state", "code-state")
+	if got := location.Query().Get("sso_error"); got != "sso_invalid_state" {
+		t.Fatalf("expected sso_invalid_state, got %q (%s)", got, location)
+	}
+	if _, ok := identityUserID(t, "state-user"); ok {
+		t.Fatal("identity linked despite the state mismatch")
+	}
+}
+
+func TestSSOCallbackRejectsNonceMismatch(t *testing.T) {
+	testIdP.issue("code-nonce", jwt.MapClaims{"sub": "nonce-user", "nonce": "replayed-nonce", "email": "nonce@example.com", "email_verified": true})
+
+	location := ssoCallback(t, "state-nonce", "expected-nonce", "state-nonce", "code-nonce")
+	if got := location.Query().Get("sso_error"); got != "sso_invalid_token" {
+		t.Fatalf("expected sso_invalid_token, got %q (%s)", got, location)
+	}
+	if _, ok := identityUserID(t, "nonce-user"); ok {
+		t.Fatal("identity linked despite the nonce mismatch")
+	}
+}
+
+func TestSSOCallbackRejectsBadAudience(t *testing.T) {
+	testIdP.issue("code-aud", jwt.MapClaims{"sub": "aud-user", "aud": "another-client", "nonce": "nonce-aud", "email": "aud@example.com", "email_verified": true})
+
+	location := ssoCallback(t, "state-aud", "nonce-aud", "state-aud", "code-aud")
+	if got := location.Query().Get("sso_error"); got != "sso_invalid_token" {
+		t.Fatalf("expected sso_invalid_token, got %q (%s)", got, location)
+	}
+	if _, ok := identityUserID(t, "aud-user"); ok {
+		t.Fatal("identity linked despite the bad audience")
+	}
+}
+
+func TestSSOCallbackLinksVerifiedEmail(t *testing.T) {
+	existing := createTestUser(t, "verified", "verified@example.com", true)
+	testIdP.issue("code-verified", jwt.MapClaims{"sub": "verified-user", "nonce": "nonce-verified", "email": "Verified@example.com", "email_verified": true})
+
+	location := ssoCallback(t, "state-verified", "nonce-verified", "state-verified", "code-verified")
+	if location.Path != "/challenges" || location.Query().Get("sso_error") != "" {
+		t.Fatalf("expected a login redirect, got %s", location)
+	}
+	userID, ok := identityUserID(t, "verified-user")
+	if !ok || userID != existing.ID {
+		t.Fatalf("expected the identity to be linked to user %d, got %d (linked: %v)", existing.ID, userID, ok)
+	}
+}
+
+func TestSSOCallbackDoesNotLinkUnverifiedEmail(t *testing.T) {
+	existing := createTestUser(t, "unverified", "unverified@example.com", true)
+	testIdP.issue("code-unverified", jwt.MapClaims{"sub": "unverified-user", "nonce": "nonce-unverified", "email": "unverified@example.com", "email_verified": false})
+
+	location := ssoCallback(t, "state-unverified", "nonce-unverified", "state-unverified", "code-unverified")
+	if location.Query().Get("sso_error") == "" {
+		t.Fatalf("expected the login to be refused, got %s", location)
+	}
+	if userID, ok := identityUserID(t, "unverified-user"); ok && userID == existing.ID {
+		t.Fatal("identity with an unverified email linked to an existing account")
+	}
+
+	var sessions int64
+	config.DB.Model(&models.UserSession{}).Where("user_id = ?", existing.ID).Count(&sessions)
+	if sessions != 0 {
+		t.Fatalf("expected no session for the existing account, got %d", sessions)
+	}
+}
+
+func TestSSOCallbackDoesNotLinkUnverifiedLocalAccount(t *testing.T) {
+	existing := createTestUser(t, "squatter", "squatted@example.com", false)
+	testIdP.issue("code-squatted", jwt.MapClaims{"sub": "squatted-user", "nonce": "nonce-squatted", "email": "squatted@example.com", "email_verified": true})
+
+	location := ssoCallback(t, "state-squatted", "nonce-squatted", "state-squatted", "code-squatted")
+	if got := location.Query().Get("sso_error"); got != "sso_account_not_linked" {
+		t.Fatalf("expected sso_account_not_linked, got %q (%s)", got, location)
+	}
+	if userID, ok := identityUserID(t, "squatted-user"); ok && userID == existing.ID {
+		t.Fatal("identity linked to an account whose email was never verified")
+	}
+}
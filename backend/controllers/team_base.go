@@ -9,6 +9,7 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/jinzhu/copier"
 	"github.com/pwnthemall/pwnthemall/backend/config"
+	"github.com/pwnthemall/pwnthemall/backend/events"
 	"github.com/pwnthemall/pwnthemall/backend/models"
 	"github.com/pwnthemall/pwnthemall/backend/utils"
 	"golang.org/x/crypto/bcrypt"
@@ -159,6 +160,13 @@ func CreateTeam(c *gin.Context) {
 		utils.InternalServerError(c, "user_update_failed")
 		return
 	}
+	events.Publish(events.TeamJoined{
+		TeamID:   team.ID,
+		TeamName: team.Name,
+		UserID:   user.ID,
+		Username: user.Username,
+		Created:  true,
+	})
 	utils.CreatedResponse(c, gin.H{"team": team})
 }
 
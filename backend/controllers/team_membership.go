@@ -3,7 +3,8 @@ package controllers
 import (
 	"fmt"
 
-	"github.com/pwnthemall/pwnthemall/backend/debug"
+	"github.com/pwnthemall/pwnthemall/backend/events"
+	"github.com/pwnthemall/pwnthemall/backend/logger"
 
 	"github.com/gin-gonic/gin"
 	"github.com/pwnthemall/pwnthemall/backend/config"
@@ -128,6 +129,17 @@ func JoinTeam(c *gin.Context) {
 		config.DB.Where("name = ?", input.Name).First(&team)
 	}
 
+	var username string
+	if user, ok := c.Get("user"); ok {
+		username = user.(*models.User).Username
+	}
+	events.Publish(events.TeamJoined{
+		TeamID:   team.ID,
+		TeamName: team.Name,
+		UserID:   userID.(uint),
+		Username: username,
+	})
+
 	utils.OKResponse(c, gin.H{"message": "Joined team", "team": team})
 }
 
@@ -297,43 +309,43 @@ func deleteTeamCompletely(teamID uint) error {
 	return config.DB.Transaction(func(tx *gorm.DB) error {
 		var userIds []uint
 		if err := tx.Model(&models.User{}).Where("team_id = ?", teamID).Pluck("id", &userIds).Error; err != nil {
-			debug.Log("Failed to get user IDs for team %d: %v", teamID, err)
+			logger.Errorf("Failed to get user IDs for team %d: %v", teamID, err)
 			return err
 		}
 
 		if len(userIds) > 0 {
 			if err := tx.Where("user_id IN ?", userIds).Delete(&models.Submission{}).Error; err != nil {
-				debug.Log("Failed to delete submissions for team %d: %v", teamID, err)
+				logger.Errorf("Failed to delete submissions for team %d: %v", teamID, err)
 				return err
 			}
 		}
 
 		if err := tx.Where("team_id = ?", teamID).Delete(&models.Instance{}).Error; err != nil {
-			debug.Log("Failed to delete instances for team %d: %v", teamID, err)
+			logger.Errorf("Failed to delete instances for team %d: %v", teamID, err)
 			return err
 		}
 
 		if err := tx.Where("team_id = ?", teamID).Delete(&models.DynamicFlag{}).Error; err != nil {
-			debug.Log("Failed to delete dynamic flags for team %d: %v", teamID, err)
+			logger.Errorf("Failed to delete dynamic flags for team %d: %v", teamID, err)
 			return err
 		}
 
 		if err := tx.Where("team_id = ?", teamID).Delete(&models.Solve{}).Error; err != nil {
-			debug.Log("Failed to delete solves for team %d: %v", teamID, err)
+			logger.Errorf("Failed to delete solves for team %d: %v", teamID, err)
 			return err
 		}
 
 		if err := tx.Where("team_id = ?", teamID).Delete(&models.HintPurchase{}).Error; err != nil {
-			debug.Log("Failed to delete hint purchases for team %d: %v", teamID, err)
+			logger.Errorf("Failed to delete hint purchases for team %d: %v", teamID, err)
 			return err
 		}
 
 		if err := tx.Delete(&models.Team{}, teamID).Error; err != nil {
-			debug.Log("Failed to delete team %d: %v", teamID, err)
+			logger.Errorf("Failed to delete team %d: %v", teamID, err)
 			return err
 		}
 
-		debug.Log("Successfully deleted team %d and all related records", teamID)
+		logger.Infof("Successfully deleted team %d and all related records", teamID)
 		return nil
 	})
 }
@@ -1,13 +1,14 @@
 package controllers
 
 import (
+	"fmt"
 	"sort"
 
 	"github.com/gin-gonic/gin"
 	"github.com/jinzhu/copier"
 	"github.com/pwnthemall/pwnthemall/backend/config"
-	"github.com/pwnthemall/pwnthemall/backend/debug"
 	"github.com/pwnthemall/pwnthemall/backend/dto"
+	"github.com/pwnthemall/pwnthemall/backend/logger"
 	"github.com/pwnthemall/pwnthemall/backend/models"
 	"github.com/pwnthemall/pwnthemall/backend/utils"
 )
@@ -75,7 +76,7 @@ func calculateTeamScore(teamID uint, decayService *utils.DecayService) (int, int
 		position := getSolvePosition(challenge.ID, solve.CreatedAt)
 		points := calculateSolvePointsWithDecay(&solve, &challenge, position, decayService)
 		totalScore += points
-		debug.Log("[DEBUG] Team %d, Challenge %d (slug: %s), Position: %d, CurrentPoints: %d, DecayFormulaID: %d",
+		logger.Debugf("Team %d, Challenge %d (slug: %s), Position: %d, CurrentPoints: %d, DecayFormulaID: %d",
 			teamID, challenge.ID, challenge.Slug, position, points, challenge.DecayFormulaID)
 	}
 
@@ -132,7 +133,7 @@ func processSolveRecalculation(solve *models.Solve, position int, decayService *
 
 	if firstBloodBonus > 0 {
 		if err := createFirstBloodEntryForRecalc(&challenge, solve, position, firstBloodBonus); err != nil {
-			debug.Log("Failed to recreate FirstBlood entry: %v", err)
+			logger.Errorf("Failed to recreate FirstBlood entry: %v", err)
 		}
 	}
 
@@ -227,14 +228,23 @@ func buildTimelinePoint(solve *models.Solve, allTeams []models.Team, teamScoresM
 
 // GetLeaderboard calculates and returns team rankings with current points
 func GetLeaderboard(c *gin.Context) {
-	var teams []models.Team
-	result := config.DB.Preload("Users").Find(&teams)
-	if result.Error != nil {
-		debug.Log("Failed to fetch teams for leaderboard", result.Error)
+	leaderboard, err := buildLeaderboard()
+	if err != nil {
+		logger.Ctx(c).Errorf("Failed to fetch teams for leaderboard: %v", err)
 		utils.InternalServerError(c, "failed_to_fetch_teams")
 		return
 	}
 
+	utils.OKResponse(c, leaderboard)
+}
+
+// buildLeaderboard scores every team and ranks them
+func buildLeaderboard() ([]dto.TeamScore, error) {
+	var teams []models.Team
+	if err := config.DB.Preload("Users").Find(&teams).Error; err != nil {
+		return nil, err
+	}
+
 	decayService := utils.NewDecay()
 	var leaderboard []dto.TeamScore
 
@@ -263,7 +273,7 @@ func GetLeaderboard(c *gin.Context) {
 		leaderboard[i].Rank = i + 1
 	}
 
-	utils.OKResponse(c, leaderboard)
+	return leaderboard, nil
 }
 
 // RecalculateTeamPoints recalculates all solve points for all teams based on current challenge values
@@ -272,7 +282,7 @@ func RecalculateTeamPoints(c *gin.Context) {
 
 	// Delete all existing FirstBlood entries and recreate them
 	if err := config.DB.Delete(&models.FirstBlood{}, "1=1").Error; err != nil {
-		debug.Log("Failed to delete existing FirstBlood entries: %v", err)
+		logger.Ctx(c).Errorf("Failed to delete existing FirstBlood entries: %v", err)
 	}
 
 	// Get all solves grouped by challenge and ordered by creation time
@@ -283,6 +293,8 @@ func RecalculateTeamPoints(c *gin.Context) {
 	}
 
 	updatedCount := 0
+	pointsBefore := map[string]int{}
+	pointsAfter := map[string]int{}
 	challengePositions := make(map[uint]int) // Track position per challenge
 
 	for _, solve := range solves {
@@ -296,15 +308,21 @@ func RecalculateTeamPoints(c *gin.Context) {
 
 		// Update if points have changed
 		if solve.Points != newPointsWithBonus {
+			previousPoints := solve.Points
 			solve.Points = newPointsWithBonus
 			if err := config.DB.Save(&solve).Error; err != nil {
-				debug.Log("Failed to update solve for team %d, challenge %d: %v", solve.TeamID, solve.ChallengeID, err)
+				logger.Ctx(c).Errorf("Failed to update solve for team %d, challenge %d: %v", solve.TeamID, solve.ChallengeID, err)
 				continue
 			}
+			solveKey := fmt.Sprintf("team:%d/challenge:%d", solve.TeamID, solve.ChallengeID)
+			pointsBefore[solveKey] = previousPoints
+			pointsAfter[solveKey] = solve.Points
 			updatedCount++
 		}
 	}
 
+	utils.RecordAudit(c, "team.recalculate_points", "solve", nil, pointsBefore, pointsAfter)
+
 	utils.OKResponse(c, gin.H{
 		"message":        "points_recalculated",
 		"updated_solves": updatedCount,
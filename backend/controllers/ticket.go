@@ -2,20 +2,22 @@ package controllers
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"net/http"
 	"path/filepath"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/minio/minio-go/v7"
 	"github.com/pwnthemall/pwnthemall/backend/config"
-	"github.com/pwnthemall/pwnthemall/backend/debug"
 	"github.com/pwnthemall/pwnthemall/backend/dto"
+	"github.com/pwnthemall/pwnthemall/backend/events"
+	"github.com/pwnthemall/pwnthemall/backend/logger"
 	"github.com/pwnthemall/pwnthemall/backend/models"
 	"github.com/pwnthemall/pwnthemall/backend/utils"
+	"gorm.io/gorm/clause"
 )
 
 // ticketToResponse converts a Ticket model to TicketResponse DTO
@@ -35,6 +37,7 @@ func ticketToResponse(ticket models.Ticket) dto.TicketResponse {
 		UpdatedAt:   ticket.UpdatedAt,
 		ClaimedAt:   ticket.ClaimedAt,
 		ResolvedAt:  ticket.ResolvedAt,
+		ReopenedAt:  ticket.ReopenedAt,
 	}
 
 	// Set username if user is loaded
@@ -81,6 +84,7 @@ func messageToResponse(msg models.TicketMessage) dto.TicketMessageResponse {
 		UserID:      msg.UserID,
 		Message:     msg.Message,
 		IsAdmin:     msg.IsAdmin,
+		IsInternal:  msg.IsInternal,
 		Attachments: msg.Attachments,
 		CreatedAt:   msg.CreatedAt,
 	}
@@ -103,64 +107,6 @@ func validateTicketID(c *gin.Context) (uint, bool) {
 	return uint(ticketID), true
 }
 
-// sendTicketWebSocketEvent sends a ticket event via WebSocket to authorized users only
-func sendTicketWebSocketEvent(event dto.TicketWebSocketEvent, ticket *models.Ticket, excludeUserID uint) {
-	messageBytes, err := json.Marshal(event)
-	if err != nil {
-		debug.Log("Failed to marshal ticket WebSocket event: %v", err)
-		return
-	}
-
-	if utils.UpdatesHub == nil {
-		debug.Log("UpdatesHub not initialized, cannot send ticket WebSocket event")
-		return
-	}
-
-	// Collect authorized user IDs: ticket owner + team members (if team ticket) + all admins
-	authorizedUserIDs := make(map[uint]bool)
-
-	// Add ticket owner
-	if ticket != nil {
-		authorizedUserIDs[ticket.UserID] = true
-
-		// Add team members if it's a team ticket
-		if ticket.TeamID != nil {
-			var teamMemberIDs []uint
-			if err := config.DB.Model(&models.User{}).
-				Where("team_id = ?", *ticket.TeamID).
-				Pluck("id", &teamMemberIDs).Error; err == nil {
-				for _, userID := range teamMemberIDs {
-					authorizedUserIDs[userID] = true
-				}
-			}
-		}
-	}
-
-	// Add all admins
-	var adminIDs []uint
-	if err := config.DB.Model(&models.User{}).
-		Where("role = ?", "admin").
-		Pluck("id", &adminIDs).Error; err == nil {
-		for _, adminID := range adminIDs {
-			authorizedUserIDs[adminID] = true
-		}
-	}
-
-	// Remove excluded user if specified
-	if excludeUserID > 0 {
-		delete(authorizedUserIDs, excludeUserID)
-	}
-
-	// Send to each authorized user
-	debug.Log("Sending ticket WebSocket event: %s for ticket %d to %d authorized users (excluding %d)",
-		event.Event, event.TicketID, len(authorizedUserIDs), excludeUserID)
-
-	for userID := range authorizedUserIDs {
-		utils.WebSocketHub.SendToUser(userID, messageBytes)
-		utils.UpdatesHub.SendToUser(userID, messageBytes)
-	}
-}
-
 // ============================================================================
 // USER ENDPOINTS
 // ============================================================================
@@ -209,7 +155,7 @@ func CreateTicket(c *gin.Context) {
 	}
 
 	if err := config.DB.Create(&ticket).Error; err != nil {
-		debug.Log("Failed to create ticket: %v", err)
+		logger.Ctx(c).Errorf("Failed to create ticket: %v", err)
 		utils.InternalServerError(c, "ticket_creation_failed")
 		return
 	}
@@ -217,16 +163,13 @@ func CreateTicket(c *gin.Context) {
 	// Load relations for response
 	config.DB.Preload("User").Preload("Team").Preload("Challenge").First(&ticket, ticket.ID)
 
-	// Send WebSocket notification to authorized users (admins + team members)
-	event := dto.TicketWebSocketEvent{
-		Event:    "ticket_created",
+	events.Publish(events.TicketCreated{
 		TicketID: ticket.ID,
 		Subject:  ticket.Subject,
 		UserID:   userID,
 		Username: user.Username,
 		TeamID:   ticket.TeamID,
-	}
-	sendTicketWebSocketEvent(event, &ticket, userID)
+	})
 
 	utils.CreatedResponse(c, ticketToResponse(ticket))
 }
@@ -266,7 +209,7 @@ func GetUserTickets(c *gin.Context) {
 		Preload("Team").
 		Preload("Challenge").
 		Preload("ClaimedBy").
-		Preload("Messages").
+		Preload("Messages", "is_internal = ?", false).
 		Order("created_at DESC").
 		Offset(offset).
 		Limit(pageSize).
@@ -342,12 +285,12 @@ func GetTicket(c *gin.Context) {
 
 	// Get total message count
 	var totalMessages int64
-	config.DB.Model(&models.TicketMessage{}).Where("ticket_id = ?", ticket.ID).Count(&totalMessages)
+	config.DB.Model(&models.TicketMessage{}).Where("ticket_id = ? AND is_internal = ?", ticket.ID, false).Count(&totalMessages)
 
 	// Build paginated message query
 	messagesQuery := config.DB.
 		Model(&models.TicketMessage{}).
-		Where("ticket_id = ?", ticket.ID).
+		Where("ticket_id = ? AND is_internal = ?", ticket.ID, false).
 		Preload("User").
 		Order("id ASC")
 
@@ -455,19 +398,18 @@ func SendTicketMessage(c *gin.Context) {
 	// Load user for response
 	config.DB.Preload("User").First(&message, message.ID)
 
-	// Send WebSocket notification to authorized users
-	event := dto.TicketWebSocketEvent{
-		Event:       "ticket_message",
-		TicketID:    ticket.ID,
-		MessageID:   message.ID,
-		UserID:      userID,
-		Username:    user.Username,
-		Message:     sanitizedMessage,
-		Attachments: message.Attachments,
-		CreatedAt:   message.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
-		IsAdmin:     false,
-	}
-	sendTicketWebSocketEvent(event, &ticket, userID)
+	events.Publish(events.TicketMessageAdded{
+		TicketID:     ticket.ID,
+		TicketUserID: ticket.UserID,
+		TeamID:       ticket.TeamID,
+		MessageID:    message.ID,
+		UserID:       userID,
+		Username:     user.Username,
+		Message:      sanitizedMessage,
+		Attachments:  message.Attachments,
+		IsAdmin:      false,
+		CreatedAt:    message.CreatedAt,
+	})
 
 	utils.CreatedResponse(c, messageToResponse(message))
 }
@@ -501,17 +443,86 @@ func CloseTicket(c *gin.Context) {
 		return
 	}
 
-	// Send WebSocket notification to authorized users (including the one who closed it)
-	event := dto.TicketWebSocketEvent{
-		Event:    "ticket_resolved",
-		TicketID: ticket.ID,
-		UserID:   userID,
-	}
-	sendTicketWebSocketEvent(event, &ticket, 0) // Don't exclude anyone, everyone needs to see the status change
+	events.Publish(events.TicketResolved{
+		TicketID:     ticket.ID,
+		TicketUserID: ticket.UserID,
+		TeamID:       ticket.TeamID,
+		UserID:       userID,
+	})
 
 	utils.OKResponse(c, gin.H{"message": "ticket_closed"})
 }
 
+// ReopenTicket lets the creator or their team reopen a resolved ticket within the reopen window
+func ReopenTicket(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	ticket, ok := findTicket(c)
+	if !ok {
+		return
+	}
+
+	var user models.User
+	if err := config.DB.First(&user, userID).Error; err != nil {
+		utils.InternalServerError(c, "user_not_found")
+		return
+	}
+
+	// Check access: user must be the creator or part of the team
+	hasAccess := ticket.UserID == userID
+	if user.TeamID != nil && ticket.TeamID != nil && *user.TeamID == *ticket.TeamID {
+		hasAccess = true
+	}
+	if !hasAccess {
+		utils.ForbiddenError(c, "access_denied")
+		return
+	}
+
+	if ticket.Status != models.TicketStatusResolved {
+		utils.BadRequestError(c, "ticket_not_resolved")
+		return
+	}
+	window := ticketReopenWindow()
+	if window <= 0 || ticket.ResolvedAt == nil || time.Since(*ticket.ResolvedAt) > window {
+		utils.BadRequestError(c, "ticket_reopen_window_expired")
+		return
+	}
+
+	if !reopenTicket(c, &ticket, userID) {
+		return
+	}
+	utils.OKResponse(c, gin.H{"message": "ticket_reopened"})
+}
+
+// ticketReopenWindow returns how long after being resolved a ticket can be reopened by players, zero when disabled
+func ticketReopenWindow() time.Duration {
+	hours, err := strconv.Atoi(config.GetConfigValue("TICKET_REOPEN_WINDOW_HOURS", "48"))
+	if err != nil || hours <= 0 {
+		return 0
+	}
+	return time.Duration(hours) * time.Hour
+}
+
+// reopenTicket moves a resolved ticket back to open and publishes it
+func reopenTicket(c *gin.Context, ticket *models.Ticket, userID uint) bool {
+	now := time.Now()
+	ticket.Status = models.TicketStatusOpen
+	ticket.ResolvedAt = nil
+	ticket.ReopenedAt = &now
+
+	if err := config.DB.Omit(clause.Associations).Save(ticket).Error; err != nil {
+		utils.InternalServerError(c, "failed_to_reopen_ticket")
+		return false
+	}
+
+	events.Publish(events.TicketReopened{
+		TicketID:     ticket.ID,
+		TicketUserID: ticket.UserID,
+		TeamID:       ticket.TeamID,
+		UserID:       userID,
+	})
+	return true
+}
+
 // UploadTicketAttachment handles file upload for tickets
 func UploadTicketAttachment(c *gin.Context) {
 	userID := c.GetUint("user_id")
@@ -552,13 +563,13 @@ func UploadTicketAttachment(c *gin.Context) {
 	bucketName := "tickets"
 	exists, err := config.FS.BucketExists(context.Background(), bucketName)
 	if err != nil {
-		debug.Log("Failed to check bucket existence: %v", err)
+		logger.Ctx(c).Errorf("Failed to check bucket existence: %v", err)
 		utils.InternalServerError(c, "upload_failed")
 		return
 	}
 	if !exists {
 		if err := config.FS.MakeBucket(context.Background(), bucketName, minio.MakeBucketOptions{}); err != nil {
-			debug.Log("Failed to create bucket: %v", err)
+			logger.Ctx(c).Errorf("Failed to create bucket: %v", err)
 			utils.InternalServerError(c, "upload_failed")
 			return
 		}
@@ -574,7 +585,7 @@ func UploadTicketAttachment(c *gin.Context) {
 		minio.PutObjectOptions{ContentType: contentType},
 	)
 	if err != nil {
-		debug.Log("Failed to upload file to MinIO: %v", err)
+		logger.Ctx(c).Errorf("Failed to upload file to MinIO: %v", err)
 		utils.InternalServerError(c, "upload_failed")
 		return
 	}
@@ -613,7 +624,8 @@ func GetTicketAttachment(c *gin.Context) {
 	}
 
 	// Check access
-	hasAccess := ticket.UserID == userID || user.Role == "admin"
+	canManage, _ := config.Enforce(user.Role, "/admin/tickets/:id", "read")
+	hasAccess := ticket.UserID == userID || canManage
 	if user.TeamID != nil && ticket.TeamID != nil && *user.TeamID == *ticket.TeamID {
 		hasAccess = true
 	}
@@ -708,6 +720,32 @@ func GetAllTickets(c *gin.Context) {
 		query = query.Where("ticket_type = ?", ticketType)
 	}
 
+	// Filter by claimant: an admin ID, "me" or "none"
+	switch claimedBy := c.Query("claimedBy"); claimedBy {
+	case "":
+	case "none":
+		query = query.Where("claimed_by_id IS NULL")
+	case "me":
+		query = query.Where("claimed_by_id = ?", c.GetUint("user_id"))
+	default:
+		query = query.Where("claimed_by_id = ?", claimedBy)
+	}
+
+	// Filter by challenge
+	if challengeID := c.Query("challengeId"); challengeID != "" {
+		query = query.Where("challenge_id = ?", challengeID)
+	}
+
+	// Filter by priority
+	if priority := c.Query("priority"); priority != "" {
+		query = query.Where("priority = ?", priority)
+	}
+
+	// Filter by tag
+	if tag := c.Query("tag"); tag != "" {
+		query = query.Where("? = ANY(tags)", strings.ToLower(tag))
+	}
+
 	// Count total
 	var total int64
 	query.Count(&total)
@@ -732,9 +770,10 @@ func GetAllTickets(c *gin.Context) {
 	}
 
 	// Convert to response
+	sla := ticketSLA()
 	ticketResponses := make([]dto.TicketResponse, len(tickets))
 	for i, ticket := range tickets {
-		ticketResponses[i] = ticketToResponse(ticket)
+		ticketResponses[i] = adminTicketToResponse(ticket, sla)
 	}
 
 	totalPages := int(total) / pageSize
@@ -812,7 +851,7 @@ func GetAdminTicket(c *gin.Context) {
 	}
 
 	response := dto.TicketDetailResponse{
-		TicketResponse: ticketToResponse(ticket),
+		TicketResponse: adminTicketToResponse(ticket, ticketSLA()),
 		Messages:       messageResponses,
 		HasMore:        hasMore,
 		NextCursor:     nextCursor,
@@ -835,6 +874,7 @@ func ResolveTicket(c *gin.Context) {
 		return
 	}
 
+	previousStatus := ticket.Status
 	now := time.Now()
 	ticket.Status = models.TicketStatusResolved
 	ticket.ResolvedAt = &now
@@ -844,17 +884,36 @@ func ResolveTicket(c *gin.Context) {
 		return
 	}
 
-	// Send WebSocket notification to authorized users (including the admin who resolved)
-	event := dto.TicketWebSocketEvent{
-		Event:    "ticket_resolved",
-		TicketID: ticket.ID,
-	}
-	debug.Log("Sending ticket_resolved WebSocket event for ticket %d", ticket.ID)
-	sendTicketWebSocketEvent(event, &ticket, 0) // Don't exclude anyone
+	utils.RecordAudit(c, "ticket.resolve", "ticket", ticket.ID, gin.H{"status": previousStatus}, gin.H{"status": ticket.Status})
+
+	events.Publish(events.TicketResolved{
+		TicketID:     ticket.ID,
+		TicketUserID: ticket.UserID,
+		TeamID:       ticket.TeamID,
+	})
 
 	utils.OKResponse(c, gin.H{"message": "ticket_resolved"})
 }
 
+// AdminReopenTicket allows an admin to reopen a resolved ticket at any time
+func AdminReopenTicket(c *gin.Context) {
+	ticket, ok := findTicket(c)
+	if !ok {
+		return
+	}
+	if ticket.Status != models.TicketStatusResolved {
+		utils.BadRequestError(c, "ticket_not_resolved")
+		return
+	}
+
+	if !reopenTicket(c, &ticket, c.GetUint("user_id")) {
+		return
+	}
+
+	utils.RecordAudit(c, "ticket.reopen", "ticket", ticket.ID, gin.H{"status": models.TicketStatusResolved}, gin.H{"status": ticket.Status})
+	utils.OKResponse(c, gin.H{"message": "ticket_reopened"})
+}
+
 // AdminReplyTicket allows an admin to reply to a ticket
 func AdminReplyTicket(c *gin.Context) {
 	adminID := c.GetUint("user_id")
@@ -870,7 +929,7 @@ func AdminReplyTicket(c *gin.Context) {
 		return
 	}
 
-	var input dto.TicketMessageInput
+	var input dto.AdminTicketMessageInput
 	if err := c.ShouldBindJSON(&input); err != nil {
 		utils.BadRequestError(c, "invalid_input")
 		return
@@ -892,6 +951,7 @@ func AdminReplyTicket(c *gin.Context) {
 		UserID:      adminID,
 		Message:     sanitizedMessage,
 		IsAdmin:     true,
+		IsInternal:  input.Internal,
 		Attachments: input.Attachments,
 	}
 
@@ -903,22 +963,27 @@ func AdminReplyTicket(c *gin.Context) {
 	// Update ticket updated_at
 	config.DB.Model(&ticket).Update("updated_at", time.Now())
 
+	// The first admin reply stops the SLA timer, internal notes don't count
+	if !message.IsInternal {
+		config.DB.Model(&ticket).Where("first_response_at IS NULL").Update("first_response_at", message.CreatedAt)
+	}
+
 	// Load user for response
 	config.DB.Preload("User").First(&message, message.ID)
 
-	// Send WebSocket notification to authorized users
-	event := dto.TicketWebSocketEvent{
-		Event:       "ticket_message",
-		TicketID:    ticket.ID,
-		MessageID:   message.ID,
-		UserID:      adminID,
-		Username:    admin.Username,
-		Message:     sanitizedMessage,
-		Attachments: message.Attachments,
-		CreatedAt:   message.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
-		IsAdmin:     true,
-	}
-	sendTicketWebSocketEvent(event, &ticket, adminID)
+	events.Publish(events.TicketMessageAdded{
+		TicketID:     ticket.ID,
+		TicketUserID: ticket.UserID,
+		TeamID:       ticket.TeamID,
+		MessageID:    message.ID,
+		UserID:       adminID,
+		Username:     admin.Username,
+		Message:      sanitizedMessage,
+		Attachments:  message.Attachments,
+		IsAdmin:      true,
+		Internal:     message.IsInternal,
+		CreatedAt:    message.CreatedAt,
+	})
 
 	utils.CreatedResponse(c, messageToResponse(message))
 }
@@ -948,5 +1013,7 @@ func DeleteTicket(c *gin.Context) {
 		return
 	}
 
+	utils.RecordAudit(c, "ticket.delete", "ticket", ticket.ID, gin.H{"subject": ticket.Subject, "status": ticket.Status, "userId": ticket.UserID}, nil)
+
 	utils.OKResponse(c, gin.H{"message": "ticket_deleted"})
 }
@@ -0,0 +1,52 @@
+package controllers
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/pwnthemall/pwnthemall/backend/config"
+	"github.com/pwnthemall/pwnthemall/backend/dto"
+	"github.com/pwnthemall/pwnthemall/backend/logger"
+	"github.com/pwnthemall/pwnthemall/backend/utils"
+)
+
+// GetTicketAnalytics returns ticket counts and resolution times, overall and per challenge
+func GetTicketAnalytics(c *gin.Context) {
+	response := dto.TicketAnalyticsResponse{Challenges: []dto.TicketChallengeStats{}}
+
+	if err := config.DB.Raw(`
+		SELECT
+			COUNT(*) AS total,
+			COUNT(*) FILTER (WHERE status = 'open') AS open,
+			COUNT(*) FILTER (WHERE status = 'resolved') AS resolved,
+			COUNT(*) FILTER (WHERE reopened_at IS NOT NULL) AS reopened,
+			percentile_cont(0.5) WITHIN GROUP (ORDER BY EXTRACT(EPOCH FROM resolved_at - created_at)) AS median_resolve_seconds,
+			percentile_cont(0.5) WITHIN GROUP (ORDER BY EXTRACT(EPOCH FROM first_response_at - created_at)) AS median_first_response_seconds
+		FROM tickets`).Scan(&response).Error; err != nil {
+		logger.Ctx(c).Errorf("Failed to compute ticket analytics: %v", err)
+		utils.InternalServerError(c, "failed_to_fetch_ticket_analytics")
+		return
+	}
+
+	// Challenges with the most tickets, then the most messages, generate the most support load
+	if err := config.DB.Raw(`
+		SELECT
+			t.challenge_id,
+			ch.name AS challenge_name,
+			ch.slug AS challenge_slug,
+			COUNT(*) AS tickets,
+			COUNT(*) FILTER (WHERE t.status = 'open') AS open,
+			COALESCE(SUM(m.messages), 0) AS messages,
+			percentile_cont(0.5) WITHIN GROUP (ORDER BY EXTRACT(EPOCH FROM t.resolved_at - t.created_at)) AS median_resolve_seconds
+		FROM tickets t
+		JOIN challenges ch ON ch.id = t.challenge_id
+		LEFT JOIN (
+			SELECT ticket_id, COUNT(*) AS messages FROM ticket_messages WHERE NOT is_internal GROUP BY ticket_id
+		) m ON m.ticket_id = t.id
+		GROUP BY t.challenge_id, ch.name, ch.slug
+		ORDER BY tickets DESC, messages DESC, t.challenge_id`).Scan(&response.Challenges).Error; err != nil {
+		logger.Ctx(c).Errorf("Failed to compute ticket analytics per challenge: %v", err)
+		utils.InternalServerError(c, "failed_to_fetch_ticket_analytics")
+		return
+	}
+
+	utils.OKResponse(c, response)
+}
@@ -0,0 +1,99 @@
+package controllers
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/pwnthemall/pwnthemall/backend/config"
+	"github.com/pwnthemall/pwnthemall/backend/dto"
+	"github.com/pwnthemall/pwnthemall/backend/models"
+	"github.com/pwnthemall/pwnthemall/backend/utils"
+)
+
+// GetTicketCannedResponses lists the reply templates, sorted by title
+func GetTicketCannedResponses(c *gin.Context) {
+	responses := []models.TicketCannedResponse{}
+	if err := config.DB.Order("title").Find(&responses).Error; err != nil {
+		utils.InternalServerError(c, "failed_to_fetch_canned_responses")
+		return
+	}
+	utils.OKResponse(c, responses)
+}
+
+// CreateTicketCannedResponse adds a reply template
+func CreateTicketCannedResponse(c *gin.Context) {
+	var input dto.TicketCannedResponseInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.BadRequestError(c, "invalid_input")
+		return
+	}
+
+	adminID := c.GetUint("user_id")
+	response := models.TicketCannedResponse{
+		Title:       input.Title,
+		Message:     utils.SanitizeUGC(input.Message),
+		CreatedByID: &adminID,
+	}
+	if err := config.DB.Where("title = ?", response.Title).First(&models.TicketCannedResponse{}).Error; err == nil {
+		utils.ConflictError(c, "canned_response_title_taken")
+		return
+	}
+	if err := config.DB.Create(&response).Error; err != nil {
+		utils.InternalServerError(c, "failed_to_create_canned_response")
+		return
+	}
+
+	utils.RecordAudit(c, "ticket_canned_response.create", "ticket_canned_response", response.ID, nil, response)
+	utils.CreatedResponse(c, response)
+}
+
+// UpdateTicketCannedResponse changes the title or message of a reply template
+func UpdateTicketCannedResponse(c *gin.Context) {
+	response, ok := findTicketCannedResponse(c)
+	if !ok {
+		return
+	}
+
+	var input dto.TicketCannedResponseInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.BadRequestError(c, "invalid_input")
+		return
+	}
+	if err := config.DB.Where("title = ? AND id <> ?", input.Title, response.ID).First(&models.TicketCannedResponse{}).Error; err == nil {
+		utils.ConflictError(c, "canned_response_title_taken")
+		return
+	}
+
+	before := response
+	response.Title = input.Title
+	response.Message = utils.SanitizeUGC(input.Message)
+	if err := config.DB.Save(&response).Error; err != nil {
+		utils.InternalServerError(c, "failed_to_update_canned_response")
+		return
+	}
+
+	utils.RecordAudit(c, "ticket_canned_response.update", "ticket_canned_response", response.ID, before, response)
+	utils.OKResponse(c, response)
+}
+
+// DeleteTicketCannedResponse removes a reply template
+func DeleteTicketCannedResponse(c *gin.Context) {
+	response, ok := findTicketCannedResponse(c)
+	if !ok {
+		return
+	}
+	if err := config.DB.Delete(&response).Error; err != nil {
+		utils.InternalServerError(c, "failed_to_delete_canned_response")
+		return
+	}
+
+	utils.RecordAudit(c, "ticket_canned_response.delete", "ticket_canned_response", response.ID, response, nil)
+	utils.OKResponse(c, gin.H{"message": "canned_response_deleted"})
+}
+
+func findTicketCannedResponse(c *gin.Context) (models.TicketCannedResponse, bool) {
+	var response models.TicketCannedResponse
+	if err := config.DB.First(&response, c.Param("responseId")).Error; err != nil {
+		utils.NotFoundError(c, "canned_response_not_found")
+		return response, false
+	}
+	return response, true
+}
@@ -0,0 +1,209 @@
+package controllers
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lib/pq"
+	"github.com/pwnthemall/pwnthemall/backend/config"
+	"github.com/pwnthemall/pwnthemall/backend/dto"
+	"github.com/pwnthemall/pwnthemall/backend/events"
+	"github.com/pwnthemall/pwnthemall/backend/models"
+	"github.com/pwnthemall/pwnthemall/backend/utils"
+)
+
+// ticketSLA returns the time allowed before the first admin reply, zero when disabled
+func ticketSLA() time.Duration {
+	minutes, err := strconv.Atoi(config.GetConfigValue("TICKET_SLA_MINUTES", "30"))
+	if err != nil || minutes <= 0 {
+		return 0
+	}
+	return time.Duration(minutes) * time.Minute
+}
+
+// adminTicketToResponse converts a Ticket model to TicketResponse DTO with the triage and SLA fields
+func adminTicketToResponse(ticket models.Ticket, sla time.Duration) dto.TicketResponse {
+	response := ticketToResponse(ticket)
+	response.Priority = string(ticket.Priority)
+	response.Tags = ticket.Tags
+	response.FirstResponseAt = ticket.FirstResponseAt
+
+	if sla > 0 {
+		due := ticket.CreatedAt.Add(sla)
+		response.SLADueAt = &due
+		if ticket.FirstResponseAt != nil {
+			response.SLABreached = ticket.FirstResponseAt.After(due)
+		} else {
+			response.SLABreached = ticket.Status == models.TicketStatusOpen && time.Now().After(due)
+		}
+	}
+
+	return response
+}
+
+// ClaimTicket lets an admin take a ticket that nobody else claimed
+func ClaimTicket(c *gin.Context) {
+	adminID := c.GetUint("user_id")
+	ticket, ok := findTicket(c)
+	if !ok {
+		return
+	}
+
+	now := time.Now()
+	result := config.DB.Model(&models.Ticket{}).
+		Where("id = ? AND (claimed_by_id IS NULL OR claimed_by_id = ?)", ticket.ID, adminID).
+		Updates(map[string]interface{}{"claimed_by_id": adminID, "claimed_at": now})
+	if result.Error != nil {
+		utils.InternalServerError(c, "failed_to_claim_ticket")
+		return
+	}
+	if result.RowsAffected == 0 {
+		utils.ConflictError(c, "ticket_already_claimed")
+		return
+	}
+
+	utils.RecordAudit(c, "ticket.claim", "ticket", ticket.ID, gin.H{"claimedById": ticket.ClaimedByID}, gin.H{"claimedById": adminID})
+	publishTicketClaim(ticket, &adminID, adminID)
+
+	utils.OKResponse(c, gin.H{"message": "ticket_claimed"})
+}
+
+// UnclaimTicket puts a claimed ticket back in the queue
+func UnclaimTicket(c *gin.Context) {
+	ticket, ok := findTicket(c)
+	if !ok {
+		return
+	}
+	if ticket.ClaimedByID == nil {
+		utils.BadRequestError(c, "ticket_not_claimed")
+		return
+	}
+
+	if err := config.DB.Model(&ticket).Updates(map[string]interface{}{"claimed_by_id": nil, "claimed_at": nil}).Error; err != nil {
+		utils.InternalServerError(c, "failed_to_unclaim_ticket")
+		return
+	}
+
+	utils.RecordAudit(c, "ticket.unclaim", "ticket", ticket.ID, gin.H{"claimedById": ticket.ClaimedByID}, nil)
+	publishTicketClaim(ticket, nil, c.GetUint("user_id"))
+
+	utils.OKResponse(c, gin.H{"message": "ticket_unclaimed"})
+}
+
+// AssignTicket claims a ticket on behalf of another admin
+func AssignTicket(c *gin.Context) {
+	ticket, ok := findTicket(c)
+	if !ok {
+		return
+	}
+
+	var input dto.TicketAssignInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.BadRequestError(c, "invalid_input")
+		return
+	}
+
+	var assignee models.User
+	if err := config.DB.First(&assignee, input.UserID).Error; err != nil {
+		utils.NotFoundError(c, "user_not_found")
+		return
+	}
+	if !slices.Contains(config.RolesWithPermission("/admin/tickets/:id/messages", "write"), assignee.Role) {
+		utils.BadRequestError(c, "assignee_not_staff")
+		return
+	}
+
+	now := time.Now()
+	if err := config.DB.Model(&ticket).Updates(map[string]interface{}{"claimed_by_id": assignee.ID, "claimed_at": now}).Error; err != nil {
+		utils.InternalServerError(c, "failed_to_assign_ticket")
+		return
+	}
+
+	utils.RecordAudit(c, "ticket.assign", "ticket", ticket.ID, gin.H{"claimedById": ticket.ClaimedByID}, gin.H{"claimedById": assignee.ID})
+	publishTicketClaim(ticket, &assignee.ID, c.GetUint("user_id"))
+
+	utils.OKResponse(c, gin.H{"message": "ticket_assigned"})
+}
+
+// UpdateTicketTriage sets the priority and tags of a ticket
+func UpdateTicketTriage(c *gin.Context) {
+	ticket, ok := findTicket(c)
+	if !ok {
+		return
+	}
+
+	var input dto.TicketTriageInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.BadRequestError(c, "invalid_input")
+		return
+	}
+
+	before := gin.H{"priority": ticket.Priority, "tags": ticket.Tags}
+	updates := map[string]interface{}{}
+	if input.Priority != nil {
+		updates["priority"] = *input.Priority
+	}
+	if input.Tags != nil {
+		updates["tags"] = pq.StringArray(normalizeTicketTags(*input.Tags))
+	}
+	if len(updates) == 0 {
+		utils.BadRequestError(c, "nothing_to_update")
+		return
+	}
+
+	if err := config.DB.Model(&ticket).Updates(updates).Error; err != nil {
+		utils.InternalServerError(c, "failed_to_update_ticket")
+		return
+	}
+	config.DB.First(&ticket, ticket.ID)
+
+	utils.RecordAudit(c, "ticket.triage", "ticket", ticket.ID, before, updates)
+	utils.OKResponse(c, adminTicketToResponse(ticket, ticketSLA()))
+}
+
+// normalizeTicketTags lowercases and deduplicates tags
+func normalizeTicketTags(tags []string) []string {
+	normalized := []string{}
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag != "" && !slices.Contains(normalized, tag) {
+			normalized = append(normalized, tag)
+		}
+	}
+	return normalized
+}
+
+// findTicket loads the ticket of the id route parameter
+func findTicket(c *gin.Context) (models.Ticket, bool) {
+	var ticket models.Ticket
+	ticketID, ok := validateTicketID(c)
+	if !ok {
+		return ticket, false
+	}
+	if err := config.DB.First(&ticket, ticketID).Error; err != nil {
+		utils.NotFoundError(c, "ticket_not_found")
+		return ticket, false
+	}
+	return ticket, true
+}
+
+// publishTicketClaim publishes the new claimant of a ticket, nil once unclaimed
+func publishTicketClaim(ticket models.Ticket, claimedByID *uint, actorID uint) {
+	event := events.TicketClaimed{
+		TicketID:     ticket.ID,
+		TicketUserID: ticket.UserID,
+		TeamID:       ticket.TeamID,
+		ClaimedByID:  claimedByID,
+		UserID:       actorID,
+	}
+	if claimedByID != nil {
+		var claimant models.User
+		if err := config.DB.Select("username").First(&claimant, *claimedByID).Error; err == nil {
+			event.ClaimedByName = claimant.Username
+		}
+	}
+	events.Publish(event)
+}
@@ -0,0 +1,250 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lib/pq"
+	"github.com/pwnthemall/pwnthemall/backend/config"
+	"github.com/pwnthemall/pwnthemall/backend/dto"
+	"github.com/pwnthemall/pwnthemall/backend/models"
+	"github.com/pwnthemall/pwnthemall/backend/utils"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// verifySecondFactor accepts a TOTP code or consumes a recovery code
+func verifySecondFactor(user *models.User, code string) bool {
+	if step, ok := utils.ValidateTOTP(user.TOTPSecret, code, user.TOTPLastStep); ok {
+		user.TOTPLastStep = step
+		return config.DB.Model(user).Update("totp_last_step", step).Error == nil
+	}
+
+	hash := utils.HashRecoveryCode(code)
+	for i, stored := range user.RecoveryCodes {
+		if stored != hash {
+			continue
+		}
+		remaining := append(pq.StringArray{}, user.RecoveryCodes[:i]...)
+		remaining = append(remaining, user.RecoveryCodes[i+1:]...)
+		user.RecoveryCodes = remaining
+		return config.DB.Model(user).Update("recovery_codes", remaining).Error == nil
+	}
+	return false
+}
+
+// LoginTwoFactor completes a login started with a password when 2FA is enabled
+func LoginTwoFactor(c *gin.Context) {
+	var input dto.TwoFactorLoginInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.BadRequestError(c, "invalid_input")
+		return
+	}
+
+	if input.PreAuthToken == "" {
+		input.PreAuthToken, _ = c.Cookie(ssoPreAuthCookie)
+	}
+	userID, err := utils.ParsePreAuthToken(input.PreAuthToken)
+	if err != nil {
+		utils.UnauthorizedError(c, "invalid_pre_auth_token")
+		return
+	}
+
+	var user models.User
+	if err := config.DB.First(&user, userID).Error; err != nil || !user.TOTPEnabled {
+		utils.UnauthorizedError(c, "invalid_pre_auth_token")
+		return
+	}
+	if user.Banned {
+		utils.ErrorResponse(c, 418, "banned")
+		return
+	}
+
+	if !verifySecondFactor(&user, input.Code) {
+		utils.UnauthorizedError(c, "invalid_2fa_code")
+		return
+	}
+
+	if err := generateAndSetTokens(c, user.ID, user.Role); err != nil {
+		utils.InternalServerError(c, err.Error())
+		return
+	}
+	c.SetSameSite(http.SameSiteStrictMode)
+	c.SetCookie(ssoPreAuthCookie, "", -1, "/", "", true, true)
+
+	utils.OKResponse(c, gin.H{"message": "Login successful"})
+}
+
+// SetupTwoFactor generates a new TOTP secret to be confirmed with ConfirmTwoFactor
+func SetupTwoFactor(c *gin.Context) {
+	user, ok := utils.GetAuthenticatedUser(c)
+	if !ok {
+		utils.UnauthorizedError(c, "unauthorized")
+		return
+	}
+	if user.TOTPEnabled {
+		utils.ConflictError(c, "2fa_already_enabled")
+		return
+	}
+
+	secret, err := utils.GenerateTOTPSecret()
+	if err != nil {
+		utils.InternalServerError(c, "2fa_setup_failed")
+		return
+	}
+	if err := config.DB.Model(user).Updates(map[string]interface{}{"totp_secret": secret, "totp_last_step": 0}).Error; err != nil {
+		utils.InternalServerError(c, "2fa_setup_failed")
+		return
+	}
+
+	issuer := config.GetConfigValue("SITE_NAME", "pwnthemall")
+	utils.OKResponse(c, gin.H{
+		"secret": secret,
+		"uri":    utils.TOTPProvisioningURI(secret, issuer, user.Username),
+	})
+}
+
+// ConfirmTwoFactor enables 2FA once the user proves the authenticator works and returns the recovery codes
+func ConfirmTwoFactor(c *gin.Context) {
+	user, ok := utils.GetAuthenticatedUser(c)
+	if !ok {
+		utils.UnauthorizedError(c, "unauthorized")
+		return
+	}
+
+	var input dto.TwoFactorCodeInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.BadRequestError(c, "invalid_input")
+		return
+	}
+	if user.TOTPEnabled {
+		utils.ConflictError(c, "2fa_already_enabled")
+		return
+	}
+	if user.TOTPSecret == "" {
+		utils.BadRequestError(c, "2fa_setup_required")
+		return
+	}
+
+	step, valid := utils.ValidateTOTP(user.TOTPSecret, input.Code, user.TOTPLastStep)
+	if !valid {
+		utils.BadRequestError(c, "invalid_2fa_code")
+		return
+	}
+
+	codes, hashes, err := utils.GenerateRecoveryCodes()
+	if err != nil {
+		utils.InternalServerError(c, "2fa_setup_failed")
+		return
+	}
+	if err := config.DB.Model(user).Updates(map[string]interface{}{
+		"totp_enabled":   true,
+		"totp_last_step": step,
+		"recovery_codes": pq.StringArray(hashes),
+	}).Error; err != nil {
+		utils.InternalServerError(c, "2fa_setup_failed")
+		return
+	}
+
+	utils.OKResponse(c, gin.H{"message": "2fa_enabled", "recoveryCodes": codes})
+}
+
+// DisableTwoFactor turns 2FA off after checking the password and a second factor
+func DisableTwoFactor(c *gin.Context) {
+	user, ok := utils.GetAuthenticatedUser(c)
+	if !ok {
+		utils.UnauthorizedError(c, "unauthorized")
+		return
+	}
+
+	var input dto.TwoFactorDisableInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.BadRequestError(c, "invalid_input")
+		return
+	}
+	if !user.TOTPEnabled {
+		utils.BadRequestError(c, "2fa_not_enabled")
+		return
+	}
+	if config.HasAdminAccess(user.Role) && config.GetConfigBool("REQUIRE_ADMIN_2FA", false) {
+		utils.ForbiddenError(c, "2fa_required_for_role")
+		return
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(input.Password)); err != nil {
+		utils.UnauthorizedError(c, "invalid_credentials")
+		return
+	}
+	if !verifySecondFactor(user, input.Code) {
+		utils.UnauthorizedError(c, "invalid_2fa_code")
+		return
+	}
+
+	if err := config.DB.Model(user).Updates(map[string]interface{}{
+		"totp_enabled":   false,
+		"totp_secret":    "",
+		"totp_last_step": 0,
+		"recovery_codes": pq.StringArray{},
+	}).Error; err != nil {
+		utils.InternalServerError(c, "2fa_disable_failed")
+		return
+	}
+
+	utils.OKResponse(c, gin.H{"message": "2fa_disabled"})
+}
+
+// RegenerateRecoveryCodes replaces the recovery codes of the current user
+func RegenerateRecoveryCodes(c *gin.Context) {
+	user, ok := utils.GetAuthenticatedUser(c)
+	if !ok {
+		utils.UnauthorizedError(c, "unauthorized")
+		return
+	}
+
+	var input dto.TwoFactorCodeInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.BadRequestError(c, "invalid_input")
+		return
+	}
+	if !user.TOTPEnabled {
+		utils.BadRequestError(c, "2fa_not_enabled")
+		return
+	}
+	if !verifySecondFactor(user, input.Code) {
+		utils.UnauthorizedError(c, "invalid_2fa_code")
+		return
+	}
+
+	codes, hashes, err := utils.GenerateRecoveryCodes()
+	if err != nil {
+		utils.InternalServerError(c, "recovery_codes_failed")
+		return
+	}
+	if err := config.DB.Model(user).Update("recovery_codes", pq.StringArray(hashes)).Error; err != nil {
+		utils.InternalServerError(c, "recovery_codes_failed")
+		return
+	}
+
+	utils.OKResponse(c, gin.H{"recoveryCodes": codes})
+}
+
+// ResetUserTwoFactor lets an admin turn off 2FA for a user who lost their authenticator
+func ResetUserTwoFactor(c *gin.Context) {
+	var user models.User
+	if err := config.DB.First(&user, c.Param("id")).Error; err != nil {
+		utils.NotFoundError(c, "user_not_found")
+		return
+	}
+
+	if err := config.DB.Model(&user).Updates(map[string]interface{}{
+		"totp_enabled":   false,
+		"totp_secret":    "",
+		"totp_last_step": 0,
+		"recovery_codes": pq.StringArray{},
+	}).Error; err != nil {
+		utils.InternalServerError(c, "2fa_disable_failed")
+		return
+	}
+
+	utils.RecordAudit(c, "user.2fa_reset", "user", user.ID, gin.H{"totpEnabled": user.TOTPEnabled}, gin.H{"totpEnabled": false})
+
+	utils.OKResponse(c, gin.H{"message": "2fa_disabled"})
+}
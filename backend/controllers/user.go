@@ -1,13 +1,14 @@
 package controllers
 
 import (
-	"encoding/json"
 	"strings"
 
 	"github.com/gin-gonic/gin"
 	"github.com/jinzhu/copier"
 	"github.com/pwnthemall/pwnthemall/backend/config"
 	"github.com/pwnthemall/pwnthemall/backend/dto"
+	"github.com/pwnthemall/pwnthemall/backend/events"
+	"github.com/pwnthemall/pwnthemall/backend/logger"
 	"github.com/pwnthemall/pwnthemall/backend/models"
 	"github.com/pwnthemall/pwnthemall/backend/utils"
 	"golang.org/x/crypto/bcrypt"
@@ -56,9 +57,16 @@ func CreateUser(c *gin.Context) {
 		return
 	}
 
+	if errKey := checkRoleAssignment(c, "member", input.Role); errKey != "" {
+		utils.BadRequestError(c, errKey)
+		return
+	}
+
 	var user models.User
 	copier.Copy(&user, &input)
 	user.Password = string(hashedPassword)
+	// Accounts created by an admin do not go through email verification
+	user.EmailVerified = true
 	if user.Role == "" {
 		user.Role = "member"
 	}
@@ -68,6 +76,8 @@ func CreateUser(c *gin.Context) {
 		return
 	}
 
+	utils.RecordAudit(c, "user.create", "user", user.ID, nil, user)
+
 	// Ne retourne jamais le mot de passe dans la réponse
 	utils.CreatedResponse(c, gin.H{
 		"id":       user.ID,
@@ -97,6 +107,14 @@ func UpdateUser(c *gin.Context) {
 		return
 	}
 
+	if errKey := checkRoleAssignment(c, user.Role, input.Role); errKey != "" {
+		utils.BadRequestError(c, errKey)
+		return
+	}
+	revokeSessions := user.Role != input.Role
+	before := user
+	passwordChanged := false
+
 	user.Username = input.Username
 	user.Email = input.Email
 	user.Role = input.Role
@@ -108,9 +126,23 @@ func UpdateUser(c *gin.Context) {
 			return
 		}
 		user.Password = string(hashedPassword)
+		revokeSessions = true
+		passwordChanged = true
+	}
+	// A new password or role logs the user out everywhere
+	if revokeSessions {
+		if _, err := utils.RevokeUserSessions(user.ID, ""); err != nil {
+			utils.InternalServerError(c, "Failed to revoke sessions")
+			return
+		}
 	}
 	config.DB.Save(&user)
 
+	utils.RecordAudit(c, "user.update", "user", user.ID, before, user)
+	if passwordChanged {
+		utils.RecordAudit(c, "user.password_reset", "user", user.ID, nil, nil)
+	}
+
 	utils.OKResponse(c, user)
 }
 
@@ -124,6 +156,7 @@ func DeleteUser(c *gin.Context) {
 	}
 
 	config.DB.Delete(&user)
+	utils.RecordAudit(c, "user.delete", "user", user.ID, user, nil)
 	utils.OKResponse(c, gin.H{"message": "User deleted"})
 }
 
@@ -195,6 +228,8 @@ func GetCurrentUser(c *gin.Context) {
 		"challengesCompleted": solvesCount,
 		"totalChallenges":     totalChallenges,
 		"socialLinks":         user.SocialLinks,
+		"totpEnabled":         user.TOTPEnabled,
+		"emailVerified":       user.EmailVerified,
 	}
 
 	if user.Team != nil {
@@ -219,15 +254,26 @@ func BanOrUnbanUser(c *gin.Context) {
 	}
 
 	user.Banned = !user.Banned
-	config.DB.Save(&user)
+	if err := config.DB.Save(&user).Error; err != nil {
+		logger.Ctx(c).Errorf("Failed to update ban of user %d: %v", user.ID, err)
+		utils.InternalServerError(c, "failed_to_update_user")
+		return
+	}
 
-	// Broadcast ban event to the specific user via WebSocket
-	if user.Banned && utils.UpdatesHub != nil {
-		payload, _ := json.Marshal(gin.H{
-			"event":   "user-banned",
-			"user_id": user.ID,
-		})
-		utils.UpdatesHub.SendToUser(user.ID, payload)
+	action := "user.unban"
+	if user.Banned {
+		action = "user.ban"
+	}
+	utils.RecordAudit(c, action, "user", user.ID, gin.H{"banned": !user.Banned}, gin.H{"banned": user.Banned})
+
+	if user.Banned {
+		events.Publish(events.UserBanned{UserID: user.ID})
+		// The ban only takes effect once the sessions are gone, so a failure is reported to the admin
+		if _, err := utils.RevokeUserSessions(user.ID, ""); err != nil {
+			logger.Ctx(c).Errorf("Failed to revoke sessions of banned user %d: %v", user.ID, err)
+			utils.InternalServerError(c, "failed_to_revoke_sessions")
+			return
+		}
 	}
 
 	utils.OKResponse(c, gin.H{"banned": user.Banned})
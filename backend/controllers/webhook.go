@@ -4,7 +4,7 @@ import (
 	"context"
 	"strings"
 
-	"github.com/pwnthemall/pwnthemall/backend/debug"
+	"github.com/pwnthemall/pwnthemall/backend/logger"
 
 	"github.com/gin-gonic/gin"
 	"github.com/pwnthemall/pwnthemall/backend/utils"
@@ -23,7 +23,7 @@ func MinioWebhook(c *gin.Context) {
 			go func() {
 				ctx := context.Background()
 				if err := utils.SyncChallengesFromMinIO(ctx, key, utils.UpdatesHub); err != nil {
-					debug.Log("MinIO challenge sync error: %v", err)
+					logger.Errorf("MinIO challenge sync error: %v", err)
 				}
 			}()
 			utils.OKResponse(c, gin.H{"status": "challenge sync started"})
@@ -35,17 +35,14 @@ func MinioWebhook(c *gin.Context) {
 			go func() {
 				ctx := context.Background()
 				if err := utils.SyncPagesFromMinIO(ctx, key, utils.UpdatesHub); err != nil {
-					debug.Log("MinIO page sync error: %v", err)
-				} else {
-					// Broadcast page sync event to connected clients
-					utils.UpdatesHub.SendToAll([]byte(`{"event":"page","action":"synced"}`))
+					logger.Errorf("MinIO page sync error: %v", err)
 				}
 			}()
 			utils.OKResponse(c, gin.H{"status": "page sync started"})
 			return
 		}
 	} else {
-		debug.Log("Key not found or not a string")
+		logger.Ctx(c).Warn("MinIO webhook without a usable object key, nothing synced")
 	}
 
 	utils.OKResponse(c, gin.H{"status": "webhook received"})
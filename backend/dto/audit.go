@@ -0,0 +1,12 @@
+package dto
+
+import "github.com/pwnthemall/pwnthemall/backend/models"
+
+// AuditLogListResponse is the paginated audit log
+type AuditLogListResponse struct {
+	Entries    []models.AuditLog `json:"entries"`
+	Total      int64             `json:"total"`
+	Page       int               `json:"page"`
+	PageSize   int               `json:"pageSize"`
+	TotalPages int               `json:"totalPages"`
+}
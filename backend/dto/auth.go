@@ -13,3 +13,43 @@ type LoginInput struct {
 	Username string `json:"username"`
 	Password string `json:"password"`
 }
+
+// TwoFactorLoginInput represents the second step of a login with 2FA, SSO logins carry the pre-auth token in a cookie instead
+type TwoFactorLoginInput struct {
+	PreAuthToken string `json:"preAuthToken"`
+	Code         string `json:"code" binding:"required"`
+}
+
+// TwoFactorCodeInput represents a TOTP or recovery code confirmation
+type TwoFactorCodeInput struct {
+	Code string `json:"code" binding:"required"`
+}
+
+// TwoFactorDisableInput represents a request to turn 2FA off
+type TwoFactorDisableInput struct {
+	Password string `json:"password" binding:"required"`
+	Code     string `json:"code" binding:"required"`
+}
+
+// EmailTokenInput represents a request carrying an emailed token
+type EmailTokenInput struct {
+	Token string `json:"token" binding:"required"`
+}
+
+// ForgotPasswordInput represents a password reset request
+type ForgotPasswordInput struct {
+	Email string `json:"email" binding:"required,email,max=254"`
+}
+
+// ResetPasswordInput represents a new password set with a reset token
+type ResetPasswordInput struct {
+	Token    string `json:"token" binding:"required"`
+	Password string `json:"password" binding:"required,min=8,max=72"`
+}
+
+// APITokenInput represents a personal API token creation request
+type APITokenInput struct {
+	Name          string   `json:"name" binding:"required,max=64"`
+	Scopes        []string `json:"scopes" binding:"required,min=1"`
+	ExpiresInDays int      `json:"expiresInDays" binding:"min=0,max=365"`
+}
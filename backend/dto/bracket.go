@@ -0,0 +1,23 @@
+package dto
+
+import "time"
+
+// BracketInput represents a bracket creation or update request
+type BracketInput struct {
+	Name        string `json:"name" binding:"required,max=50"`
+	Description string `json:"description" binding:"max=255"`
+}
+
+// BracketTeamsInput replaces the teams of a bracket
+type BracketTeamsInput struct {
+	TeamIDs []uint `json:"teamIds"`
+}
+
+// BracketResponse is a bracket as listed in the admin panel
+type BracketResponse struct {
+	ID          uint      `json:"id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	TeamIDs     []uint    `json:"teamIds"`
+	CreatedAt   time.Time `json:"createdAt"`
+}
@@ -1,6 +1,8 @@
 package dto
 
 import (
+	"time"
+
 	"github.com/lib/pq"
 	"github.com/pwnthemall/pwnthemall/backend/models"
 )
@@ -39,6 +41,7 @@ type ChallengeCreateRequest struct {
 	Hints          []HintCreateRequest `json:"hints"`
 	Hidden         bool                `json:"hidden"`
 	Author         string              `json:"author"`
+	Tags           []string            `json:"tags"`
 	// Geo-specific fields (required when type=geo)
 	TargetLat *float64 `json:"targetLat"`
 	TargetLng *float64 `json:"targetLng"`
@@ -72,6 +75,7 @@ type ChallengeGeneralUpdateRequest struct {
 	CoverPositionX *float64 `json:"coverPositionX"`
 	CoverPositionY *float64 `json:"coverPositionY"`
 	CoverZoom      *float64 `json:"coverZoom"`
+	Tags           []string `json:"tags"`
 }
 
 // ChallengeWithSolved represents a challenge with solve status and hints
@@ -146,3 +150,44 @@ type SafeChallenge struct {
 	Hints                 []HintWithPurchased         `json:"hints,omitempty"`
 	Files                 pq.StringArray              `gorm:"type:text[]" json:"files"`
 }
+
+// ChallengeRatingRequest represents a rating submitted after solving a challenge
+type ChallengeRatingRequest struct {
+	Rating   int    `json:"rating" binding:"required,min=1,max=5"`
+	Feedback string `json:"feedback" binding:"max=1000"`
+}
+
+// ChallengeRatingWithUser represents a rating with its author for admins
+type ChallengeRatingWithUser struct {
+	ID        uint      `json:"id"`
+	UserID    uint      `json:"userId,omitempty"`
+	Username  string    `json:"username,omitempty"`
+	Rating    int       `json:"rating"`
+	Feedback  string    `json:"feedback"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// ChallengeAuthorsInput replaces the users allowed to manage a challenge
+type ChallengeAuthorsInput struct {
+	UserIDs []uint `json:"userIds" binding:"max=50"`
+}
+
+// ChallengeAuthorUser is a challenge author as listed in the admin panel
+type ChallengeAuthorUser struct {
+	ID       uint   `json:"id"`
+	Username string `json:"username"`
+	Role     string `json:"role"`
+}
+
+// AuthorChallengeStats summarizes a challenge on the author dashboard, without any player data
+type AuthorChallengeStats struct {
+	ID           uint                          `json:"id"`
+	Name         string                        `json:"name"`
+	Category     string                        `json:"category"`
+	Hidden       bool                          `json:"hidden"`
+	Points       int                           `json:"points"`
+	Solves       int64                         `json:"solves"`
+	Attempts     int64                         `json:"attempts"`
+	FirstSolveAt *time.Time                    `json:"firstSolveAt"`
+	Ratings      models.ChallengeRatingSummary `json:"ratings"`
+}
@@ -4,11 +4,25 @@ import "time"
 
 // NotificationInput represents notification creation request
 type NotificationInput struct {
-	Title   string `json:"title" binding:"required,max=255"`
-	Message string `json:"message" binding:"required"`
-	Type    string `json:"type" binding:"required,oneof=info warning error success"`
-	UserID  *uint  `json:"userId"`
-	TeamID  *uint  `json:"teamId"`
+	Title               string     `json:"title" binding:"required,max=255"`
+	Message             string     `json:"message" binding:"required"`
+	Type                string     `json:"type" binding:"required,oneof=info warning error success"`
+	UserID              *uint      `json:"userId"`
+	TeamID              *uint      `json:"teamId"`
+	TargetRole          string     `json:"targetRole" binding:"max=50"`
+	UnsolvedChallengeID *uint      `json:"unsolvedChallengeId"`
+	TargetBracketID     *uint      `json:"targetBracketId"`
+	SendAt              *time.Time `json:"sendAt"` // null or past sends right away
+	Pinned              bool       `json:"pinned"`
+}
+
+// NotificationUpdateInput represents notification edit request
+type NotificationUpdateInput struct {
+	Title   string     `json:"title" binding:"required,max=255"`
+	Message string     `json:"message" binding:"required"`
+	Type    string     `json:"type" binding:"required,oneof=info warning error success"`
+	SendAt  *time.Time `json:"sendAt"` // only for notifications not sent yet
+	Pinned  *bool      `json:"pinned"`
 }
 
 // NotificationResponse represents notification with read status
@@ -17,19 +31,44 @@ type NotificationResponse struct {
 	Title     string     `json:"title"`
 	Message   string     `json:"message"`
 	Type      string     `json:"type"`
+	Pinned    bool       `json:"pinned,omitempty"`
 	ReadAt    *time.Time `json:"readAt,omitempty"`
+	EditedAt  *time.Time `json:"editedAt,omitempty"`
 	CreatedAt time.Time  `json:"createdAt"`
 }
 
-// SentNotificationResponse represents sent notification with full details
-type SentNotificationResponse struct {
+// NotificationUpdateEvent is the WebSocket message sent when a delivered notification is edited
+type NotificationUpdateEvent struct {
+	Event string `json:"event"`
+	NotificationResponse
+}
+
+// AnnouncementResponse represents a pinned announcement in the public feed
+type AnnouncementResponse struct {
 	ID        uint       `json:"id"`
 	Title     string     `json:"title"`
 	Message   string     `json:"message"`
 	Type      string     `json:"type"`
-	UserID    *uint      `json:"userId,omitempty"`
-	TeamID    *uint      `json:"teamId,omitempty"`
-	Username  *string    `json:"username,omitempty"`
-	TeamName  *string    `json:"teamName,omitempty"`
+	EditedAt  *time.Time `json:"editedAt,omitempty"`
 	CreatedAt time.Time  `json:"createdAt"`
 }
+
+// SentNotificationResponse represents sent notification with full details
+type SentNotificationResponse struct {
+	ID                  uint       `json:"id"`
+	Title               string     `json:"title"`
+	Message             string     `json:"message"`
+	Type                string     `json:"type"`
+	UserID              *uint      `json:"userId,omitempty"`
+	TeamID              *uint      `json:"teamId,omitempty"`
+	Username            *string    `json:"username,omitempty"`
+	TeamName            *string    `json:"teamName,omitempty"`
+	TargetRole          string     `json:"targetRole,omitempty"`
+	UnsolvedChallengeID *uint      `json:"unsolvedChallengeId,omitempty"`
+	TargetBracketID     *uint      `json:"targetBracketId,omitempty"`
+	SendAt              *time.Time `json:"sendAt,omitempty"`
+	Pending             bool       `json:"pending"`
+	Pinned              bool       `json:"pinned"`
+	EditedAt            *time.Time `json:"editedAt,omitempty"`
+	CreatedAt           time.Time  `json:"createdAt"`
+}
@@ -1,9 +1,67 @@
 package dto
 
+import (
+	"encoding/json"
+	"time"
+)
+
 type PageDTO struct {
-	ID           uint       `gorm:"primaryKey" json:"id"`
-	Slug         string     `gorm:"uniqueIndex;not null" json:"slug"`
-	Title        string     `gorm:"not null" json:"title"`
-	IsInSidebar  bool       `gorm:"default:false" json:"is_in_sidebar"`
-	Order        int        `gorm:"default:0" json:"order"`
-}
\ No newline at end of file
+	ID          uint   `gorm:"primaryKey" json:"id"`
+	Slug        string `gorm:"uniqueIndex;not null" json:"slug"`
+	Title       string `gorm:"not null" json:"title"`
+	IsInSidebar bool   `gorm:"default:false" json:"is_in_sidebar"`
+	Order       int    `gorm:"default:0" json:"order"`
+}
+
+// PageInput is the body of a page creation or update, the content field matching the format is required
+type PageInput struct {
+	Slug        string       `json:"slug" binding:"required"`
+	Title       string       `json:"title" binding:"required"`
+	Format      string       `json:"format" binding:"omitempty,oneof=html markdown"`
+	HTML        string       `json:"html"`
+	Markdown    string       `json:"markdown"`
+	Draft       *bool        `json:"draft"`
+	PublishAt   OptionalTime `json:"publish_at"`
+	UnpublishAt OptionalTime `json:"unpublish_at"`
+}
+
+// OptionalTime tells an omitted time apart from an explicit null, which clears it
+type OptionalTime struct {
+	Set   bool
+	Value *time.Time
+}
+
+// UnmarshalJSON is only called when the field is present, null included
+func (o *OptionalTime) UnmarshalJSON(data []byte) error {
+	o.Set = true
+	o.Value = nil
+	if string(data) == "null" {
+		return nil
+	}
+	var t time.Time
+	if err := json.Unmarshal(data, &t); err != nil {
+		return err
+	}
+	o.Value = &t
+	return nil
+}
+
+// PageRevisionDTO is a saved version of a page, the content is only set when a single revision is fetched
+type PageRevisionDTO struct {
+	ID           uint      `json:"id"`
+	Number       int       `json:"number"`
+	Title        string    `json:"title"`
+	Format       string    `json:"format"`
+	Content      string    `json:"content,omitempty"`
+	AuthorID     *uint     `json:"author_id,omitempty"`
+	AuthorName   string    `json:"author_name,omitempty"`
+	RestoredFrom *int      `json:"restored_from,omitempty"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// PageDiffResponse is the unified diff between two revisions of a page
+type PageDiffResponse struct {
+	From int    `json:"from"`
+	To   int    `json:"to"`
+	Diff string `json:"diff"`
+}
@@ -0,0 +1,29 @@
+package dto
+
+// RoleInput represents a custom role creation or update request
+type RoleInput struct {
+	Name        string `json:"name" binding:"max=50"`
+	Description string `json:"description" binding:"max=255"`
+	Inherits    string `json:"inherits" binding:"max=50"`
+}
+
+// PermissionInput represents a Casbin (obj, act) permission
+type PermissionInput struct {
+	Obj string `json:"obj" binding:"required,max=255"`
+	Act string `json:"act" binding:"required,oneof=read write delete *"`
+}
+
+// UserRoleInput represents a role assignment
+type UserRoleInput struct {
+	Role string `json:"role" binding:"required,max=50"`
+}
+
+// RoleWithPermissions is a role as listed in the admin panel
+type RoleWithPermissions struct {
+	Name        string     `json:"name"`
+	Description string     `json:"description"`
+	BuiltIn     bool       `json:"builtIn"`
+	Inherits    []string   `json:"inherits"`
+	Permissions [][]string `json:"permissions"`
+	UserCount   int64      `json:"userCount"`
+}
@@ -18,6 +18,29 @@ type TicketMessageInput struct {
 	Attachments []string `json:"attachments"`
 }
 
+// AdminTicketMessageInput represents an admin reply, internal notes are hidden from players
+type AdminTicketMessageInput struct {
+	TicketMessageInput
+	Internal bool `json:"internal"`
+}
+
+// TicketAssignInput represents the admin a ticket is assigned to
+type TicketAssignInput struct {
+	UserID uint `json:"userId" binding:"required"`
+}
+
+// TicketTriageInput represents the priority and tags set by admins, missing fields are left unchanged
+type TicketTriageInput struct {
+	Priority *string   `json:"priority" binding:"omitempty,oneof=low normal high urgent"`
+	Tags     *[]string `json:"tags" binding:"omitempty,max=10,dive,min=1,max=32"`
+}
+
+// TicketCannedResponseInput represents a reply template
+type TicketCannedResponseInput struct {
+	Title   string `json:"title" binding:"required,max=100"`
+	Message string `json:"message" binding:"required"`
+}
+
 // TicketResponse represents a ticket in API responses
 type TicketResponse struct {
 	ID            uint       `json:"id"`
@@ -39,8 +62,16 @@ type TicketResponse struct {
 	UpdatedAt     time.Time  `json:"updatedAt"`
 	ClaimedAt     *time.Time `json:"claimedAt,omitempty"`
 	ResolvedAt    *time.Time `json:"resolvedAt,omitempty"`
+	ReopenedAt    *time.Time `json:"reopenedAt,omitempty"`
 	MessageCount  int        `json:"messageCount"`
 	LastMessage   *string    `json:"lastMessage,omitempty"`
+
+	// Admin only
+	Priority        string     `json:"priority,omitempty"`
+	Tags            []string   `json:"tags,omitempty"`
+	FirstResponseAt *time.Time `json:"firstResponseAt,omitempty"`
+	SLADueAt        *time.Time `json:"slaDueAt,omitempty"`
+	SLABreached     bool       `json:"slaBreached,omitempty"`
 }
 
 // TicketDetailResponse includes messages for the detail view
@@ -68,6 +99,7 @@ type TicketMessageResponse struct {
 	Username    string    `json:"username"`
 	Message     string    `json:"message"`
 	IsAdmin     bool      `json:"isAdmin"`
+	IsInternal  bool      `json:"isInternal,omitempty"`
 	Attachments []string  `json:"attachments"`
 	CreatedAt   time.Time `json:"createdAt"`
 }
@@ -81,6 +113,28 @@ type TicketListResponse struct {
 	TotalPages int              `json:"totalPages"`
 }
 
+// TicketAnalyticsResponse summarizes the support load, overall and per challenge
+type TicketAnalyticsResponse struct {
+	Total                      int64                  `json:"total"`
+	Open                       int64                  `json:"open"`
+	Resolved                   int64                  `json:"resolved"`
+	Reopened                   int64                  `json:"reopened"`
+	MedianResolveSeconds       *float64               `json:"medianResolveSeconds"`
+	MedianFirstResponseSeconds *float64               `json:"medianFirstResponseSeconds"`
+	Challenges                 []TicketChallengeStats `json:"challenges"` // most support load first
+}
+
+// TicketChallengeStats holds the ticket figures of a challenge
+type TicketChallengeStats struct {
+	ChallengeID          uint     `json:"challengeId"`
+	ChallengeName        string   `json:"challengeName"`
+	ChallengeSlug        string   `json:"challengeSlug"`
+	Tickets              int64    `json:"tickets"`
+	Open                 int64    `json:"open"`
+	Messages             int64    `json:"messages"`
+	MedianResolveSeconds *float64 `json:"medianResolveSeconds"`
+}
+
 // TicketWebSocketEvent represents a WebSocket event for tickets
 type TicketWebSocketEvent struct {
 	Event         string   `json:"event"`
@@ -94,6 +148,7 @@ type TicketWebSocketEvent struct {
 	Attachments   []string `json:"attachments,omitempty"`
 	CreatedAt     string   `json:"createdAt,omitempty"`
 	IsAdmin       bool     `json:"isAdmin,omitempty"`
+	IsInternal    bool     `json:"isInternal,omitempty"`
 	ClaimedByID   *uint    `json:"claimedById,omitempty"`
 	ClaimedByName *string  `json:"claimedByName,omitempty"`
 }
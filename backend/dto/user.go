@@ -5,7 +5,7 @@ type UserInput struct {
 	Username string `json:"username" binding:"required,max=32"`
 	Email    string `json:"email" binding:"required,email,max=254"`
 	Password string `json:"password" binding:"omitempty,min=8,max=72"`
-	Role     string `json:"role" binding:"required,oneof=member admin"`
+	Role     string `json:"role" binding:"required,max=50"`
 	TeamID   *uint  `json:"teamId"`
 }
 
@@ -0,0 +1,21 @@
+package dto
+
+import "github.com/pwnthemall/pwnthemall/backend/models"
+
+// WebhookTargetInput represents an outbound webhook creation or update request
+type WebhookTargetInput struct {
+	Name    string   `json:"name" binding:"required,max=100"`
+	URL     string   `json:"url" binding:"required,max=2048"`
+	Format  string   `json:"format" binding:"required,oneof=json discord slack"`
+	Events  []string `json:"events" binding:"required,min=1"`
+	Enabled *bool    `json:"enabled"`
+}
+
+// WebhookDeliveryListResponse is a page of the delivery log of a webhook target
+type WebhookDeliveryListResponse struct {
+	Deliveries []models.WebhookDelivery `json:"deliveries"`
+	Total      int64                    `json:"total"`
+	Page       int                      `json:"page"`
+	PageSize   int                      `json:"pageSize"`
+	TotalPages int                      `json:"totalPages"`
+}
@@ -0,0 +1,77 @@
+package events
+
+import (
+	"encoding/json"
+	"runtime/debug"
+	"sync"
+	"time"
+
+	"github.com/pwnthemall/pwnthemall/backend/logger"
+)
+
+// Event is a platform event published by controllers and handed to every subscriber
+type Event interface {
+	EventName() string
+}
+
+// Handler receives the published events
+type Handler func(Event)
+
+type subscriber struct {
+	name    string
+	handler Handler
+}
+
+var (
+	mu          sync.RWMutex
+	subscribers []subscriber
+)
+
+// Subscribe registers a handler for every event, the name identifies it in logs
+func Subscribe(name string, handler Handler) {
+	mu.Lock()
+	defer mu.Unlock()
+	subscribers = append(subscribers, subscriber{name: name, handler: handler})
+}
+
+// On registers a handler for a single event type
+func On[T Event](name string, handler func(T)) {
+	Subscribe(name, func(e Event) {
+		if typed, ok := e.(T); ok {
+			handler(typed)
+		}
+	})
+}
+
+// Publish hands the event to the subscribers in registration order, a panicking subscriber is logged and skipped
+func Publish(e Event) {
+	mu.RLock()
+	current := subscribers
+	mu.RUnlock()
+
+	logger.Debug("Publishing event", "event", e.EventName(), "subscribers", len(current))
+	for _, s := range current {
+		deliver(s, e)
+	}
+}
+
+func deliver(s subscriber, e Event) {
+	defer func() {
+		if r := recover(); r != nil {
+			logger.Error("Event subscriber panicked", "subscriber", s.name, "event", e.EventName(), "panic", r, "stack", string(debug.Stack()))
+		}
+	}()
+	s.handler(e)
+}
+
+// Envelope is the serialized form of an event sent to webhooks and plugins
+type Envelope struct {
+	Event     string    `json:"event"`
+	Timestamp time.Time `json:"timestamp"`
+	Data      Event     `json:"data"`
+}
+
+// Marshal wraps the event in an envelope and encodes it as JSON
+func Marshal(e Event) ([]byte, error) {
+	return json.Marshal(Envelope{Event: e.EventName(), Timestamp: time.Now().UTC(), Data: e})
+}
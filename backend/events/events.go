@@ -0,0 +1,219 @@
+package events
+
+import "time"
+
+// SolveCreated is published when a team solves a challenge
+type SolveCreated struct {
+	TeamID        uint      `json:"teamId"`
+	TeamName      string    `json:"teamName"`
+	ChallengeID   uint      `json:"challengeId"`
+	ChallengeName string    `json:"challengeName"`
+	ChallengeSlug string    `json:"challengeSlug"`
+	UserID        uint      `json:"userId"`
+	Username      string    `json:"username"`
+	Points        int       `json:"points"`
+	Position      int64     `json:"position"` // 1 for the first solve
+	SolvedAt      time.Time `json:"solvedAt"`
+}
+
+func (SolveCreated) EventName() string { return "solve.created" }
+
+// FirstBlood is published when a solve earns a first blood bonus
+type FirstBlood struct {
+	TeamID        uint   `json:"teamId"`
+	TeamName      string `json:"teamName"`
+	ChallengeID   uint   `json:"challengeId"`
+	ChallengeName string `json:"challengeName"`
+	UserID        uint   `json:"userId"`
+	Username      string `json:"username"`
+	Position      int64  `json:"position"` // 1 for the first solve
+	Bonus         int    `json:"bonus"`
+	Badge         string `json:"badge"`
+}
+
+func (FirstBlood) EventName() string { return "solve.first_blood" }
+
+// InstanceStarted is published when a team instance is running
+type InstanceStarted struct {
+	TeamID         uint      `json:"teamId"`
+	UserID         uint      `json:"userId"`
+	Username       string    `json:"username"`
+	ChallengeID    uint      `json:"challengeId"`
+	Name           string    `json:"name"`
+	Ports          []int     `json:"ports"`
+	ConnectionInfo []string  `json:"connectionInfo"`
+	CreatedAt      time.Time `json:"createdAt"`
+	ExpiresAt      time.Time `json:"expiresAt"`
+}
+
+func (InstanceStarted) EventName() string { return "instance.started" }
+
+// InstanceStopped is published when a team instance is stopped, Reason is "user" or "solve"
+type InstanceStopped struct {
+	TeamID      uint   `json:"teamId"`
+	UserID      uint   `json:"userId"`
+	Username    string `json:"username"`
+	ChallengeID uint   `json:"challengeId"`
+	Reason      string `json:"reason"`
+}
+
+func (InstanceStopped) EventName() string { return "instance.stopped" }
+
+// HintPurchased is published when a team buys a hint, the content is only sent to the team
+type HintPurchased struct {
+	TeamID      uint   `json:"teamId"`
+	ChallengeID uint   `json:"challengeId"`
+	HintID      uint   `json:"hintId"`
+	HintTitle   string `json:"hintTitle"`
+	HintContent string `json:"-"`
+	Cost        int    `json:"cost"`
+	UserID      uint   `json:"userId"`
+	Username    string `json:"username"`
+}
+
+func (HintPurchased) EventName() string { return "hint.purchased" }
+
+// TeamJoined is published when a user creates or joins a team
+type TeamJoined struct {
+	TeamID   uint   `json:"teamId"`
+	TeamName string `json:"teamName"`
+	UserID   uint   `json:"userId"`
+	Username string `json:"username"`
+	Created  bool   `json:"created"`
+}
+
+func (TeamJoined) EventName() string { return "team.joined" }
+
+// TicketCreated is published when a user opens a ticket
+type TicketCreated struct {
+	TicketID uint   `json:"ticketId"`
+	Subject  string `json:"subject"`
+	UserID   uint   `json:"userId"`
+	Username string `json:"username"`
+	TeamID   *uint  `json:"teamId,omitempty"`
+}
+
+func (TicketCreated) EventName() string { return "ticket.created" }
+
+// TicketMessageAdded is published when a user or staff member replies to a ticket
+type TicketMessageAdded struct {
+	TicketID     uint      `json:"ticketId"`
+	TicketUserID uint      `json:"ticketUserId"`
+	TeamID       *uint     `json:"teamId,omitempty"`
+	MessageID    uint      `json:"messageId"`
+	UserID       uint      `json:"userId"`
+	Username     string    `json:"username"`
+	Message      string    `json:"message"`
+	Attachments  []string  `json:"attachments,omitempty"`
+	IsAdmin      bool      `json:"isAdmin"`
+	Internal     bool      `json:"internal"` // staff only note
+	CreatedAt    time.Time `json:"createdAt"`
+}
+
+func (TicketMessageAdded) EventName() string { return "ticket.message" }
+
+// TicketReopened is published when a resolved ticket is reopened by its owner, their team or staff
+type TicketReopened struct {
+	TicketID     uint  `json:"ticketId"`
+	TicketUserID uint  `json:"ticketUserId"`
+	TeamID       *uint `json:"teamId,omitempty"`
+	UserID       uint  `json:"userId"`
+}
+
+func (TicketReopened) EventName() string { return "ticket.reopened" }
+
+// TicketClaimed is published when a ticket is claimed, assigned or unclaimed, ClaimedByID is nil once unclaimed
+type TicketClaimed struct {
+	TicketID      uint   `json:"ticketId"`
+	TicketUserID  uint   `json:"ticketUserId"`
+	TeamID        *uint  `json:"teamId,omitempty"`
+	ClaimedByID   *uint  `json:"claimedById,omitempty"`
+	ClaimedByName string `json:"claimedByName,omitempty"`
+	UserID        uint   `json:"userId"`
+}
+
+func (TicketClaimed) EventName() string { return "ticket.claimed" }
+
+// TicketResolved is published when a ticket is closed by its owner or resolved by staff
+type TicketResolved struct {
+	TicketID     uint  `json:"ticketId"`
+	TicketUserID uint  `json:"ticketUserId"`
+	TeamID       *uint `json:"teamId,omitempty"`
+	UserID       uint  `json:"userId,omitempty"`
+}
+
+func (TicketResolved) EventName() string { return "ticket.resolved" }
+
+// NotificationSent is published when a notification is delivered, a global one targets everyone but the sender
+type NotificationSent struct {
+	NotificationID uint      `json:"notificationId"`
+	Title          string    `json:"title"`
+	Message        string    `json:"message"`
+	Type           string    `json:"type"`
+	UserID         *uint     `json:"userId,omitempty"`
+	TeamID         *uint     `json:"teamId,omitempty"`
+	Global         bool      `json:"global"`
+	Recipients     []uint    `json:"-"` // resolved audience of role or challenge targeted notifications
+	SenderID       uint      `json:"senderId"`
+	CreatedAt      time.Time `json:"createdAt"`
+}
+
+func (NotificationSent) EventName() string { return "notification.sent" }
+
+// NotificationUpdated is published when an admin edits a notification that was already delivered
+type NotificationUpdated struct {
+	NotificationID uint      `json:"notificationId"`
+	Title          string    `json:"title"`
+	Message        string    `json:"message"`
+	Type           string    `json:"type"`
+	UserID         *uint     `json:"userId,omitempty"`
+	TeamID         *uint     `json:"teamId,omitempty"`
+	Global         bool      `json:"global"`
+	Recipients     []uint    `json:"-"`
+	EditorID       uint      `json:"editorId"`
+	CreatedAt      time.Time `json:"createdAt"`
+	EditedAt       time.Time `json:"editedAt"`
+}
+
+func (NotificationUpdated) EventName() string { return "notification.updated" }
+
+// UserBanned is published when an admin bans a user
+type UserBanned struct {
+	UserID uint `json:"userId"`
+}
+
+func (UserBanned) EventName() string { return "user.banned" }
+
+// ChallengeReleased is published when a challenge becomes visible to players
+type ChallengeReleased struct {
+	ChallengeID uint   `json:"challengeId"`
+	Name        string `json:"name"`
+	Slug        string `json:"slug"`
+	Category    string `json:"category"`
+	Points      int    `json:"points"`
+}
+
+func (ChallengeReleased) EventName() string { return "challenge.released" }
+
+// ChallengesChanged is published when challenges or categories change, Action tells what changed
+type ChallengesChanged struct {
+	Action string `json:"action"`
+}
+
+func (ChallengesChanged) EventName() string { return "challenges.changed" }
+
+// ConfigChanged is published when a configuration value is created or updated
+type ConfigChanged struct {
+	Key    string `json:"key"`
+	Value  string `json:"-"`
+	Public bool   `json:"public"`
+}
+
+func (ConfigChanged) EventName() string { return "config.changed" }
+
+// PageSynced is published when a page is synced from MinIO
+type PageSynced struct {
+	Slug string `json:"slug"`
+}
+
+func (PageSynced) EventName() string { return "page.synced" }
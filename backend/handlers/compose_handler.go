@@ -1,7 +1,6 @@
 package handlers
 
 import (
-	"encoding/json"
 	"fmt"
 	"net/http"
 	"os"
@@ -12,7 +11,8 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/lib/pq"
 	"github.com/pwnthemall/pwnthemall/backend/config"
-	"github.com/pwnthemall/pwnthemall/backend/debug"
+	"github.com/pwnthemall/pwnthemall/backend/events"
+	"github.com/pwnthemall/pwnthemall/backend/logger"
 	"github.com/pwnthemall/pwnthemall/backend/models"
 	"github.com/pwnthemall/pwnthemall/backend/shared"
 	"github.com/pwnthemall/pwnthemall/backend/utils"
@@ -37,7 +37,7 @@ func prepareComposeProject(challengeSlug string, teamID, userID int) (interface{
 }
 
 func (h *composeChallengeHandler) Start(c *gin.Context, challenge shared.Challenge) error {
-	debug.Log("Starting Compose instance for challenge ID: %d", challenge.GetID())
+	logger.Ctx(c).Infof("Starting Compose instance for challenge ID: %d", challenge.GetID())
 
 	// Get user
 	userID, ok := c.Get("user_id")
@@ -49,7 +49,7 @@ func (h *composeChallengeHandler) Start(c *gin.Context, challenge shared.Challen
 	// Load docker config
 	var dockerConfig models.DockerConfig
 	if err := config.DB.First(&dockerConfig).Error; err != nil {
-		debug.Log("Docker config not found: %v", err)
+		logger.Ctx(c).Errorf("Docker config not found: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "docker_config_not_found"})
 		return err
 	}
@@ -62,7 +62,7 @@ func (h *composeChallengeHandler) Start(c *gin.Context, challenge shared.Challen
 
 	// Ensure Docker is available
 	if err := utils.EnsureDockerClientConnected(); err != nil {
-		debug.Log("Docker connection failed: %v", err)
+		logger.Ctx(c).Errorf("Docker connection failed: %v", err)
 		c.JSON(http.StatusServiceUnavailable, gin.H{
 			"error":   "docker_unavailable",
 			"message": "Docker service unavailable.",
@@ -72,7 +72,7 @@ func (h *composeChallengeHandler) Start(c *gin.Context, challenge shared.Challen
 
 	project, err := prepareComposeProject(challenge.GetSlug(), int(*user.TeamID), int(user.ID))
 	if err != nil {
-		debug.Log("CreateComposeProject failed: %v", err)
+		logger.Ctx(c).Errorf("CreateComposeProject failed: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "create_compose_failed"})
 		return err
 	}
@@ -80,7 +80,7 @@ func (h *composeChallengeHandler) Start(c *gin.Context, challenge shared.Challen
 	// Randomize ports
 	ports, err := utils.RandomizeServicePorts(project.(*types.Project))
 	if err != nil {
-		debug.Log("RandomizeServicePorts failed: %v", err)
+		logger.Ctx(c).Errorf("RandomizeServicePorts failed: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "randomize_ports_failed"})
 		return err
 	}
@@ -127,7 +127,7 @@ func (h *composeChallengeHandler) Start(c *gin.Context, challenge shared.Challen
 	// Start compose asynchronously (takes 10+ seconds)
 	go func() {
 		if err := utils.StartComposeInstance(project.(*types.Project), int(*user.TeamID)); err != nil {
-			debug.Log("StartComposeInstance failed: %v", err)
+			logger.Errorf("StartComposeInstance failed: %v", err)
 			config.DB.Delete(&instance)
 			return
 		}
@@ -135,16 +135,16 @@ func (h *composeChallengeHandler) Start(c *gin.Context, challenge shared.Challen
 		// Push firewall
 		teamIPs, ipErr := utils.GetTeamIPs(*user.TeamID)
 		if ipErr != nil {
-			debug.Log("failed to retrieve team IPs: %v", ipErr)
+			logger.Errorf("failed to retrieve team IPs: %v", ipErr)
 		} else {
 			if err := utils.PushFirewallToAgent(*user.TeamID, ports, teamIPs); err != nil {
-				debug.Log("Could not push team firewall config: %v", err)
+				logger.Errorf("Could not push team firewall config: %v", err)
 			}
 		}
 
 		// Broadcast instance start after successful startup
 		h.broadcastInstanceStart(&instance, *user, challenge, ports)
-		debug.Log("Compose instance started successfully: %s", projectName)
+		logger.Infof("Compose instance started successfully: %s", projectName)
 	}()
 
 	return nil
@@ -198,15 +198,15 @@ func (h *composeChallengeHandler) Stop(c *gin.Context, challenge shared.Challeng
 
 	go func() {
 		if err := utils.StopComposeInstance(instance.Name); err != nil {
-			debug.Log("Failed to stop Compose instance: %v", err)
+			logger.Errorf("Failed to stop Compose instance: %v", err)
 			return
 		}
 		if err := config.DB.Delete(&instance).Error; err != nil {
-			debug.Log("Failed to delete Compose instance from DB: %v", err)
+			logger.Errorf("Failed to delete Compose instance from DB: %v", err)
 			return
 		}
 		h.broadcastInstanceStop(userID, &instance)
-		debug.Log("Compose instance stopped and broadcast sent: %s", instance.Name)
+		logger.Debugf("Compose instance stopped and broadcast sent: %s", instance.Name)
 	}()
 
 	c.JSON(http.StatusOK, gin.H{
@@ -248,7 +248,7 @@ func (h *composeChallengeHandler) GetStatus(c *gin.Context, challenge shared.Cha
 			})
 			return nil
 		}
-		debug.Log("Database error when checking instance status: %v", err)
+		logger.Ctx(c).Errorf("Database error when checking instance status: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "database_error"})
 		return err
 	}
@@ -373,10 +373,6 @@ func (h *composeChallengeHandler) buildConnectionInfo(challenge *models.Challeng
 }
 
 func (h *composeChallengeHandler) broadcastInstanceStart(instance *models.Instance, user models.User, challenge shared.Challenge, ports []int) {
-	if utils.WebSocketHub == nil {
-		return
-	}
-
 	ip := os.Getenv("PTA_DOCKER_WORKER_IP")
 	if ip == "" {
 		ip = "worker-ip"
@@ -400,76 +396,32 @@ func (h *composeChallengeHandler) broadcastInstanceStart(instance *models.Instan
 		connectionInfo = append(connectionInfo, formattedInfo)
 	}
 
-	type InstanceEvent struct {
-		Event          string    `json:"event"`
-		TeamID         uint      `json:"teamId"`
-		UserID         uint      `json:"userId"`
-		Username       string    `json:"username"`
-		ChallengeID    uint      `json:"challengeId"`
-		Status         string    `json:"status"`
-		CreatedAt      time.Time `json:"createdAt"`
-		ExpiresAt      time.Time `json:"expiresAt"`
-		Name           string    `json:"name"`
-		Ports          []int     `json:"ports"`
-		ConnectionInfo []string  `json:"connectionInfo"`
-	}
-
-	event := InstanceEvent{
-		Event:          "instance_update",
+	events.Publish(events.InstanceStarted{
 		TeamID:         user.Team.ID,
 		UserID:         user.ID,
 		Username:       user.Username,
 		ChallengeID:    challenge.GetID(),
-		Status:         "running",
-		CreatedAt:      instance.CreatedAt,
-		ExpiresAt:      instance.ExpiresAt,
 		Name:           instance.Name,
 		Ports:          ports,
 		ConnectionInfo: connectionInfo,
-	}
-
-	if payload, err := json.Marshal(event); err == nil {
-		utils.WebSocketHub.SendToTeamExcept(user.Team.ID, user.ID, payload)
-	}
+		CreatedAt:      instance.CreatedAt,
+		ExpiresAt:      instance.ExpiresAt,
+	})
 }
 
 func (h *composeChallengeHandler) broadcastInstanceStop(userID interface{}, instance *models.Instance) {
-	if utils.WebSocketHub == nil {
-		return
-	}
-
 	var user models.User
 	if err := config.DB.Select("id, username, team_id").First(&user, userID).Error; err != nil || user.TeamID == nil {
 		return
 	}
 
-	type InstanceEvent struct {
-		Event       string    `json:"event"`
-		TeamID      uint      `json:"teamId"`
-		UserID      uint      `json:"userId"`
-		Username    string    `json:"username"`
-		ChallengeID uint      `json:"challengeId"`
-		Status      string    `json:"status"`
-		UpdatedAt   time.Time `json:"updatedAt"`
-	}
-
-	event := InstanceEvent{
-		Event:       "instance_update",
+	events.Publish(events.InstanceStopped{
 		TeamID:      *user.TeamID,
 		UserID:      user.ID,
 		Username:    user.Username,
 		ChallengeID: instance.ChallengeID,
-		Status:      "stopped",
-		UpdatedAt:   time.Now().UTC(),
-	}
-
-	if payload, err := json.Marshal(event); err == nil {
-		debug.Log("[WebSocket] Broadcasting instance_update (stopped) for challenge %d to team %d", instance.ChallengeID, *user.TeamID)
-		utils.WebSocketHub.SendToTeam(*user.TeamID, payload)
-		debug.Log("[WebSocket] Broadcast sent successfully")
-	} else {
-		debug.Log("[WebSocket] Failed to marshal event: %v", err)
-	}
+		Reason:      "user",
+	})
 }
 
 func init() {
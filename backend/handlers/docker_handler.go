@@ -1,7 +1,6 @@
 package handlers
 
 import (
-	"encoding/json"
 	"fmt"
 	"net/http"
 	"os"
@@ -12,7 +11,8 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/lib/pq"
 	"github.com/pwnthemall/pwnthemall/backend/config"
-	"github.com/pwnthemall/pwnthemall/backend/debug"
+	"github.com/pwnthemall/pwnthemall/backend/events"
+	"github.com/pwnthemall/pwnthemall/backend/logger"
 	"github.com/pwnthemall/pwnthemall/backend/models"
 	"github.com/pwnthemall/pwnthemall/backend/shared"
 	"github.com/pwnthemall/pwnthemall/backend/utils"
@@ -29,13 +29,13 @@ func (h *dockerChallengeHandler) ensureImageBuiltOrBuild(challenge shared.Challe
 	}
 
 	if err := utils.EnsureDockerClientConnected(); err != nil {
-		debug.Log("Docker connection failed for challenge %s: %v", challenge.GetSlug(), err)
+		logger.Errorf("Docker connection failed for challenge %s: %v", challenge.GetSlug(), err)
 		return "", fmt.Errorf("docker_unavailable")
 	}
 
 	tmpDir := filepath.Join(os.TempDir(), challenge.GetSlug())
 	if err := utils.DownloadChallengeContext(challenge.GetSlug(), tmpDir); err != nil {
-		debug.Log("Failed to download challenge context: %v", err)
+		logger.Errorf("Failed to download challenge context: %v", err)
 		os.RemoveAll(tmpDir)
 		return "", fmt.Errorf("failed_to_download_challenge")
 	}
@@ -43,18 +43,18 @@ func (h *dockerChallengeHandler) ensureImageBuiltOrBuild(challenge shared.Challe
 
 	imageName, err := utils.BuildDockerImage(challenge.GetSlug(), tmpDir)
 	if err != nil {
-		debug.Log("Docker build failed for challenge %s: %v", challenge.GetSlug(), err)
+		logger.Errorf("Docker build failed for challenge %s: %v", challenge.GetSlug(), err)
 		return "", fmt.Errorf("docker_build_failed")
 	}
 
-	debug.Log("Image built successfully: %s", imageName)
+	logger.Infof("Image built successfully: %s", imageName)
 	return imageName, nil
 }
 
 // validateInstanceStartPreconditions checks all preconditions for starting instance
 func (h *dockerChallengeHandler) validateInstanceStartPreconditions(c *gin.Context, user models.User, challengeID uint, dockerConfig models.DockerConfig) bool {
 	if user.Team == nil || user.TeamID == nil {
-		debug.Log("User has no team")
+		logger.Ctx(c).Debug("User has no team")
 		c.JSON(http.StatusForbidden, gin.H{"error": "team_required"})
 		return false
 	}
@@ -108,7 +108,7 @@ func (h *dockerChallengeHandler) validateInstanceStartPreconditions(c *gin.Conte
 }
 
 func (h *dockerChallengeHandler) Start(c *gin.Context, challenge shared.Challenge) error {
-	debug.Log("Starting Docker instance for challenge ID: %d", challenge.GetID())
+	logger.Ctx(c).Infof("Starting Docker instance for challenge ID: %d", challenge.GetID())
 
 	// Ensure image is built
 	imageName, err := h.ensureImageBuiltOrBuild(challenge)
@@ -133,14 +133,14 @@ func (h *dockerChallengeHandler) Start(c *gin.Context, challenge shared.Challeng
 
 	var dockerConfig models.DockerConfig
 	if err := config.DB.First(&dockerConfig).Error; err != nil {
-		debug.Log("Docker config not found: %v", err)
+		logger.Ctx(c).Errorf("Docker config not found: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "docker_config_not_found"})
 		return err
 	}
 
 	var user models.User
 	if err := config.DB.Preload("Team").First(&user, userID).Error; err != nil {
-		debug.Log("User not found with ID %v: %v", userID, err)
+		logger.Ctx(c).Debugf("User not found with ID %v: %v", userID, err)
 		c.JSON(http.StatusNotFound, gin.H{"error": "user_not_found"})
 		return err
 	}
@@ -171,7 +171,7 @@ func (h *dockerChallengeHandler) Start(c *gin.Context, challenge shared.Challeng
 
 	// Ensure Docker connection
 	if err := utils.EnsureDockerClientConnected(); err != nil {
-		debug.Log("Docker connection failed: %v", err)
+		logger.Ctx(c).Errorf("Docker connection failed: %v", err)
 		c.JSON(http.StatusServiceUnavailable, gin.H{
 			"error":   "docker_unavailable",
 			"message": "Docker service is currently unavailable.",
@@ -182,7 +182,7 @@ func (h *dockerChallengeHandler) Start(c *gin.Context, challenge shared.Challeng
 	// Start container
 	containerName, err := utils.StartDockerInstance(imageName, int(*user.TeamID), int(user.ID), internalPorts, hostPorts)
 	if err != nil {
-		debug.Log("Error starting Docker instance: %v", err)
+		logger.Ctx(c).Errorf("Error starting Docker instance: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return err
 	}
@@ -220,10 +220,10 @@ func (h *dockerChallengeHandler) Start(c *gin.Context, challenge shared.Challeng
 	// Push firewall rules
 	teamIPs, ipErr := utils.GetTeamIPs(*user.TeamID)
 	if ipErr != nil {
-		debug.Log("failed to retrieve team IPs: %v", ipErr)
+		logger.Ctx(c).Errorf("failed to retrieve team IPs: %v", ipErr)
 	} else {
 		if err := utils.PushFirewallToAgent(*user.TeamID, hostPorts, teamIPs); err != nil {
-			debug.Log("Could not push team firewall config: %v", err)
+			logger.Ctx(c).Errorf("Could not push team firewall config: %v", err)
 		}
 	}
 
@@ -289,13 +289,13 @@ func (h *dockerChallengeHandler) Stop(c *gin.Context, challenge shared.Challenge
 
 	go func() {
 		if err := utils.StopDockerInstance(instance.Name); err != nil {
-			debug.Log("Failed to stop Docker instance: %v", err)
+			logger.Errorf("Failed to stop Docker instance: %v", err)
 		}
 		if err := config.DB.Delete(&instance).Error; err != nil {
-			debug.Log("Failed to delete instance from DB: %v", err)
+			logger.Errorf("Failed to delete instance from DB: %v", err)
 		}
 		h.broadcastInstanceStop(userID, &instance)
-		debug.Log("Docker instance stopped and broadcast sent: %s", instance.Name)
+		logger.Debugf("Docker instance stopped and broadcast sent: %s", instance.Name)
 	}()
 
 	c.JSON(http.StatusOK, gin.H{
@@ -337,7 +337,7 @@ func (h *dockerChallengeHandler) GetStatus(c *gin.Context, challenge shared.Chal
 			})
 			return nil
 		}
-		debug.Log("Database error when checking instance status: %v", err)
+		logger.Ctx(c).Errorf("Database error when checking instance status: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "database_error"})
 		return err
 	}
@@ -408,10 +408,6 @@ func (h *dockerChallengeHandler) buildConnectionInfo(challenge *models.Challenge
 }
 
 func (h *dockerChallengeHandler) broadcastInstanceStart(instance *models.Instance, user models.User, challenge shared.Challenge, ports []int) {
-	if utils.WebSocketHub == nil {
-		return
-	}
-
 	ip := os.Getenv("PTA_DOCKER_WORKER_IP")
 	if ip == "" {
 		ip = "worker-ip"
@@ -435,72 +431,32 @@ func (h *dockerChallengeHandler) broadcastInstanceStart(instance *models.Instanc
 		connectionInfo = append(connectionInfo, formattedInfo)
 	}
 
-	type InstanceEvent struct {
-		Event          string    `json:"event"`
-		TeamID         uint      `json:"teamId"`
-		UserID         uint      `json:"userId"`
-		Username       string    `json:"username"`
-		ChallengeID    uint      `json:"challengeId"`
-		Status         string    `json:"status"`
-		CreatedAt      time.Time `json:"createdAt"`
-		ExpiresAt      time.Time `json:"expiresAt"`
-		Name           string    `json:"name"`
-		Ports          []int     `json:"ports"`
-		ConnectionInfo []string  `json:"connectionInfo"`
-	}
-
-	event := InstanceEvent{
-		Event:          "instance_update",
+	events.Publish(events.InstanceStarted{
 		TeamID:         user.Team.ID,
 		UserID:         user.ID,
 		Username:       user.Username,
 		ChallengeID:    challenge.GetID(),
-		Status:         "running",
-		CreatedAt:      instance.CreatedAt,
-		ExpiresAt:      instance.ExpiresAt,
 		Name:           instance.Name,
 		Ports:          ports,
 		ConnectionInfo: connectionInfo,
-	}
-
-	if payload, err := json.Marshal(event); err == nil {
-		utils.WebSocketHub.SendToTeamExcept(user.Team.ID, user.ID, payload)
-	}
+		CreatedAt:      instance.CreatedAt,
+		ExpiresAt:      instance.ExpiresAt,
+	})
 }
 
 func (h *dockerChallengeHandler) broadcastInstanceStop(userID interface{}, instance *models.Instance) {
-	if utils.WebSocketHub == nil {
-		return
-	}
-
 	var user models.User
 	if err := config.DB.Select("id, username, team_id").First(&user, userID).Error; err != nil || user.TeamID == nil {
 		return
 	}
 
-	type InstanceEvent struct {
-		Event       string    `json:"event"`
-		TeamID      uint      `json:"teamId"`
-		UserID      uint      `json:"userId"`
-		Username    string    `json:"username"`
-		ChallengeID uint      `json:"challengeId"`
-		Status      string    `json:"status"`
-		UpdatedAt   time.Time `json:"updatedAt"`
-	}
-
-	event := InstanceEvent{
-		Event:       "instance_update",
+	events.Publish(events.InstanceStopped{
 		TeamID:      *user.TeamID,
 		UserID:      user.ID,
 		Username:    user.Username,
 		ChallengeID: instance.ChallengeID,
-		Status:      "stopped",
-		UpdatedAt:   time.Now().UTC(),
-	}
-
-	if payload, err := json.Marshal(event); err == nil {
-		utils.WebSocketHub.SendToTeam(*user.TeamID, payload)
-	}
+		Reason:      "user",
+	})
 }
 
 func init() {
@@ -0,0 +1,116 @@
+package logger
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pwnthemall/pwnthemall/backend/models"
+)
+
+// Logger writes leveled, structured entries, errors are always written
+type Logger struct {
+	l *slog.Logger
+}
+
+var std = &Logger{l: slog.New(newHandler(os.Stderr))}
+
+// newHandler builds the handler from PTA_LOG_FORMAT (text or json) and PTA_LOG_LEVEL
+func newHandler(w io.Writer) slog.Handler {
+	opts := &slog.HandlerOptions{Level: level()}
+	if strings.ToLower(os.Getenv("PTA_LOG_FORMAT")) == "json" {
+		return slog.NewJSONHandler(w, opts)
+	}
+	return slog.NewTextHandler(w, opts)
+}
+
+// level reads PTA_LOG_LEVEL, PTA_DEBUG_ENABLED still enables debug entries when no level is set
+func level() slog.Level {
+	switch strings.ToLower(os.Getenv("PTA_LOG_LEVEL")) {
+	case "debug":
+		return slog.LevelDebug
+	case "warn", "warning":
+		return slog.LevelWarn
+	case "error":
+		return slog.LevelError
+	case "info":
+		return slog.LevelInfo
+	}
+	if enabled, _ := strconv.ParseBool(os.Getenv("PTA_DEBUG_ENABLED")); enabled {
+		return slog.LevelDebug
+	}
+	return slog.LevelInfo
+}
+
+// Ctx returns a logger carrying the request ID, user and team of a request
+func Ctx(c *gin.Context) *Logger {
+	if c == nil {
+		return std
+	}
+	var args []any
+	if requestID := c.GetString("request_id"); requestID != "" {
+		args = append(args, "request_id", requestID)
+	}
+	if user, ok := c.Get("user"); ok {
+		if u, ok := user.(*models.User); ok {
+			args = append(args, "user_id", u.ID)
+			if u.TeamID != nil {
+				args = append(args, "team_id", *u.TeamID)
+			}
+		}
+	} else if userID, ok := c.Get("user_id"); ok {
+		args = append(args, "user_id", userID)
+	}
+	if len(args) == 0 {
+		return std
+	}
+	return std.With(args...)
+}
+
+// With returns the default logger with additional fields
+func With(args ...any) *Logger {
+	return std.With(args...)
+}
+
+// With returns a logger with additional fields
+func (l *Logger) With(args ...any) *Logger {
+	return &Logger{l: l.l.With(args...)}
+}
+
+// Enabled reports whether entries of the level are written
+func (l *Logger) Enabled(level slog.Level) bool {
+	return l.l.Enabled(context.Background(), level)
+}
+
+func (l *Logger) Debug(msg string, args ...any) { l.l.Debug(msg, args...) }
+func (l *Logger) Info(msg string, args ...any)  { l.l.Info(msg, args...) }
+func (l *Logger) Warn(msg string, args ...any)  { l.l.Warn(msg, args...) }
+func (l *Logger) Error(msg string, args ...any) { l.l.Error(msg, args...) }
+
+func (l *Logger) Debugf(format string, v ...any) { l.logf(slog.LevelDebug, format, v...) }
+func (l *Logger) Infof(format string, v ...any)  { l.logf(slog.LevelInfo, format, v...) }
+func (l *Logger) Warnf(format string, v ...any)  { l.logf(slog.LevelWarn, format, v...) }
+func (l *Logger) Errorf(format string, v ...any) { l.logf(slog.LevelError, format, v...) }
+
+// logf formats the message only when the level is enabled
+func (l *Logger) logf(level slog.Level, format string, v ...any) {
+	if !l.Enabled(level) {
+		return
+	}
+	l.l.Log(context.Background(), level, fmt.Sprintf(format, v...))
+}
+
+func Debug(msg string, args ...any) { std.Debug(msg, args...) }
+func Info(msg string, args ...any)  { std.Info(msg, args...) }
+func Warn(msg string, args ...any)  { std.Warn(msg, args...) }
+func Error(msg string, args ...any) { std.Error(msg, args...) }
+
+func Debugf(format string, v ...any) { std.logf(slog.LevelDebug, format, v...) }
+func Infof(format string, v ...any)  { std.logf(slog.LevelInfo, format, v...) }
+func Warnf(format string, v ...any)  { std.logf(slog.LevelWarn, format, v...) }
+func Errorf(format string, v ...any) { std.logf(slog.LevelError, format, v...) }
@@ -11,8 +11,9 @@ import (
 	"github.com/gin-contrib/sessions/cookie"
 	"github.com/gin-gonic/gin"
 	"github.com/pwnthemall/pwnthemall/backend/config"
-	"github.com/pwnthemall/pwnthemall/backend/debug"
 	_ "github.com/pwnthemall/pwnthemall/backend/handlers" // Import to trigger init() functions
+	"github.com/pwnthemall/pwnthemall/backend/logger"
+	"github.com/pwnthemall/pwnthemall/backend/metrics"
 	"github.com/pwnthemall/pwnthemall/backend/middleware"
 	"github.com/pwnthemall/pwnthemall/backend/pluginsystem"
 	"github.com/pwnthemall/pwnthemall/backend/routes"
@@ -25,15 +26,43 @@ var (
 	cleanDemo     = flag.Bool("clean-demo", false, "Remove all demo data from the database")
 	seedTeams     = flag.Int("teams", 30, "Number of demo teams to create (default: 30)")
 	seedTimeRange = flag.Int("time-range", 20, "Time range in hours for spreading solve timestamps (default: 20)")
+	exportBackup  = flag.String("export-backup", "", "Write a full event archive to the given path")
+	restoreBackup = flag.String("restore-backup", "", "Restore a full event archive from the given path")
+	importCTFd    = flag.String("import-ctfd", "", "Import a CTFd export ZIP from the given path")
+	keepAdmins    = flag.Bool("ctfd-keep-admins", false, "Keep the admin role of CTFd admins during -import-ctfd (default: imported as members)")
 )
 
 // initWebSocketHub initializes the WebSocket hubs
 func initWebSocketHub() {
-	utils.WebSocketHub = utils.NewHub()
+	utils.WebSocketHub = utils.NewHub("notifications")
 	go utils.WebSocketHub.Run()
 
-	utils.UpdatesHub = utils.NewHub()
+	utils.UpdatesHub = utils.NewHub("updates")
 	go utils.UpdatesHub.Run()
+
+	metrics.RegisterHub("notifications", func() int { return len(utils.WebSocketHub.GetConnectedUsers()) })
+	metrics.RegisterHub("updates", func() int { return len(utils.UpdatesHub.GetConnectedUsers()) })
+
+	utils.SubscribeWebSocketHubs()
+	utils.SubscribeWebhooks()
+	pluginsystem.SubscribePlugins()
+}
+
+// startMetricsServer serves /metrics on PTA_METRICS_ADDR, an address meant to stay on the internal network
+func startMetricsServer() {
+	addr := os.Getenv("PTA_METRICS_ADDR")
+	if addr == "" {
+		return
+	}
+	metricsRouter := gin.New()
+	metricsRouter.Use(middleware.Recovery())
+	metricsRouter.GET("/metrics", middleware.MetricsAuth(), metrics.Handler())
+	go func() {
+		logger.Infof("Serving metrics on %s", addr)
+		if err := metricsRouter.Run(addr); err != nil {
+			logger.Errorf("Metrics server stopped: %v", err)
+		}
+	}()
 }
 
 func main() {
@@ -44,65 +73,143 @@ func main() {
 		config.ConnectDB()
 
 		if *cleanDemo {
-			debug.Println("Running: clean-demo")
+			logger.Info("Running: clean-demo")
 			if err := config.CleanDemoData(); err != nil {
-				debug.Log("Failed to clean demo data: %v", err)
+				logger.Errorf("Failed to clean demo data: %v", err)
 				os.Exit(1)
 			}
-			debug.Println("Demo data cleaned successfully")
+			logger.Info("Demo data cleaned successfully")
 			os.Exit(0)
 		}
 
 		if *seedDemo {
-			debug.Log("Running: seed-demo (teams=%d, time-range=%dh)", *seedTeams, *seedTimeRange)
+			logger.Infof("Running: seed-demo (teams=%d, time-range=%dh)", *seedTeams, *seedTimeRange)
 			if err := config.SeedDemoData(*seedTeams, *seedTimeRange); err != nil {
-				debug.Log("Failed to seed demo data: %v", err)
+				logger.Errorf("Failed to seed demo data: %v", err)
 				os.Exit(1)
 			}
-			debug.Println("Demo data seeded successfully")
+			logger.Info("Demo data seeded successfully")
 			os.Exit(0)
 		}
 	}
 
+	// Handle CLI backup commands (requires DB, MinIO and Casbin for the custom role policies)
+	if *exportBackup != "" || *restoreBackup != "" {
+		config.ConnectDB()
+		config.ConnectMinio()
+		config.InitCasbin()
+
+		if *exportBackup != "" {
+			logger.Infof("Running: export-backup (path=%s)", *exportBackup)
+			file, err := os.Create(*exportBackup)
+			if err != nil {
+				logger.Errorf("Failed to create backup file: %v", err)
+				os.Exit(1)
+			}
+			manifest, err := utils.ExportEventArchive(file)
+			file.Close()
+			if err != nil {
+				logger.Errorf("Failed to export backup: %v", err)
+				os.Exit(1)
+			}
+			logger.Infof("Backup exported successfully: %v", manifest.Counts)
+			os.Exit(0)
+		}
+
+		logger.Infof("Running: restore-backup (path=%s)", *restoreBackup)
+		data, err := os.ReadFile(*restoreBackup)
+		if err != nil {
+			logger.Errorf("Failed to read backup file: %v", err)
+			os.Exit(1)
+		}
+		report, err := utils.RestoreEventArchive(data)
+		if err != nil {
+			logger.Errorf("Failed to restore backup: %v", err)
+			os.Exit(1)
+		}
+		for _, warning := range report.Warnings {
+			logger.Warnf("%s", warning)
+		}
+		logger.Infof("Backup restored successfully: %v", report.Counts)
+		os.Exit(0)
+	}
+
+	// Handle CLI CTFd import (requires DB and MinIO connections)
+	if *importCTFd != "" {
+		config.ConnectDB()
+		config.ConnectMinio()
+
+		logger.Infof("Running: import-ctfd (path=%s)", *importCTFd)
+		data, err := os.ReadFile(*importCTFd)
+		if err != nil {
+			logger.Errorf("Failed to read CTFd export: %v", err)
+			os.Exit(1)
+		}
+		report, err := utils.ImportCTFdExport(context.Background(), data, *keepAdmins, nil)
+		if report != nil {
+			for _, result := range report.Challenges {
+				logger.Infof("Challenge %s (%s): %s %v", result.Name, result.Slug, result.Status, result.Errors)
+			}
+			for _, warning := range report.Warnings {
+				logger.Warnf("%s", warning)
+			}
+		}
+		if err != nil {
+			logger.Errorf("Failed to import CTFd export: %v", err)
+			os.Exit(1)
+		}
+		logger.Infof("CTFd export imported successfully: %v", report.Counts)
+		os.Exit(0)
+	}
+
 	config.ConnectDB()
 	config.ConnectMinio()
 	config.InitCasbin()
 
 	if os.Getenv("PTA_DEMO") == "true" {
-		debug.Log("WARNING: Application started in DEMO MODE.")
+		logger.Warn("Application started in DEMO MODE.")
 	}
 
 	if err := config.ConnectDocker(); err != nil {
-		debug.Log("Failed to connect to docker host: %s", err.Error())
+		logger.Errorf("Failed to connect to docker host: %s", err.Error())
 	}
 
 	initWebSocketHub()
 
 	// Sync all challenges from MinIO on startup
-	debug.Println("INFO: Launching initial challenge sync goroutine...")
+	logger.Info("Launching initial challenge sync goroutine...")
 	go func() {
 		ctx := context.Background()
 		if err := utils.SyncAllChallengesFromMinIO(ctx, utils.UpdatesHub); err != nil {
-			debug.Log("Warning: Initial challenge sync failed: %v", err)
+			logger.Warnf("Initial challenge sync failed: %v", err)
 		} else {
-			debug.Println("INFO: Initial challenge sync goroutine completed successfully")
+			logger.Info("Initial challenge sync goroutine completed successfully")
 		}
 	}()
 
 	// Sync all pages from MinIO on startup
-	debug.Println("INFO: Launching initial page sync goroutine...")
+	logger.Info("Launching initial page sync goroutine...")
 	go func() {
 		ctx := context.Background()
 		if err := utils.SyncAllPagesFromMinIO(ctx); err != nil {
-			debug.Log("Warning: Initial page sync failed: %v", err)
+			logger.Warnf("Initial page sync failed: %v", err)
 		} else {
-			debug.Println("INFO: Initial page sync goroutine completed successfully")
+			logger.Info("Initial page sync goroutine completed successfully")
 		}
 	}()
 
 	// Start hint activation scheduler
 	utils.StartHintScheduler()
 
+	// Purge revocations and sessions of expired tokens
+	utils.StartTokenCleanup()
+
+	// Send queued outbound webhook deliveries
+	utils.StartWebhookWorker()
+
+	// Deliver scheduled notifications
+	utils.StartNotificationScheduler()
+
 	var gReleaseMode string
 	if os.Getenv("PTA_DEBUG_ENABLED") == "true" {
 		gReleaseMode = gin.DebugMode
@@ -111,7 +218,8 @@ func main() {
 	}
 	gin.SetMode(gReleaseMode)
 
-	router := gin.Default()
+	router := gin.New()
+	router.Use(middleware.RequestID(), middleware.RequestLogger(), middleware.Recovery(), middleware.Metrics())
 
 	sessionSecret := os.Getenv("SESSION_SECRET")
 	if sessionSecret == "" {
@@ -132,14 +240,14 @@ func main() {
 	router.SetTrustedProxies([]string{"172.70.1.0/24"})
 	allowedOrigin := os.Getenv("NEXT_PUBLIC_API_URL")
 	if allowedOrigin == "" {
-		debug.Log("Warning: NEXT_PUBLIC_API_URL is not set, CORS will allow all origins")
+		logger.Warn("NEXT_PUBLIC_API_URL is not set, CORS will allow all origins")
 		allowedOrigin = "*"
 	}
 	router.Use(cors.New(cors.Config{
 		AllowOrigins:     []string{allowedOrigin},
 		AllowMethods:     []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
 		AllowHeaders:     []string{"Origin", "Content-Type", "X-CSRF-Token"},
-		ExposeHeaders:    []string{"Content-Length"},
+		ExposeHeaders:    []string{"Content-Length", "X-Request-ID"},
 		AllowCredentials: true,
 	}))
 
@@ -163,20 +271,28 @@ func main() {
 	routes.RegisterDashboardRoutes(router)
 	routes.RegisterTicketRoutes(router)
 	routes.RegisterPageRoutes(router)
+	routes.RegisterBackupRoutes(router)
+	routes.RegisterRoleRoutes(router)
+	routes.RegisterBracketRoutes(router)
+	routes.RegisterAuditRoutes(router)
+	routes.RegisterOutboundWebhookRoutes(router)
+	routes.RegisterMetricsRoutes(router)
 
 	if os.Getenv("PTA_PLUGINS_ENABLED") == "true" {
-		debug.Log("Loading plugins...")
+		logger.Info("Loading plugins...")
 		pluginsystem.LoadAllPlugins("/app/plugins/bin", router, config.CEF)
 		routes.RegisterPluginRoutes(router)
 		defer pluginsystem.ShutdownAllPlugins()
 	}
 
+	startMetricsServer()
+
 	port := os.Getenv("PORT")
 	if port == "" {
 		port = "8080"
 	}
 
-	debug.Log("Starting server on port %s", port)
-	debug.Log("Server starting on port %s", port)
+	logger.Infof("Starting server on port %s", port)
+	logger.Infof("Server starting on port %s", port)
 	router.Run(":" + port)
 }
@@ -20,6 +20,7 @@ type BaseChallengeMetadata struct {
 	DependsOn        string              `yaml:"depends_on,omitempty"` // Name of challenge that must be solved first
 	CoverImg         string              `yaml:"cover_img,omitempty"`  // Cover image filename relative to challenge folder
 	Emoji            string              `yaml:"emoji,omitempty"`      // Emoji to display when no cover image
+	Tags             []string            `yaml:"tags,omitempty"`
 }
 
 type HintMetadata struct {
@@ -0,0 +1,207 @@
+package metrics
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/pwnthemall/pwnthemall/backend/config"
+	"github.com/pwnthemall/pwnthemall/backend/logger"
+)
+
+const namespace = "pwnthemall"
+
+// Registry holds every collector exposed on /metrics
+var Registry = prometheus.NewRegistry()
+
+var (
+	HTTPRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "Latency of HTTP requests by route and status.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+
+	Submissions = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "submissions_total",
+		Help:      "Flag submissions by challenge and result.",
+	}, []string{"challenge", "result"})
+
+	DockerOperationDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "docker_operation_duration_seconds",
+		Help:      "Duration of Docker image builds and instance starts.",
+		Buckets:   []float64{0.5, 1, 2.5, 5, 10, 30, 60, 120, 300, 600},
+	}, []string{"operation", "type"})
+
+	DockerOperationFailures = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "docker_operation_failures_total",
+		Help:      "Failed Docker image builds and instance starts.",
+	}, []string{"operation", "type"})
+
+	MinioSyncDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "minio_sync_duration_seconds",
+		Help:      "Duration of the sync of a MinIO object to the database.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"kind"})
+
+	MinioSyncErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "minio_sync_errors_total",
+		Help:      "Failed syncs of MinIO objects.",
+	}, []string{"kind"})
+
+	PluginRPCDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "plugin_rpc_duration_seconds",
+		Help:      "Latency of RPC calls to plugins.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"plugin", "handler"})
+
+	PluginRPCErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "plugin_rpc_errors_total",
+		Help:      "Failed RPC calls to plugins.",
+	}, []string{"plugin", "handler"})
+
+	WebhookDeliveries = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "webhook_deliveries_total",
+		Help:      "Outbound webhook delivery attempts by format and result.",
+	}, []string{"format", "result"})
+
+	connectedClients = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "websocket_connected_clients",
+		Help:      "Users connected to each WebSocket hub.",
+	}, []string{"hub"})
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		HTTPRequestDuration,
+		Submissions,
+		DockerOperationDuration,
+		DockerOperationFailures,
+		MinioSyncDuration,
+		MinioSyncErrors,
+		PluginRPCDuration,
+		PluginRPCErrors,
+		WebhookDeliveries,
+		&hubCollector{},
+		&instanceCollector{desc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "", "running_instances"),
+			"Running instances by challenge and challenge type.",
+			[]string{"challenge", "type"}, nil,
+		)},
+	)
+}
+
+// Handler serves the registry in the Prometheus text format
+func Handler() gin.HandlerFunc {
+	return gin.WrapH(promhttp.HandlerFor(Registry, promhttp.HandlerOpts{}))
+}
+
+// ObserveSubmission counts a flag submission
+func ObserveSubmission(challenge string, correct bool) {
+	result := "incorrect"
+	if correct {
+		result = "correct"
+	}
+	Submissions.WithLabelValues(challenge, result).Inc()
+}
+
+// ObserveDocker records the duration of a Docker operation started at start and counts its failure
+func ObserveDocker(operation, challengeType string, start time.Time, err error) {
+	DockerOperationDuration.WithLabelValues(operation, challengeType).Observe(time.Since(start).Seconds())
+	if err != nil {
+		DockerOperationFailures.WithLabelValues(operation, challengeType).Inc()
+	}
+}
+
+// ObserveMinioSync records the duration of a MinIO sync started at start and counts its failure
+func ObserveMinioSync(kind string, start time.Time, err error) {
+	MinioSyncDuration.WithLabelValues(kind).Observe(time.Since(start).Seconds())
+	if err != nil {
+		MinioSyncErrors.WithLabelValues(kind).Inc()
+	}
+}
+
+// ObservePluginRPC records the latency of a plugin RPC call started at start and counts its failure
+func ObservePluginRPC(plugin, handler string, start time.Time, err error) {
+	PluginRPCDuration.WithLabelValues(plugin, handler).Observe(time.Since(start).Seconds())
+	if err != nil {
+		PluginRPCErrors.WithLabelValues(plugin, handler).Inc()
+	}
+}
+
+// ObserveWebhookDelivery counts an outbound webhook delivery attempt
+func ObserveWebhookDelivery(format string, err error) {
+	result := "success"
+	if err != nil {
+		result = "failure"
+	}
+	WebhookDeliveries.WithLabelValues(format, result).Inc()
+}
+
+var hubCounters = map[string]func() int{}
+
+// RegisterHub exposes the number of users connected to a WebSocket hub, call it before serving metrics
+func RegisterHub(name string, count func() int) {
+	hubCounters[name] = count
+}
+
+// hubCollector reads the hub sizes at scrape time
+type hubCollector struct{}
+
+func (h *hubCollector) Describe(ch chan<- *prometheus.Desc) {
+	connectedClients.Describe(ch)
+}
+
+func (h *hubCollector) Collect(ch chan<- prometheus.Metric) {
+	for name, count := range hubCounters {
+		connectedClients.WithLabelValues(name).Set(float64(count()))
+	}
+	connectedClients.Collect(ch)
+}
+
+// instanceCollector counts running instances at scrape time so the value survives restarts
+type instanceCollector struct {
+	desc *prometheus.Desc
+}
+
+func (i *instanceCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- i.desc
+}
+
+func (i *instanceCollector) Collect(ch chan<- prometheus.Metric) {
+	if config.DB == nil {
+		return
+	}
+	var rows []struct {
+		Slug  string
+		Type  string
+		Count int64
+	}
+	err := config.DB.Table("instances").
+		Select("challenges.slug AS slug, COALESCE(challenge_types.name, '') AS type, COUNT(*) AS count").
+		Joins("JOIN challenges ON challenges.id = instances.challenge_id").
+		Joins("LEFT JOIN challenge_types ON challenge_types.id = challenges.challenge_type_id").
+		Where("instances.status = ?", "running").
+		Group("challenges.slug, challenge_types.name").
+		Scan(&rows).Error
+	if err != nil {
+		logger.Errorf("Failed to count running instances for metrics: %v", err)
+		return
+	}
+	for _, row := range rows {
+		ch <- prometheus.MustNewConstMetric(i.desc, prometheus.GaugeValue, float64(row.Count), row.Slug, row.Type)
+	}
+}
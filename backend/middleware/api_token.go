@@ -0,0 +1,103 @@
+package middleware
+
+import (
+	"net/http"
+	"slices"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pwnthemall/pwnthemall/backend/models"
+	"github.com/pwnthemall/pwnthemall/backend/utils"
+)
+
+// apiTokenWriteRoutes lists the non-GET routes each API token scope unlocks
+// Any other write is refused for token auth, GET routes stay subject to CheckPolicy
+var apiTokenWriteRoutes = map[string][]string{
+	utils.ScopeSubmit: {
+		"POST /challenges/:id/submit",
+	},
+	utils.ScopeInstances: {
+		"POST /challenges/:id/start",
+		"POST /challenges/:id/stop",
+	},
+	utils.ScopeChallengesSync: {
+		"POST /admin/challenges/import",
+	},
+}
+
+// apiTokenDeniedRoutes can't be reached with a token whatever its scopes
+// The backup holds password hashes and team secrets, it is only downloaded from a browser session
+var apiTokenDeniedRoutes = map[string]bool{
+	"GET /admin/backup": true,
+}
+
+// apiTokenFromRequest validates the bearer token once per request and keeps it in the context
+func apiTokenFromRequest(c *gin.Context, raw string) (*models.APIToken, error) {
+	if cached, exists := c.Get("api_token"); exists {
+		return cached.(*models.APIToken), nil
+	}
+	token, err := utils.LookupAPIToken(raw)
+	if err != nil {
+		return nil, err
+	}
+	c.Set("api_token", token)
+	return token, nil
+}
+
+// apiTokenAllowsRoute reports whether the token scopes cover the current route
+func apiTokenAllowsRoute(token *models.APIToken, c *gin.Context) bool {
+	method := c.Request.Method
+	route := method + " " + c.FullPath()
+	if apiTokenDeniedRoutes[route] {
+		return false
+	}
+	if method == http.MethodGet || method == http.MethodHead {
+		return true
+	}
+	for scope, routes := range apiTokenWriteRoutes {
+		if !token.HasScope(scope) {
+			continue
+		}
+		for _, r := range routes {
+			if r == route {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// apiTokenRole is the Casbin subject of a token, any role above member needs admin:read to keep its permissions
+// challenges:sync keeps them only on the import route it unlocks
+func apiTokenRole(token *models.APIToken, c *gin.Context) string {
+	if token.User.Role == "member" || token.HasScope(utils.ScopeAdminRead) {
+		return token.User.Role
+	}
+	if token.HasScope(utils.ScopeChallengesSync) && slices.Contains(apiTokenWriteRoutes[utils.ScopeChallengesSync], c.Request.Method+" "+c.FullPath()) {
+		return token.User.Role
+	}
+	return "member"
+}
+
+// requestUserID resolves the caller from an API token or the access_token cookie
+// It aborts the request and returns false when neither is valid
+func requestUserID(c *gin.Context) (uint, bool) {
+	if raw := utils.BearerToken(c); raw != "" {
+		token, err := apiTokenFromRequest(c, raw)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "invalid_api_token"})
+			return 0, false
+		}
+		if !apiTokenAllowsRoute(token, c) {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "insufficient_scope"})
+			return 0, false
+		}
+		return token.UserID, true
+	}
+
+	claims, err := utils.GetClaimsFromCookie(c)
+	if claims == nil {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": err})
+		return 0, false
+	}
+	return claims.UserID, true
+}
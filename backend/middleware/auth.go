@@ -1,14 +1,13 @@
 package middleware
 
 import (
-	"fmt"
 	"net"
 	"net/http"
 	"os"
 	"strings"
 
 	"github.com/pwnthemall/pwnthemall/backend/config"
-	"github.com/pwnthemall/pwnthemall/backend/debug"
+	"github.com/pwnthemall/pwnthemall/backend/logger"
 	"github.com/pwnthemall/pwnthemall/backend/models"
 	"github.com/pwnthemall/pwnthemall/backend/utils"
 
@@ -80,6 +79,36 @@ func updateUserIP(userID uint, currentIP string) {
 	})
 }
 
+// twoFactorSetupRoutes stay reachable for admins who still have to enrol 2FA
+var twoFactorSetupRoutes = map[string]bool{
+	"/me":             true,
+	"/me/2fa/setup":   true,
+	"/me/2fa/confirm": true,
+	"/logout":         true,
+}
+
+// emailVerificationRoutes stay reachable for users who still have to verify their email
+var emailVerificationRoutes = map[string]bool{
+	"/me":                       true,
+	"/auth/verify-email/resend": true,
+	"/logout":                   true,
+}
+
+// accountSetupError returns the error key of a pending account requirement, or an empty string
+// The email comes first for every role, 2FA setup is only asked once it is verified
+func accountSetupError(c *gin.Context, user *models.User) string {
+	if !user.EmailVerified && config.GetConfigBool("REQUIRE_EMAIL_VERIFICATION", false) {
+		if emailVerificationRoutes[c.FullPath()] {
+			return ""
+		}
+		return "email_verification_required"
+	}
+	if config.HasAdminAccess(user.Role) && !user.TOTPEnabled && !twoFactorSetupRoutes[c.FullPath()] && config.GetConfigBool("REQUIRE_ADMIN_2FA", false) {
+		return "2fa_setup_required"
+	}
+	return ""
+}
+
 // AuthRequired ensures a user is logged in
 func SessionAuthRequired(needTeam bool) gin.HandlerFunc {
 	return func(c *gin.Context) {
@@ -124,36 +153,35 @@ func SessionAuthRequired(needTeam bool) gin.HandlerFunc {
 
 func CheckPolicy(obj string, act string) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		claims, _ := utils.GetClaimsFromCookie(c)
-
-		var sub string
-		if claims == nil {
-			sub = "anonymous"
-		} else {
-			sub = fmt.Sprint(claims.Role)
-			if sub == "" {
-				sub = "anonymous"
+		sub := "anonymous"
+		if raw := utils.BearerToken(c); raw != "" {
+			token, err := apiTokenFromRequest(c, raw)
+			if err != nil {
+				c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "invalid_api_token"})
+				return
 			}
+			if !apiTokenAllowsRoute(token, c) {
+				c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "insufficient_scope"})
+				return
+			}
+			sub = apiTokenRole(token, c)
+		} else if user, ok := utils.GetAuthenticatedUser(c); ok && user.Role != "" {
+			// The role loaded by AuthRequired is fresher than the one in the access token
+			sub = user.Role
+		} else if claims, _ := utils.GetClaimsFromCookie(c); claims != nil && claims.Role != "" {
+			sub = claims.Role
 		}
 
 		// Vérification Casbin
-		err := config.CEF.LoadPolicy()
-
-		if err != nil {
-			debug.Log("Casbin error: %v", err)
-			c.AbortWithStatusJSON(500, gin.H{"error": "Internal server error"})
-			return
-
-		}
-		ok, err := config.CEF.Enforce(sub, obj, act)
+		ok, err := config.Enforce(sub, obj, act)
 		if err != nil {
-			debug.Log("Casbin error: %v", err)
+			logger.Ctx(c).Errorf("Casbin error: %v", err)
 			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "authorization error"})
 			return
 		}
 
 		if !ok {
-			// debug.Log("Unauthorized action: sub:%s act:%s obj:%s", sub, act, obj)
+			// logger.Ctx(c).Debugf("Unauthorized action: sub:%s act:%s obj:%s", sub, act, obj)
 			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "unauthorized: wrong permissions"})
 			return
 		}
@@ -164,13 +192,12 @@ func CheckPolicy(obj string, act string) gin.HandlerFunc {
 
 func AuthRequired(needTeam bool) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		claims, err := utils.GetClaimsFromCookie(c)
-		if claims == nil {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": err})
+		userID, ok := requestUserID(c)
+		if !ok {
 			return
 		}
 		var user models.User
-		if err := config.DB.Preload("Team").First(&user, claims.UserID).Error; err != nil {
+		if err := config.DB.Preload("Team").First(&user, userID).Error; err != nil {
 			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "user not found"})
 			return
 		}
@@ -180,6 +207,11 @@ func AuthRequired(needTeam bool) gin.HandlerFunc {
 			return
 		}
 
+		if errKey := accountSetupError(c, &user); errKey != "" {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": errKey})
+			return
+		}
+
 		if needTeam && (user.TeamID == nil || user.Team == nil) {
 			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "team_required"})
 			return
@@ -196,13 +228,12 @@ func AuthRequired(needTeam bool) gin.HandlerFunc {
 
 func AuthRequiredTeamOrAdmin() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		claims, err := utils.GetClaimsFromCookie(c)
-		if claims == nil {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": err})
+		userID, ok := requestUserID(c)
+		if !ok {
 			return
 		}
 		var user models.User
-		if err := config.DB.Preload("Team").First(&user, claims.UserID).Error; err != nil {
+		if err := config.DB.Preload("Team").First(&user, userID).Error; err != nil {
 			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "user not found"})
 			return
 		}
@@ -212,6 +243,11 @@ func AuthRequiredTeamOrAdmin() gin.HandlerFunc {
 			return
 		}
 
+		if errKey := accountSetupError(c, &user); errKey != "" {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": errKey})
+			return
+		}
+
 		if user.Role != "admin" && (user.TeamID == nil || user.Team == nil) {
 			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "team_required"})
 			return
@@ -235,12 +271,12 @@ func InitCSRFProtection() {
 	if csrfSecret == "" {
 		csrfSecret, _ = utils.GenerateRandomString(32)
 		if os.Getenv("GIN_MODE") == "release" {
-			debug.Log("WARNING: CSRF_SECRET not set in production. Using random secret - tokens will invalidate on restart!")
+			logger.Warn("CSRF_SECRET not set in production. Using random secret - tokens will invalidate on restart!")
 		} else {
-			debug.Log("INFO: CSRF_SECRET not set, using random secret for development")
+			logger.Warn("CSRF_SECRET not set, using random secret for development")
 		}
 	}
-	debug.Log("CSRF protection initialized")
+	logger.Debug("CSRF protection initialized")
 }
 
 func CSRFProtection() gin.HandlerFunc {
@@ -252,7 +288,7 @@ func CSRFProtection() gin.HandlerFunc {
 		csrf.Path("/"),
 		csrf.ErrorHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			reason := csrf.FailureReason(r)
-			debug.Log("CSRF validation failed for %s %s: %v", r.Method, r.URL.Path, reason)
+			logger.Warnf("CSRF validation failed for %s %s: %v", r.Method, r.URL.Path, reason)
 
 			// Return structured error code based on failure reason
 			var errorCode string
@@ -272,6 +308,14 @@ func CSRFProtection() gin.HandlerFunc {
 	)
 
 	return func(c *gin.Context) {
+		// Browsers never attach the Authorization header on their own, so token auth needs no CSRF token
+		if raw := utils.BearerToken(c); raw != "" {
+			if _, err := apiTokenFromRequest(c, raw); err == nil {
+				c.Next()
+				return
+			}
+		}
+
 		responseWritten := false
 		wrappedWriter := &csrfResponseWriter{
 			ResponseWriter: c.Writer,
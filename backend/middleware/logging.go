@@ -0,0 +1,70 @@
+package middleware
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"regexp"
+	"runtime/debug"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pwnthemall/pwnthemall/backend/logger"
+)
+
+const requestIDHeader = "X-Request-ID"
+
+// validRequestID limits the request IDs accepted from proxies to safe log values
+var validRequestID = regexp.MustCompile(`^[A-Za-z0-9._-]{1,64}$`)
+
+// RequestID reuses the X-Request-ID of the proxy or generates one, and returns it to the client
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requestID := c.GetHeader(requestIDHeader)
+		if !validRequestID.MatchString(requestID) {
+			buf := make([]byte, 8)
+			rand.Read(buf)
+			requestID = hex.EncodeToString(buf)
+		}
+		c.Set("request_id", requestID)
+		c.Header(requestIDHeader, requestID)
+		c.Next()
+	}
+}
+
+// RequestLogger writes one entry per request, errors are logged at the error level
+func RequestLogger() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		status := c.Writer.Status()
+		log := logger.Ctx(c).With(
+			"method", c.Request.Method,
+			"path", c.Request.URL.Path,
+			"status", status,
+			"latency_ms", time.Since(start).Milliseconds(),
+			"ip", c.ClientIP(),
+		)
+		if len(c.Errors) > 0 {
+			log = log.With("errors", c.Errors.String())
+		}
+		switch {
+		case status >= http.StatusInternalServerError:
+			log.Error("request")
+		case status >= http.StatusBadRequest:
+			log.Warn("request")
+		default:
+			log.Info("request")
+		}
+	}
+}
+
+// Recovery logs panics with their stack trace and answers 500
+func Recovery() gin.HandlerFunc {
+	return gin.CustomRecoveryWithWriter(io.Discard, func(c *gin.Context, err any) {
+		logger.Ctx(c).Error("panic recovered", "error", err, "stack", string(debug.Stack()))
+		c.AbortWithStatus(http.StatusInternalServerError)
+	})
+}
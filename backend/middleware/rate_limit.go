@@ -1,155 +1,130 @@
 package middleware
 
 import (
+	"fmt"
+	"math"
 	"net/http"
+	"strconv"
 	"sync"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/pwnthemall/pwnthemall/backend/logger"
+	"github.com/pwnthemall/pwnthemall/backend/utils"
 )
 
-type rateLimiter struct {
-	attempts map[string][]time.Time
-	mu       sync.RWMutex
-	maxTries int
-	window   time.Duration
+// rateLimitPolicies are the named policies of route groups, PTA_RATE_LIMITS overrides them
+var rateLimitPolicies = map[string]utils.RateLimitPolicy{
+	"login":     {Burst: 5, Period: time.Minute, Key: utils.RateLimitByIP, ErrorKey: "too_many_login_attempts"},
+	"join_team": {Burst: 5, Period: time.Minute, Key: utils.RateLimitByUser, ErrorKey: "too_many_attempts"},
+	"submit":    {Burst: 20, Period: time.Minute, Key: utils.RateLimitByTeam},
 }
 
-var joinTeamLimiter = &rateLimiter{
-	attempts: make(map[string][]time.Time),
-	maxTries: 5,
-	window:   1 * time.Minute,
-}
+var (
+	rateLimitOverrides     map[string]utils.RateLimitPolicy
+	rateLimitOverridesOnce sync.Once
+)
 
-var loginLimiter = &rateLimiter{
-	attempts: make(map[string][]time.Time),
-	maxTries: 5,
-	window:   1 * time.Minute,
+// resolveRateLimitPolicy applies the PTA_RATE_LIMITS override of a policy, the error key is kept
+func resolveRateLimitPolicy(policy utils.RateLimitPolicy) utils.RateLimitPolicy {
+	rateLimitOverridesOnce.Do(func() {
+		rateLimitOverrides = utils.RateLimitOverrides()
+	})
+	if override, ok := rateLimitOverrides[policy.Name]; ok {
+		override.ErrorKey = policy.ErrorKey
+		return override
+	}
+	return policy
 }
 
 func RateLimitLogin() gin.HandlerFunc {
-	return func(c *gin.Context) {
-		// Use IP address as key for login attempts
-		key := c.ClientIP()
-		now := time.Now()
-
-		loginLimiter.mu.Lock()
-		defer loginLimiter.mu.Unlock()
-
-		attempts := loginLimiter.attempts[key]
-		var validAttempts []time.Time
-		for _, attemptTime := range attempts {
-			if now.Sub(attemptTime) < loginLimiter.window {
-				validAttempts = append(validAttempts, attemptTime)
-			}
-		}
-
-		if len(validAttempts) >= loginLimiter.maxTries {
-			c.JSON(http.StatusTooManyRequests, gin.H{"error": "too_many_login_attempts"})
-			c.Abort()
-			return
-		}
+	return RateLimitPolicy("login")
+}
 
-		validAttempts = append(validAttempts, now)
-		loginLimiter.attempts[key] = validAttempts
+func RateLimitJoinTeam() gin.HandlerFunc {
+	return RateLimitPolicy("join_team")
+}
 
-		c.Next()
+// RateLimitPolicy applies one of the named policies
+func RateLimitPolicy(name string) gin.HandlerFunc {
+	policy, ok := rateLimitPolicies[name]
+	if !ok {
+		panic(fmt.Sprintf("unknown rate limit policy %s", name))
+	}
+	policy.Name = name
+	policy = resolveRateLimitPolicy(policy)
+	return func(c *gin.Context) {
+		applyRateLimit(c, policy)
 	}
 }
 
-func RateLimitJoinTeam() gin.HandlerFunc {
+// RateLimit allows maxRequests per minute and per user (or IP when anonymous) on a route
+// The policy is named after the route, e.g. "POST /admin/challenges/import", so it can be overridden
+func RateLimit(maxRequests int) gin.HandlerFunc {
+	var (
+		policy utils.RateLimitPolicy
+		once   sync.Once
+	)
 	return func(c *gin.Context) {
-		userID, exists := c.Get("user_id")
-		if !exists {
-			c.Next()
-			return
-		}
-
-		key := string(rune(userID.(uint)))
-		now := time.Now()
-
-		joinTeamLimiter.mu.Lock()
-		defer joinTeamLimiter.mu.Unlock()
-
-		attempts := joinTeamLimiter.attempts[key]
-		var validAttempts []time.Time
-		for _, attemptTime := range attempts {
-			if now.Sub(attemptTime) < joinTeamLimiter.window {
-				validAttempts = append(validAttempts, attemptTime)
-			}
-		}
-
-		if len(validAttempts) >= joinTeamLimiter.maxTries {
-			c.JSON(http.StatusTooManyRequests, gin.H{"error": "too_many_attempts"})
-			c.Abort()
-			return
-		}
-
-		validAttempts = append(validAttempts, now)
-		joinTeamLimiter.attempts[key] = validAttempts
-
-		c.Next()
+		once.Do(func() {
+			policy = resolveRateLimitPolicy(utils.RateLimitPolicy{
+				Name:   c.Request.Method + " " + c.FullPath(),
+				Burst:  maxRequests,
+				Period: time.Minute,
+				Key:    utils.RateLimitByUser,
+			})
+		})
+		applyRateLimit(c, policy)
 	}
 }
 
-func RateLimit(maxRequests int) gin.HandlerFunc {
-	limiter := &rateLimiter{
-		attempts: make(map[string][]time.Time),
-		maxTries: maxRequests,
-		window:   1 * time.Minute,
+func applyRateLimit(c *gin.Context, policy utils.RateLimitPolicy) {
+	bucket := policy.Name + "|" + rateLimitSubject(c, policy.Key)
+	res, err := utils.GetRateLimitStore().Take(bucket, policy)
+	if err != nil {
+		// Failing open keeps the platform usable when the store is unavailable
+		logger.Ctx(c).Errorf("Rate limit store error for %s: %v", bucket, err)
+		c.Next()
+		return
 	}
 
-	go func() {
-		ticker := time.NewTicker(5 * time.Minute)
-		defer ticker.Stop()
+	c.Header("RateLimit-Limit", strconv.Itoa(res.Limit))
+	c.Header("RateLimit-Remaining", strconv.Itoa(res.Remaining))
+	c.Header("RateLimit-Reset", strconv.Itoa(ceilSeconds(res.Reset)))
+	c.Header("RateLimit-Policy", fmt.Sprintf("%d;w=%d", policy.Burst, ceilSeconds(policy.Period)))
 
-		for range ticker.C {
-			limiter.mu.Lock()
-			now := time.Now()
-			for key, attempts := range limiter.attempts {
-				var validAttempts []time.Time
-				for _, attemptTime := range attempts {
-					if now.Sub(attemptTime) < limiter.window {
-						validAttempts = append(validAttempts, attemptTime)
-					}
-				}
-				if len(validAttempts) == 0 {
-					delete(limiter.attempts, key)
-				} else {
-					limiter.attempts[key] = validAttempts
-				}
-			}
-			limiter.mu.Unlock()
+	if !res.Allowed {
+		c.Header("Retry-After", strconv.Itoa(max(ceilSeconds(res.RetryAfter), 1)))
+		if policy.ErrorKey != "" {
+			c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{"error": policy.ErrorKey})
+			return
 		}
-	}()
-
-	return func(c *gin.Context) {
-		key := c.ClientIP()
-		now := time.Now()
+		c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{
+			"error":   "rate_limit_exceeded",
+			"message": "Too many requests. Please try again later.",
+		})
+		return
+	}
 
-		limiter.mu.Lock()
-		defer limiter.mu.Unlock()
+	c.Next()
+}
 
-		attempts := limiter.attempts[key]
-		var validAttempts []time.Time
-		for _, attemptTime := range attempts {
-			if now.Sub(attemptTime) < limiter.window {
-				validAttempts = append(validAttempts, attemptTime)
-			}
+// rateLimitSubject returns who shares the bucket, falling back from team to user to IP
+func rateLimitSubject(c *gin.Context, key utils.RateLimitKey) string {
+	if key == utils.RateLimitByTeam {
+		if user, ok := utils.GetAuthenticatedUser(c); ok && user.TeamID != nil {
+			return fmt.Sprintf("team:%d", *user.TeamID)
 		}
-
-		if len(validAttempts) >= limiter.maxTries {
-			c.JSON(http.StatusTooManyRequests, gin.H{
-				"error":   "rate_limit_exceeded",
-				"message": "Too many requests. Please try again later.",
-			})
-			c.Abort()
-			return
+		key = utils.RateLimitByUser
+	}
+	if key == utils.RateLimitByUser {
+		if userID, ok := utils.GetAuthenticatedUserID(c); ok {
+			return fmt.Sprintf("user:%d", userID)
 		}
-
-		validAttempts = append(validAttempts, now)
-		limiter.attempts[key] = validAttempts
-
-		c.Next()
 	}
+	return "ip:" + c.ClientIP()
+}
+
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
 }
@@ -0,0 +1,57 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// AuditLog is an append-only record of an admin mutation
+// ActorID has no foreign key so entries survive the deletion of their actor
+type AuditLog struct {
+	ID         uint         `gorm:"primaryKey" json:"id"`
+	ActorID    uint         `gorm:"index" json:"actorId"`
+	ActorName  string       `gorm:"size:32" json:"actorName"`
+	ActorRole  string       `gorm:"size:50" json:"actorRole"`
+	IPAddress  string       `gorm:"size:64" json:"ipAddress"`
+	Action     string       `gorm:"size:64;not null;index" json:"action"`
+	TargetType string       `gorm:"size:32;index:idx_audit_target" json:"targetType"`
+	TargetID   string       `gorm:"size:64;index:idx_audit_target" json:"targetId"`
+	Changes    AuditChanges `gorm:"type:jsonb" json:"changes"`
+	CreatedAt  time.Time    `gorm:"index" json:"createdAt"`
+}
+
+// AuditChange holds the value of a field before and after a mutation
+type AuditChange struct {
+	Before interface{} `json:"before"`
+	After  interface{} `json:"after"`
+}
+
+// AuditChanges maps changed fields to their before/after values
+type AuditChanges map[string]AuditChange
+
+// Value implements the driver.Valuer interface for AuditChanges
+func (a AuditChanges) Value() (driver.Value, error) {
+	if a == nil {
+		return "{}", nil
+	}
+	return json.Marshal(a)
+}
+
+// Scan implements the sql.Scanner interface for AuditChanges
+func (a *AuditChanges) Scan(value interface{}) error {
+	if value == nil {
+		*a = nil
+		return nil
+	}
+
+	switch v := value.(type) {
+	case []byte:
+		return json.Unmarshal(v, a)
+	case string:
+		return json.Unmarshal([]byte(v), a)
+	default:
+		return errors.New("cannot scan AuditChanges")
+	}
+}
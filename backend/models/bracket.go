@@ -0,0 +1,12 @@
+package models
+
+import "time"
+
+// Bracket groups teams competing in the same division, such as students or professionals
+type Bracket struct {
+	ID          uint      `gorm:"primaryKey" json:"id"`
+	Name        string    `gorm:"unique;not null;size:50" json:"name"`
+	Description string    `gorm:"size:255" json:"description"`
+	Teams       []Team    `json:"-"`
+	CreatedAt   time.Time `json:"createdAt"`
+}
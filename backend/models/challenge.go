@@ -31,6 +31,7 @@ type Challenge struct {
 	DecayFormulaID        uint                 `json:"decayFormulaId,omitempty"`
 	DecayFormula          *DecayFormula        `gorm:"foreignKey:DecayFormulaID" json:"decayFormula,omitempty"`
 	Hints                 []Hint               `gorm:"foreignKey:ChallengeID;constraint:OnDelete:CASCADE;" json:"hints,omitempty"`
+	Tags                  []Tag                `gorm:"many2many:challenge_tags;constraint:OnDelete:CASCADE;" json:"tags,omitempty"`
 	FirstBlood            *FirstBlood          `gorm:"foreignKey:ChallengeID;constraint:OnDelete:CASCADE;" json:"firstBlood,omitempty"`
 	EnableFirstBlood      bool                 `gorm:"default:false" json:"enableFirstBlood"`
 	FirstBloodBonuses     pq.Int64Array        `gorm:"type:integer[]" json:"firstBloodBonuses"`
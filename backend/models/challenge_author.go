@@ -0,0 +1,12 @@
+package models
+
+import "time"
+
+// ChallengeAuthor links a challenge to a user allowed to manage it with the author role
+type ChallengeAuthor struct {
+	ChallengeID uint       `gorm:"primaryKey" json:"challengeId"`
+	Challenge   *Challenge `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;" json:"-"`
+	UserID      uint       `gorm:"primaryKey;index" json:"userId"`
+	User        *User      `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;" json:"-"`
+	CreatedAt   time.Time  `json:"createdAt"`
+}
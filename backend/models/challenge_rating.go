@@ -0,0 +1,15 @@
+package models
+
+import "time"
+
+// ChallengeRating is a player's rating (1-5) and feedback for a solved challenge
+type ChallengeRating struct {
+	ID          uint       `gorm:"primaryKey" json:"id"`
+	ChallengeID uint       `gorm:"not null;uniqueIndex:idx_challenge_rating_user" json:"challengeId"`
+	Challenge   *Challenge `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;" json:"challenge,omitempty"`
+	UserID      uint       `gorm:"not null;uniqueIndex:idx_challenge_rating_user" json:"userId"`
+	User        *User      `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;" json:"user,omitempty"`
+	Rating      int        `gorm:"not null;check:rating >= 1 AND rating <= 5" json:"rating"`
+	Feedback    string     `gorm:"type:text" json:"feedback"`
+	CreatedAt   time.Time  `json:"createdAt"`
+}
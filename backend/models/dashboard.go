@@ -7,6 +7,7 @@ type DashboardStats struct {
 	Teams       TeamStats       `json:"teams"`
 	Submissions SubmissionStats `json:"submissions"`
 	Instances   InstanceStats   `json:"instances"`
+	Ratings     RatingStats     `json:"ratings"`
 }
 
 // ChallengeStats represents challenge-related statistics
@@ -43,6 +44,21 @@ type InstanceStats struct {
 	Total   int64 `json:"total"`
 }
 
+// RatingStats represents challenge rating statistics
+type RatingStats struct {
+	Total      int64                    `json:"total"`
+	Average    float64                  `json:"average"`
+	Challenges []ChallengeRatingSummary `json:"challenges"`
+}
+
+// ChallengeRatingSummary represents the aggregated ratings of a challenge
+type ChallengeRatingSummary struct {
+	ChallengeID   uint    `json:"challengeId"`
+	ChallengeName string  `json:"challengeName,omitempty"`
+	Average       float64 `json:"average"`
+	Count         int64   `json:"count"`
+}
+
 // SubmissionTrend represents submission count over time
 type SubmissionTrend struct {
 	Date  string `json:"date"`
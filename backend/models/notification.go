@@ -3,15 +3,73 @@ package models
 import "time"
 
 type Notification struct {
-	ID        uint       `gorm:"primaryKey" json:"id"`
-	Title     string     `gorm:"not null;size:255" json:"title"`
-	Message   string     `gorm:"not null;type:text" json:"message"`
-	Type      string     `gorm:"not null;default:'info';size:20" json:"type"` // info, warning, error
-	UserID    *uint      `json:"userId,omitempty"`                            // null for global notifications
-	User      *User      `gorm:"foreignKey:UserID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;" json:"user,omitempty"`
-	TeamID    *uint      `json:"teamId,omitempty"` // null for global notifications
-	Team      *Team      `gorm:"foreignKey:TeamID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;" json:"team,omitempty"`
-	ReadAt    *time.Time `json:"readAt,omitempty"`
-	CreatedAt time.Time  `json:"createdAt"`
-	UpdatedAt time.Time  `json:"updatedAt"`
+	ID                  uint       `gorm:"primaryKey" json:"id"`
+	Title               string     `gorm:"not null;size:255" json:"title"`
+	Message             string     `gorm:"not null;type:text" json:"message"`
+	Type                string     `gorm:"not null;default:'info';size:20" json:"type"` // info, warning, error
+	UserID              *uint      `json:"userId,omitempty"`                            // null for global notifications
+	User                *User      `gorm:"foreignKey:UserID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;" json:"user,omitempty"`
+	TeamID              *uint      `json:"teamId,omitempty"` // null for global notifications
+	Team                *Team      `gorm:"foreignKey:TeamID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;" json:"team,omitempty"`
+	TargetRole          string     `gorm:"not null;default:'';size:50" json:"targetRole,omitempty"` // only users with this role
+	UnsolvedChallengeID *uint      `json:"unsolvedChallengeId,omitempty"`                           // only users whose team has not solved it
+	TargetBracketID     *uint      `json:"targetBracketId,omitempty"`                               // only users whose team is in this bracket
+	SenderID            *uint      `json:"senderId,omitempty"`
+	SendAt              *time.Time `json:"sendAt,omitempty"`
+	Pending             bool       `gorm:"not null;default:false;index" json:"pending"` // waiting for SendAt
+	ClaimedUntil        *time.Time `json:"-"`                                           // a replica is delivering it until then
+	Pinned              bool       `gorm:"not null;default:false" json:"pinned"`        // shown in the public announcement feed
+	EditedAt            *time.Time `json:"editedAt,omitempty"`
+	CreatedAt           time.Time  `json:"createdAt"`
+	UpdatedAt           time.Time  `json:"updatedAt"`
+}
+
+// NotificationGlobalCondition selects the notifications sent to every user
+const NotificationGlobalCondition = "user_id IS NULL AND team_id IS NULL AND target_role = '' AND unsolved_challenge_id IS NULL AND target_bracket_id IS NULL"
+
+// IsGlobal reports whether the notification targets every user
+func (n Notification) IsGlobal() bool {
+	return n.UserID == nil && n.TeamID == nil && !n.HasAudienceFilter()
+}
+
+// HasAudienceFilter reports whether the recipients are resolved from role, challenge or bracket filters
+func (n Notification) HasAudienceFilter() bool {
+	return n.TargetRole != "" || n.UnsolvedChallengeID != nil || n.TargetBracketID != nil
+}
+
+// SentAt returns when the notification was or will be delivered
+func (n Notification) SentAt() time.Time {
+	if n.SendAt != nil {
+		return *n.SendAt
+	}
+	return n.CreatedAt
+}
+
+// NotificationRecipient is a user resolved for a role or challenge targeted notification when it was delivered
+type NotificationRecipient struct {
+	NotificationID uint          `gorm:"primaryKey" json:"notificationId"`
+	Notification   *Notification `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;" json:"-"`
+	UserID         uint          `gorm:"primaryKey;index" json:"userId"`
+	User           *User         `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;" json:"-"`
+}
+
+// NotificationRead records when a user read a notification, every member of the audience has their own read state
+type NotificationRead struct {
+	NotificationID uint          `gorm:"primaryKey" json:"notificationId"`
+	Notification   *Notification `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;" json:"-"`
+	UserID         uint          `gorm:"primaryKey;index" json:"userId"`
+	User           *User         `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;" json:"-"`
+	ReadAt         time.Time     `json:"readAt"`
+}
+
+// NotificationRevision keeps the content of a notification before an edit
+type NotificationRevision struct {
+	ID             uint          `gorm:"primaryKey" json:"id"`
+	NotificationID uint          `gorm:"not null;index" json:"notificationId"`
+	Notification   *Notification `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;" json:"-"`
+	Title          string        `gorm:"not null;size:255" json:"title"`
+	Message        string        `gorm:"not null;type:text" json:"message"`
+	Type           string        `gorm:"not null;size:20" json:"type"`
+	EditorID       *uint         `json:"editorId,omitempty"`
+	CreatedAt      time.Time     `json:"createdAt"`
 }
@@ -2,6 +2,12 @@ package models
 
 import "time"
 
+// Source formats of a page, both are rendered to sanitized HTML
+const (
+	PageFormatHTML     = "html"
+	PageFormatMarkdown = "markdown"
+)
+
 type Page struct {
 	ID           uint       `gorm:"primaryKey" json:"id"`
 	Slug         string     `gorm:"uniqueIndex;not null" json:"slug"`
@@ -10,6 +16,10 @@ type Page struct {
 	IsInSidebar  bool       `gorm:"default:false" json:"is_in_sidebar"`
 	Order        int        `gorm:"default:0" json:"order"`
 	Source       string     `gorm:"default:'ui'" json:"source"` // 'ui' or 'minio'
+	Format       string     `gorm:"size:10;not null;default:'html'" json:"format"`
+	Draft        bool       `gorm:"default:false" json:"draft"`
+	PublishAt    *time.Time `json:"publish_at,omitempty"`   // hidden until then
+	UnpublishAt  *time.Time `json:"unpublish_at,omitempty"` // hidden from then
 	LastSyncedAt *time.Time `json:"last_synced_at,omitempty"`
 	CreatedAt    time.Time  `json:"created_at"`
 	UpdatedAt    time.Time  `json:"updated_at"`
@@ -18,3 +28,21 @@ type Page struct {
 func (Page) TableName() string {
 	return "pages"
 }
+
+// PageVisibleCondition selects the pages that are not drafts and within their publish window at the given time
+const PageVisibleCondition = "draft = ? AND (publish_at IS NULL OR publish_at <= ?) AND (unpublish_at IS NULL OR unpublish_at > ?)"
+
+// PageRevision is a saved version of a page source
+type PageRevision struct {
+	ID           uint      `gorm:"primaryKey" json:"id"`
+	PageID       uint      `gorm:"not null;uniqueIndex:idx_page_revision_number" json:"page_id"`
+	Page         *Page     `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;" json:"-"`
+	Number       int       `gorm:"not null;uniqueIndex:idx_page_revision_number" json:"number"`
+	Title        string    `gorm:"not null" json:"title"`
+	Format       string    `gorm:"size:10;not null" json:"format"`
+	Content      string    `gorm:"type:text;not null" json:"content,omitempty"`
+	AuthorID     *uint     `json:"author_id,omitempty"`
+	Author       *User     `gorm:"foreignKey:AuthorID;constraint:OnUpdate:CASCADE,OnDelete:SET NULL;" json:"-"`
+	RestoredFrom *int      `json:"restored_from,omitempty"` // revision number a rollback copied
+	CreatedAt    time.Time `json:"created_at"`
+}
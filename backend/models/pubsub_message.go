@@ -0,0 +1,11 @@
+package models
+
+import "time"
+
+// PubSubMessage holds a payload too large for a NOTIFY, the notification only carries its ID
+type PubSubMessage struct {
+	ID        uint      `gorm:"primaryKey"`
+	Channel   string    `gorm:"size:63;not null"`
+	Payload   []byte    `gorm:"not null"`
+	CreatedAt time.Time `gorm:"index"`
+}
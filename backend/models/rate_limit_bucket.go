@@ -0,0 +1,12 @@
+package models
+
+import "time"
+
+// RateLimitBucket is a token bucket of the Postgres rate limit store
+type RateLimitBucket struct {
+	Key       string    `gorm:"primaryKey;size:255"`
+	Tokens    float64   `gorm:"not null"`
+	Allowed   bool      `gorm:"not null"`
+	UpdatedAt time.Time `gorm:"not null"`
+	ExpiresAt time.Time `gorm:"not null;index"`
+}
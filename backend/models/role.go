@@ -0,0 +1,11 @@
+package models
+
+import "time"
+
+// Role is an admin-defined Casbin subject, its permissions live in the casbin_rule table
+type Role struct {
+	ID          uint      `gorm:"primaryKey" json:"id"`
+	Name        string    `gorm:"unique;not null;size:50" json:"name"`
+	Description string    `gorm:"size:255" json:"description"`
+	CreatedAt   time.Time `json:"createdAt"`
+}
@@ -1,8 +1,8 @@
 package models
 
 import (
-	"time"
 	"gorm.io/gorm"
+	"time"
 )
 
 type Team struct {
@@ -10,6 +10,8 @@ type Team struct {
 	Name          string         `gorm:"not null;uniqueIndex:idx_teams_name_deleted" json:"name"`
 	Password      string         `json:"-"`
 	CreatorID     uint           `json:"creatorId"`
+	BracketID     *uint          `gorm:"index" json:"bracketId,omitempty"`
+	Bracket       *Bracket       `gorm:"constraint:OnUpdate:CASCADE,OnDelete:SET NULL;" json:"bracket,omitempty"`
 	Creator       User           `gorm:"constraint:OnUpdate:CASCADE,OnDelete:SET NULL;" json:"creator,omitempty"`
 	CreatedAt     time.Time      `json:"createdAt"`
 	UpdatedAt     time.Time      `json:"updatedAt"`
@@ -22,6 +22,16 @@ const (
 	TicketTypeTeam TicketType = "team"
 )
 
+// TicketPriority represents how urgently a ticket should be handled
+type TicketPriority string
+
+const (
+	TicketPriorityLow    TicketPriority = "low"
+	TicketPriorityNormal TicketPriority = "normal"
+	TicketPriorityHigh   TicketPriority = "high"
+	TicketPriorityUrgent TicketPriority = "urgent"
+)
+
 // Ticket represents a support ticket in the system
 type Ticket struct {
 	ID          uint         `gorm:"primaryKey" json:"id"`
@@ -30,6 +40,10 @@ type Ticket struct {
 	Status      TicketStatus `gorm:"size:20;default:'open'" json:"status"`
 	TicketType  TicketType   `gorm:"size:20;not null" json:"ticketType"`
 
+	// Triage, set by admins
+	Priority TicketPriority `gorm:"size:20;not null;default:'normal';index" json:"priority"`
+	Tags     pq.StringArray `gorm:"type:text[]" json:"tags"`
+
 	// Creator
 	UserID uint `gorm:"not null" json:"userId"`
 	User   User `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;" json:"user,omitempty"`
@@ -54,6 +68,10 @@ type Ticket struct {
 	UpdatedAt  time.Time  `json:"updatedAt"`
 	ClaimedAt  *time.Time `json:"claimedAt,omitempty"`
 	ResolvedAt *time.Time `json:"resolvedAt,omitempty"`
+	ReopenedAt *time.Time `json:"reopenedAt,omitempty"`
+
+	// First admin reply, the SLA is measured from CreatedAt to it
+	FirstResponseAt *time.Time `json:"firstResponseAt,omitempty"`
 
 	// Messages relation (for chat)
 	Messages []TicketMessage `gorm:"foreignKey:TicketID" json:"messages,omitempty"`
@@ -0,0 +1,14 @@
+package models
+
+import "time"
+
+// TicketCannedResponse is a reply template admins can insert when answering tickets
+type TicketCannedResponse struct {
+	ID          uint      `gorm:"primaryKey" json:"id"`
+	Title       string    `gorm:"size:100;not null;uniqueIndex" json:"title"`
+	Message     string    `gorm:"type:text;not null" json:"message"`
+	CreatedByID *uint     `json:"createdById,omitempty"`
+	CreatedBy   *User     `gorm:"foreignKey:CreatedByID;constraint:OnUpdate:CASCADE,OnDelete:SET NULL;" json:"-"`
+	CreatedAt   time.Time `json:"createdAt"`
+	UpdatedAt   time.Time `json:"updatedAt"`
+}
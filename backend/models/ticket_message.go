@@ -20,6 +20,9 @@ type TicketMessage struct {
 	Message string `gorm:"type:text;not null" json:"message"`
 	IsAdmin bool   `gorm:"default:false" json:"isAdmin"`
 
+	// Internal notes are only shown to staff
+	IsInternal bool `gorm:"not null;default:false" json:"isInternal"`
+
 	// Attachments (MinIO paths)
 	Attachments pq.StringArray `gorm:"type:text[]" json:"attachments"`
 
@@ -6,6 +6,7 @@ import (
 	"errors"
 	"time"
 
+	"github.com/lib/pq"
 	"gorm.io/gorm"
 )
 
@@ -53,6 +54,12 @@ type User struct {
 	IPAddresses   IPAddresses    `gorm:"type:json" json:"ipAddresses"`
 	SocialLinks   SocialLinks    `gorm:"type:jsonb" json:"socialLinks,omitempty"`
 	MemberSince   time.Time      `gorm:"-" json:"memberSince"`
+	EmailVerified bool           `gorm:"default:false" json:"emailVerified"`
+	// Two-factor authentication
+	TOTPEnabled   bool           `gorm:"default:false" json:"totpEnabled"`
+	TOTPSecret    string         `json:"-"`
+	TOTPLastStep  int64          `json:"-"`
+	RecoveryCodes pq.StringArray `gorm:"type:text[]" json:"-"`
 }
 
 // SocialLinks stores user's social media profiles
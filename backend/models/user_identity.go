@@ -0,0 +1,15 @@
+package models
+
+import "time"
+
+// UserIdentity links a user to an external single sign-on identity
+type UserIdentity struct {
+	ID          uint      `gorm:"primaryKey" json:"id"`
+	UserID      uint      `gorm:"not null;index" json:"userId"`
+	User        *User     `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;" json:"user,omitempty"`
+	Provider    string    `gorm:"not null;size:255;uniqueIndex:idx_user_identity_subject" json:"provider"`
+	Subject     string    `gorm:"not null;size:255;uniqueIndex:idx_user_identity_subject" json:"subject"`
+	Email       string    `gorm:"size:254" json:"email"`
+	CreatedAt   time.Time `json:"createdAt"`
+	LastLoginAt time.Time `json:"lastLoginAt"`
+}
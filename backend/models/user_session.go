@@ -0,0 +1,24 @@
+package models
+
+import "time"
+
+// UserSession is a login on one device, identified by the jti of its refresh token
+type UserSession struct {
+	ID         uint       `gorm:"primaryKey" json:"id"`
+	UserID     uint       `gorm:"not null;index" json:"userId"`
+	User       *User      `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;" json:"-"`
+	JTI        string     `gorm:"column:jti;not null;uniqueIndex;size:64" json:"-"`
+	UserAgent  string     `gorm:"size:512" json:"userAgent"`
+	IPAddress  string     `gorm:"size:64" json:"ipAddress"`
+	CreatedAt  time.Time  `json:"createdAt"`
+	LastSeenAt time.Time  `json:"lastSeenAt"`
+	ExpiresAt  time.Time  `gorm:"not null;index" json:"expiresAt"`
+	RevokedAt  *time.Time `json:"revokedAt,omitempty"`
+}
+
+// RevokedToken blocks a JWT until it expires, shared by all backend replicas
+type RevokedToken struct {
+	JTI       string    `gorm:"column:jti;primaryKey;size:64" json:"jti"`
+	ExpiresAt time.Time `gorm:"not null;index" json:"expiresAt"`
+	CreatedAt time.Time `json:"createdAt"`
+}
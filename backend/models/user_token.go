@@ -0,0 +1,15 @@
+package models
+
+import "time"
+
+// UserToken records an emailed token so it can only be used once
+type UserToken struct {
+	ID        uint       `gorm:"primaryKey" json:"id"`
+	UserID    uint       `gorm:"not null;index" json:"userId"`
+	User      *User      `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;" json:"user,omitempty"`
+	Purpose   string     `gorm:"not null;size:32;index" json:"purpose"`
+	JTI       string     `gorm:"column:jti;not null;uniqueIndex;size:64" json:"-"`
+	ExpiresAt time.Time  `gorm:"not null;index" json:"expiresAt"`
+	UsedAt    *time.Time `json:"usedAt,omitempty"`
+	CreatedAt time.Time  `json:"createdAt"`
+}
@@ -0,0 +1,45 @@
+package models
+
+import (
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// WebhookTarget is an outbound webhook receiving the platform events it subscribed to
+type WebhookTarget struct {
+	ID        uint           `gorm:"primaryKey" json:"id"`
+	Name      string         `gorm:"not null;size:100" json:"name"`
+	URL       string         `gorm:"not null;size:2048" json:"url"`
+	Format    string         `gorm:"not null;size:20;default:'json'" json:"format"` // json, discord or slack
+	Secret    string         `gorm:"size:64" json:"-"`                              // signs json deliveries
+	Events    pq.StringArray `gorm:"type:text[]" json:"events"`
+	Enabled   bool           `gorm:"not null;default:true" json:"enabled"`
+	CreatedAt time.Time      `json:"createdAt"`
+	UpdatedAt time.Time      `json:"updatedAt"`
+}
+
+// WebhookDeliveryStatus represents the possible states of a webhook delivery
+type WebhookDeliveryStatus string
+
+const (
+	WebhookDeliveryPending WebhookDeliveryStatus = "pending"
+	WebhookDeliverySuccess WebhookDeliveryStatus = "success"
+	WebhookDeliveryFailed  WebhookDeliveryStatus = "failed"
+)
+
+// WebhookDelivery is a queued or attempted delivery of an event to a webhook target
+type WebhookDelivery struct {
+	ID             uint                  `gorm:"primaryKey" json:"id"`
+	TargetID       uint                  `gorm:"not null;index" json:"targetId"`
+	Target         *WebhookTarget        `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;" json:"-"`
+	Event          string                `gorm:"not null;size:64" json:"event"`
+	Payload        string                `gorm:"type:text;not null" json:"payload"`
+	Status         WebhookDeliveryStatus `gorm:"not null;size:20;index:idx_webhook_delivery_due" json:"status"`
+	Attempts       int                   `gorm:"not null;default:0" json:"attempts"`
+	ResponseStatus int                   `json:"responseStatus,omitempty"`
+	Error          string                `gorm:"type:text" json:"error,omitempty"`
+	NextAttemptAt  time.Time             `gorm:"index:idx_webhook_delivery_due" json:"nextAttemptAt"`
+	DeliveredAt    *time.Time            `json:"deliveredAt,omitempty"`
+	CreatedAt      time.Time             `gorm:"index" json:"createdAt"`
+}
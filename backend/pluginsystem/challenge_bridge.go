@@ -3,10 +3,12 @@ package pluginsystem
 import (
 	"encoding/json"
 	"fmt"
-	"log"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/pwnthemall/pwnthemall/backend/config"
+	"github.com/pwnthemall/pwnthemall/backend/logger"
+	"github.com/pwnthemall/pwnthemall/backend/metrics"
 	"github.com/pwnthemall/pwnthemall/backend/models"
 	"github.com/pwnthemall/pwnthemall/backend/shared"
 	"github.com/pwnthemall/pwnthemall/backend/utils"
@@ -96,23 +98,23 @@ func (h *PluginChallengeHandler) executeAction(action string, c *gin.Context, ch
 					conv := convertToStringKeys(raw)
 					if fd, ok := conv.(map[string]interface{}); ok {
 						challengeData["yaml"] = fd
-						log.Printf("Attached YAML from MinIO for slug=%s key=%s", slug, key)
+						logger.Ctx(c).Debugf("Attached YAML from MinIO for slug=%s key=%s", slug, key)
 						break
 					} else {
-						log.Printf("parsed YAML is not a map for %s (type=%T)", key, conv)
+						logger.Ctx(c).Warnf("Parsed YAML is not a map for %s (type=%T)", key, conv)
 					}
 				} else {
-					log.Printf("failed to parse YAML from MinIO for %s: %v", key, err)
+					logger.Ctx(c).Errorf("Failed to parse YAML from MinIO for %s: %v", key, err)
 				}
 			} else {
-				log.Printf("could not retrieve %s from MinIO: %v", key, err)
+				logger.Ctx(c).Warnf("Could not retrieve %s from MinIO: %v", key, err)
 			}
 		}
 	}
 
 	body, err := json.Marshal(challengeData)
 	if err != nil {
-		log.Printf("failed to marshal challengeData for sending: %v", err)
+		logger.Ctx(c).Errorf("Failed to marshal challengeData for sending: %v", err)
 	}
 	requestData := shared.RequestData{
 		Method:  "POST",
@@ -128,7 +130,7 @@ func (h *PluginChallengeHandler) executeAction(action string, c *gin.Context, ch
 
 	if rpcPlugin, ok := h.plugin.(*shared.PluginRPC); ok {
 		handlerName := fmt.Sprintf("ChallengeInstance%s", action)
-		response, err := rpcPlugin.HandleRequest(handlerName, requestData)
+		response, err := handleRPCRequest(rpcPlugin, h.challengeType, handlerName, requestData)
 		if err != nil {
 			return err
 		}
@@ -143,6 +145,14 @@ func (h *PluginChallengeHandler) executeAction(action string, c *gin.Context, ch
 	return fmt.Errorf("plugin does not implement RPC interface")
 }
 
+// handleRPCRequest forwards a request to a plugin and records the RPC latency
+func handleRPCRequest(rpcPlugin *shared.PluginRPC, pluginName, handlerName string, requestData shared.RequestData) (shared.ResponseData, error) {
+	start := time.Now()
+	response, err := rpcPlugin.HandleRequest(handlerName, requestData)
+	metrics.ObservePluginRPC(pluginName, handlerName, start, err)
+	return response, err
+}
+
 // RegisterPluginChallengeHandler registers a plugin as a challenge handler
 func RegisterPluginChallengeHandler(plugin shared.Plugin, challengeType string) {
 	handler := &PluginChallengeHandler{
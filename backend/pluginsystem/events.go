@@ -0,0 +1,78 @@
+package pluginsystem
+
+import (
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/pwnthemall/pwnthemall/backend/events"
+	"github.com/pwnthemall/pwnthemall/backend/logger"
+	"github.com/pwnthemall/pwnthemall/backend/metrics"
+	"github.com/pwnthemall/pwnthemall/backend/shared"
+)
+
+// pluginEventQueueSize is the number of events buffered for a slow plugin before new ones are dropped
+const pluginEventQueueSize = 256
+
+type pluginEvent struct {
+	name    string
+	payload []byte
+}
+
+var (
+	pluginQueuesMu sync.Mutex
+	pluginQueues   = map[string]chan pluginEvent{}
+)
+
+// SubscribePlugins forwards platform events to the plugins that listed them in their metadata
+func SubscribePlugins() {
+	events.Subscribe("plugins", func(e events.Event) {
+		var payload []byte
+		for name, loaded := range shared.LoadedPlugins {
+			receiver, ok := loaded.Plugin.(shared.EventReceiver)
+			if !ok || !wantsEvent(loaded.Metadata.Events, e.EventName()) {
+				continue
+			}
+			if payload == nil {
+				var err error
+				if payload, err = events.Marshal(e); err != nil {
+					logger.Errorf("Failed to marshal event %s for plugins: %v", e.EventName(), err)
+					return
+				}
+			}
+			select {
+			case pluginQueue(name, receiver) <- pluginEvent{name: e.EventName(), payload: payload}:
+			default:
+				logger.Warn("Plugin event queue full, dropping event", "plugin", name, "event", e.EventName())
+			}
+		}
+	})
+}
+
+func wantsEvent(subscribed []string, name string) bool {
+	return slices.Contains(subscribed, "*") || slices.Contains(subscribed, name)
+}
+
+// pluginQueue returns the queue of a plugin, events are delivered in order by one goroutine per plugin
+func pluginQueue(name string, receiver shared.EventReceiver) chan pluginEvent {
+	pluginQueuesMu.Lock()
+	defer pluginQueuesMu.Unlock()
+
+	if queue, ok := pluginQueues[name]; ok {
+		return queue
+	}
+	queue := make(chan pluginEvent, pluginEventQueueSize)
+	pluginQueues[name] = queue
+
+	go func() {
+		for evt := range queue {
+			start := time.Now()
+			err := receiver.HandleEvent(evt.name, evt.payload)
+			metrics.ObservePluginRPC(name, "event:"+evt.name, start, err)
+			if err != nil {
+				logger.Warn("Plugin failed to handle event", "plugin", name, "event", evt.name, "error", err)
+			}
+		}
+	}()
+	return queue
+}
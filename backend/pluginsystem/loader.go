@@ -8,23 +8,23 @@ import (
 	"github.com/casbin/casbin/v2"
 	"github.com/gin-gonic/gin"
 	"github.com/hashicorp/go-plugin"
-	"github.com/pwnthemall/pwnthemall/backend/debug"
+	"github.com/pwnthemall/pwnthemall/backend/logger"
 	"github.com/pwnthemall/pwnthemall/backend/shared"
 )
 
 func LoadAllPlugins(pluginDir string, router *gin.Engine, enforcer *casbin.Enforcer) {
 	files, err := filepath.Glob(filepath.Join(pluginDir, "bin-*"))
 	if err != nil {
-		debug.Log("Failed to list plugins: %v", err)
+		logger.Errorf("Failed to list plugins: %v", err)
 		return
 	}
 
 	if len(files) == 0 {
-		debug.Log("No plugins found in %s", pluginDir)
+		logger.Debugf("No plugins found in %s", pluginDir)
 		return
 	}
 
-	debug.Log("Found these plugins files: %v", files)
+	logger.Debugf("Found these plugins files: %v", files)
 
 	handshakeConfig := plugin.HandshakeConfig{
 		ProtocolVersion:  1,
@@ -39,16 +39,16 @@ func LoadAllPlugins(pluginDir string, router *gin.Engine, enforcer *casbin.Enfor
 
 func loadSinglePlugin(file string, router *gin.Engine, enforcer *casbin.Enforcer, handshakeConfig plugin.HandshakeConfig) {
 	pluginName := filepath.Base(file)
-	debug.Log("Loading plugin: %s", pluginName)
+	logger.Infof("Loading plugin: %s", pluginName)
 
 	envVars, err := shared.LoadPluginEnv(file)
 	if err != nil {
-		debug.Log("Failed to load .env for %s: %v", pluginName, err)
+		logger.Errorf("Failed to load .env for %s: %v", pluginName, err)
 		return
 	}
 
 	if len(envVars) > 0 {
-		debug.Log("Loaded %d environment variables for %s", len(envVars), pluginName)
+		logger.Debugf("Loaded %d environment variables for %s", len(envVars), pluginName)
 	}
 
 	client := plugin.NewClient(&plugin.ClientConfig{
@@ -61,13 +61,13 @@ func loadSinglePlugin(file string, router *gin.Engine, enforcer *casbin.Enforcer
 
 	rpcClient, err := client.Client()
 	if err != nil {
-		debug.Log("Failed to get client for %s: %v", file, err)
+		logger.Errorf("Failed to get client for %s: %v", file, err)
 		return
 	}
 
 	raw, err := rpcClient.Dispense("generic")
 	if err != nil {
-		debug.Log("Failed to dispense plugin %s: %v", file, err)
+		logger.Errorf("Failed to dispense plugin %s: %v", file, err)
 		return
 	}
 
@@ -75,24 +75,30 @@ func loadSinglePlugin(file string, router *gin.Engine, enforcer *casbin.Enforcer
 	metadata := plug.GetMetadata()
 	metadata.EnvVars = envVars
 
+	if rpcPlugin, ok := plug.(*shared.PluginRPC); ok {
+		if err := rpcPlugin.SetLogSink(&pluginLogSink{name: metadata.Name}); err != nil {
+			logger.Warnf("Plugin %s does not accept a log sink: %v", metadata.Name, err)
+		}
+	}
+
 	plugConfig := map[string]interface{}{
 		"db_connection": os.Getenv("DATABASE_URL"),
 	}
 
 	plugConfig = shared.MergeEnvToConfig(plugConfig, envVars)
 
-	debug.Log("Initializing plugin %s with config", metadata.Name)
+	logger.Debugf("Initializing plugin %s with config", metadata.Name)
 
 	if err := plug.Initialize(plugConfig); err != nil {
-		debug.Log("Failed to initialize plugin %s: %v", metadata.Name, err)
+		logger.Errorf("Failed to initialize plugin %s: %v", metadata.Name, err)
 		client.Kill()
 		return
 	}
 
-	registrar := NewGinRouteRegistrar(router, plug, enforcer)
+	registrar := NewGinRouteRegistrar(router, plug, metadata.Name, enforcer)
 
 	if err := plug.RegisterRoutes(registrar); err != nil {
-		debug.Log("Failed to register routes for %s: %v", metadata.Name, err)
+		logger.Errorf("Failed to register routes for %s: %v", metadata.Name, err)
 		client.Kill()
 		return
 	}
@@ -100,7 +106,14 @@ func loadSinglePlugin(file string, router *gin.Engine, enforcer *casbin.Enforcer
 	if metadata.Type == "challenge-handler" {
 		challengeType := metadata.Name
 		RegisterPluginChallengeHandler(plug, challengeType)
-		debug.Log("Registered challenge handler for type: %s", challengeType)
+		logger.Debugf("Registered challenge handler for type: %s", challengeType)
+	}
+
+	if metadata.Type == "sso" {
+		if ssoHandler, ok := plug.(shared.SSOHandler); ok {
+			shared.RegisterSSOHandler(metadata.Name, ssoHandler)
+			logger.Debugf("Registered SSO handler: %s", metadata.Name)
+		}
 	}
 
 	shared.LoadedPlugins[metadata.Name] = &shared.LoadedPlugin{
@@ -109,12 +122,12 @@ func loadSinglePlugin(file string, router *gin.Engine, enforcer *casbin.Enforcer
 		Metadata: metadata,
 	}
 
-	debug.Log("✓ Loaded plugin: %s v%s (%s)", metadata.Name, metadata.Version, metadata.Type)
+	logger.Infof("✓ Loaded plugin: %s v%s (%s)", metadata.Name, metadata.Version, metadata.Type)
 }
 
 func ShutdownAllPlugins() {
 	for name, p := range shared.LoadedPlugins {
-		debug.Log("Shutting down plugin: %s", name)
+		logger.Debugf("Shutting down plugin: %s", name)
 		p.Plugin.Shutdown()
 		p.Client.Kill()
 	}
@@ -0,0 +1,32 @@
+package pluginsystem
+
+import (
+	"github.com/pwnthemall/pwnthemall/backend/logger"
+	"github.com/pwnthemall/pwnthemall/backend/shared"
+)
+
+// pluginLogSink writes the log entries of a plugin to the backend logger
+type pluginLogSink struct {
+	name string
+}
+
+func (s *pluginLogSink) Log(entry shared.LogEntry) error {
+	args := make([]any, 0, 2+2*len(entry.Fields))
+	args = append(args, "plugin", s.name)
+	for key, value := range entry.Fields {
+		args = append(args, key, value)
+	}
+	log := logger.With(args...)
+
+	switch entry.Level {
+	case "debug":
+		log.Debug(entry.Message)
+	case "warn":
+		log.Warn(entry.Message)
+	case "error":
+		log.Error(entry.Message)
+	default:
+		log.Info(entry.Message)
+	}
+	return nil
+}
@@ -3,12 +3,13 @@ package pluginsystem
 import (
 	"bytes"
 	"io"
-	
+
 	"net/http"
 
 	"github.com/casbin/casbin/v2"
 	"github.com/gin-gonic/gin"
-	"github.com/pwnthemall/pwnthemall/backend/debug"
+	"github.com/pwnthemall/pwnthemall/backend/config"
+	"github.com/pwnthemall/pwnthemall/backend/logger"
 	"github.com/pwnthemall/pwnthemall/backend/shared"
 	"github.com/pwnthemall/pwnthemall/backend/utils"
 )
@@ -16,13 +17,15 @@ import (
 type GinRouteRegistrar struct {
 	router   *gin.Engine
 	plugin   shared.Plugin
+	name     string
 	enforcer *casbin.Enforcer
 }
 
-func NewGinRouteRegistrar(router *gin.Engine, plugin shared.Plugin, enforcer *casbin.Enforcer) *GinRouteRegistrar {
+func NewGinRouteRegistrar(router *gin.Engine, plugin shared.Plugin, name string, enforcer *casbin.Enforcer) *GinRouteRegistrar {
 	return &GinRouteRegistrar{
 		router:   router,
 		plugin:   plugin,
+		name:     name,
 		enforcer: enforcer,
 	}
 }
@@ -61,9 +64,9 @@ func (g *GinRouteRegistrar) RegisterRouteWithMiddlewares(method, path, handlerNa
 	}
 
 	if len(middlewareNames) > 0 {
-		debug.Log("Registered route: %s %s (role: %s, middlewares: %v)", method, path, requireRole, middlewareNames)
+		logger.Debugf("Registered route: %s %s (role: %s, middlewares: %v)", method, path, requireRole, middlewareNames)
 	} else {
-		debug.Log("Registered route: %s %s (role: %s, no extra middlewares)", method, path, requireRole)
+		logger.Debugf("Registered route: %s %s (role: %s, no extra middlewares)", method, path, requireRole)
 	}
 }
 
@@ -86,9 +89,9 @@ func (g *GinRouteRegistrar) createAuthMiddleware(path, method, requireRole strin
 		}
 
 		if g.enforcer != nil {
-			ok, err := g.enforcer.Enforce(role, path, action)
+			ok, err := config.Enforce(role, path, action)
 			if err != nil {
-				debug.Log("Casbin error for plugin route %s: %v", path, err)
+				logger.Ctx(c).Errorf("Casbin error for plugin route %s: %v", path, err)
 				c.AbortWithStatusJSON(500, gin.H{"error": "authorization error"})
 				return
 			}
@@ -120,7 +123,7 @@ func (g *GinRouteRegistrar) createPluginHandler(handlerName string) gin.HandlerF
 		}
 
 		if rpcPlugin, ok := g.plugin.(*shared.PluginRPC); ok {
-			response, err := rpcPlugin.HandleRequest(handlerName, requestData)
+			response, err := handleRPCRequest(rpcPlugin, g.name, handlerName, requestData)
 			if err != nil {
 				c.JSON(500, gin.H{"error": err.Error()})
 				return
@@ -0,0 +1,15 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/pwnthemall/pwnthemall/backend/controllers"
+	"github.com/pwnthemall/pwnthemall/backend/middleware"
+)
+
+func RegisterAuditRoutes(router *gin.Engine) {
+	audit := router.Group("/admin/audit", middleware.AuthRequired(false), middleware.CSRFProtection())
+	{
+		audit.GET("", middleware.CheckPolicy("/admin/audit", "read"), controllers.GetAuditLogs)
+		audit.GET("/export", middleware.CheckPolicy("/admin/audit/export", "read"), middleware.RateLimit(5), controllers.ExportAuditLogs)
+	}
+}
@@ -10,9 +10,19 @@ func RegisterAuthRoutes(router *gin.Engine) {
 	auth := router.Group("/")
 	{
 		auth.POST("login", middleware.RateLimitLogin(), middleware.CSRFProtection(), controllers.Login)
+		auth.POST("login/2fa", middleware.RateLimitLogin(), middleware.CSRFProtection(), controllers.LoginTwoFactor)
 		auth.POST("register", middleware.CSRFProtection(), controllers.Register)
 		auth.POST("logout", middleware.AuthRequired(false), middleware.CSRFProtection(), controllers.Logout)
 
+		auth.GET("auth/sso", controllers.GetSSOConfig)
+		auth.GET("auth/sso/login", middleware.RateLimit(10), controllers.SSOLogin)
+		auth.GET("auth/sso/callback", middleware.RateLimit(10), controllers.SSOCallback)
+
+		auth.POST("auth/verify-email", middleware.RateLimit(10), middleware.CSRFProtection(), controllers.VerifyEmail)
+		auth.POST("auth/verify-email/resend", middleware.AuthRequired(false), middleware.RateLimit(3), middleware.CSRFProtection(), controllers.ResendVerificationEmail)
+		auth.POST("auth/forgot-password", middleware.RateLimitLogin(), middleware.CSRFProtection(), controllers.ForgotPassword)
+		auth.POST("auth/reset-password", middleware.RateLimit(10), middleware.CSRFProtection(), controllers.ResetPassword)
+
 		auth.POST("refresh", controllers.Refresh)
 		auth.GET("me", middleware.AuthRequired(false), controllers.GetCurrentUser)
 		auth.GET("csrf-token", middleware.RateLimit(30), middleware.CSRFProtection(), controllers.GetCSRFToken)
@@ -23,5 +33,18 @@ func RegisterAuthRoutes(router *gin.Engine) {
 		auth.PATCH("me", middleware.AuthRequired(false), middleware.CSRFProtection(), controllers.UpdateCurrentUser)
 		auth.PUT("me/password", middleware.AuthRequired(false), middleware.CSRFProtection(), controllers.UpdateCurrentUserPassword)
 		auth.DELETE("me", middleware.AuthRequired(false), middleware.CSRFProtection(), controllers.DeleteCurrentUser)
+
+		auth.GET("me/sessions", middleware.AuthRequired(false), controllers.GetSessions)
+		auth.DELETE("me/sessions", middleware.AuthRequired(false), middleware.CSRFProtection(), controllers.RevokeOtherSessions)
+		auth.DELETE("me/sessions/:id", middleware.AuthRequired(false), middleware.CSRFProtection(), controllers.RevokeSessionByID)
+
+		auth.GET("me/tokens", middleware.AuthRequired(false), controllers.GetAPITokens)
+		auth.POST("me/tokens", middleware.AuthRequired(false), middleware.RateLimit(10), middleware.CSRFProtection(), controllers.CreateAPIToken)
+		auth.DELETE("me/tokens/:id", middleware.AuthRequired(false), middleware.CSRFProtection(), controllers.RevokeAPIToken)
+
+		auth.POST("me/2fa/setup", middleware.AuthRequired(false), middleware.CSRFProtection(), controllers.SetupTwoFactor)
+		auth.POST("me/2fa/confirm", middleware.AuthRequired(false), middleware.RateLimit(10), middleware.CSRFProtection(), controllers.ConfirmTwoFactor)
+		auth.POST("me/2fa/disable", middleware.AuthRequired(false), middleware.RateLimit(10), middleware.CSRFProtection(), controllers.DisableTwoFactor)
+		auth.POST("me/2fa/recovery-codes", middleware.AuthRequired(false), middleware.RateLimit(10), middleware.CSRFProtection(), controllers.RegenerateRecoveryCodes)
 	}
 }
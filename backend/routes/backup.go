@@ -0,0 +1,15 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/pwnthemall/pwnthemall/backend/controllers"
+	"github.com/pwnthemall/pwnthemall/backend/middleware"
+)
+
+func RegisterBackupRoutes(router *gin.Engine) {
+	backup := router.Group("/admin/backup", middleware.AuthRequired(false), middleware.CSRFProtection())
+	{
+		backup.GET("", middleware.CheckPolicy("/admin/backup", "read"), middleware.RateLimit(5), controllers.ExportEventBackup)
+		backup.POST("/restore", middleware.DemoRestriction, middleware.CheckPolicy("/admin/backup/restore", "write"), middleware.RateLimit(2), controllers.RestoreEventBackup)
+	}
+}
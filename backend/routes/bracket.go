@@ -0,0 +1,18 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/pwnthemall/pwnthemall/backend/controllers"
+	"github.com/pwnthemall/pwnthemall/backend/middleware"
+)
+
+func RegisterBracketRoutes(router *gin.Engine) {
+	brackets := router.Group("/admin/brackets", middleware.AuthRequired(false), middleware.CSRFProtection())
+	{
+		brackets.GET("", middleware.CheckPolicy("/admin/brackets", "read"), controllers.GetBrackets)
+		brackets.POST("", middleware.DemoRestriction, middleware.CheckPolicy("/admin/brackets", "write"), controllers.CreateBracket)
+		brackets.PUT("/:id", middleware.DemoRestriction, middleware.CheckPolicy("/admin/brackets/:id", "write"), controllers.UpdateBracket)
+		brackets.PUT("/:id/teams", middleware.DemoRestriction, middleware.CheckPolicy("/admin/brackets/:id/teams", "write"), controllers.SetBracketTeams)
+		brackets.DELETE("/:id", middleware.DemoRestriction, middleware.CheckPolicy("/admin/brackets/:id", "write"), controllers.DeleteBracket)
+	}
+}
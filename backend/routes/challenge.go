@@ -10,6 +10,7 @@ func RegisterChallengeRoutes(router *gin.Engine) {
 	challenges := router.Group("/challenges", middleware.AuthRequiredTeamOrAdmin(), middleware.CSRFProtection())
 	{
 		challenges.GET("", middleware.CheckPolicy("/challenges", "read"), controllers.GetChallenges)
+		challenges.GET("/tags", middleware.CheckPolicy("/challenges/tags", "read"), controllers.GetChallengeTags)
 		challenges.GET("/:id", middleware.CheckPolicy("/challenges/:id", "read"), controllers.GetChallenge)
 		challenges.GET("/:id/solves", middleware.CheckPolicy("/challenges/:id/solves", "read"), controllers.GetChallengeSolves)
 		challenges.GET("/:id/firstbloods", middleware.CheckPolicy("/challenges/:id/firstbloods", "read"), controllers.GetChallengeFirstBloods)
@@ -17,10 +18,12 @@ func RegisterChallengeRoutes(router *gin.Engine) {
 		challenges.GET("/:id/files", middleware.CheckPolicy("/challenges/:id/files", "read"), controllers.GetChallengeFiles)
 		challenges.GET("/:id/files/:filename", middleware.RateLimit(10), middleware.CheckPolicy("/challenges/:id/files/:filename", "read"), controllers.DownloadChallengeFile)
 		challenges.GET("/:id/cover", middleware.CheckPolicy("/challenges/:id/cover", "read"), controllers.GetChallengeCover)
+		challenges.GET("/:id/rating", middleware.CheckPolicy("/challenges/:id/rating", "read"), controllers.GetChallengeRating)
 		challenges.GET("/:id/instance-status", middleware.DemoRestriction, middleware.CheckPolicy("/challenges/:id/instance-status", "read"), controllers.GetInstanceStatus)
 
 		challenges.POST("", middleware.CheckPolicy("/challenges", "write"), controllers.CreateChallenge)
-		challenges.POST("/:id/submit", middleware.CheckPolicy("/challenges/:id/submit", "write"), controllers.SubmitChallenge)
+		challenges.POST("/:id/submit", middleware.RateLimitPolicy("submit"), middleware.CheckPolicy("/challenges/:id/submit", "write"), controllers.SubmitChallenge)
+		challenges.POST("/:id/rating", middleware.RateLimit(10), middleware.CheckPolicy("/challenges/:id/rating", "write"), controllers.RateChallenge)
 		challenges.POST("/:id/build", middleware.DemoRestriction, middleware.CheckPolicy("/challenges/:id/build", "write"), controllers.BuildChallengeImage)
 		challenges.POST("/:id/start", middleware.DemoRestriction, middleware.CheckPolicy("/challenges/:id/start", "write"), controllers.StartChallengeInstance)
 		challenges.POST("/:id/stop", middleware.DemoRestriction, middleware.CheckPolicy("/challenges/:id/stop", "write"), controllers.StopChallengeInstance)
@@ -34,10 +37,21 @@ func RegisterChallengeRoutes(router *gin.Engine) {
 		// Allow admins access without requiring a team; policy check restricts to admin role
 		adminChallenges.GET("", middleware.CheckPolicy("/admin/challenges", "read"), controllers.GetAllChallengesAdmin)
 		adminChallenges.GET("/:id", middleware.CheckPolicy("/admin/challenges/:id", "read"), controllers.GetChallengeAdmin)
+		adminChallenges.GET("/:id/ratings", middleware.CheckPolicy("/admin/challenges/:id/ratings", "read"), controllers.GetChallengeRatingsAdmin)
 		adminChallenges.GET("/:id/export", middleware.CheckPolicy("/admin/challenges/:id/export", "read"), controllers.ExportChallenge)
+		adminChallenges.GET("/:id/authors", middleware.CheckPolicy("/admin/challenges/:id/authors", "read"), controllers.GetChallengeAuthors)
 		adminChallenges.POST("", middleware.CheckPolicy("/admin/challenges", "write"), controllers.CreateChallengeAdmin)
+		adminChallenges.POST("/import", middleware.CheckPolicy("/admin/challenges/import", "write"), middleware.RateLimit(5), controllers.ImportChallengesAdmin)
+		adminChallenges.POST("/import/ctfd", middleware.DemoRestriction, middleware.CheckPolicy("/admin/challenges/import/ctfd", "write"), middleware.RateLimit(2), controllers.ImportCTFdAdmin)
 		adminChallenges.PUT("/:id", middleware.CheckPolicy("/admin/challenges/:id", "write"), controllers.UpdateChallengeAdmin)
+		adminChallenges.PUT("/:id/authors", middleware.CheckPolicy("/admin/challenges/:id/authors", "write"), controllers.SetChallengeAuthors)
+		adminChallenges.POST("/:id/test-flag", middleware.RateLimit(10), middleware.CheckPolicy("/admin/challenges/:id/test-flag", "write"), controllers.TestChallengeFlag)
 		adminChallenges.PUT("/:id/general", middleware.CheckPolicy("/admin/challenges/:id", "write"), controllers.UpdateChallengeGeneralAdmin)
 		adminChallenges.DELETE("/hints/:hintId", middleware.CheckPolicy("/admin/challenges/hints/:hintId", "write"), controllers.DeleteHint)
 	}
+
+	author := router.Group("/author", middleware.AuthRequired(false), middleware.CSRFProtection())
+	{
+		author.GET("/dashboard", middleware.CheckPolicy("/author/dashboard", "read"), controllers.GetAuthorDashboard)
+	}
 }
@@ -0,0 +1,18 @@
+package routes
+
+import (
+	"os"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pwnthemall/pwnthemall/backend/metrics"
+	"github.com/pwnthemall/pwnthemall/backend/middleware"
+)
+
+// RegisterMetricsRoutes exposes /metrics on the public router, only when it is protected by a token
+// Without a token, metrics are only served on the internal PTA_METRICS_ADDR listener
+func RegisterMetricsRoutes(router *gin.Engine) {
+	if os.Getenv("PTA_METRICS_TOKEN") == "" {
+		return
+	}
+	router.GET("/metrics", middleware.MetricsAuth(), metrics.Handler())
+}
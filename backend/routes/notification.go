@@ -20,6 +20,9 @@ func RegisterNotificationRoutes(router *gin.Engine) {
 		utils.ServeWs(utils.UpdatesHub, userID, c.Writer, c.Request)
 	})
 
+	// Pinned announcements, public
+	router.GET("/announcements", middleware.RateLimit(30), controllers.GetAnnouncements)
+
 	notifications := router.Group("/notifications", middleware.AuthRequired(false), middleware.CSRFProtection())
 	{
 		notifications.GET("", controllers.GetUserNotifications)
@@ -32,8 +35,10 @@ func RegisterNotificationRoutes(router *gin.Engine) {
 	adminNotifications := router.Group("/admin/notifications", middleware.AuthRequired(false), middleware.CSRFProtection())
 	{
 		adminNotifications.GET("", middleware.CheckPolicy("/admin/notifications", "read"), controllers.GetSentNotifications)
+		adminNotifications.GET("/:id/revisions", middleware.CheckPolicy("/admin/notifications", "read"), controllers.GetNotificationRevisions)
 
 		adminNotifications.POST("", middleware.CheckPolicy("/admin/notifications", "write"), controllers.SendNotification)
+		adminNotifications.PUT("/:id", middleware.CheckPolicy("/admin/notifications", "write"), controllers.UpdateNotification)
 		adminNotifications.DELETE("/:id", middleware.CheckPolicy("/admin/notifications", "write"), controllers.DeleteNotification)
 	}
 }
@@ -0,0 +1,21 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/pwnthemall/pwnthemall/backend/controllers"
+	"github.com/pwnthemall/pwnthemall/backend/middleware"
+)
+
+func RegisterOutboundWebhookRoutes(router *gin.Engine) {
+	webhooks := router.Group("/admin/webhooks", middleware.AuthRequired(false), middleware.CSRFProtection())
+	{
+		webhooks.GET("", middleware.CheckPolicy("/admin/webhooks", "read"), controllers.GetWebhookTargets)
+		webhooks.POST("", middleware.DemoRestriction, middleware.CheckPolicy("/admin/webhooks", "write"), controllers.CreateWebhookTarget)
+		webhooks.PUT("/:id", middleware.DemoRestriction, middleware.CheckPolicy("/admin/webhooks/:id", "write"), controllers.UpdateWebhookTarget)
+		webhooks.DELETE("/:id", middleware.DemoRestriction, middleware.CheckPolicy("/admin/webhooks/:id", "write"), controllers.DeleteWebhookTarget)
+		webhooks.POST("/:id/secret", middleware.DemoRestriction, middleware.CheckPolicy("/admin/webhooks/:id", "write"), controllers.RotateWebhookSecret)
+		webhooks.POST("/:id/test", middleware.DemoRestriction, middleware.CheckPolicy("/admin/webhooks/:id", "write"), middleware.RateLimit(10), controllers.TestWebhookTarget)
+		webhooks.GET("/:id/deliveries", middleware.CheckPolicy("/admin/webhooks/:id", "read"), controllers.GetWebhookDeliveries)
+		webhooks.POST("/:id/deliveries/:deliveryId/redeliver", middleware.DemoRestriction, middleware.CheckPolicy("/admin/webhooks/:id", "write"), controllers.RedeliverWebhookDelivery)
+	}
+}
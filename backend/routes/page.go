@@ -15,6 +15,10 @@ func RegisterPageRoutes(router *gin.Engine) {
 		adminPages.POST("", middleware.CheckPolicy("/admin/pages", "write"), middleware.RateLimit(10), controllers.CreatePage)
 		adminPages.PUT("/:id", middleware.CheckPolicy("/admin/pages/:id", "write"), middleware.RateLimit(30), controllers.UpdatePage)
 		adminPages.DELETE("/:id", middleware.CheckPolicy("/admin/pages/:id", "write"), middleware.RateLimit(10), controllers.DeletePage)
+		adminPages.GET("/:id/revisions", middleware.CheckPolicy("/admin/pages/:id/revisions", "read"), middleware.RateLimit(60), controllers.GetPageRevisions)
+		adminPages.GET("/:id/revisions/:revision", middleware.CheckPolicy("/admin/pages/:id/revisions/:revision", "read"), middleware.RateLimit(60), controllers.GetPageRevision)
+		adminPages.POST("/:id/revisions/:revision/rollback", middleware.CheckPolicy("/admin/pages/:id/revisions/:revision/rollback", "write"), middleware.RateLimit(10), controllers.RollbackPage)
+		adminPages.GET("/:id/diff", middleware.CheckPolicy("/admin/pages/:id/diff", "read"), middleware.RateLimit(60), controllers.GetPageDiff)
 	}
 
 	// API route for serving custom pages as JSON (used by Next.js SSR)
@@ -0,0 +1,19 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/pwnthemall/pwnthemall/backend/controllers"
+	"github.com/pwnthemall/pwnthemall/backend/middleware"
+)
+
+func RegisterRoleRoutes(router *gin.Engine) {
+	roles := router.Group("/admin/roles", middleware.AuthRequired(false), middleware.CSRFProtection())
+	{
+		roles.GET("", middleware.CheckPolicy("/admin/roles", "read"), controllers.GetRoles)
+		roles.POST("", middleware.DemoRestriction, middleware.CheckPolicy("/admin/roles", "write"), controllers.CreateRole)
+		roles.PUT("/:name", middleware.DemoRestriction, middleware.CheckPolicy("/admin/roles/:name", "write"), controllers.UpdateRole)
+		roles.DELETE("/:name", middleware.DemoRestriction, middleware.CheckPolicy("/admin/roles/:name", "write"), controllers.DeleteRole)
+		roles.POST("/:name/permissions", middleware.DemoRestriction, middleware.CheckPolicy("/admin/roles/:name/permissions", "write"), controllers.GrantPermission)
+		roles.DELETE("/:name/permissions", middleware.DemoRestriction, middleware.CheckPolicy("/admin/roles/:name/permissions", "write"), controllers.RevokePermission)
+	}
+}
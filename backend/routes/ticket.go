@@ -16,6 +16,7 @@ func RegisterTicketRoutes(router *gin.Engine) {
 		tickets.GET("/:id", middleware.CheckPolicy("/tickets/:id", "read"), controllers.GetTicket)
 		tickets.POST("/:id/messages", middleware.CheckPolicy("/tickets/:id/messages", "write"), controllers.SendTicketMessage)
 		tickets.PUT("/:id/close", middleware.CheckPolicy("/tickets/:id/close", "write"), controllers.CloseTicket)
+		tickets.PUT("/:id/reopen", middleware.CheckPolicy("/tickets/:id/reopen", "write"), controllers.ReopenTicket)
 		tickets.POST("/upload", middleware.RateLimit(3), middleware.CheckPolicy("/tickets/upload", "write"), controllers.UploadTicketAttachment)
 		tickets.GET("/:id/attachments/:filename", middleware.CheckPolicy("/tickets/:id", "read"), controllers.GetTicketAttachment)
 	}
@@ -24,8 +25,18 @@ func RegisterTicketRoutes(router *gin.Engine) {
 	adminTickets := router.Group("/admin/tickets", middleware.TicketsEnabled, middleware.AuthRequired(false), middleware.CSRFProtection())
 	{
 		adminTickets.GET("", middleware.CheckPolicy("/admin/tickets", "read"), controllers.GetAllTickets)
+		adminTickets.GET("/analytics", middleware.CheckPolicy("/admin/tickets/analytics", "read"), controllers.GetTicketAnalytics)
+		adminTickets.GET("/canned-responses", middleware.CheckPolicy("/admin/tickets/canned-responses", "read"), controllers.GetTicketCannedResponses)
+		adminTickets.POST("/canned-responses", middleware.CheckPolicy("/admin/tickets/canned-responses", "write"), controllers.CreateTicketCannedResponse)
+		adminTickets.PUT("/canned-responses/:responseId", middleware.CheckPolicy("/admin/tickets/canned-responses", "write"), controllers.UpdateTicketCannedResponse)
+		adminTickets.DELETE("/canned-responses/:responseId", middleware.CheckPolicy("/admin/tickets/canned-responses", "write"), controllers.DeleteTicketCannedResponse)
 		adminTickets.GET("/:id", middleware.CheckPolicy("/admin/tickets/:id", "read"), controllers.GetAdminTicket)
 		adminTickets.PUT("/:id/resolve", middleware.CheckPolicy("/admin/tickets/:id/resolve", "write"), controllers.ResolveTicket)
+		adminTickets.PUT("/:id/reopen", middleware.CheckPolicy("/admin/tickets/:id/resolve", "write"), controllers.AdminReopenTicket)
+		adminTickets.PUT("/:id/claim", middleware.CheckPolicy("/admin/tickets/:id/claim", "write"), controllers.ClaimTicket)
+		adminTickets.PUT("/:id/unclaim", middleware.CheckPolicy("/admin/tickets/:id/claim", "write"), controllers.UnclaimTicket)
+		adminTickets.PUT("/:id/assign", middleware.CheckPolicy("/admin/tickets/:id/assign", "write"), controllers.AssignTicket)
+		adminTickets.PUT("/:id/triage", middleware.CheckPolicy("/admin/tickets/:id/triage", "write"), controllers.UpdateTicketTriage)
 		adminTickets.POST("/:id/messages", middleware.CheckPolicy("/admin/tickets/:id/messages", "write"), controllers.AdminReplyTicket)
 		adminTickets.DELETE("/:id", middleware.CheckPolicy("/admin/tickets/:id", "write"), controllers.DeleteTicket)
 	}
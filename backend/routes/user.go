@@ -24,6 +24,8 @@ func RegisterUserRoutes(router *gin.Engine) {
 		users.POST("", middleware.CheckPolicy("/users", "write"), controllers.CreateUser)
 		users.PUT("/:id", middleware.CheckPolicy("/users/:id", "write"), controllers.UpdateUser)
 		users.DELETE("/:id", middleware.CheckPolicy("/users/:id", "write"), controllers.DeleteUser)
+		users.PUT("/:id/role", middleware.DemoRestriction, middleware.CheckPolicy("/users/:id/role", "write"), controllers.SetUserRole)
 		users.POST("/:id/ban", middleware.CheckPolicy("/users/:id/ban", "write"), controllers.BanOrUnbanUser)
+		users.DELETE("/:id/2fa", middleware.CheckPolicy("/users/:id/2fa", "write"), controllers.ResetUserTwoFactor)
 	}
 }
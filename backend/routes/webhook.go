@@ -10,14 +10,14 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/pwnthemall/pwnthemall/backend/controllers"
-	"github.com/pwnthemall/pwnthemall/backend/debug"
+	"github.com/pwnthemall/pwnthemall/backend/logger"
 )
 
 func tokenAuthMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
-			debug.Log("Webhook auth failed: missing Authorization header")
+			logger.Ctx(c).Warn("Webhook auth failed: missing Authorization header")
 			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Authorization header required"})
 			return
 		}
@@ -26,11 +26,11 @@ func tokenAuthMiddleware() gin.HandlerFunc {
 		expectedToken := os.Getenv("MINIO_NOTIFY_WEBHOOK_AUTH_TOKEN_DBSYNC")
 
 		if expectedToken == "" {
-			debug.Log("WARNING: MINIO_NOTIFY_WEBHOOK_AUTH_TOKEN_DBSYNC not set, webhooks are insecure!")
+			logger.Ctx(c).Warn("MINIO_NOTIFY_WEBHOOK_AUTH_TOKEN_DBSYNC not set, webhooks are insecure!")
 		}
 
 		if len(parts) != 2 || parts[0] != "Bearer" || parts[1] != expectedToken {
-			debug.Log("Webhook auth failed: invalid token")
+			logger.Ctx(c).Warn("Webhook auth failed: invalid token")
 			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
 			return
 		}
@@ -51,7 +51,7 @@ func validateWebhookSignature(c *gin.Context, body []byte) bool {
 
 	// If secret is configured, signature must be present and valid
 	if signature == "" {
-		debug.Log("Webhook signature validation failed: missing X-Minio-Signature header")
+		logger.Ctx(c).Warn("Webhook signature validation failed: missing X-Minio-Signature header")
 		return false
 	}
 
@@ -62,7 +62,7 @@ func validateWebhookSignature(c *gin.Context, body []byte) bool {
 
 	// Constant-time comparison to prevent timing attacks
 	if !hmac.Equal([]byte(signature), []byte(expectedSignature)) {
-		debug.Log("Webhook signature validation failed: signature mismatch")
+		logger.Ctx(c).Warn("Webhook signature validation failed: signature mismatch")
 		return false
 	}
 
@@ -0,0 +1,41 @@
+package shared
+
+import "errors"
+
+// EventReceiver is implemented by plugins that consume platform events
+// The payload is the JSON envelope {"event", "timestamp", "data"} of the event
+type EventReceiver interface {
+	HandleEvent(name string, payload []byte) error
+}
+
+type HandleEventArgs struct {
+	Name    string
+	Payload []byte
+}
+
+type HandleEventResponse struct {
+	Error string
+}
+
+// HandleEvent sends a platform event to the plugin
+func (p *PluginRPC) HandleEvent(name string, payload []byte) error {
+	var resp HandleEventResponse
+	if err := p.client.Call("Plugin.HandleEvent", &HandleEventArgs{Name: name, Payload: payload}, &resp); err != nil {
+		return err
+	}
+	if resp.Error != "" {
+		return errors.New(resp.Error)
+	}
+	return nil
+}
+
+func (s *PluginRPCServer) HandleEvent(args *HandleEventArgs, resp *HandleEventResponse) error {
+	receiver, ok := s.Impl.(EventReceiver)
+	if !ok {
+		return nil
+	}
+	if err := receiver.HandleEvent(args.Name, args.Payload); err != nil {
+		resp.Error = err.Error()
+	}
+	return nil
+}
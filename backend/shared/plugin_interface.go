@@ -29,6 +29,7 @@ type PluginMetadata struct {
 	Author      string
 	Type        string            // "vm", "sso", "storage", etc.
 	EnvVars     map[string]string // Variables d'environnement du plugin
+	Events      []string          // Platform events forwarded to the plugin, "*" for all
 }
 
 type RouteInfo struct {
@@ -86,6 +87,7 @@ type ShutdownResponse struct {
 
 type PluginRPC struct {
 	client *rpc.Client
+	broker *plugin.MuxBroker
 }
 
 func (p *PluginRPC) GetMetadata() PluginMetadata {
@@ -139,6 +141,21 @@ func (p *PluginRPC) Shutdown() error {
 	return nil
 }
 
+// SetLogSink lets the plugin write to sink, the backend serves it on a broker connection
+func (p *PluginRPC) SetLogSink(sink LogSink) error {
+	id := p.broker.NextId()
+	go p.broker.AcceptAndServe(id, &LogSinkRPCServer{Sink: sink})
+
+	var resp SetLogSinkResponse
+	if err := p.client.Call("Plugin.SetLogSink", id, &resp); err != nil {
+		return err
+	}
+	if resp.Error != "" {
+		return errors.New(resp.Error)
+	}
+	return nil
+}
+
 func (p *PluginRPC) HandleRequest(handlerName string, request RequestData) (ResponseData, error) {
 	args := &HandleRequestArgs{
 		HandlerName: handlerName,
@@ -149,6 +166,23 @@ func (p *PluginRPC) HandleRequest(handlerName string, request RequestData) (Resp
 	return resp, err
 }
 
+type MapSSOIdentityResponse struct {
+	Mapping SSOMapping
+	Error   string
+}
+
+func (p *PluginRPC) MapIdentity(identity SSOIdentity) (SSOMapping, error) {
+	var resp MapSSOIdentityResponse
+	err := p.client.Call("Plugin.MapSSOIdentity", identity, &resp)
+	if err != nil {
+		return SSOMapping{}, err
+	}
+	if resp.Error != "" {
+		return SSOMapping{}, errors.New(resp.Error)
+	}
+	return resp.Mapping, nil
+}
+
 type RequestData struct {
 	Method  string
 	Path    string
@@ -183,7 +217,8 @@ type HandleRequestArgs struct {
 }
 
 type PluginRPCServer struct {
-	Impl Plugin
+	Impl   Plugin
+	broker *plugin.MuxBroker
 }
 
 func (s *PluginRPCServer) GetMetadata(args interface{}, resp *PluginMetadata) error {
@@ -214,6 +249,22 @@ func (s *PluginRPCServer) Shutdown(args interface{}, resp *ShutdownResponse) err
 	return nil
 }
 
+// SetLogSink dials the log sink served by the backend, the connection is closed when the plugin does not log
+func (s *PluginRPCServer) SetLogSink(id uint32, resp *SetLogSinkResponse) error {
+	conn, err := s.broker.Dial(id)
+	if err != nil {
+		resp.Error = err.Error()
+		return nil
+	}
+	receiver, ok := s.Impl.(LogSinkReceiver)
+	if !ok {
+		conn.Close()
+		return nil
+	}
+	receiver.SetLogSink(&LogSinkRPC{client: rpc.NewClient(conn)})
+	return nil
+}
+
 func (s *PluginRPCServer) HandleRequest(args *HandleRequestArgs, resp *ResponseData) error {
 	if handler, ok := s.Impl.(RequestHandler); ok {
 		result, err := handler.HandleRequest(args.HandlerName, args.Request)
@@ -223,6 +274,19 @@ func (s *PluginRPCServer) HandleRequest(args *HandleRequestArgs, resp *ResponseD
 	return nil
 }
 
+func (s *PluginRPCServer) MapSSOIdentity(identity SSOIdentity, resp *MapSSOIdentityResponse) error {
+	if handler, ok := s.Impl.(SSOHandler); ok {
+		mapping, err := handler.MapIdentity(identity)
+		resp.Mapping = mapping
+		if err != nil {
+			resp.Error = err.Error()
+		}
+		return nil
+	}
+	resp.Error = "plugin does not implement SSOHandler"
+	return nil
+}
+
 type RequestHandler interface {
 	HandleRequest(handlerName string, request RequestData) (ResponseData, error)
 }
@@ -231,10 +295,10 @@ type GenericPlugin struct {
 	Impl Plugin
 }
 
-func (p *GenericPlugin) Server(*plugin.MuxBroker) (interface{}, error) {
-	return &PluginRPCServer{Impl: p.Impl}, nil
+func (p *GenericPlugin) Server(b *plugin.MuxBroker) (interface{}, error) {
+	return &PluginRPCServer{Impl: p.Impl, broker: b}, nil
 }
 
 func (GenericPlugin) Client(b *plugin.MuxBroker, c *rpc.Client) (interface{}, error) {
-	return &PluginRPC{client: c}, nil
+	return &PluginRPC{client: c, broker: b}, nil
 }
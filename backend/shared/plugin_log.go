@@ -0,0 +1,47 @@
+package shared
+
+import (
+	"net/rpc"
+)
+
+// LogEntry is a log line written by a plugin to the backend logs
+type LogEntry struct {
+	Level   string // "debug", "info", "warn" or "error"
+	Message string
+	Fields  map[string]string
+}
+
+// LogSink receives the log entries of a plugin
+type LogSink interface {
+	Log(entry LogEntry) error
+}
+
+// LogSinkReceiver is implemented by plugins that write to the backend logs
+// SetLogSink is called before Initialize
+type LogSinkReceiver interface {
+	SetLogSink(sink LogSink)
+}
+
+type LogResponse struct{}
+
+type SetLogSinkResponse struct {
+	Error string
+}
+
+// LogSinkRPCServer serves a LogSink of the backend to a plugin through the broker
+type LogSinkRPCServer struct {
+	Sink LogSink
+}
+
+func (s *LogSinkRPCServer) Log(entry LogEntry, resp *LogResponse) error {
+	return s.Sink.Log(entry)
+}
+
+// LogSinkRPC is the LogSink handed to plugins, it forwards entries to the backend
+type LogSinkRPC struct {
+	client *rpc.Client
+}
+
+func (l *LogSinkRPC) Log(entry LogEntry) error {
+	return l.client.Call("Plugin.Log", entry, &LogResponse{})
+}
@@ -0,0 +1,49 @@
+package shared
+
+import "sync"
+
+// SSOIdentity is the identity verified by the SSO login flow
+type SSOIdentity struct {
+	Provider string
+	Subject  string
+	Email    string
+	Username string
+	Groups   []string
+	Claims   []byte // raw ID token claims as JSON
+}
+
+// SSOMapping is the decision of an SSO plugin for an identity, empty fields keep the default behaviour
+type SSOMapping struct {
+	Deny   bool
+	Reason string
+	Role   string
+	Team   string
+}
+
+// SSOHandler is implemented by "sso" plugins to map identities to roles and teams
+type SSOHandler interface {
+	MapIdentity(identity SSOIdentity) (SSOMapping, error)
+}
+
+var (
+	ssoHandlers   = make(map[string]SSOHandler)
+	ssoHandlersMu sync.RWMutex
+)
+
+func RegisterSSOHandler(name string, handler SSOHandler) {
+	ssoHandlersMu.Lock()
+	defer ssoHandlersMu.Unlock()
+	ssoHandlers[name] = handler
+}
+
+// ListSSOHandlers returns the registered SSO handlers
+func ListSSOHandlers() []SSOHandler {
+	ssoHandlersMu.RLock()
+	defer ssoHandlersMu.RUnlock()
+
+	handlers := make([]SSOHandler, 0, len(ssoHandlers))
+	for _, h := range ssoHandlers {
+		handlers = append(handlers, h)
+	}
+	return handlers
+}
@@ -0,0 +1,151 @@
+package utils
+
+import (
+	"time"
+
+	"github.com/pwnthemall/pwnthemall/backend/config"
+	"github.com/pwnthemall/pwnthemall/backend/events"
+	"github.com/pwnthemall/pwnthemall/backend/logger"
+	"github.com/pwnthemall/pwnthemall/backend/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	notificationPollInterval = 15 * time.Second
+	notificationBatchSize    = 50
+	// notificationClaimLease lets another replica retry a notification whose delivery failed or never finished
+	notificationClaimLease = 5 * time.Minute
+)
+
+// StartNotificationScheduler delivers scheduled notifications once their send time is reached
+func StartNotificationScheduler() {
+	go func() {
+		ticker := time.NewTicker(notificationPollInterval)
+		defer ticker.Stop()
+		for {
+			DeliverDueNotifications()
+			<-ticker.C
+		}
+	}()
+}
+
+// DeliverDueNotifications sends the pending notifications whose send time has passed
+func DeliverDueNotifications() {
+	for {
+		notifications, err := claimDueNotifications()
+		if err != nil {
+			logger.Errorf("Failed to claim scheduled notifications: %v", err)
+			return
+		}
+		for i := range notifications {
+			if err := DeliverNotification(&notifications[i]); err != nil {
+				logger.Errorf("Failed to deliver scheduled notification %d, retrying after the lease: %v", notifications[i].ID, err)
+			}
+		}
+		if len(notifications) < notificationBatchSize {
+			return
+		}
+	}
+}
+
+// claimDueNotifications leases a batch of due notifications so other replicas skip them,
+// they stay pending until delivered
+func claimDueNotifications() ([]models.Notification, error) {
+	var notifications []models.Notification
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("pending = ? AND send_at <= ?", true, now).
+			Where("claimed_until IS NULL OR claimed_until <= ?", now).
+			Order("send_at, id").Limit(notificationBatchSize).
+			Find(&notifications).Error; err != nil {
+			return err
+		}
+		if len(notifications) == 0 {
+			return nil
+		}
+		ids := make([]uint, len(notifications))
+		for i := range notifications {
+			ids[i] = notifications[i].ID
+		}
+		return tx.Model(&models.Notification{}).Where("id IN ?", ids).Update("claimed_until", now.Add(notificationClaimLease)).Error
+	})
+	return notifications, err
+}
+
+// DeliverNotification resolves the audience of a notification and publishes it
+// A scheduled notification leaves the pending state only once its audience is resolved
+func DeliverNotification(n *models.Notification) error {
+	var recipients []uint
+	if n.HasAudienceFilter() {
+		var err error
+		if recipients, err = resolveNotificationRecipients(n); err != nil {
+			return err
+		}
+	}
+	if n.Pending {
+		if err := config.DB.Model(&models.Notification{}).Where("id = ?", n.ID).
+			Updates(map[string]interface{}{"pending": false, "claimed_until": nil}).Error; err != nil {
+			return err
+		}
+		n.Pending = false
+	}
+
+	var senderID uint
+	if n.SenderID != nil {
+		senderID = *n.SenderID
+	}
+	events.Publish(events.NotificationSent{
+		NotificationID: n.ID,
+		Title:          n.Title,
+		Message:        n.Message,
+		Type:           n.Type,
+		UserID:         n.UserID,
+		TeamID:         n.TeamID,
+		Global:         n.IsGlobal(),
+		Recipients:     recipients,
+		SenderID:       senderID,
+		CreatedAt:      n.SentAt(),
+	})
+	return nil
+}
+
+// resolveNotificationRecipients stores the users matching the role, unsolved challenge and bracket filters of a notification
+func resolveNotificationRecipients(n *models.Notification) ([]uint, error) {
+	query := config.DB.Model(&models.User{}).Where("banned = ?", false)
+	if n.TargetRole != "" {
+		query = query.Where("role = ?", n.TargetRole)
+	}
+	if n.UnsolvedChallengeID != nil {
+		solvers := config.DB.Model(&models.Solve{}).Select("team_id").Where("challenge_id = ?", *n.UnsolvedChallengeID)
+		query = query.Where("team_id IS NULL OR team_id NOT IN (?)", solvers)
+	}
+	if n.TargetBracketID != nil {
+		query = query.Where("team_id IN (?)", config.DB.Model(&models.Team{}).Select("id").Where("bracket_id = ?", *n.TargetBracketID))
+	}
+
+	var userIDs []uint
+	if err := query.Pluck("id", &userIDs).Error; err != nil {
+		return nil, err
+	}
+	if len(userIDs) == 0 {
+		return userIDs, nil
+	}
+
+	rows := make([]models.NotificationRecipient, len(userIDs))
+	for i, id := range userIDs {
+		rows[i] = models.NotificationRecipient{NotificationID: n.ID, UserID: id}
+	}
+	if err := config.DB.Clauses(clause.OnConflict{DoNothing: true}).CreateInBatches(rows, 500).Error; err != nil {
+		return nil, err
+	}
+	return userIDs, nil
+}
+
+// NotificationRecipientIDs returns the users a targeted notification was delivered to
+func NotificationRecipientIDs(notificationID uint) []uint {
+	var userIDs []uint
+	config.DB.Model(&models.NotificationRecipient{}).Where("notification_id = ?", notificationID).Pluck("user_id", &userIDs)
+	return userIDs
+}
@@ -0,0 +1,63 @@
+package utils
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pwnthemall/pwnthemall/backend/config"
+	"github.com/pwnthemall/pwnthemall/backend/models"
+)
+
+const (
+	APITokenPrefix = "pta_"
+
+	ScopeSubmit         = "submit"
+	ScopeInstances      = "instances"
+	ScopeAdminRead      = "admin:read"
+	ScopeChallengesSync = "challenges:sync"
+
+	// apiTokenTouchInterval limits how often last_used_at is written
+	apiTokenTouchInterval = time.Minute
+)
+
+// APITokenScopes lists the scopes a token can be granted
+var APITokenScopes = []string{ScopeSubmit, ScopeInstances, ScopeAdminRead, ScopeChallengesSync}
+
+// GenerateAPIToken returns a new raw token and the hash to store
+func GenerateAPIToken() (string, string, error) {
+	raw, err := GenerateRandomString(32)
+	if err != nil {
+		return "", "", err
+	}
+	token := APITokenPrefix + raw
+	return token, HashFlag(token), nil
+}
+
+// BearerToken returns the token of an "Authorization: Bearer" header, or an empty string
+func BearerToken(c *gin.Context) string {
+	scheme, token, found := strings.Cut(c.GetHeader("Authorization"), " ")
+	if !found || !strings.EqualFold(scheme, "Bearer") {
+		return ""
+	}
+	return strings.TrimSpace(token)
+}
+
+// LookupAPIToken finds a valid token with its user and records its use
+func LookupAPIToken(raw string) (*models.APIToken, error) {
+	var token models.APIToken
+	if err := config.DB.Preload("User").Where("token_hash = ?", HashFlag(raw)).First(&token).Error; err != nil {
+		return nil, fmt.Errorf("invalid api token")
+	}
+	now := time.Now()
+	if token.User == nil || (token.ExpiresAt != nil && token.ExpiresAt.Before(now)) {
+		return nil, fmt.Errorf("invalid api token")
+	}
+
+	if token.LastUsedAt == nil || now.Sub(*token.LastUsedAt) > apiTokenTouchInterval {
+		token.LastUsedAt = &now
+		config.DB.Model(&token).UpdateColumn("last_used_at", now)
+	}
+	return &token, nil
+}
@@ -0,0 +1,116 @@
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pwnthemall/pwnthemall/backend/config"
+	"github.com/pwnthemall/pwnthemall/backend/logger"
+	"github.com/pwnthemall/pwnthemall/backend/models"
+)
+
+const auditRedacted = "[redacted]"
+
+// auditIgnoredFields change on every save and would only add noise to the diff
+var auditIgnoredFields = map[string]bool{
+	"updatedAt":  true,
+	"updated_at": true,
+}
+
+// RecordAudit appends an admin mutation to the audit log
+// before and after are snapshots of the target, nil for creations and deletions
+// A failure is logged and never fails the request that already succeeded
+func RecordAudit(c *gin.Context, action, targetType string, targetID interface{}, before, after interface{}) {
+	entry := models.AuditLog{
+		IPAddress:  c.ClientIP(),
+		Action:     action,
+		TargetType: targetType,
+		Changes:    AuditDiff(before, after),
+	}
+	if targetID != nil {
+		entry.TargetID = fmt.Sprint(targetID)
+	}
+	if user, ok := GetAuthenticatedUser(c); ok {
+		entry.ActorID = user.ID
+		entry.ActorName = user.Username
+		entry.ActorRole = user.Role
+	} else if userID, ok := GetAuthenticatedUserID(c); ok {
+		entry.ActorID = userID
+	}
+
+	if err := config.DB.Create(&entry).Error; err != nil {
+		logger.Ctx(c).Errorf("Failed to record audit entry %s on %s %s: %v", action, targetType, entry.TargetID, err)
+	}
+}
+
+// AuditDiff returns the fields that differ between two snapshots, secrets are redacted
+func AuditDiff(before, after interface{}) models.AuditChanges {
+	beforeFields := auditFields(before)
+	afterFields := auditFields(after)
+
+	changes := models.AuditChanges{}
+	for key, value := range afterFields {
+		if auditIgnoredFields[key] {
+			continue
+		}
+		previous, existed := beforeFields[key]
+		if existed && reflect.DeepEqual(previous, value) {
+			continue
+		}
+		changes[key] = auditChange(key, previous, value)
+	}
+	for key, previous := range beforeFields {
+		if _, exists := afterFields[key]; exists || auditIgnoredFields[key] {
+			continue
+		}
+		changes[key] = auditChange(key, previous, nil)
+	}
+	return changes
+}
+
+func auditChange(key string, before, after interface{}) models.AuditChange {
+	if isSecretField(key) {
+		if before != nil {
+			before = auditRedacted
+		}
+		if after != nil {
+			after = auditRedacted
+		}
+	}
+	return models.AuditChange{Before: before, After: after}
+}
+
+// auditFields flattens a snapshot to its JSON fields, scalars are stored under "value"
+func auditFields(snapshot interface{}) map[string]interface{} {
+	if snapshot == nil {
+		return nil
+	}
+	raw, err := json.Marshal(snapshot)
+	if err != nil {
+		return nil
+	}
+	var decoded interface{}
+	if err := json.Unmarshal(raw, &decoded); err != nil {
+		return nil
+	}
+	if fields, ok := decoded.(map[string]interface{}); ok {
+		return fields
+	}
+	if decoded == nil {
+		return nil
+	}
+	return map[string]interface{}{"value": decoded}
+}
+
+func isSecretField(key string) bool {
+	key = strings.ToLower(key)
+	for _, marker := range []string{"password", "secret", "token", "flag", "recovery"} {
+		if strings.Contains(key, marker) {
+			return true
+		}
+	}
+	return false
+}
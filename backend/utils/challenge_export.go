@@ -10,10 +10,63 @@ import (
 
 	"github.com/minio/minio-go/v7"
 	"github.com/pwnthemall/pwnthemall/backend/config"
-	"github.com/pwnthemall/pwnthemall/backend/debug"
+	"github.com/pwnthemall/pwnthemall/backend/logger"
 	"github.com/pwnthemall/pwnthemall/backend/models"
 )
 
+const (
+	bucketNameChallengeFiles = "challenge-files"
+)
+
+// BuildChallengeBundle exports a challenge as ZIP (chall.yml + files, flags excluded)
+// The challenge must be loaded with its category, difficulty, type, hints, tags and decay formula
+func BuildChallengeBundle(challenge models.Challenge) ([]byte, error) {
+	var buf bytes.Buffer
+	zipWriter := zip.NewWriter(&buf)
+
+	// Create chall.yml file (without flags for security)
+	challYml, err := zipWriter.Create("chall.yml")
+	if err != nil {
+		zipWriter.Close()
+		return nil, fmt.Errorf("failed to create chall.yml: %w", err)
+	}
+
+	// Generate YAML content
+	yamlContent := GenerateChallengeYAML(challenge)
+	if _, err := challYml.Write([]byte(yamlContent)); err != nil {
+		zipWriter.Close()
+		return nil, fmt.Errorf("failed to write chall.yml: %w", err)
+	}
+
+	// Try to get files from the challenge ZIP in MinIO (new system)
+	filesAdded := 0
+	zipData, err := GetChallengeZipFromMinIO(bucketNameChallengeFiles, challenge.Slug)
+	if err == nil {
+		// Extract and copy files from ZIP
+		count, err := CopyZipFilesToWriter(zipData, zipWriter)
+		if err != nil {
+			logger.Errorf("Failed to copy files from ZIP: %v", err)
+		} else {
+			filesAdded = count
+		}
+	} else {
+		logger.Debugf("Challenge ZIP not found: %v", err)
+	}
+
+	// Fallback: Try to copy individual files from old system
+	if filesAdded == 0 {
+		logger.Debugf("Trying to get files from old system for challenge %s", challenge.Slug)
+		CopyIndividualFilesFromMinIO(bucketNameChallenges, challenge.Slug, challenge.Files, zipWriter)
+	}
+
+	// Finalize ZIP
+	if err := zipWriter.Close(); err != nil {
+		return nil, fmt.Errorf("failed to close zip writer: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
 // GenerateChallengeYAML creates YAML content for challenge export (flags redacted)
 func GenerateChallengeYAML(challenge models.Challenge) string {
 	yaml := fmt.Sprintf("name: %s\n", challenge.Name)
@@ -56,6 +109,17 @@ func GenerateChallengeYAML(challenge models.Challenge) string {
 		yaml += fmt.Sprintf("cover_img: %s\n", challenge.CoverImg)
 	}
 
+	if len(challenge.Tags) > 0 {
+		yaml += "tags: ["
+		for i, tag := range challenge.Tags {
+			if i > 0 {
+				yaml += ", "
+			}
+			yaml += fmt.Sprintf("\"%s\"", tag.Name)
+		}
+		yaml += "]\n"
+	}
+
 	if len(challenge.ConnectionInfo) > 0 {
 		yaml += "connection_info: ["
 		for i, info := range challenge.ConnectionInfo {
@@ -86,19 +150,19 @@ func CopyZipFilesToWriter(zipData []byte, destWriter *zip.Writer) (int, error) {
 
 		rc, err := f.Open()
 		if err != nil {
-			debug.Log("Failed to open file %s from ZIP: %v", f.Name, err)
+			logger.Errorf("Failed to open file %s from ZIP: %v", f.Name, err)
 			continue
 		}
 
 		w, err := destWriter.Create(f.Name)
 		if err != nil {
 			rc.Close()
-			debug.Log("Failed to create file %s in export ZIP: %v", f.Name, err)
+			logger.Errorf("Failed to create file %s in export ZIP: %v", f.Name, err)
 			continue
 		}
 
 		if _, err := io.Copy(w, rc); err != nil {
-			debug.Log("Failed to copy file %s to export ZIP: %v", f.Name, err)
+			logger.Errorf("Failed to copy file %s to export ZIP: %v", f.Name, err)
 		}
 		rc.Close()
 		filesAdded++
@@ -148,19 +212,19 @@ func CopyIndividualFilesFromMinIO(bucket, challengeSlug string, filesList []stri
 		objectPath := fmt.Sprintf("%s/%s", challengeSlug, fileName)
 		obj, err := config.FS.GetObject(context.Background(), bucket, objectPath, minio.GetObjectOptions{})
 		if err != nil {
-			debug.Log("File %s not found in old system: %v", fileName, err)
+			logger.Debugf("File %s not found in old system: %v", fileName, err)
 			continue
 		}
 
 		fileWriter, err := destWriter.Create(fileName)
 		if err != nil {
 			obj.Close()
-			debug.Log("Failed to create file %s in ZIP: %v", fileName, err)
+			logger.Errorf("Failed to create file %s in ZIP: %v", fileName, err)
 			continue
 		}
 
 		if _, err := io.Copy(fileWriter, obj); err != nil {
-			debug.Log("Failed to copy file %s to ZIP: %v", fileName, err)
+			logger.Errorf("Failed to copy file %s to ZIP: %v", fileName, err)
 		}
 		obj.Close()
 		filesAdded++
@@ -179,7 +243,7 @@ func CopyIndividualFilesFromMinIO(bucket, challengeSlug string, filesList []stri
 
 	for object := range objectCh {
 		if object.Err != nil {
-			debug.Log("Error listing objects: %v", object.Err)
+			logger.Errorf("Error listing objects: %v", object.Err)
 			continue
 		}
 
@@ -201,19 +265,19 @@ func CopyIndividualFilesFromMinIO(bucket, challengeSlug string, filesList []stri
 
 		obj, err := config.FS.GetObject(context.Background(), bucket, object.Key, minio.GetObjectOptions{})
 		if err != nil {
-			debug.Log("Failed to get object %s: %v", object.Key, err)
+			logger.Errorf("Failed to get object %s: %v", object.Key, err)
 			continue
 		}
 
 		fileWriter, err := destWriter.Create(fileName)
 		if err != nil {
 			obj.Close()
-			debug.Log("Failed to create file %s in ZIP: %v", fileName, err)
+			logger.Errorf("Failed to create file %s in ZIP: %v", fileName, err)
 			continue
 		}
 
 		if _, err := io.Copy(fileWriter, obj); err != nil {
-			debug.Log("Failed to copy file %s to ZIP: %v", fileName, err)
+			logger.Errorf("Failed to copy file %s to ZIP: %v", fileName, err)
 		}
 		obj.Close()
 		filesAdded++
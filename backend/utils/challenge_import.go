@@ -0,0 +1,310 @@
+package utils
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/pwnthemall/pwnthemall/backend/config"
+	"github.com/pwnthemall/pwnthemall/backend/logger"
+	"github.com/pwnthemall/pwnthemall/backend/meta"
+	"github.com/pwnthemall/pwnthemall/backend/models"
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	importMaxFileSize  = 50 * 1024 * 1024  // 50MB per file
+	importMaxTotalSize = 200 * 1024 * 1024 // 200MB per challenge
+	importMaxChallYml  = 1024 * 1024       // 1MB for chall.yml, read in memory before any check
+)
+
+// Statuses reported for each challenge of a bulk import
+const (
+	ImportStatusValid   = "valid"
+	ImportStatusInvalid = "invalid"
+	ImportStatusCreated = "created"
+	ImportStatusUpdated = "updated"
+	ImportStatusFailed  = "failed"
+	ImportStatusSkipped = "skipped"
+)
+
+// ChallengeImportResult reports the outcome of a bulk import for one challenge folder
+type ChallengeImportResult struct {
+	Slug   string   `json:"slug"`
+	Name   string   `json:"name,omitempty"`
+	Type   string   `json:"type,omitempty"`
+	Files  int      `json:"files"`
+	Status string   `json:"status"`
+	Errors []string `json:"errors,omitempty"`
+}
+
+// challengeImportFolder is a challenge folder found in an import archive
+type challengeImportFolder struct {
+	slug     string
+	challYml *zip.File
+	files    map[string]*zip.File // relative path -> entry
+	result   *ChallengeImportResult
+}
+
+// ImportChallengesFromZip validates every slug/chall.yml folder of the archive and, when all of them are valid,
+// uploads them to the challenges bucket and syncs them to the database
+// Nothing is uploaded if at least one challenge is invalid or when dryRun is set
+func ImportChallengesFromZip(ctx context.Context, data []byte, dryRun bool, updatesHub *Hub) ([]ChallengeImportResult, bool, error) {
+	zipReader, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, false, fmt.Errorf("invalid archive: %w", err)
+	}
+
+	folders, err := collectImportFolders(zipReader)
+	if err != nil {
+		return nil, false, err
+	}
+	if len(folders) == 0 {
+		return nil, false, fmt.Errorf("no chall.yml found in archive")
+	}
+
+	valid := validateImportFolders(folders)
+
+	results := make([]ChallengeImportResult, 0, len(folders))
+	if !valid || dryRun {
+		for _, folder := range folders {
+			results = append(results, *folder.result)
+		}
+		return results, valid, nil
+	}
+
+	for _, folder := range folders {
+		uploadImportFolder(ctx, folder, updatesHub)
+		results = append(results, *folder.result)
+	}
+	return results, true, nil
+}
+
+// collectImportFolders groups archive entries by the folder containing their chall.yml
+func collectImportFolders(zipReader *zip.Reader) ([]*challengeImportFolder, error) {
+	byDir := make(map[string]*challengeImportFolder)
+	for _, f := range zipReader.File {
+		if path.Base(f.Name) != "chall.yml" || f.FileInfo().IsDir() {
+			continue
+		}
+		dir := path.Dir(f.Name)
+		if dir == "." {
+			return nil, fmt.Errorf("chall.yml must be inside a challenge folder")
+		}
+		slug := path.Base(dir)
+		byDir[dir] = &challengeImportFolder{
+			slug:     slug,
+			challYml: f,
+			files:    make(map[string]*zip.File),
+			result:   &ChallengeImportResult{Slug: slug, Status: ImportStatusValid},
+		}
+	}
+
+	for _, f := range zipReader.File {
+		if f.FileInfo().IsDir() {
+			continue
+		}
+		for dir, folder := range byDir {
+			if !strings.HasPrefix(f.Name, dir+"/") {
+				continue
+			}
+			rel := strings.TrimPrefix(f.Name, dir+"/")
+			if rel != "chall.yml" {
+				folder.files[rel] = f
+			}
+		}
+	}
+
+	folders := make([]*challengeImportFolder, 0, len(byDir))
+	for _, folder := range byDir {
+		folders = append(folders, folder)
+	}
+	sort.Slice(folders, func(i, j int) bool { return folders[i].slug < folders[j].slug })
+	return folders, nil
+}
+
+// validateImportFolders checks every folder and returns true when all of them are valid
+func validateImportFolders(folders []*challengeImportFolder) bool {
+	slugs := make(map[string]int)
+	names := make(map[string]int)
+	for _, folder := range folders {
+		validateImportFolder(folder)
+		slugs[folder.slug]++
+		if folder.result.Name != "" {
+			names[folder.result.Name]++
+		}
+	}
+
+	valid := true
+	for _, folder := range folders {
+		if slugs[folder.slug] > 1 {
+			folder.result.Errors = append(folder.result.Errors, "duplicate slug in archive")
+		}
+		if folder.result.Name != "" && names[folder.result.Name] > 1 {
+			folder.result.Errors = append(folder.result.Errors, "duplicate challenge name in archive")
+		}
+		if len(folder.result.Errors) > 0 {
+			folder.result.Status = ImportStatusInvalid
+			valid = false
+		}
+	}
+	return valid
+}
+
+// validateImportFolder parses chall.yml and checks its fields and referenced files
+func validateImportFolder(folder *challengeImportFolder) {
+	result := folder.result
+	addError := func(format string, args ...interface{}) {
+		result.Errors = append(result.Errors, fmt.Sprintf(format, args...))
+	}
+
+	if GenerateSlug(folder.slug) != folder.slug {
+		addError("invalid folder name %q (expected lowercase letters, digits and hyphens)", folder.slug)
+	}
+
+	// The zip reader refuses to read past the declared size, so the header is enough
+	if folder.challYml.UncompressedSize64 > importMaxChallYml {
+		addError("chall.yml exceeds maximum size (1MB)")
+		return
+	}
+	content, err := readZipEntry(folder.challYml)
+	if err != nil {
+		addError("failed to read chall.yml: %v", err)
+		return
+	}
+
+	var base meta.BaseChallengeMetadata
+	if err := yaml.Unmarshal(content, &base); err != nil {
+		addError("invalid YAML: %v", err)
+		return
+	}
+	metaData, _, geoMeta, err := parseChallengeByType(base, content, folder.slug+"/chall.yml")
+	if err != nil {
+		addError("invalid %s metadata: %v", base.Type, err)
+		return
+	}
+	result.Name = metaData.Name
+	result.Type = metaData.Type
+
+	if metaData.Name == "" {
+		addError("name is required")
+	}
+	if metaData.Category == "" {
+		addError("category is required")
+	}
+	if metaData.Difficulty == "" {
+		addError("difficulty is required")
+	}
+	if metaData.Type == "" {
+		addError("type is required")
+	}
+	if metaData.Points < 0 {
+		addError("points must be non-negative")
+	}
+	if metaData.Type == "geo" {
+		if geoMeta == nil || geoMeta.RadiusKm <= 0 {
+			addError("geo challenges require radius_km greater than 0")
+		} else if geoMeta.TargetLat < -90 || geoMeta.TargetLat > 90 || geoMeta.TargetLng < -180 || geoMeta.TargetLng > 180 {
+			addError("geo challenges require valid target_lat and target_lng")
+		}
+	} else if len(metaData.Flags) == 0 {
+		addError("at least one flag is required")
+	}
+	for _, flag := range metaData.Flags {
+		if _, err := StoredFlagValue(flag); err != nil {
+			addError("%v", err)
+		}
+	}
+	if metaData.Type != "" {
+		var cType models.ChallengeType
+		if err := config.DB.Where("name = ?", metaData.Type).First(&cType).Error; err != nil {
+			addError("unknown challenge type %q", metaData.Type)
+		}
+	}
+
+	var totalSize int64
+	for rel, f := range folder.files {
+		cleanPath := path.Clean(rel)
+		if strings.HasPrefix(cleanPath, "..") || path.IsAbs(cleanPath) {
+			addError("invalid file path: %s", rel)
+			continue
+		}
+		size := int64(f.UncompressedSize64)
+		if size > importMaxFileSize {
+			addError("file %s exceeds maximum size (50MB)", rel)
+		}
+		totalSize += size
+	}
+	if totalSize > importMaxTotalSize {
+		addError("total file size exceeds maximum (200MB)")
+	}
+
+	for _, fileName := range metaData.Files {
+		if _, ok := folder.files[path.Clean(fileName)]; !ok {
+			addError("file listed in chall.yml not found: %s", fileName)
+		}
+	}
+	if metaData.CoverImg != "" {
+		if _, ok := folder.files[path.Clean(metaData.CoverImg)]; !ok {
+			addError("cover image not found: %s", metaData.CoverImg)
+		}
+	}
+	result.Files = len(folder.files)
+}
+
+// uploadImportFolder uploads the folder files then its chall.yml, and syncs the challenge
+func uploadImportFolder(ctx context.Context, folder *challengeImportFolder, updatesHub *Hub) {
+	result := folder.result
+
+	var existing int64
+	config.DB.Model(&models.Challenge{}).Where(querySlug, folder.slug).Count(&existing)
+
+	fail := func(format string, args ...interface{}) {
+		msg := fmt.Sprintf(format, args...)
+		logger.Errorf("Challenge import %s failed: %s", folder.slug, msg)
+		result.Status = ImportStatusFailed
+		result.Errors = append(result.Errors, msg)
+	}
+
+	// chall.yml is uploaded last so files exist when the sync validates them
+	for rel, f := range folder.files {
+		if err := putImportEntry(ctx, path.Join(folder.slug, rel), f); err != nil {
+			fail("failed to upload %s: %v", rel, err)
+			return
+		}
+	}
+	if err := putImportEntry(ctx, path.Join(folder.slug, "chall.yml"), folder.challYml); err != nil {
+		fail("failed to upload chall.yml: %v", err)
+		return
+	}
+
+	if err := SyncChallengesFromMinIO(ctx, bucketNameChallenges+"/"+folder.slug+"/chall.yml", updatesHub); err != nil {
+		fail("sync failed: %v", err)
+		return
+	}
+
+	if existing > 0 {
+		result.Status = ImportStatusUpdated
+	} else {
+		result.Status = ImportStatusCreated
+	}
+}
+
+func putImportEntry(ctx context.Context, objectKey string, f *zip.File) error {
+	content, err := readZipEntry(f)
+	if err != nil {
+		return err
+	}
+	return putChallengeObject(ctx, objectKey, content)
+}
+
+// putChallengeObject stores an object in the challenges bucket
+func putChallengeObject(ctx context.Context, objectKey string, content []byte) error {
+	_, err := config.FS.PutObject(ctx, bucketNameChallenges, objectKey, bytes.NewReader(content), int64(len(content)), minio.PutObjectOptions{})
+	return err
+}
@@ -1,10 +1,15 @@
 package utils
 
 import (
+	"fmt"
 	"math"
+	"regexp"
 	"strings"
 )
 
+// regexFlagPrefix marks flags matched as regular expressions (e.g. regex:CTF\{[a-z]+\})
+const regexFlagPrefix = "regex:"
+
 // IsWithinRadiusKm returns true if (lat2,lng2) is within radiusKm of (lat1,lng1)
 func IsWithinRadiusKm(lat1, lng1, lat2, lng2, radiusKm float64) bool {
 	const earthRadiusKm = 6371.0
@@ -21,6 +26,29 @@ func degreesToRadians(deg float64) float64 { return deg * math.Pi / 180.0 }
 // helpers for encoded geo flags (if needed)
 func IsGeoFlag(hashedOrRaw string) bool { return strings.HasPrefix(hashedOrRaw, "geo:") }
 
+// IsRegexFlag returns true if the flag is a regular expression, regex flags are stored in clear
+func IsRegexFlag(flag string) bool { return strings.HasPrefix(flag, regexFlagPrefix) }
+
+// StoredFlagValue returns the value to store for a flag: regex flags are kept as is, others are hashed
+func StoredFlagValue(flag string) (string, error) {
+	if IsRegexFlag(flag) {
+		if _, err := regexp.Compile(strings.TrimPrefix(flag, regexFlagPrefix)); err != nil {
+			return "", fmt.Errorf("invalid regex flag: %w", err)
+		}
+		return flag, nil
+	}
+	return HashFlag(flag), nil
+}
+
+// MatchRegexFlag returns true if the whole submitted value matches the stored regex flag
+func MatchRegexFlag(storedFlag, submitted string) bool {
+	re, err := regexp.Compile("^(?:" + strings.TrimPrefix(storedFlag, regexFlagPrefix) + ")$")
+	if err != nil {
+		return false
+	}
+	return re.MatchString(submitted)
+}
+
 // ParseGeoSpecFromHashed is a placeholder; actual flags are hashed, so we can't reverse.
 // Kept for compatibility with controller checks; return false by default.
 func ParseGeoSpecFromHashed(_ string) (float64, float64, float64, bool) { return 0, 0, 0, false }
@@ -1,9 +1,12 @@
 package utils
 
 import (
+	"crypto/hmac"
 	"crypto/rand"
 	"crypto/sha256"
 	"encoding/hex"
+
+	"golang.org/x/crypto/bcrypt"
 )
 
 func HashFlag(flag string) string {
@@ -11,10 +14,31 @@ func HashFlag(flag string) string {
 	return hex.EncodeToString(hash[:])
 }
 
+// DeriveSecret derives a signing key from the access secret
+// Tokens signed with it are never accepted as access or refresh tokens
+func DeriveSecret(label string) []byte {
+	mac := hmac.New(sha256.New, AccessSecret)
+	mac.Write([]byte(label))
+	return mac.Sum(nil)
+}
+
 func GenerateRandomString(n int) (string, error) {
 	bytes := make([]byte, n)
 	if _, err := rand.Read(bytes); err != nil {
 		return "", err
 	}
 	return hex.EncodeToString(bytes), nil
-}
\ No newline at end of file
+}
+
+// RandomPasswordHash returns the bcrypt hash of a random password nobody knows
+func RandomPasswordHash() (string, error) {
+	password, err := GenerateRandomString(24)
+	if err != nil {
+		return "", err
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", err
+	}
+	return string(hash), nil
+}
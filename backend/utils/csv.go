@@ -0,0 +1,11 @@
+package utils
+
+import "strings"
+
+// SanitizeCSVField neutralizes values that spreadsheet software would run as formulas
+func SanitizeCSVField(value string) string {
+	if value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
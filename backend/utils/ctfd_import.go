@@ -0,0 +1,668 @@
+package utils
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pwnthemall/pwnthemall/backend/config"
+	"github.com/pwnthemall/pwnthemall/backend/logger"
+	"github.com/pwnthemall/pwnthemall/backend/meta"
+	"github.com/pwnthemall/pwnthemall/backend/models"
+	"gopkg.in/yaml.v2"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// CTFdImportReport summarizes a CTFd export import
+type CTFdImportReport struct {
+	Challenges []ChallengeImportResult `json:"challenges"`
+	Counts     map[string]int          `json:"counts"`
+	Warnings   []string                `json:"warnings"`
+}
+
+func (r *CTFdImportReport) warn(format string, args ...interface{}) {
+	msg := fmt.Sprintf(format, args...)
+	logger.Warnf("CTFd import warning: %s", msg)
+	r.Warnings = append(r.Warnings, msg)
+}
+
+// ctfdTime parses the datetime formats found in CTFd exports
+type ctfdTime struct{ time.Time }
+
+func (t *ctfdTime) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil || s == "" {
+		return nil
+	}
+	for _, layout := range []string{time.RFC3339Nano, "2006-01-02T15:04:05.999999", "2006-01-02 15:04:05.999999"} {
+		if parsed, err := time.Parse(layout, s); err == nil {
+			t.Time = parsed
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid date %q", s)
+}
+
+// ctfdBool accepts booleans and 0/1 integers (MySQL exports)
+type ctfdBool bool
+
+func (b *ctfdBool) UnmarshalJSON(data []byte) error {
+	switch strings.TrimSpace(string(data)) {
+	case "true", "1":
+		*b = true
+	default:
+		*b = false
+	}
+	return nil
+}
+
+type ctfdChallenge struct {
+	ID             int             `json:"id"`
+	Name           string          `json:"name"`
+	Description    string          `json:"description"`
+	ConnectionInfo *string         `json:"connection_info"`
+	MaxAttempts    *int            `json:"max_attempts"`
+	Value          *int            `json:"value"`
+	Initial        *int            `json:"initial"`
+	Category       string          `json:"category"`
+	Type           string          `json:"type"`
+	State          string          `json:"state"`
+	Requirements   json.RawMessage `json:"requirements"`
+}
+
+type ctfdFlag struct {
+	ChallengeID int    `json:"challenge_id"`
+	Type        string `json:"type"`
+	Content     string `json:"content"`
+	Data        string `json:"data"`
+}
+
+type ctfdHint struct {
+	ChallengeID int     `json:"challenge_id"`
+	Title       *string `json:"title"`
+	Content     string  `json:"content"`
+	Cost        int     `json:"cost"`
+}
+
+type ctfdFile struct {
+	Type        string `json:"type"`
+	Location    string `json:"location"`
+	ChallengeID *int   `json:"challenge_id"`
+}
+
+type ctfdTag struct {
+	ChallengeID int    `json:"challenge_id"`
+	Value       string `json:"value"`
+}
+
+type ctfdUser struct {
+	ID       int      `json:"id"`
+	Name     string   `json:"name"`
+	Email    *string  `json:"email"`
+	Type     string   `json:"type"`
+	Verified ctfdBool `json:"verified"`
+	Banned   ctfdBool `json:"banned"`
+	TeamID   *int     `json:"team_id"`
+	Website  *string  `json:"website"`
+	Created  ctfdTime `json:"created"`
+}
+
+type ctfdTeam struct {
+	ID        int      `json:"id"`
+	Name      string   `json:"name"`
+	CaptainID *int     `json:"captain_id"`
+	Created   ctfdTime `json:"created"`
+}
+
+type ctfdSubmission struct {
+	ID          int      `json:"id"`
+	ChallengeID int      `json:"challenge_id"`
+	UserID      *int     `json:"user_id"`
+	TeamID      *int     `json:"team_id"`
+	Provided    string   `json:"provided"`
+	Type        string   `json:"type"`
+	Date        ctfdTime `json:"date"`
+}
+
+// ctfdExport holds the tables of a CTFd export used by the importer
+type ctfdExport struct {
+	Challenges  []ctfdChallenge
+	Flags       []ctfdFlag
+	Hints       []ctfdHint
+	Files       []ctfdFile
+	Tags        []ctfdTag
+	Users       []ctfdUser
+	Teams       []ctfdTeam
+	Submissions []ctfdSubmission
+	Solves      []ctfdSubmission
+	uploads     map[string]*zip.File // location -> archive entry
+}
+
+// ImportCTFdExport imports a CTFd export ZIP: challenges are uploaded to MinIO with a generated chall.yml
+// and synced, then users, teams, submissions and solves are created in a single transaction
+// CTFd password hashes are not compatible, imported users get a random password
+// CTFd admins are imported as members unless keepAdmins is set
+func ImportCTFdExport(ctx context.Context, data []byte, keepAdmins bool, updatesHub *Hub) (*CTFdImportReport, error) {
+	export, err := readCTFdExport(data)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &CTFdImportReport{
+		Challenges: []ChallengeImportResult{},
+		Counts:     make(map[string]int),
+		Warnings:   []string{},
+	}
+
+	challengeIDs := importCTFdChallenges(ctx, export, report, updatesHub)
+
+	if err := config.DB.Transaction(func(tx *gorm.DB) error {
+		return importCTFdPlayers(tx, export, challengeIDs, keepAdmins, report)
+	}); err != nil {
+		return report, err
+	}
+	return report, nil
+}
+
+// readCTFdExport loads the db/*.json tables and indexes the uploads of the export
+func readCTFdExport(data []byte) (*ctfdExport, error) {
+	zipReader, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("invalid archive: %w", err)
+	}
+
+	export := &ctfdExport{uploads: make(map[string]*zip.File)}
+	tables := map[string]interface{}{
+		"challenges":  &export.Challenges,
+		"flags":       &export.Flags,
+		"hints":       &export.Hints,
+		"files":       &export.Files,
+		"tags":        &export.Tags,
+		"users":       &export.Users,
+		"teams":       &export.Teams,
+		"submissions": &export.Submissions,
+		"solves":      &export.Solves,
+	}
+
+	found := false
+	for _, f := range zipReader.File {
+		if strings.HasPrefix(f.Name, "uploads/") && !f.FileInfo().IsDir() {
+			export.uploads[strings.TrimPrefix(f.Name, "uploads/")] = f
+			continue
+		}
+		if path.Dir(f.Name) != "db" {
+			continue
+		}
+		dest, ok := tables[strings.TrimSuffix(path.Base(f.Name), ".json")]
+		if !ok {
+			continue
+		}
+		content, err := readZipEntry(f)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", f.Name, err)
+		}
+		// CTFd tables are wrapped as {"count": n, "results": [...]}
+		var table struct {
+			Results json.RawMessage `json:"results"`
+		}
+		if err := json.Unmarshal(content, &table); err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", f.Name, err)
+		}
+		if len(table.Results) == 0 {
+			continue
+		}
+		if err := json.Unmarshal(table.Results, dest); err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", f.Name, err)
+		}
+		if f.Name == "db/challenges.json" {
+			found = true
+		}
+	}
+
+	if !found {
+		return nil, fmt.Errorf("not a CTFd export: db/challenges.json not found")
+	}
+	return export, nil
+}
+
+// importCTFdChallenges uploads every challenge folder to MinIO and syncs it, returning CTFd ID -> challenge ID
+func importCTFdChallenges(ctx context.Context, export *ctfdExport, report *CTFdImportReport, updatesHub *Hub) map[int]uint {
+	challengeIDs := make(map[int]uint)
+
+	names := make(map[int]string)
+	for _, ch := range export.Challenges {
+		names[ch.ID] = ch.Name
+	}
+
+	for _, ch := range export.Challenges {
+		result := ChallengeImportResult{Name: ch.Name, Type: "standard"}
+
+		var existing models.Challenge
+		if err := config.DB.Where("name = ?", ch.Name).First(&existing).Error; err == nil {
+			result.Slug = existing.Slug
+			result.Status = ImportStatusSkipped
+			result.Errors = []string{"a challenge with this name already exists"}
+			report.Challenges = append(report.Challenges, result)
+			continue
+		}
+
+		slug, err := GenerateUniqueSlug(ch.Name)
+		if err != nil {
+			result.Status = ImportStatusInvalid
+			result.Errors = []string{err.Error()}
+			report.Challenges = append(report.Challenges, result)
+			continue
+		}
+		result.Slug = slug
+
+		challYml, files := buildCTFdChallenge(ch, export, names, report)
+		result.Files = len(files)
+		content, err := yaml.Marshal(challYml)
+		if err != nil {
+			result.Status = ImportStatusFailed
+			result.Errors = []string{err.Error()}
+			report.Challenges = append(report.Challenges, result)
+			continue
+		}
+
+		if err := uploadCTFdChallenge(ctx, slug, content, files, updatesHub); err != nil {
+			result.Status = ImportStatusFailed
+			result.Errors = []string{err.Error()}
+			report.Challenges = append(report.Challenges, result)
+			continue
+		}
+
+		var created models.Challenge
+		if err := config.DB.Where(querySlug, slug).First(&created).Error; err != nil {
+			result.Status = ImportStatusFailed
+			result.Errors = []string{"challenge not found after sync"}
+			report.Challenges = append(report.Challenges, result)
+			continue
+		}
+		challengeIDs[ch.ID] = created.ID
+		result.Status = ImportStatusCreated
+		report.Challenges = append(report.Challenges, result)
+		report.Counts["challenges"]++
+	}
+	return challengeIDs
+}
+
+// buildCTFdChallenge maps a CTFd challenge to chall.yml metadata and its files
+func buildCTFdChallenge(ch ctfdChallenge, export *ctfdExport, names map[int]string, report *CTFdImportReport) (meta.BaseChallengeMetadata, map[string][]byte) {
+	base := meta.BaseChallengeMetadata{
+		Name:        ch.Name,
+		Description: ch.Description,
+		Category:    ch.Category,
+		Difficulty:  "Imported",
+		Type:        "standard",
+		Hidden:      ch.State == "hidden",
+	}
+	if base.Category == "" {
+		base.Category = "Imported"
+	}
+	if ch.Type != "standard" && ch.Type != "dynamic" {
+		report.warn("challenge %s: CTFd type %q imported as standard", ch.Name, ch.Type)
+	}
+	switch {
+	case ch.Value != nil:
+		base.Points = *ch.Value
+	case ch.Initial != nil:
+		base.Points = *ch.Initial
+	}
+	if ch.Type == "dynamic" {
+		report.warn("challenge %s: dynamic scoring not imported, using %d points without decay", ch.Name, base.Points)
+	}
+	if ch.MaxAttempts != nil {
+		base.Attempts = *ch.MaxAttempts
+	}
+	if ch.ConnectionInfo != nil && *ch.ConnectionInfo != "" {
+		base.ConnectionInfo = []string{*ch.ConnectionInfo}
+	}
+
+	// Requirements may be stored as an object or as a JSON encoded string
+	var requirements struct {
+		Prerequisites []int `json:"prerequisites"`
+	}
+	raw := ch.Requirements
+	var encoded string
+	if json.Unmarshal(raw, &encoded) == nil {
+		raw = json.RawMessage(encoded)
+	}
+	if len(raw) > 0 && json.Unmarshal(raw, &requirements) == nil && len(requirements.Prerequisites) > 0 {
+		base.DependsOn = names[requirements.Prerequisites[0]]
+		if len(requirements.Prerequisites) > 1 {
+			report.warn("challenge %s: only the first prerequisite is kept", ch.Name)
+		}
+	}
+
+	for _, flag := range export.Flags {
+		if flag.ChallengeID != ch.ID {
+			continue
+		}
+		switch {
+		case flag.Type == "regex":
+			pattern := flag.Content
+			if flag.Data == "case_insensitive" {
+				pattern = "(?i)" + pattern
+			}
+			base.Flags = append(base.Flags, regexFlagPrefix+pattern)
+		case flag.Data == "case_insensitive":
+			base.Flags = append(base.Flags, regexFlagPrefix+"(?i)"+regexp.QuoteMeta(flag.Content))
+		default:
+			base.Flags = append(base.Flags, flag.Content)
+		}
+	}
+
+	isActive := true
+	for _, hint := range export.Hints {
+		if hint.ChallengeID != ch.ID {
+			continue
+		}
+		title := "Hint"
+		if hint.Title != nil && *hint.Title != "" {
+			title = *hint.Title
+		}
+		base.Hints = append(base.Hints, meta.HintMetadata{
+			Title:    title,
+			Content:  hint.Content,
+			Cost:     hint.Cost,
+			IsActive: &isActive,
+		})
+	}
+
+	files := make(map[string][]byte)
+	for _, file := range export.Files {
+		if file.Type != "challenge" || file.ChallengeID == nil || *file.ChallengeID != ch.ID {
+			continue
+		}
+		entry, ok := export.uploads[file.Location]
+		if !ok {
+			report.warn("challenge %s: file %s missing from export", ch.Name, file.Location)
+			continue
+		}
+		content, err := readZipEntry(entry)
+		if err != nil {
+			report.warn("challenge %s: failed to read %s: %v", ch.Name, file.Location, err)
+			continue
+		}
+		name := path.Base(file.Location)
+		for i := 2; files[name] != nil; i++ {
+			name = fmt.Sprintf("%d_%s", i, path.Base(file.Location))
+		}
+		files[name] = content
+		base.Files = append(base.Files, name)
+	}
+	sort.Strings(base.Files)
+
+	for _, tag := range export.Tags {
+		if tag.ChallengeID == ch.ID && tag.Value != "" {
+			base.Tags = append(base.Tags, tag.Value)
+		}
+	}
+	return base, files
+}
+
+// uploadCTFdChallenge uploads files then chall.yml, and syncs the challenge
+func uploadCTFdChallenge(ctx context.Context, slug string, challYml []byte, files map[string][]byte, updatesHub *Hub) error {
+	for name, content := range files {
+		if err := putChallengeObject(ctx, path.Join(slug, name), content); err != nil {
+			return fmt.Errorf("failed to upload %s: %w", name, err)
+		}
+	}
+	if err := putChallengeObject(ctx, path.Join(slug, "chall.yml"), challYml); err != nil {
+		return fmt.Errorf("failed to upload chall.yml: %w", err)
+	}
+	return SyncChallengesFromMinIO(ctx, bucketNameChallenges+"/"+slug+"/chall.yml", updatesHub)
+}
+
+// importCTFdPlayers creates users, teams, submissions and solves
+// Users and teams share one random password hash nobody knows
+func importCTFdPlayers(tx *gorm.DB, export *ctfdExport, challengeIDs map[int]uint, keepAdmins bool, report *CTFdImportReport) error {
+	lockedPassword, err := RandomPasswordHash()
+	if err != nil {
+		return err
+	}
+
+	userIDs := make(map[int]uint)
+	usernames := make(map[uint]string)
+
+	for _, u := range export.Users {
+		username := u.Name
+		if len(username) > 32 {
+			username = username[:32]
+		}
+		email := fmt.Sprintf("ctfd-user-%d@import.invalid", u.ID)
+		if u.Email != nil && *u.Email != "" {
+			email = *u.Email
+		}
+
+		var user models.User
+		if err := tx.Where("username = ?", username).First(&user).Error; err == nil {
+			if user.Email != email {
+				report.warn("user %s: username already taken by another account, skipped", username)
+				continue
+			}
+			userIDs[u.ID] = user.ID
+			usernames[user.ID] = user.Username
+			report.Counts["users_merged"]++
+			continue
+		}
+
+		role := "member"
+		if u.Type == "admin" {
+			if keepAdmins {
+				role = "admin"
+			} else {
+				report.warn("user %s: CTFd admin imported as member", username)
+			}
+		}
+		user = models.User{
+			Username:      username,
+			Email:         email,
+			EmailVerified: bool(u.Verified),
+			Password:      lockedPassword,
+			Role:          role,
+			Banned:        bool(u.Banned),
+			CreatedAt:     u.Created.Time,
+		}
+		if u.Website != nil {
+			user.SocialLinks.Website = *u.Website
+		}
+		if err := tx.Omit(clause.Associations).Create(&user).Error; err != nil {
+			return fmt.Errorf("failed to create user %s: %w", username, err)
+		}
+		userIDs[u.ID] = user.ID
+		usernames[user.ID] = user.Username
+		report.Counts["users"]++
+	}
+
+	teamIDs, err := importCTFdTeams(tx, export, userIDs, usernames, lockedPassword, report)
+	if err != nil {
+		return err
+	}
+
+	// Team of a CTFd user: the CTFd team, or a solo team created in user mode
+	teamOf := func(s ctfdSubmission) (uint, bool) {
+		if s.TeamID != nil {
+			id, ok := teamIDs.teams[*s.TeamID]
+			return id, ok
+		}
+		if s.UserID != nil {
+			id, ok := teamIDs.solo[*s.UserID]
+			return id, ok
+		}
+		return 0, false
+	}
+
+	// Submissions hold the solve dates, solves share their IDs
+	dates := make(map[int]time.Time)
+	for _, s := range export.Submissions {
+		dates[s.ID] = s.Date.Time
+		if s.UserID == nil {
+			continue
+		}
+		userID, okUser := userIDs[*s.UserID]
+		challengeID, okChallenge := challengeIDs[s.ChallengeID]
+		if !okUser || !okChallenge {
+			continue
+		}
+		isCorrect := s.Type == "correct"
+		value := s.Provided
+		if isCorrect {
+			value = HashFlag(value)
+		}
+		submission := models.Submission{
+			Value: value, IsCorrect: isCorrect, UserID: userID, ChallengeID: challengeID, CreatedAt: s.Date.Time,
+		}
+		if err := tx.Omit(clause.Associations).Create(&submission).Error; err != nil {
+			return fmt.Errorf("failed to create submission: %w", err)
+		}
+		report.Counts["submissions"]++
+	}
+
+	var points = make(map[uint]int)
+	for _, s := range export.Solves {
+		challengeID, okChallenge := challengeIDs[s.ChallengeID]
+		teamID, okTeam := teamOf(s)
+		if s.UserID == nil || !okChallenge || !okTeam {
+			report.warn("skipped solve %d: unknown challenge, user or team", s.ID)
+			continue
+		}
+		userID, okUser := userIDs[*s.UserID]
+		if !okUser {
+			report.warn("skipped solve %d: unknown user", s.ID)
+			continue
+		}
+		if _, ok := points[challengeID]; !ok {
+			var challenge models.Challenge
+			if err := tx.Select("points").First(&challenge, challengeID).Error; err != nil {
+				return err
+			}
+			points[challengeID] = challenge.Points
+		}
+		createdAt := dates[s.ID]
+		if !s.Date.IsZero() {
+			createdAt = s.Date.Time
+		}
+		solve := models.Solve{
+			TeamID: teamID, ChallengeID: challengeID, UserID: userID,
+			Points: points[challengeID], SolvedBy: usernames[userID], CreatedAt: createdAt,
+		}
+		result := tx.Omit(clause.Associations).Clauses(clause.OnConflict{DoNothing: true}).Create(&solve)
+		if result.Error != nil {
+			return fmt.Errorf("failed to create solve: %w", result.Error)
+		}
+		if result.RowsAffected > 0 {
+			report.Counts["solves"]++
+		}
+	}
+	return nil
+}
+
+// ctfdTeamIDs maps CTFd teams, and CTFd users playing alone, to teams
+type ctfdTeamIDs struct {
+	teams map[int]uint
+	solo  map[int]uint
+}
+
+// importCTFdTeams creates CTFd teams, and solo teams for users who solved challenges without a team
+func importCTFdTeams(tx *gorm.DB, export *ctfdExport, userIDs map[int]uint, usernames map[uint]string, password string, report *CTFdImportReport) (*ctfdTeamIDs, error) {
+	ids := &ctfdTeamIDs{teams: make(map[int]uint), solo: make(map[int]uint)}
+
+	members := make(map[int][]int)
+	for _, u := range export.Users {
+		if u.TeamID != nil {
+			members[*u.TeamID] = append(members[*u.TeamID], u.ID)
+		}
+	}
+
+	for _, t := range export.Teams {
+		creator := 0
+		if t.CaptainID != nil {
+			creator = *t.CaptainID
+		} else if len(members[t.ID]) > 0 {
+			creator = members[t.ID][0]
+		}
+		creatorID, ok := userIDs[creator]
+		if !ok {
+			report.warn("skipped team %s: no imported captain or member", t.Name)
+			continue
+		}
+		teamID, err := createCTFdTeam(tx, t.Name, password, creatorID, t.Created.Time)
+		if err != nil {
+			return nil, err
+		}
+		ids.teams[t.ID] = teamID
+		report.Counts["teams"]++
+
+		for _, member := range members[t.ID] {
+			if userID, ok := userIDs[member]; ok {
+				if err := tx.Model(&models.User{}).Where("id = ? AND team_id IS NULL", userID).Update("team_id", teamID).Error; err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+
+	// User mode: every user with a solve and no team gets a team of their own
+	for _, s := range export.Solves {
+		if s.TeamID != nil || s.UserID == nil {
+			continue
+		}
+		if _, done := ids.solo[*s.UserID]; done {
+			continue
+		}
+		userID, ok := userIDs[*s.UserID]
+		if !ok {
+			continue
+		}
+		var user models.User
+		if err := tx.First(&user, userID).Error; err != nil {
+			return nil, err
+		}
+		if user.TeamID != nil {
+			ids.solo[*s.UserID] = *user.TeamID
+			continue
+		}
+		teamID, err := createCTFdTeam(tx, usernames[userID], password, userID, user.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		if err := tx.Model(&models.User{}).Where("id = ?", userID).Update("team_id", teamID).Error; err != nil {
+			return nil, err
+		}
+		ids.solo[*s.UserID] = teamID
+		report.Counts["solo_teams"]++
+	}
+	return ids, nil
+}
+
+// createCTFdTeam creates a team, suffixing its name if already taken
+func createCTFdTeam(tx *gorm.DB, name, password string, creatorID uint, createdAt time.Time) (uint, error) {
+	teamName := name
+	for i := 2; ; i++ {
+		var count int64
+		if err := tx.Model(&models.Team{}).Where("name = ?", teamName).Count(&count).Error; err != nil {
+			return 0, err
+		}
+		if count == 0 {
+			break
+		}
+		teamName = name + " (" + strconv.Itoa(i) + ")"
+	}
+
+	team := models.Team{Name: teamName, Password: password, CreatorID: creatorID, CreatedAt: createdAt}
+	if err := tx.Omit(clause.Associations).Create(&team).Error; err != nil {
+		return 0, fmt.Errorf("failed to create team %s: %w", teamName, err)
+	}
+	return team.ID, nil
+}
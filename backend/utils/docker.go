@@ -7,12 +7,13 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
-	
+
 	"net"
 	"os"
 	"path/filepath"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/compose-spec/compose-go/v2/loader"
 	"github.com/compose-spec/compose-go/v2/types"
@@ -27,7 +28,8 @@ import (
 	"github.com/docker/docker/api/types/network"
 	"github.com/docker/go-connections/nat"
 	"github.com/pwnthemall/pwnthemall/backend/config"
-	"github.com/pwnthemall/pwnthemall/backend/debug"
+	"github.com/pwnthemall/pwnthemall/backend/logger"
+	"github.com/pwnthemall/pwnthemall/backend/metrics"
 	"github.com/pwnthemall/pwnthemall/backend/models"
 )
 
@@ -103,7 +105,15 @@ func FindAvailablePorts(count int) ([]int, error) {
 	return ports, nil
 }
 
+// BuildDockerImage builds the image of a challenge and records the build duration
 func BuildDockerImage(slug string, sourceDir string) (string, error) {
+	start := time.Now()
+	imageName, err := buildDockerImage(slug, sourceDir)
+	metrics.ObserveDocker("build", "docker", start, err)
+	return imageName, err
+}
+
+func buildDockerImage(slug string, sourceDir string) (string, error) {
 	if err := EnsureDockerClientConnected(); err != nil {
 		return "", err
 	}
@@ -129,17 +139,17 @@ func BuildDockerImage(slug string, sourceDir string) (string, error) {
 	}
 	defer buildResponse.Body.Close()
 	if err := streamAndDetectBuildError(buildResponse.Body); err != nil {
-		debug.Log("Docker build failed: %v", err)
+		logger.Errorf("Docker build failed: %v", err)
 		return imageName, err
 	}
 	io.Copy(os.Stdout, buildResponse.Body)
-	debug.Log("Built image %s for challenge %s", imageName, slug)
+	logger.Debugf("Built image %s for challenge %s", imageName, slug)
 	return imageName, nil
 }
 
 func IsImageBuilt(slug string) (string, bool) {
 	if err := EnsureDockerClientConnected(); err != nil {
-		debug.Log("Docker client not connected: %v", err)
+		logger.Errorf("Docker client not connected: %v", err)
 		return "", false
 	}
 
@@ -147,7 +157,7 @@ func IsImageBuilt(slug string) (string, bool) {
 
 	prefix, err := getDockerImagePrefix()
 	if err != nil {
-		debug.Log("Could not get Docker image prefix: %v", err)
+		logger.Errorf("Could not get Docker image prefix: %v", err)
 		return "", false
 	}
 
@@ -160,7 +170,7 @@ func IsImageBuilt(slug string) (string, bool) {
 		Filters: filtersArgs,
 	})
 	if err != nil {
-		debug.Log("Failed to list docker images: %v", err)
+		logger.Errorf("Failed to list docker images: %v", err)
 		return "", false
 	}
 
@@ -170,7 +180,15 @@ func IsImageBuilt(slug string) (string, bool) {
 	return imageName, false
 }
 
+// StartDockerInstance starts a container for a team and records the start duration
 func StartDockerInstance(image string, teamId int, userId int, internalPorts []int, hostPorts []int) (string, error) {
+	start := time.Now()
+	containerName, err := startDockerInstance(image, teamId, userId, internalPorts, hostPorts)
+	metrics.ObserveDocker("start", "docker", start, err)
+	return containerName, err
+}
+
+func startDockerInstance(image string, teamId int, userId int, internalPorts []int, hostPorts []int) (string, error) {
 	if len(internalPorts) != len(hostPorts) {
 		return "", fmt.Errorf("internal and host ports length mismatch")
 	}
@@ -243,12 +261,12 @@ func StartDockerInstance(image string, teamId int, userId int, internalPorts []i
 		},
 	}
 
-	debug.Log("Creating Docker network for team %d", teamId)
+	logger.Debugf("Creating Docker network for team %d", teamId)
 	networkName, err := EnsureTeamNetworkExists(teamId)
 	if err != nil {
 		return fmt.Sprintf("failed to ensure team %d", teamId), err
 	}
-	debug.Log("Docker network created: %s", networkName)
+	logger.Debugf("Docker network created: %s", networkName)
 
 	containerTimeout := 60
 	networkingConfig := &network.NetworkingConfig{
@@ -278,7 +296,7 @@ func StartDockerInstance(image string, teamId int, userId int, internalPorts []i
 		return "", fmt.Errorf("failed to start container: %w", err)
 	}
 
-	debug.Log(
+	logger.Debugf(
 		"Started container %s for team %d user %d on host ports %v mapping to internal %v",
 		containerName, teamId, userId, hostPorts, internalPorts,
 	)
@@ -286,27 +304,27 @@ func StartDockerInstance(image string, teamId int, userId int, internalPorts []i
 }
 
 func StopDockerInstance(containerName string) error {
-	debug.Log("Attempting to stop Docker container: %s", containerName)
+	logger.Debugf("Attempting to stop Docker container: %s", containerName)
 
 	if err := EnsureDockerClientConnected(); err != nil {
-		debug.Log("Docker client connection failed: %v", err)
+		logger.Errorf("Docker client connection failed: %v", err)
 		return fmt.Errorf("docker client not connected: %w", err)
 	}
 
 	ctx := context.Background()
 
 	if containerName == "" {
-		debug.Println("containerName invalid, nothing to stop")
+		logger.Debug("containerName invalid, nothing to stop")
 		return nil
 	}
 
-	debug.Log("Removing container %s with force", containerName)
+	logger.Debugf("Removing container %s with force", containerName)
 	if err := config.DockerClient.ContainerRemove(ctx, containerName, container.RemoveOptions{Force: true}); err != nil {
-		debug.Log("Failed to remove container %s: %v", containerName, err)
+		logger.Errorf("Failed to remove container %s: %v", containerName, err)
 		return fmt.Errorf("failed to remove container %s: %w", containerName, err)
 	}
 
-	debug.Log("Successfully stopped and removed container %s", containerName)
+	logger.Infof("Successfully stopped and removed container %s", containerName)
 	return nil
 }
 
@@ -318,7 +336,7 @@ func EnsureTeamNetworkExists(teamId int) (string, error) {
 		Filters: filters.NewArgs(filters.Arg("name", networkName)),
 	})
 	if err != nil {
-		debug.Log(err.Error())
+		logger.Errorf("Failed to list networks for team %d: %v", teamId, err)
 		return "", fmt.Errorf("docker_network_unavailable")
 	}
 
@@ -328,11 +346,11 @@ func EnsureTeamNetworkExists(teamId int) (string, error) {
 
 	subnet, gateway, err := GetTeamSubnet(teamId)
 	if err != nil {
-		debug.Log(err.Error())
+		logger.Errorf("Failed to compute subnet for team %d: %v", teamId, err)
 		return "", fmt.Errorf("docker_network_unavailable")
 	}
 
-	debug.Log("Team %d | subnet: %s | gateway: %s", teamId, subnet, gateway)
+	logger.Debugf("Team %d | subnet: %s | gateway: %s", teamId, subnet, gateway)
 
 	ipamConfig := &network.IPAMConfig{
 		Subnet:  subnet,  // exemple: "172.18.0.0/24"
@@ -354,14 +372,14 @@ func EnsureTeamNetworkExists(teamId int) (string, error) {
 		},
 	)
 	if err != nil {
-		debug.Log(err.Error())
+		logger.Errorf("Failed to create network %s: %v", networkName, err)
 		return "", fmt.Errorf("docker_network_unavailable")
 	}
 	return networkName, nil
 }
 
 func GetComposeFile(slug string) (string, error) {
-	debug.Log("GetComposeFile with slug: %s", slug)
+	logger.Debugf("GetComposeFile with slug: %s", slug)
 	_, content, err := prepareChallengeContext(slug)
 	if err != nil {
 		return "", err
@@ -431,16 +449,16 @@ func CreateComposeProject(slug string, teamId int, userId int, composeFile strin
 			svc.Image = imageName
 			svc.Build = nil
 			p.Services[svcName] = svc
-			debug.Log("Built image %s for service %s", imageName, svcName)
+			logger.Debugf("Built image %s for service %s", imageName, svcName)
 		}
 	}
 
-	debug.Log("Creating Docker network for team %d", teamId)
+	logger.Debugf("Creating Docker network for team %d", teamId)
 	networkName, err := EnsureTeamNetworkExists(teamId)
 	if err != nil {
 		return nil, fmt.Errorf("failed to ensure team network: %w", err)
 	}
-	debug.Log("Docker network created: %s", networkName)
+	logger.Debugf("Docker network created: %s", networkName)
 	p.Networks = map[string]types.NetworkConfig{
 		networkName: {
 			Name:     networkName,
@@ -460,14 +478,22 @@ func CreateComposeProject(slug string, teamId int, userId int, composeFile strin
 	return p, nil
 }
 
+// StartComposeInstance starts a compose project for a team and records the start duration
 func StartComposeInstance(project *types.Project, teamId int) error {
+	start := time.Now()
+	err := startComposeInstance(project, teamId)
+	metrics.ObserveDocker("start", "compose", start, err)
+	return err
+}
+
+func startComposeInstance(project *types.Project, teamId int) error {
 	ctx := context.TODO()
 
 	networkName, err := EnsureTeamNetworkExists(teamId)
 	if err != nil {
 		return fmt.Errorf("failed to ensure team network: %w", err)
 	}
-	debug.Log("StartComposeInstance started for team %d on network %s", teamId, networkName)
+	logger.Debugf("StartComposeInstance started for team %d on network %s", teamId, networkName)
 
 	dockerCli, err := command.NewDockerCli(
 		command.WithStandardStreams(),
@@ -476,11 +502,11 @@ func StartComposeInstance(project *types.Project, teamId int) error {
 	if err != nil {
 		return err
 	}
-	debug.Log("dockerCli created")
+	logger.Debug("dockerCli created")
 	if err := dockerCli.Initialize(flags.NewClientOptions()); err != nil {
 		return err
 	}
-	debug.Log("serverInfo: %s", dockerCli.ServerInfo().OSType)
+	logger.Debugf("serverInfo: %s", dockerCli.ServerInfo().OSType)
 	srv := compose.NewComposeService(dockerCli)
 	for _, service := range project.Services {
 		if service.Networks == nil {
@@ -489,7 +515,7 @@ func StartComposeInstance(project *types.Project, teamId int) error {
 		service.Networks[networkName] = &types.ServiceNetworkConfig{}
 	}
 
-	// debug.Log("Building compose project: %s", project.Name)
+	// logger.Debugf("Building compose project: %s", project.Name)
 	// if err := srv.Build(ctx, project, api.BuildOptions{Deps: true}); err != nil {
 	// 	return fmt.Errorf("error building compose project: %w", err)
 	// }
@@ -517,12 +543,12 @@ func StopComposeInstance(projectName string) error {
 	if err != nil {
 		return err
 	}
-	debug.Log("dockerCli created")
+	logger.Debug("dockerCli created")
 
 	if err := dockerCli.Initialize(flags.NewClientOptions()); err != nil {
 		return err
 	}
-	debug.Log("serverInfo: %s", dockerCli.ServerInfo().OSType)
+	logger.Debugf("serverInfo: %s", dockerCli.ServerInfo().OSType)
 	srv := compose.NewComposeService(dockerCli)
 	options := api.DownOptions{
 		RemoveOrphans: true,
@@ -602,9 +628,9 @@ func prepareChallengeContext(slug string) (string, string, error) {
 	}
 	// Debug: List the contents of tmpDir
 	entries, _ := os.ReadDir(tmpDir)
-	debug.Log("Contents of %s:", tmpDir)
+	logger.Debugf("Contents of %s:", tmpDir)
 	for _, e := range entries {
-		debug.Log("  %s (isDir: %v)", e.Name(), e.IsDir())
+		logger.Debugf("  %s (isDir: %v)", e.Name(), e.IsDir())
 	}
 	composePath := filepath.Join(tmpDir, "docker-compose.yml")
 	content, err := os.ReadFile(composePath)
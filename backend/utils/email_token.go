@@ -0,0 +1,67 @@
+package utils
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/pwnthemall/pwnthemall/backend/config"
+	"github.com/pwnthemall/pwnthemall/backend/models"
+)
+
+const (
+	EmailTokenVerify = "verify_email"
+	EmailTokenReset  = "reset_password"
+)
+
+// IssueEmailToken signs a single-use token for the given purpose, older unused tokens of that purpose are invalidated
+func IssueEmailToken(userID uint, purpose string, ttl time.Duration) (string, error) {
+	jti, err := GenerateRandomString(16)
+	if err != nil {
+		return "", err
+	}
+	now := time.Now()
+
+	config.DB.Where("expires_at < ?", now).Delete(&models.UserToken{})
+	config.DB.Model(&models.UserToken{}).
+		Where("user_id = ? AND purpose = ? AND used_at IS NULL", userID, purpose).
+		Update("used_at", now)
+
+	record := models.UserToken{UserID: userID, Purpose: purpose, JTI: jti, ExpiresAt: now.Add(ttl)}
+	if err := config.DB.Create(&record).Error; err != nil {
+		return "", err
+	}
+
+	claims := jwt.RegisteredClaims{
+		ID:        jti,
+		Subject:   fmt.Sprintf("%d", userID),
+		Audience:  jwt.ClaimStrings{purpose},
+		ExpiresAt: jwt.NewNumericDate(record.ExpiresAt),
+	}
+	return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(DeriveSecret("email-token"))
+}
+
+// ConsumeEmailToken checks the signature of a token and marks it as used, it returns the user ID
+func ConsumeEmailToken(tokenStr, purpose string) (uint, error) {
+	claims := &jwt.RegisteredClaims{}
+	token, err := jwt.ParseWithClaims(tokenStr, claims, func(t *jwt.Token) (interface{}, error) {
+		return DeriveSecret("email-token"), nil
+	}, jwt.WithAudience(purpose), jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}))
+	if err != nil || !token.Valid || claims.ID == "" {
+		return 0, fmt.Errorf("invalid token")
+	}
+
+	var userID uint
+	if _, err := fmt.Sscanf(claims.Subject, "%d", &userID); err != nil {
+		return 0, fmt.Errorf("invalid token")
+	}
+
+	// The update only matches once, so a token cannot be replayed
+	result := config.DB.Model(&models.UserToken{}).
+		Where("jti = ? AND user_id = ? AND purpose = ? AND used_at IS NULL AND expires_at > ?", claims.ID, userID, purpose, time.Now()).
+		Update("used_at", time.Now())
+	if result.Error != nil || result.RowsAffected != 1 {
+		return 0, fmt.Errorf("invalid token")
+	}
+	return userID, nil
+}
@@ -0,0 +1,1386 @@
+package utils
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/casbin/casbin/v2"
+	"github.com/lib/pq"
+	"github.com/minio/minio-go/v7"
+	"github.com/pwnthemall/pwnthemall/backend/config"
+	"github.com/pwnthemall/pwnthemall/backend/logger"
+	"github.com/pwnthemall/pwnthemall/backend/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	eventArchiveVersion      = 1
+	eventArchiveManifest     = "manifest.json"
+	eventArchiveDataDir      = "data/"
+	eventArchiveChallengeDir = "challenges/"
+	eventArchivePageDir      = "pages/"
+)
+
+// ErrEventArchiveTargetNotEmpty is returned when restoring into an instance that already has event data
+var ErrEventArchiveTargetNotEmpty = errors.New("target instance already contains challenges or teams")
+
+// EventArchiveManifest describes the content of an event archive
+type EventArchiveManifest struct {
+	Version   int            `json:"version"`
+	CreatedAt time.Time      `json:"createdAt"`
+	Counts    map[string]int `json:"counts"`
+}
+
+// EventRestoreReport summarizes what was restored from an event archive
+type EventRestoreReport struct {
+	Counts   map[string]int `json:"counts"`
+	Warnings []string       `json:"warnings"`
+}
+
+func (r *EventRestoreReport) warn(format string, args ...interface{}) {
+	msg := fmt.Sprintf(format, args...)
+	logger.Warnf("Restore warning: %s", msg)
+	r.Warnings = append(r.Warnings, msg)
+}
+
+// Archive records use flat structs so that relations are referenced by their original ID only
+
+type archivedUser struct {
+	ID            uint               `json:"id"`
+	Username      string             `json:"username"`
+	Email         string             `json:"email"`
+	EmailVerified bool               `json:"emailVerified"`
+	Password      string             `json:"password"`
+	Role          string             `json:"role"`
+	TeamID        *uint              `json:"teamId,omitempty"`
+	Banned        bool               `json:"banned"`
+	IPAddresses   models.IPAddresses `json:"ipAddresses"`
+	SocialLinks   models.SocialLinks `json:"socialLinks"`
+	TOTPEnabled   bool               `json:"totpEnabled,omitempty"`
+	TOTPSecret    string             `json:"totpSecret,omitempty"`
+	TOTPLastStep  int64              `json:"totpLastStep,omitempty"`
+	RecoveryCodes pq.StringArray     `json:"recoveryCodes,omitempty"`
+	CreatedAt     time.Time          `json:"createdAt"`
+}
+
+type archivedRole struct {
+	Name        string      `json:"name"`
+	Description string      `json:"description"`
+	Inherits    []string    `json:"inherits,omitempty"`
+	Permissions [][2]string `json:"permissions"`
+	CreatedAt   time.Time   `json:"createdAt"`
+}
+
+type archivedTeam struct {
+	ID        uint      `json:"id"`
+	Name      string    `json:"name"`
+	Password  string    `json:"password"`
+	CreatorID uint      `json:"creatorId"`
+	BracketID *uint     `json:"bracketId,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+type archivedDecay struct {
+	Name      string `json:"name"`
+	Type      string `json:"type"`
+	Step      int    `json:"step"`
+	MinPoints int    `json:"minPoints"`
+}
+
+type archivedChallenge struct {
+	ID                uint            `json:"id"`
+	Slug              string          `json:"slug"`
+	Name              string          `json:"name"`
+	Description       string          `json:"description"`
+	Category          string          `json:"category"`
+	Difficulty        string          `json:"difficulty"`
+	Type              string          `json:"type"`
+	Decay             *archivedDecay  `json:"decay,omitempty"`
+	Author            string          `json:"author"`
+	Hidden            bool            `json:"hidden"`
+	FlagHashes        []string        `json:"flagHashes"`
+	Files             pq.StringArray  `json:"files"`
+	Ports             pq.Int64Array   `json:"ports"`
+	ConnectionInfo    pq.StringArray  `json:"connectionInfo"`
+	Points            int             `json:"points"`
+	Order             int             `json:"order"`
+	Hints             []models.Hint   `json:"hints"`
+	Tags              []string        `json:"tags,omitempty"`
+	EnableFirstBlood  bool            `json:"enableFirstBlood"`
+	FirstBloodBonuses pq.Int64Array   `json:"firstBloodBonuses"`
+	FirstBloodBadges  pq.StringArray  `json:"firstBloodBadges"`
+	MaxAttempts       int             `json:"maxAttempts"`
+	DependsOn         string          `json:"dependsOn"`
+	CoverImg          string          `json:"coverImg"`
+	Emoji             string          `json:"emoji"`
+	CoverPositionX    float64         `json:"coverPositionX"`
+	CoverPositionY    float64         `json:"coverPositionY"`
+	CoverZoom         float64         `json:"coverZoom"`
+	GeoSpec           *models.GeoSpec `json:"geoSpec,omitempty"`
+	CreatedAt         time.Time       `json:"createdAt"`
+}
+
+type archivedHintPurchase struct {
+	TeamID    uint      `json:"teamId"`
+	HintID    uint      `json:"hintId"`
+	UserID    uint      `json:"userId"`
+	Cost      int       `json:"cost"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+type archivedTicket struct {
+	ID              uint                  `json:"id"`
+	Subject         string                `json:"subject"`
+	Description     string                `json:"description"`
+	Status          models.TicketStatus   `json:"status"`
+	TicketType      models.TicketType     `json:"ticketType"`
+	Priority        models.TicketPriority `json:"priority,omitempty"`
+	Tags            pq.StringArray        `json:"tags,omitempty"`
+	UserID          uint                  `json:"userId"`
+	TeamID          *uint                 `json:"teamId,omitempty"`
+	ChallengeID     *uint                 `json:"challengeId,omitempty"`
+	ClaimedByID     *uint                 `json:"claimedById,omitempty"`
+	Attachments     pq.StringArray        `json:"attachments"`
+	CreatedAt       time.Time             `json:"createdAt"`
+	ClaimedAt       *time.Time            `json:"claimedAt,omitempty"`
+	ResolvedAt      *time.Time            `json:"resolvedAt,omitempty"`
+	ReopenedAt      *time.Time            `json:"reopenedAt,omitempty"`
+	FirstResponseAt *time.Time            `json:"firstResponseAt,omitempty"`
+}
+
+type archivedTicketMessage struct {
+	TicketID    uint           `json:"ticketId"`
+	UserID      uint           `json:"userId"`
+	Message     string         `json:"message"`
+	IsAdmin     bool           `json:"isAdmin"`
+	IsInternal  bool           `json:"isInternal,omitempty"`
+	Attachments pq.StringArray `json:"attachments"`
+	CreatedAt   time.Time      `json:"createdAt"`
+}
+
+type archivedPage struct {
+	ID          uint       `json:"id"`
+	Slug        string     `json:"slug"`
+	Title       string     `json:"title"`
+	MinioKey    string     `json:"minioKey"`
+	IsInSidebar bool       `json:"isInSidebar"`
+	Order       int        `json:"order"`
+	Source      string     `json:"source"`
+	Format      string     `json:"format,omitempty"`
+	Draft       bool       `json:"draft,omitempty"`
+	PublishAt   *time.Time `json:"publishAt,omitempty"`
+	UnpublishAt *time.Time `json:"unpublishAt,omitempty"`
+	CreatedAt   time.Time  `json:"createdAt"`
+}
+
+// archivedWebhookTarget keeps the signing secret that the model hides from JSON
+type archivedWebhookTarget struct {
+	Name      string         `json:"name"`
+	URL       string         `json:"url"`
+	Format    string         `json:"format"`
+	Secret    string         `json:"secret"`
+	Events    pq.StringArray `json:"events"`
+	Enabled   bool           `json:"enabled"`
+	CreatedAt time.Time      `json:"createdAt"`
+}
+
+// eventArchiveData holds every table exported in an event archive
+type eventArchiveData struct {
+	Roles          []archivedRole
+	Users          []archivedUser
+	Brackets       []models.Bracket
+	Teams          []archivedTeam
+	Challenges     []archivedChallenge
+	Authors        []models.ChallengeAuthor
+	Solves         []models.Solve
+	Submissions    []models.Submission
+	HintPurchases  []archivedHintPurchase
+	FirstBloods    []models.FirstBlood
+	Badges         []models.Badge
+	UserBadges     []models.UserBadge
+	Ratings        []models.ChallengeRating
+	Identities     []models.UserIdentity
+	Notifications  []models.Notification
+	Recipients     []models.NotificationRecipient
+	Reads          []models.NotificationRead
+	Revisions      []models.NotificationRevision
+	Tickets        []archivedTicket
+	TicketMessages []archivedTicketMessage
+	CannedReplies  []models.TicketCannedResponse
+	Pages          []archivedPage
+	PageRevisions  []models.PageRevision
+	Webhooks       []archivedWebhookTarget
+	Configs        []models.Config
+}
+
+// tables lists the data files of the archive with their destination
+func (d *eventArchiveData) tables() map[string]interface{} {
+	return map[string]interface{}{
+		"roles":                   &d.Roles,
+		"users":                   &d.Users,
+		"brackets":                &d.Brackets,
+		"teams":                   &d.Teams,
+		"challenges":              &d.Challenges,
+		"authors":                 &d.Authors,
+		"solves":                  &d.Solves,
+		"submissions":             &d.Submissions,
+		"hint_purchases":          &d.HintPurchases,
+		"first_bloods":            &d.FirstBloods,
+		"badges":                  &d.Badges,
+		"user_badges":             &d.UserBadges,
+		"challenge_ratings":       &d.Ratings,
+		"user_identities":         &d.Identities,
+		"notifications":           &d.Notifications,
+		"notification_recipients": &d.Recipients,
+		"notification_reads":      &d.Reads,
+		"notification_revisions":  &d.Revisions,
+		"tickets":                 &d.Tickets,
+		"ticket_messages":         &d.TicketMessages,
+		"canned_responses":        &d.CannedReplies,
+		"pages":                   &d.Pages,
+		"page_revisions":          &d.PageRevisions,
+		"webhook_targets":         &d.Webhooks,
+		"configs":                 &d.Configs,
+	}
+}
+
+// ExportEventArchive writes a full event backup (database records, challenge bundles and pages) as ZIP
+func ExportEventArchive(w io.Writer) (*EventArchiveManifest, error) {
+	data, err := loadEventArchiveData()
+	if err != nil {
+		return nil, err
+	}
+
+	zipWriter := zip.NewWriter(w)
+	manifest := &EventArchiveManifest{
+		Version:   eventArchiveVersion,
+		CreatedAt: time.Now().UTC(),
+		Counts:    make(map[string]int),
+	}
+
+	for name, table := range data.tables() {
+		content, err := json.MarshalIndent(table, "", "  ")
+		if err != nil {
+			zipWriter.Close()
+			return nil, fmt.Errorf("failed to encode %s: %w", name, err)
+		}
+		if err := writeZipEntry(zipWriter, eventArchiveDataDir+name+".json", content); err != nil {
+			zipWriter.Close()
+			return nil, err
+		}
+	}
+
+	manifest.Counts["roles"] = len(data.Roles)
+	manifest.Counts["users"] = len(data.Users)
+	manifest.Counts["brackets"] = len(data.Brackets)
+	manifest.Counts["teams"] = len(data.Teams)
+	manifest.Counts["challenges"] = len(data.Challenges)
+	manifest.Counts["authors"] = len(data.Authors)
+	manifest.Counts["solves"] = len(data.Solves)
+	manifest.Counts["submissions"] = len(data.Submissions)
+	manifest.Counts["hint_purchases"] = len(data.HintPurchases)
+	manifest.Counts["first_bloods"] = len(data.FirstBloods)
+	manifest.Counts["badges"] = len(data.Badges)
+	manifest.Counts["challenge_ratings"] = len(data.Ratings)
+	manifest.Counts["user_identities"] = len(data.Identities)
+	manifest.Counts["notifications"] = len(data.Notifications)
+	manifest.Counts["tickets"] = len(data.Tickets)
+	manifest.Counts["canned_responses"] = len(data.CannedReplies)
+	manifest.Counts["pages"] = len(data.Pages)
+	manifest.Counts["page_revisions"] = len(data.PageRevisions)
+	manifest.Counts["webhook_targets"] = len(data.Webhooks)
+	manifest.Counts["configs"] = len(data.Configs)
+
+	// Challenge bundles (chall.yml + files)
+	var challenges []models.Challenge
+	if err := config.DB.
+		Preload("ChallengeCategory").
+		Preload("ChallengeDifficulty").
+		Preload("ChallengeType").
+		Preload("Hints").
+		Preload("Tags").
+		Preload("DecayFormula").
+		Find(&challenges).Error; err != nil {
+		zipWriter.Close()
+		return nil, fmt.Errorf("failed to fetch challenges: %w", err)
+	}
+	for _, challenge := range challenges {
+		bundle, err := BuildChallengeBundle(challenge)
+		if err != nil {
+			logger.Errorf("Failed to build bundle for challenge %s: %v", challenge.Slug, err)
+			continue
+		}
+		if err := writeZipEntry(zipWriter, eventArchiveChallengeDir+challenge.Slug+".zip", bundle); err != nil {
+			zipWriter.Close()
+			return nil, err
+		}
+	}
+
+	// Page contents
+	for _, page := range data.Pages {
+		for _, key := range pageObjectKeys(page) {
+			content, err := retrieveObjectFromMinio(bucketNamePages, key)
+			if err != nil {
+				logger.Errorf("Failed to fetch page object %s: %v", key, err)
+				continue
+			}
+			if err := writeZipEntry(zipWriter, eventArchivePageDir+key, content); err != nil {
+				zipWriter.Close()
+				return nil, err
+			}
+		}
+	}
+
+	content, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		zipWriter.Close()
+		return nil, fmt.Errorf("failed to encode manifest: %w", err)
+	}
+	if err := writeZipEntry(zipWriter, eventArchiveManifest, content); err != nil {
+		zipWriter.Close()
+		return nil, err
+	}
+
+	if err := zipWriter.Close(); err != nil {
+		return nil, fmt.Errorf("failed to close zip writer: %w", err)
+	}
+	return manifest, nil
+}
+
+// loadEventArchiveData reads every exported table from the database
+func loadEventArchiveData() (*eventArchiveData, error) {
+	data := &eventArchiveData{}
+	db := config.DB
+
+	// Custom roles only, their permissions live in casbin and are read from the loaded policy
+	var roles []models.Role
+	if err := db.Order("name").Find(&roles).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch roles: %w", err)
+	}
+	config.ReadPolicy(func(e *casbin.Enforcer) {
+		for _, r := range roles {
+			role := archivedRole{Name: r.Name, Description: r.Description, Permissions: [][2]string{}, CreatedAt: r.CreatedAt}
+			role.Inherits, _ = e.GetRolesForUser(r.Name)
+			rules, _ := e.GetPermissionsForUser(r.Name)
+			for _, rule := range rules {
+				if len(rule) >= 3 {
+					role.Permissions = append(role.Permissions, [2]string{rule[1], rule[2]})
+				}
+			}
+			data.Roles = append(data.Roles, role)
+		}
+	})
+
+	var users []models.User
+	if err := db.Order("id").Find(&users).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch users: %w", err)
+	}
+	for _, u := range users {
+		data.Users = append(data.Users, archivedUser{
+			ID: u.ID, Username: u.Username, Email: u.Email, EmailVerified: u.EmailVerified, Password: u.Password, Role: u.Role,
+			TeamID: u.TeamID, Banned: u.Banned, IPAddresses: u.IPAddresses, SocialLinks: u.SocialLinks,
+			TOTPEnabled: u.TOTPEnabled, TOTPSecret: u.TOTPSecret, TOTPLastStep: u.TOTPLastStep, RecoveryCodes: u.RecoveryCodes,
+			CreatedAt: u.CreatedAt,
+		})
+	}
+	if err := db.Order("id").Find(&data.Identities).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch user identities: %w", err)
+	}
+
+	if err := db.Order("id").Find(&data.Brackets).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch brackets: %w", err)
+	}
+
+	var teams []models.Team
+	if err := db.Order("id").Find(&teams).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch teams: %w", err)
+	}
+	for _, t := range teams {
+		data.Teams = append(data.Teams, archivedTeam{
+			ID: t.ID, Name: t.Name, Password: t.Password, CreatorID: t.CreatorID, BracketID: t.BracketID, CreatedAt: t.CreatedAt,
+		})
+	}
+
+	var challenges []models.Challenge
+	if err := db.
+		Preload("ChallengeCategory").
+		Preload("ChallengeDifficulty").
+		Preload("ChallengeType").
+		Preload("DecayFormula").
+		Preload("Flags").
+		Preload("Hints").
+		Preload("Tags").
+		Order("id").Find(&challenges).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch challenges: %w", err)
+	}
+	for _, c := range challenges {
+		data.Challenges = append(data.Challenges, toArchivedChallenge(c))
+	}
+
+	if err := db.Order("challenge_id, user_id").Find(&data.Authors).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch challenge authors: %w", err)
+	}
+	if err := db.Order("id").Find(&data.Ratings).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch challenge ratings: %w", err)
+	}
+
+	if err := db.Find(&data.Solves).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch solves: %w", err)
+	}
+	if err := db.Order("id").Find(&data.Submissions).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch submissions: %w", err)
+	}
+
+	var purchases []models.HintPurchase
+	if err := db.Order("id").Find(&purchases).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch hint purchases: %w", err)
+	}
+	for _, p := range purchases {
+		data.HintPurchases = append(data.HintPurchases, archivedHintPurchase{
+			TeamID: p.TeamID, HintID: p.HintID, UserID: p.UserID, Cost: p.Cost, CreatedAt: p.CreatedAt,
+		})
+	}
+
+	if err := db.Order("id").Find(&data.FirstBloods).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch first bloods: %w", err)
+	}
+
+	// Badges are optional, the tables only exist when the badge feature was migrated
+	if db.Migrator().HasTable(&models.Badge{}) {
+		if err := db.Order("id").Find(&data.Badges).Error; err != nil {
+			return nil, fmt.Errorf("failed to fetch badges: %w", err)
+		}
+	}
+	if db.Migrator().HasTable(&models.UserBadge{}) {
+		if err := db.Order("id").Find(&data.UserBadges).Error; err != nil {
+			return nil, fmt.Errorf("failed to fetch user badges: %w", err)
+		}
+	}
+
+	if err := db.Order("id").Find(&data.Notifications).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch notifications: %w", err)
+	}
+	if err := db.Order("notification_id, user_id").Find(&data.Recipients).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch notification recipients: %w", err)
+	}
+	if err := db.Order("notification_id, user_id").Find(&data.Reads).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch notification reads: %w", err)
+	}
+	if err := db.Order("id").Find(&data.Revisions).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch notification revisions: %w", err)
+	}
+
+	var tickets []models.Ticket
+	if err := db.Order("id").Find(&tickets).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch tickets: %w", err)
+	}
+	for _, t := range tickets {
+		data.Tickets = append(data.Tickets, archivedTicket{
+			ID: t.ID, Subject: t.Subject, Description: t.Description, Status: t.Status, TicketType: t.TicketType,
+			Priority: t.Priority, Tags: t.Tags,
+			UserID: t.UserID, TeamID: t.TeamID, ChallengeID: t.ChallengeID, ClaimedByID: t.ClaimedByID,
+			Attachments: t.Attachments, CreatedAt: t.CreatedAt, ClaimedAt: t.ClaimedAt, ResolvedAt: t.ResolvedAt,
+			ReopenedAt: t.ReopenedAt, FirstResponseAt: t.FirstResponseAt,
+		})
+	}
+
+	var messages []models.TicketMessage
+	if err := db.Order("id").Find(&messages).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch ticket messages: %w", err)
+	}
+	for _, m := range messages {
+		data.TicketMessages = append(data.TicketMessages, archivedTicketMessage{
+			TicketID: m.TicketID, UserID: m.UserID, Message: m.Message, IsAdmin: m.IsAdmin, IsInternal: m.IsInternal,
+			Attachments: m.Attachments, CreatedAt: m.CreatedAt,
+		})
+	}
+	if err := db.Order("id").Find(&data.CannedReplies).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch canned responses: %w", err)
+	}
+
+	var pages []models.Page
+	if err := db.Order("id").Find(&pages).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch pages: %w", err)
+	}
+	for _, p := range pages {
+		data.Pages = append(data.Pages, archivedPage{
+			ID: p.ID, Slug: p.Slug, Title: p.Title, MinioKey: p.MinioKey, IsInSidebar: p.IsInSidebar,
+			Order: p.Order, Source: p.Source, Format: p.Format, Draft: p.Draft, PublishAt: p.PublishAt,
+			UnpublishAt: p.UnpublishAt, CreatedAt: p.CreatedAt,
+		})
+	}
+
+	if err := db.Order("page_id, number").Find(&data.PageRevisions).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch page revisions: %w", err)
+	}
+
+	// Deliveries are a retry queue of past events, only the targets are kept
+	var webhooks []models.WebhookTarget
+	if err := db.Order("id").Find(&webhooks).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch webhook targets: %w", err)
+	}
+	for _, w := range webhooks {
+		data.Webhooks = append(data.Webhooks, archivedWebhookTarget{
+			Name: w.Name, URL: w.URL, Format: w.Format, Secret: w.Secret, Events: w.Events, Enabled: w.Enabled, CreatedAt: w.CreatedAt,
+		})
+	}
+
+	if err := db.Find(&data.Configs).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch config: %w", err)
+	}
+
+	return data, nil
+}
+
+// toArchivedChallenge flattens a challenge and its relations
+func toArchivedChallenge(c models.Challenge) archivedChallenge {
+	ac := archivedChallenge{
+		ID: c.ID, Slug: c.Slug, Name: c.Name, Description: c.Description, Author: c.Author, Hidden: c.Hidden,
+		Files: c.Files, Ports: c.Ports, ConnectionInfo: c.ConnectionInfo, Points: c.Points, Order: c.Order,
+		Hints: c.Hints, EnableFirstBlood: c.EnableFirstBlood, FirstBloodBonuses: c.FirstBloodBonuses,
+		FirstBloodBadges: c.FirstBloodBadges, MaxAttempts: c.MaxAttempts, DependsOn: c.DependsOn,
+		CoverImg: c.CoverImg, Emoji: c.Emoji, CoverPositionX: c.CoverPositionX, CoverPositionY: c.CoverPositionY,
+		CoverZoom: c.CoverZoom, CreatedAt: c.CreatedAt, Tags: TagNames(c.Tags),
+	}
+	if c.ChallengeCategory != nil {
+		ac.Category = c.ChallengeCategory.Name
+	}
+	if c.ChallengeDifficulty != nil {
+		ac.Difficulty = c.ChallengeDifficulty.Name
+	}
+	if c.ChallengeType != nil {
+		ac.Type = c.ChallengeType.Name
+	}
+	if c.DecayFormula != nil {
+		ac.Decay = &archivedDecay{
+			Name: c.DecayFormula.Name, Type: c.DecayFormula.Type,
+			Step: c.DecayFormula.Step, MinPoints: c.DecayFormula.MinPoints,
+		}
+	}
+	for _, f := range c.Flags {
+		ac.FlagHashes = append(ac.FlagHashes, f.Value)
+	}
+	var spec models.GeoSpec
+	if err := config.DB.Where("challenge_id = ?", c.ID).First(&spec).Error; err == nil {
+		ac.GeoSpec = &spec
+	}
+	return ac
+}
+
+// pageObjectKeys returns the MinIO objects backing a page
+func pageObjectKeys(page archivedPage) []string {
+	keys := []string{}
+	if page.Source == "minio" {
+		keys = append(keys, path.Join(path.Dir(page.MinioKey), "page.yml"))
+	}
+	return append(keys, page.MinioKey)
+}
+
+func writeZipEntry(zipWriter *zip.Writer, name string, content []byte) error {
+	w, err := zipWriter.Create(name)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", name, err)
+	}
+	if _, err := w.Write(content); err != nil {
+		return fmt.Errorf("failed to write %s: %w", name, err)
+	}
+	return nil
+}
+
+func readZipEntry(f *zip.File) ([]byte, error) {
+	rc, err := f.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	return io.ReadAll(rc)
+}
+
+// retrieveObjectFromMinio reads a whole object from the given bucket
+func retrieveObjectFromMinio(bucket, key string) ([]byte, error) {
+	obj, err := config.FS.GetObject(context.Background(), bucket, key, minio.GetObjectOptions{})
+	if err != nil {
+		return nil, err
+	}
+	defer obj.Close()
+	return io.ReadAll(obj)
+}
+
+// RestoreEventArchive rebuilds an event from an archive produced by ExportEventArchive
+// IDs are remapped; existing users are matched by username
+func RestoreEventArchive(archive []byte) (*EventRestoreReport, error) {
+	zipReader, err := zip.NewReader(bytes.NewReader(archive), int64(len(archive)))
+	if err != nil {
+		return nil, fmt.Errorf("invalid archive: %w", err)
+	}
+
+	files := make(map[string]*zip.File)
+	for _, f := range zipReader.File {
+		files[f.Name] = f
+	}
+
+	manifestFile, ok := files[eventArchiveManifest]
+	if !ok {
+		return nil, errors.New("archive has no manifest")
+	}
+	content, err := readZipEntry(manifestFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read manifest: %w", err)
+	}
+	var manifest EventArchiveManifest
+	if err := json.Unmarshal(content, &manifest); err != nil {
+		return nil, fmt.Errorf("invalid manifest: %w", err)
+	}
+	if manifest.Version != eventArchiveVersion {
+		return nil, fmt.Errorf("unsupported archive version %d", manifest.Version)
+	}
+
+	data := &eventArchiveData{}
+	for name, table := range data.tables() {
+		f, ok := files[eventArchiveDataDir+name+".json"]
+		if !ok {
+			continue
+		}
+		content, err := readZipEntry(f)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", name, err)
+		}
+		if err := json.Unmarshal(content, table); err != nil {
+			return nil, fmt.Errorf("invalid %s data: %w", name, err)
+		}
+	}
+
+	report := &EventRestoreReport{Counts: make(map[string]int), Warnings: []string{}}
+	var roles []archivedRole
+	if err := config.DB.Transaction(func(tx *gorm.DB) error {
+		var err error
+		roles, err = restoreEventData(tx, data, report)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	// Casbin saves through its own connection, so policies are only added once the roles are committed
+	restoreRolePolicies(roles, report)
+	restoreEventObjects(files, report)
+	return report, nil
+}
+
+// eventIDMaps maps archive IDs to the IDs created in the target database
+type eventIDMaps struct {
+	users      map[uint]uint
+	teams      map[uint]uint
+	brackets   map[uint]uint
+	challenges map[uint]uint
+	hints      map[uint]uint
+	badges     map[uint]uint
+	tickets    map[uint]uint
+
+	notifications map[uint]uint
+	pages         map[uint]uint
+}
+
+func (m *eventIDMaps) optional(ids map[uint]uint, id *uint) (*uint, bool) {
+	if id == nil {
+		return nil, true
+	}
+	newID, ok := ids[*id]
+	if !ok {
+		return nil, false
+	}
+	return &newID, true
+}
+
+// restoreEventData inserts the archive records into the database and returns the roles it created
+func restoreEventData(tx *gorm.DB, data *eventArchiveData, report *EventRestoreReport) ([]archivedRole, error) {
+	var existing int64
+	if err := tx.Model(&models.Challenge{}).Count(&existing).Error; err != nil {
+		return nil, err
+	}
+	if existing == 0 {
+		if err := tx.Model(&models.Team{}).Count(&existing).Error; err != nil {
+			return nil, err
+		}
+	}
+	if existing > 0 {
+		return nil, ErrEventArchiveTargetNotEmpty
+	}
+
+	ids := &eventIDMaps{
+		users:      make(map[uint]uint),
+		teams:      make(map[uint]uint),
+		brackets:   make(map[uint]uint),
+		challenges: make(map[uint]uint),
+		hints:      make(map[uint]uint),
+		badges:     make(map[uint]uint),
+		tickets:    make(map[uint]uint),
+
+		notifications: make(map[uint]uint),
+		pages:         make(map[uint]uint),
+	}
+
+	roles, err := restoreRoles(tx, data.Roles, report)
+	if err != nil {
+		return nil, err
+	}
+	if err := restoreUsersAndTeams(tx, data, ids, report); err != nil {
+		return nil, err
+	}
+	if err := restoreChallenges(tx, data.Challenges, ids, report); err != nil {
+		return nil, err
+	}
+	for _, a := range data.Authors {
+		challengeID, okChallenge := ids.challenges[a.ChallengeID]
+		userID, okUser := ids.users[a.UserID]
+		if !okChallenge || !okUser {
+			report.warn("skipped author %d of challenge %d: unknown user or challenge", a.UserID, a.ChallengeID)
+			continue
+		}
+		author := models.ChallengeAuthor{ChallengeID: challengeID, UserID: userID, CreatedAt: a.CreatedAt}
+		if err := tx.Omit(clause.Associations).Create(&author).Error; err != nil {
+			return nil, fmt.Errorf("failed to restore challenge author: %w", err)
+		}
+		report.Counts["authors"]++
+	}
+	for _, r := range data.Ratings {
+		challengeID, okChallenge := ids.challenges[r.ChallengeID]
+		userID, okUser := ids.users[r.UserID]
+		if !okChallenge || !okUser {
+			continue
+		}
+		rating := models.ChallengeRating{ChallengeID: challengeID, UserID: userID, Rating: r.Rating, Feedback: r.Feedback, CreatedAt: r.CreatedAt}
+		if err := tx.Omit(clause.Associations).Create(&rating).Error; err != nil {
+			return nil, fmt.Errorf("failed to restore challenge rating: %w", err)
+		}
+		report.Counts["challenge_ratings"]++
+	}
+
+	if err := restoreScoring(tx, data, ids, report); err != nil {
+		return nil, err
+	}
+	if err := restoreBadges(tx, data, ids, report); err != nil {
+		return nil, err
+	}
+	if err := restoreTickets(tx, data, ids, report); err != nil {
+		return nil, err
+	}
+
+	if err := restoreNotifications(tx, data, ids, report); err != nil {
+		return nil, err
+	}
+
+	if err := restorePages(tx, data, ids, report); err != nil {
+		return nil, err
+	}
+
+	for _, w := range data.Webhooks {
+		target := models.WebhookTarget{
+			Name: w.Name, URL: w.URL, Format: w.Format, Secret: w.Secret, Events: w.Events, Enabled: w.Enabled, CreatedAt: w.CreatedAt,
+		}
+		if err := tx.Create(&target).Error; err != nil {
+			return nil, fmt.Errorf("failed to restore webhook target %s: %w", w.Name, err)
+		}
+		report.Counts["webhook_targets"]++
+	}
+
+	for _, cfg := range data.Configs {
+		if err := tx.Save(&cfg).Error; err != nil {
+			return nil, fmt.Errorf("failed to restore config %s: %w", cfg.Key, err)
+		}
+		report.Counts["configs"]++
+	}
+
+	return roles, nil
+}
+
+// restorePages recreates the pages missing from the target with their revision history
+func restorePages(tx *gorm.DB, data *eventArchiveData, ids *eventIDMaps, report *EventRestoreReport) error {
+	for _, p := range data.Pages {
+		var count int64
+		tx.Model(&models.Page{}).Where("slug = ?", p.Slug).Count(&count)
+		if count > 0 {
+			report.warn("skipped page %s: slug already exists", p.Slug)
+			continue
+		}
+		page := models.Page{
+			Slug: p.Slug, Title: p.Title, MinioKey: p.MinioKey, IsInSidebar: p.IsInSidebar,
+			Order: p.Order, Source: p.Source, Format: p.Format, Draft: p.Draft, PublishAt: p.PublishAt,
+			UnpublishAt: p.UnpublishAt, CreatedAt: p.CreatedAt,
+		}
+		if err := tx.Create(&page).Error; err != nil {
+			return fmt.Errorf("failed to restore page %s: %w", p.Slug, err)
+		}
+		ids.pages[p.ID] = page.ID
+		report.Counts["pages"]++
+	}
+
+	for _, r := range data.PageRevisions {
+		pageID, ok := ids.pages[r.PageID]
+		if !ok {
+			continue
+		}
+		authorID, ok := ids.optional(ids.users, r.AuthorID)
+		if !ok {
+			authorID = nil
+		}
+		revision := models.PageRevision{
+			PageID: pageID, Number: r.Number, Title: r.Title, Format: r.Format, Content: r.Content,
+			AuthorID: authorID, RestoredFrom: r.RestoredFrom, CreatedAt: r.CreatedAt,
+		}
+		if err := tx.Omit(clause.Associations).Create(&revision).Error; err != nil {
+			return fmt.Errorf("failed to restore revision %d of page %d: %w", r.Number, r.PageID, err)
+		}
+		report.Counts["page_revisions"]++
+	}
+	return nil
+}
+
+// restoreRoles creates the custom roles missing from the target, existing ones keep their current permissions
+func restoreRoles(tx *gorm.DB, roles []archivedRole, report *EventRestoreReport) ([]archivedRole, error) {
+	var created []archivedRole
+	for _, r := range roles {
+		if !config.ValidRoleName(r.Name) {
+			report.warn("skipped role %s: invalid name", r.Name)
+			continue
+		}
+		var count int64
+		tx.Model(&models.Role{}).Where("name = ?", r.Name).Count(&count)
+		if count > 0 {
+			report.warn("skipped role %s: already exists", r.Name)
+			continue
+		}
+		role := models.Role{Name: r.Name, Description: r.Description, CreatedAt: r.CreatedAt}
+		if err := tx.Create(&role).Error; err != nil {
+			return nil, fmt.Errorf("failed to restore role %s: %w", r.Name, err)
+		}
+		created = append(created, r)
+		report.Counts["roles"]++
+	}
+	return created, nil
+}
+
+// restoreRolePolicies adds the permissions and parents of the restored roles to casbin
+func restoreRolePolicies(roles []archivedRole, report *EventRestoreReport) {
+	for _, r := range roles {
+		err := config.UpdatePolicy(func(e *casbin.Enforcer) error {
+			for _, p := range r.Permissions {
+				if _, err := e.AddPolicy(r.Name, p[0], p[1]); err != nil {
+					return err
+				}
+			}
+			for _, parent := range r.Inherits {
+				if _, err := e.AddRoleForUser(r.Name, parent); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			report.warn("role %s: failed to restore permissions: %v", r.Name, err)
+		}
+	}
+}
+
+// restoreUsersAndTeams creates users first, then brackets and teams, then links users to their team
+func restoreUsersAndTeams(tx *gorm.DB, data *eventArchiveData, ids *eventIDMaps, report *EventRestoreReport) error {
+	for _, u := range data.Users {
+		var user models.User
+		if err := tx.Where("username = ?", u.Username).First(&user).Error; err == nil {
+			ids.users[u.ID] = user.ID
+			report.Counts["users_merged"]++
+			continue
+		}
+		user = models.User{
+			Username: u.Username, Email: u.Email, EmailVerified: u.EmailVerified, Password: u.Password, Role: u.Role, Banned: u.Banned,
+			IPAddresses: u.IPAddresses, SocialLinks: u.SocialLinks, TOTPEnabled: u.TOTPEnabled, TOTPSecret: u.TOTPSecret,
+			TOTPLastStep: u.TOTPLastStep, RecoveryCodes: u.RecoveryCodes, CreatedAt: u.CreatedAt,
+		}
+		if err := tx.Omit(clause.Associations).Create(&user).Error; err != nil {
+			return fmt.Errorf("failed to restore user %s: %w", u.Username, err)
+		}
+		ids.users[u.ID] = user.ID
+		report.Counts["users"]++
+	}
+
+	// An SSO subject can only be linked once, merged users may already have theirs
+	for _, identity := range data.Identities {
+		userID, ok := ids.users[identity.UserID]
+		if !ok {
+			continue
+		}
+		var count int64
+		tx.Model(&models.UserIdentity{}).Where("provider = ? AND subject = ?", identity.Provider, identity.Subject).Count(&count)
+		if count > 0 {
+			report.warn("skipped %s identity %s: already linked", identity.Provider, identity.Subject)
+			continue
+		}
+		link := models.UserIdentity{
+			UserID: userID, Provider: identity.Provider, Subject: identity.Subject, Email: identity.Email,
+			CreatedAt: identity.CreatedAt, LastLoginAt: identity.LastLoginAt,
+		}
+		if err := tx.Omit(clause.Associations).Create(&link).Error; err != nil {
+			return fmt.Errorf("failed to restore user identity: %w", err)
+		}
+		report.Counts["user_identities"]++
+	}
+
+	// Brackets are matched by name, like users by username
+	for _, b := range data.Brackets {
+		bracket := models.Bracket{Name: b.Name}
+		if err := tx.Where("name = ?", b.Name).
+			Attrs(models.Bracket{Description: b.Description, CreatedAt: b.CreatedAt}).
+			FirstOrCreate(&bracket).Error; err != nil {
+			return fmt.Errorf("failed to restore bracket %s: %w", b.Name, err)
+		}
+		ids.brackets[b.ID] = bracket.ID
+		report.Counts["brackets"]++
+	}
+
+	for _, t := range data.Teams {
+		creatorID, ok := ids.users[t.CreatorID]
+		if !ok {
+			report.warn("skipped team %s: unknown creator", t.Name)
+			continue
+		}
+		bracketID, ok := ids.optional(ids.brackets, t.BracketID)
+		if !ok {
+			report.warn("team %s: unknown bracket %d", t.Name, *t.BracketID)
+		}
+		team := models.Team{Name: t.Name, Password: t.Password, CreatorID: creatorID, BracketID: bracketID, CreatedAt: t.CreatedAt}
+		if err := tx.Omit(clause.Associations).Create(&team).Error; err != nil {
+			return fmt.Errorf("failed to restore team %s: %w", t.Name, err)
+		}
+		ids.teams[t.ID] = team.ID
+		report.Counts["teams"]++
+	}
+
+	for _, u := range data.Users {
+		if u.TeamID == nil {
+			continue
+		}
+		teamID, ok := ids.teams[*u.TeamID]
+		if !ok {
+			report.warn("user %s: unknown team %d", u.Username, *u.TeamID)
+			continue
+		}
+		if err := tx.Model(&models.User{}).Where("id = ?", ids.users[u.ID]).Update("team_id", teamID).Error; err != nil {
+			return fmt.Errorf("failed to link user %s to team: %w", u.Username, err)
+		}
+	}
+	return nil
+}
+
+// restoreChallenges recreates challenges with their lookup entities, flags, hints and geo spec
+func restoreChallenges(tx *gorm.DB, challenges []archivedChallenge, ids *eventIDMaps, report *EventRestoreReport) error {
+	for _, ac := range challenges {
+		category := models.ChallengeCategory{Name: ac.Category}
+		if err := tx.Where("name = ?", ac.Category).FirstOrCreate(&category).Error; err != nil {
+			return fmt.Errorf("failed to restore category %s: %w", ac.Category, err)
+		}
+		difficulty := models.ChallengeDifficulty{Name: ac.Difficulty}
+		if err := tx.Where("name = ?", ac.Difficulty).FirstOrCreate(&difficulty).Error; err != nil {
+			return fmt.Errorf("failed to restore difficulty %s: %w", ac.Difficulty, err)
+		}
+		cType := models.ChallengeType{Name: ac.Type}
+		if err := tx.Where("name = ?", ac.Type).FirstOrCreate(&cType).Error; err != nil {
+			return fmt.Errorf("failed to restore type %s: %w", ac.Type, err)
+		}
+
+		challenge := models.Challenge{
+			Slug: ac.Slug, Name: ac.Name, Description: ac.Description,
+			ChallengeCategoryID: category.ID, ChallengeDifficultyID: difficulty.ID, ChallengeTypeID: cType.ID,
+			Author: ac.Author, Hidden: ac.Hidden, Files: ac.Files, Ports: ac.Ports, ConnectionInfo: ac.ConnectionInfo,
+			Points: ac.Points, Order: ac.Order, EnableFirstBlood: ac.EnableFirstBlood,
+			FirstBloodBonuses: ac.FirstBloodBonuses, FirstBloodBadges: ac.FirstBloodBadges,
+			MaxAttempts: ac.MaxAttempts, DependsOn: ac.DependsOn, CoverImg: ac.CoverImg, Emoji: ac.Emoji,
+			CoverPositionX: ac.CoverPositionX, CoverPositionY: ac.CoverPositionY, CoverZoom: ac.CoverZoom,
+			CreatedAt: ac.CreatedAt,
+		}
+		if ac.Decay != nil {
+			decay := models.DecayFormula{Name: ac.Decay.Name}
+			if err := tx.Where("name = ?", ac.Decay.Name).
+				Attrs(models.DecayFormula{Type: ac.Decay.Type, Step: ac.Decay.Step, MinPoints: ac.Decay.MinPoints}).
+				FirstOrCreate(&decay).Error; err != nil {
+				return fmt.Errorf("failed to restore decay formula %s: %w", ac.Decay.Name, err)
+			}
+			challenge.DecayFormulaID = decay.ID
+		}
+		if err := tx.Omit(clause.Associations).Create(&challenge).Error; err != nil {
+			return fmt.Errorf("failed to restore challenge %s: %w", ac.Slug, err)
+		}
+		ids.challenges[ac.ID] = challenge.ID
+
+		// Flags are already hashed in the archive
+		for _, hash := range ac.FlagHashes {
+			flag := models.Flag{Value: hash, ChallengeID: challenge.ID}
+			if err := tx.Omit(clause.Associations).Create(&flag).Error; err != nil {
+				return fmt.Errorf("failed to restore flags for %s: %w", ac.Slug, err)
+			}
+		}
+
+		for _, h := range ac.Hints {
+			hint := models.Hint{
+				ChallengeID: challenge.ID, Title: h.Title, Content: h.Content, Cost: h.Cost,
+				IsActive: h.IsActive, AutoActiveAt: h.AutoActiveAt, CreatedAt: h.CreatedAt,
+			}
+			if err := tx.Omit(clause.Associations).Create(&hint).Error; err != nil {
+				return fmt.Errorf("failed to restore hints for %s: %w", ac.Slug, err)
+			}
+			ids.hints[h.ID] = hint.ID
+		}
+
+		if err := SetChallengeTags(tx, &challenge, ac.Tags); err != nil {
+			return fmt.Errorf("failed to restore tags for %s: %w", ac.Slug, err)
+		}
+
+		if ac.GeoSpec != nil {
+			spec := models.GeoSpec{
+				ChallengeID: challenge.ID, TargetLat: ac.GeoSpec.TargetLat,
+				TargetLng: ac.GeoSpec.TargetLng, RadiusKm: ac.GeoSpec.RadiusKm,
+			}
+			if err := tx.Create(&spec).Error; err != nil {
+				return fmt.Errorf("failed to restore geo spec for %s: %w", ac.Slug, err)
+			}
+		}
+		report.Counts["challenges"]++
+	}
+	return nil
+}
+
+// restoreScoring recreates solves, submissions, hint purchases and first bloods
+func restoreScoring(tx *gorm.DB, data *eventArchiveData, ids *eventIDMaps, report *EventRestoreReport) error {
+	for _, s := range data.Solves {
+		teamID, okTeam := ids.teams[s.TeamID]
+		challengeID, okChallenge := ids.challenges[s.ChallengeID]
+		userID, okUser := ids.users[s.UserID]
+		if !okTeam || !okChallenge || !okUser {
+			report.warn("skipped solve of challenge %d by team %d: unknown reference", s.ChallengeID, s.TeamID)
+			continue
+		}
+		solve := models.Solve{
+			TeamID: teamID, ChallengeID: challengeID, UserID: userID, Points: s.Points,
+			SolvedBy: s.SolvedBy, CreatedAt: s.CreatedAt,
+		}
+		if err := tx.Omit(clause.Associations).Create(&solve).Error; err != nil {
+			return fmt.Errorf("failed to restore solve: %w", err)
+		}
+		report.Counts["solves"]++
+	}
+
+	for _, s := range data.Submissions {
+		userID, okUser := ids.users[s.UserID]
+		challengeID, okChallenge := ids.challenges[s.ChallengeID]
+		if !okUser || !okChallenge {
+			report.warn("skipped submission %d: unknown reference", s.ID)
+			continue
+		}
+		submission := models.Submission{
+			Value: s.Value, IsCorrect: s.IsCorrect, UserID: userID, ChallengeID: challengeID, CreatedAt: s.CreatedAt,
+		}
+		if err := tx.Omit(clause.Associations).Create(&submission).Error; err != nil {
+			return fmt.Errorf("failed to restore submission: %w", err)
+		}
+		report.Counts["submissions"]++
+	}
+
+	for _, p := range data.HintPurchases {
+		teamID, okTeam := ids.teams[p.TeamID]
+		hintID, okHint := ids.hints[p.HintID]
+		userID, okUser := ids.users[p.UserID]
+		if !okTeam || !okHint || !okUser {
+			report.warn("skipped purchase of hint %d: unknown reference", p.HintID)
+			continue
+		}
+		purchase := models.HintPurchase{
+			TeamID: teamID, HintID: hintID, UserID: userID, Cost: p.Cost, CreatedAt: p.CreatedAt,
+		}
+		if err := tx.Omit(clause.Associations).Create(&purchase).Error; err != nil {
+			return fmt.Errorf("failed to restore hint purchase: %w", err)
+		}
+		report.Counts["hint_purchases"]++
+	}
+
+	for _, fb := range data.FirstBloods {
+		teamID, okTeam := ids.teams[fb.TeamID]
+		challengeID, okChallenge := ids.challenges[fb.ChallengeID]
+		userID, okUser := ids.users[fb.UserID]
+		if !okTeam || !okChallenge || !okUser {
+			report.warn("skipped first blood %d: unknown reference", fb.ID)
+			continue
+		}
+		firstBlood := models.FirstBlood{
+			ChallengeID: challengeID, TeamID: teamID, UserID: userID,
+			Bonuses: fb.Bonuses, Badges: fb.Badges, CreatedAt: fb.CreatedAt,
+		}
+		if err := tx.Omit(clause.Associations).Create(&firstBlood).Error; err != nil {
+			return fmt.Errorf("failed to restore first blood: %w", err)
+		}
+		report.Counts["first_bloods"]++
+	}
+	return nil
+}
+
+// restoreBadges recreates badges when the badge tables exist on the target
+func restoreBadges(tx *gorm.DB, data *eventArchiveData, ids *eventIDMaps, report *EventRestoreReport) error {
+	if len(data.Badges) == 0 && len(data.UserBadges) == 0 {
+		return nil
+	}
+	if !tx.Migrator().HasTable(&models.Badge{}) || !tx.Migrator().HasTable(&models.UserBadge{}) {
+		report.warn("skipped %d badges: badge tables do not exist", len(data.Badges))
+		return nil
+	}
+
+	for _, b := range data.Badges {
+		badge := models.Badge{
+			Name: b.Name, Description: b.Description, Icon: b.Icon, Color: b.Color, Type: b.Type, CreatedAt: b.CreatedAt,
+		}
+		if err := tx.Create(&badge).Error; err != nil {
+			return fmt.Errorf("failed to restore badge %s: %w", b.Name, err)
+		}
+		ids.badges[b.ID] = badge.ID
+		report.Counts["badges"]++
+	}
+
+	for _, ub := range data.UserBadges {
+		userID, okUser := ids.users[ub.UserID]
+		badgeID, okBadge := ids.badges[ub.BadgeID]
+		challengeID, okChallenge := ids.optional(ids.challenges, ub.ChallengeID)
+		teamID, okTeam := ids.optional(ids.teams, ub.TeamID)
+		if !okUser || !okBadge || !okChallenge || !okTeam {
+			report.warn("skipped user badge %d: unknown reference", ub.ID)
+			continue
+		}
+		userBadge := models.UserBadge{
+			UserID: userID, BadgeID: badgeID, ChallengeID: challengeID, TeamID: teamID, AwardedAt: ub.AwardedAt,
+		}
+		if err := tx.Omit(clause.Associations).Create(&userBadge).Error; err != nil {
+			return fmt.Errorf("failed to restore user badge: %w", err)
+		}
+	}
+	return nil
+}
+
+// restoreNotifications recreates notifications with their resolved recipients, per-user read state and revisions
+func restoreNotifications(tx *gorm.DB, data *eventArchiveData, ids *eventIDMaps, report *EventRestoreReport) error {
+	for _, n := range data.Notifications {
+		userID, okUser := ids.optional(ids.users, n.UserID)
+		teamID, okTeam := ids.optional(ids.teams, n.TeamID)
+		challengeID, okChallenge := ids.optional(ids.challenges, n.UnsolvedChallengeID)
+		bracketID, okBracket := ids.optional(ids.brackets, n.TargetBracketID)
+		if !okUser || !okTeam || !okChallenge || !okBracket {
+			report.warn("skipped notification %d: unknown recipient", n.ID)
+			continue
+		}
+		notification := models.Notification{
+			Title: n.Title, Message: n.Message, Type: n.Type, UserID: userID, TeamID: teamID,
+			TargetRole: n.TargetRole, UnsolvedChallengeID: challengeID, TargetBracketID: bracketID, SendAt: n.SendAt, Pending: n.Pending, Pinned: n.Pinned, EditedAt: n.EditedAt,
+			CreatedAt: n.CreatedAt,
+		}
+		if err := tx.Omit(clause.Associations).Create(&notification).Error; err != nil {
+			return fmt.Errorf("failed to restore notification: %w", err)
+		}
+		ids.notifications[n.ID] = notification.ID
+		report.Counts["notifications"]++
+	}
+
+	for _, r := range data.Recipients {
+		notificationID, okNotification := ids.notifications[r.NotificationID]
+		userID, okUser := ids.users[r.UserID]
+		if !okNotification || !okUser {
+			continue
+		}
+		recipient := models.NotificationRecipient{NotificationID: notificationID, UserID: userID}
+		if err := tx.Omit(clause.Associations).Create(&recipient).Error; err != nil {
+			return fmt.Errorf("failed to restore notification recipient: %w", err)
+		}
+	}
+
+	for _, r := range data.Reads {
+		notificationID, okNotification := ids.notifications[r.NotificationID]
+		userID, okUser := ids.users[r.UserID]
+		if !okNotification || !okUser {
+			continue
+		}
+		read := models.NotificationRead{NotificationID: notificationID, UserID: userID, ReadAt: r.ReadAt}
+		if err := tx.Omit(clause.Associations).Create(&read).Error; err != nil {
+			return fmt.Errorf("failed to restore notification read: %w", err)
+		}
+	}
+
+	for _, r := range data.Revisions {
+		notificationID, ok := ids.notifications[r.NotificationID]
+		if !ok {
+			continue
+		}
+		editorID, ok := ids.optional(ids.users, r.EditorID)
+		if !ok {
+			editorID = nil
+		}
+		revision := models.NotificationRevision{
+			NotificationID: notificationID, Title: r.Title, Message: r.Message, Type: r.Type, EditorID: editorID, CreatedAt: r.CreatedAt,
+		}
+		if err := tx.Omit(clause.Associations).Create(&revision).Error; err != nil {
+			return fmt.Errorf("failed to restore notification revision: %w", err)
+		}
+	}
+	return nil
+}
+
+// restoreTickets recreates tickets, their messages and the canned responses
+func restoreTickets(tx *gorm.DB, data *eventArchiveData, ids *eventIDMaps, report *EventRestoreReport) error {
+	for _, t := range data.Tickets {
+		userID, okUser := ids.users[t.UserID]
+		teamID, okTeam := ids.optional(ids.teams, t.TeamID)
+		challengeID, okChallenge := ids.optional(ids.challenges, t.ChallengeID)
+		claimedByID, okClaimed := ids.optional(ids.users, t.ClaimedByID)
+		if !okUser || !okTeam || !okChallenge || !okClaimed {
+			report.warn("skipped ticket %d: unknown reference", t.ID)
+			continue
+		}
+		ticket := models.Ticket{
+			Subject: t.Subject, Description: t.Description, Status: t.Status, TicketType: t.TicketType,
+			Priority: t.Priority, Tags: t.Tags,
+			UserID: userID, TeamID: teamID, ChallengeID: challengeID, ClaimedByID: claimedByID,
+			Attachments: t.Attachments, CreatedAt: t.CreatedAt, ClaimedAt: t.ClaimedAt, ResolvedAt: t.ResolvedAt,
+			ReopenedAt: t.ReopenedAt, FirstResponseAt: t.FirstResponseAt,
+		}
+		if err := tx.Omit(clause.Associations).Create(&ticket).Error; err != nil {
+			return fmt.Errorf("failed to restore ticket %d: %w", t.ID, err)
+		}
+		ids.tickets[t.ID] = ticket.ID
+		report.Counts["tickets"]++
+	}
+
+	for _, m := range data.TicketMessages {
+		ticketID, okTicket := ids.tickets[m.TicketID]
+		userID, okUser := ids.users[m.UserID]
+		if !okTicket || !okUser {
+			continue
+		}
+		message := models.TicketMessage{
+			TicketID: ticketID, UserID: userID, Message: m.Message, IsAdmin: m.IsAdmin, IsInternal: m.IsInternal,
+			Attachments: m.Attachments, CreatedAt: m.CreatedAt,
+		}
+		if err := tx.Omit(clause.Associations).Create(&message).Error; err != nil {
+			return fmt.Errorf("failed to restore ticket message: %w", err)
+		}
+	}
+
+	for _, r := range data.CannedReplies {
+		var count int64
+		tx.Model(&models.TicketCannedResponse{}).Where("title = ?", r.Title).Count(&count)
+		if count > 0 {
+			report.warn("skipped canned response %s: title already exists", r.Title)
+			continue
+		}
+		createdByID, ok := ids.optional(ids.users, r.CreatedByID)
+		if !ok {
+			createdByID = nil
+		}
+		reply := models.TicketCannedResponse{Title: r.Title, Message: r.Message, CreatedByID: createdByID, CreatedAt: r.CreatedAt}
+		if err := tx.Omit(clause.Associations).Create(&reply).Error; err != nil {
+			return fmt.Errorf("failed to restore canned response %s: %w", r.Title, err)
+		}
+		report.Counts["canned_responses"]++
+	}
+	return nil
+}
+
+// restoreEventObjects uploads challenge bundles and page contents back to MinIO
+func restoreEventObjects(files map[string]*zip.File, report *EventRestoreReport) {
+	ctx := context.Background()
+
+	for name, f := range files {
+		switch {
+		case strings.HasPrefix(name, eventArchiveChallengeDir) && strings.HasSuffix(name, ".zip"):
+			slug := strings.TrimSuffix(strings.TrimPrefix(name, eventArchiveChallengeDir), ".zip")
+			if GenerateSlug(slug) != slug {
+				report.warn("skipped challenge bundle %s: invalid slug", name)
+				continue
+			}
+			bundle, err := readZipEntry(f)
+			if err != nil {
+				report.warn("failed to read bundle of %s: %v", slug, err)
+				continue
+			}
+			if err := restoreChallengeBundle(ctx, slug, bundle); err != nil {
+				report.warn("failed to upload files of %s: %v", slug, err)
+			}
+
+		case strings.HasPrefix(name, eventArchivePageDir):
+			key := strings.TrimPrefix(name, eventArchivePageDir)
+			if !safeArchivePath(key) {
+				report.warn("skipped page object %s: invalid path", name)
+				continue
+			}
+			content, err := readZipEntry(f)
+			if err != nil {
+				report.warn("failed to read page object %s: %v", key, err)
+				continue
+			}
+			if _, err := config.FS.PutObject(ctx, bucketNamePages, key, bytes.NewReader(content), int64(len(content)), minio.PutObjectOptions{
+				ContentType: "text/html",
+			}); err != nil {
+				report.warn("failed to upload page object %s: %v", key, err)
+			}
+		}
+	}
+}
+
+// restoreChallengeBundle stores the bundle in the challenge-files bucket and its files in the challenges bucket
+// chall.yml is not uploaded: its flags are redacted and the challenge is already restored in the database
+func restoreChallengeBundle(ctx context.Context, slug string, bundle []byte) error {
+	zipReader, err := zip.NewReader(bytes.NewReader(bundle), int64(len(bundle)))
+	if err != nil {
+		return err
+	}
+	for _, f := range zipReader.File {
+		if !safeArchivePath(f.Name) {
+			return fmt.Errorf("invalid file path: %s", f.Name)
+		}
+	}
+
+	zipPath := fmt.Sprintf("challenges/%s.zip", slug)
+	if _, err := config.FS.PutObject(ctx, bucketNameChallengeFiles, zipPath, bytes.NewReader(bundle), int64(len(bundle)), minio.PutObjectOptions{
+		ContentType: "application/zip",
+	}); err != nil {
+		return err
+	}
+
+	for _, f := range zipReader.File {
+		if f.FileInfo().IsDir() || shouldSkipFile(f.Name) {
+			continue
+		}
+		content, err := readZipEntry(f)
+		if err != nil {
+			return err
+		}
+		objectKey := path.Join(slug, f.Name)
+		if _, err := config.FS.PutObject(ctx, bucketNameChallenges, objectKey, bytes.NewReader(content), int64(len(content)), minio.PutObjectOptions{}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// safeArchivePath reports whether a zip entry name stays under the prefix it is joined to
+func safeArchivePath(name string) bool {
+	if name == "" || path.IsAbs(name) || strings.Contains(name, "\\") {
+		return false
+	}
+	for _, part := range strings.Split(name, "/") {
+		if part == ".." {
+			return false
+		}
+	}
+	return true
+}
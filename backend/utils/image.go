@@ -1,16 +1,16 @@
 package utils
 
 import (
-	"github.com/pwnthemall/pwnthemall/backend/debug"
 	"bytes"
 	"context"
 	"errors"
 	"fmt"
+	"github.com/pwnthemall/pwnthemall/backend/logger"
 	"image"
 	_ "image/gif"  // Register GIF format
 	_ "image/jpeg" // Register JPEG format
 	_ "image/png"  // Register PNG format
-	
+
 	"time"
 
 	"github.com/disintegration/imaging"
@@ -21,11 +21,11 @@ import (
 
 const (
 	// Image constraints
-	maxImageFileSize   = 5 * 1024 * 1024 // 5MB
-	maxImageWidth      = 8000
-	maxImageHeight     = 8000
-	targetImageWidth   = 800
-	targetImageHeight  = 450
+	maxImageFileSize  = 5 * 1024 * 1024 // 5MB
+	maxImageWidth     = 8000
+	maxImageHeight    = 8000
+	targetImageWidth  = 800
+	targetImageHeight = 450
 )
 
 // validateImageData performs security checks on image data
@@ -59,7 +59,7 @@ func validateImageData(data []byte) (string, error) {
 		return "", errors.New("invalid image dimensions")
 	}
 
-	debug.Log("Image validated: format=%s, size=%dx%d, bytes=%d", format, width, height, len(data))
+	logger.Debugf("Image validated: format=%s, size=%dx%d, bytes=%d", format, width, height, len(data))
 	return format, nil
 }
 
@@ -81,9 +81,9 @@ func resizeImageToPNG(data []byte) ([]byte, error) {
 		return nil, fmt.Errorf("failed to encode image: %w", err)
 	}
 
-	debug.Log("Image resized: %dx%d -> %dx%d, output size: %d bytes", 
-		img.Bounds().Dx(), img.Bounds().Dy(), 
-		targetImageWidth, targetImageHeight, 
+	logger.Debugf("Image resized: %dx%d -> %dx%d, output size: %d bytes",
+		img.Bounds().Dx(), img.Bounds().Dy(),
+		targetImageWidth, targetImageHeight,
 		buf.Len())
 
 	return buf.Bytes(), nil
@@ -120,10 +120,10 @@ func ProcessChallengeCoverImage(ctx context.Context, slug, filename string) (str
 // processImageSync performs the actual image processing synchronously
 func processImageSync(slug, filename string) (string, error) {
 	const bucketName = "challenges"
-	
+
 	// 1. Load original image from MinIO
 	originalPath := fmt.Sprintf("%s/%s", slug, filename)
-	debug.Log("Loading cover image: %s", originalPath)
+	logger.Debugf("Loading cover image: %s", originalPath)
 
 	obj, err := config.FS.GetObject(context.Background(), bucketName, originalPath, minio.GetObjectOptions{})
 	if err != nil {
@@ -147,7 +147,7 @@ func processImageSync(slug, filename string) (string, error) {
 	// 3. If GIF, keep original to preserve animation
 	if format == "gif" {
 		resizedPath := fmt.Sprintf("%s/cover_resized.gif", slug)
-		debug.Log("GIF detected, storing original: %s", resizedPath)
+		logger.Debugf("GIF detected, storing original: %s", resizedPath)
 
 		_, err = config.FS.PutObject(
 			context.Background(),
@@ -163,7 +163,7 @@ func processImageSync(slug, filename string) (string, error) {
 			return "", fmt.Errorf("failed to store GIF: %w", err)
 		}
 
-		debug.Log("Cover image processed successfully: %s", resizedPath)
+		logger.Infof("Cover image processed successfully: %s", resizedPath)
 		return "cover_resized.gif", nil
 	}
 
@@ -176,7 +176,7 @@ func processImageSync(slug, filename string) (string, error) {
 
 	// 5. Store image in MinIO (keeping original dimensions)
 	resizedPath := fmt.Sprintf("%s/cover_resized.png", slug)
-	debug.Log("Storing image: %s", resizedPath)
+	logger.Debugf("Storing image: %s", resizedPath)
 
 	// Convert to PNG without resizing
 	img, _, err := image.Decode(bytes.NewReader(imageData))
@@ -202,7 +202,7 @@ func processImageSync(slug, filename string) (string, error) {
 		return "", fmt.Errorf("failed to store image: %w", err)
 	}
 
-	debug.Log("Cover image processed successfully: %s", resizedPath)
+	logger.Infof("Cover image processed successfully: %s", resizedPath)
 	return "cover_resized.png", nil
 }
 
@@ -1,79 +1,104 @@
 package utils
 
 import (
+	"fmt"
 	"os"
 	"strconv"
-	"sync"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v5"
 )
 
+const (
+	AccessTokenTTL  = 45 * time.Minute
+	RefreshTokenTTL = 7 * 24 * time.Hour
+)
+
 var (
 	AccessSecret  = []byte(os.Getenv("JWT_SECRET"))
 	RefreshSecret = []byte(os.Getenv("REFRESH_SECRET"))
-	// TokenBlacklist stores invalidated tokens (in production, use Redis)
-	TokenBlacklist = &sync.Map{}
 )
 
+// TokenClaims are the claims of an access token, SessionID is the jti of the refresh token it was issued from
 type TokenClaims struct {
-	UserID uint   `json:"user_id"`
-	Role   string `json:"role"`
+	UserID    uint   `json:"user_id"`
+	Role      string `json:"role"`
+	SessionID string `json:"sid"`
 	jwt.RegisteredClaims
 }
 
-func GenerateAccessToken(userID uint, role string) (string, error) {
+func GenerateAccessToken(userID uint, role string, sessionID string) (string, error) {
+	jti, err := GenerateRandomString(16)
+	if err != nil {
+		return "", err
+	}
 	claims := TokenClaims{
-		UserID: userID,
-		Role:   role,
+		UserID:    userID,
+		Role:      role,
+		SessionID: sessionID,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(45 * time.Minute)),
+			ID:        jti,
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(AccessTokenTTL)),
 		},
 	}
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
 	return token.SignedString(AccessSecret)
 }
 
-func GenerateRefreshToken(userID uint) (string, error) {
+// GenerateRefreshToken returns a refresh token with its jti and expiry
+func GenerateRefreshToken(userID uint) (string, string, time.Time, error) {
+	jti, err := GenerateRandomString(16)
+	if err != nil {
+		return "", "", time.Time{}, err
+	}
+	expiresAt := time.Now().Add(RefreshTokenTTL)
 	claims := jwt.RegisteredClaims{
+		ID:        jti,
 		Subject:   strconv.Itoa(int(userID)),
-		ExpiresAt: jwt.NewNumericDate(time.Now().Add(7 * 24 * time.Hour)),
+		ExpiresAt: jwt.NewNumericDate(expiresAt),
 	}
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString(RefreshSecret)
+	signed, err := token.SignedString(RefreshSecret)
+	return signed, jti, expiresAt, err
 }
 
-func GetClaimsFromCookie(c *gin.Context) (*TokenClaims, error) {
-	tokenStr, err := c.Cookie("access_token")
-	if err != nil {
-		return nil, err
+// ParseAccessToken validates an access token and checks it was not revoked
+// Tokens issued before jti support carry no ID and are refused
+func ParseAccessToken(tokenStr string) (*TokenClaims, error) {
+	token, err := jwt.ParseWithClaims(tokenStr, &TokenClaims{}, func(token *jwt.Token) (interface{}, error) {
+		return AccessSecret, nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}))
+	if err != nil || !token.Valid {
+		return nil, fmt.Errorf("invalid token")
 	}
 
-	// Check if token is blacklisted
-	if _, blacklisted := TokenBlacklist.Load(tokenStr); blacklisted {
+	claims := token.Claims.(*TokenClaims)
+	if claims.ID == "" || claims.SessionID == "" || IsTokenRevoked(claims.ID, claims.SessionID) {
 		return nil, jwt.ErrTokenInvalidClaims
 	}
+	return claims, nil
+}
 
-	token, err := jwt.ParseWithClaims(tokenStr, &TokenClaims{}, func(token *jwt.Token) (interface{}, error) {
-		return AccessSecret, nil
-	})
+// ParseRefreshToken validates a refresh token and checks it was not revoked
+func ParseRefreshToken(tokenStr string) (*jwt.RegisteredClaims, error) {
+	claims := &jwt.RegisteredClaims{}
+	token, err := jwt.ParseWithClaims(tokenStr, claims, func(t *jwt.Token) (interface{}, error) {
+		return RefreshSecret, nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}))
 	if err != nil || !token.Valid {
-		return nil, err
+		return nil, fmt.Errorf("invalid refresh token")
 	}
-
-	return token.Claims.(*TokenClaims), nil
+	if claims.ID == "" || IsTokenRevoked(claims.ID) {
+		return nil, jwt.ErrTokenInvalidClaims
+	}
+	return claims, nil
 }
 
-// BlacklistToken adds a token to the blacklist with TTL based on expiration
-func BlacklistToken(tokenStr string, expiresAt time.Time) {
-	TokenBlacklist.Store(tokenStr, true)
-	// Schedule cleanup after token expiration
-	go func() {
-		ttl := time.Until(expiresAt)
-		if ttl > 0 {
-			time.Sleep(ttl)
-		}
-		TokenBlacklist.Delete(tokenStr)
-	}()
+func GetClaimsFromCookie(c *gin.Context) (*TokenClaims, error) {
+	tokenStr, err := c.Cookie("access_token")
+	if err != nil {
+		return nil, err
+	}
+	return ParseAccessToken(tokenStr)
 }
@@ -0,0 +1,113 @@
+package utils
+
+import (
+	"fmt"
+	"net"
+	"net/smtp"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pwnthemall/pwnthemall/backend/logger"
+)
+
+// MailMessage is a plain text email
+type MailMessage struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer sends emails, the backend is chosen with PTA_MAIL_BACKEND
+type Mailer interface {
+	Send(msg MailMessage) error
+}
+
+var (
+	mailer     Mailer
+	mailerOnce sync.Once
+)
+
+// GetMailer returns the configured mailer: smtp, file or log (default)
+func GetMailer() Mailer {
+	mailerOnce.Do(func() {
+		from := GetEnvWithDefault("PTA_MAIL_FROM", "pwnthemall@localhost")
+		switch strings.ToLower(os.Getenv("PTA_MAIL_BACKEND")) {
+		case "smtp":
+			mailer = &SMTPMailer{
+				Host:     os.Getenv("PTA_SMTP_HOST"),
+				Port:     GetEnvWithDefault("PTA_SMTP_PORT", "587"),
+				Username: os.Getenv("PTA_SMTP_USERNAME"),
+				Password: os.Getenv("PTA_SMTP_PASSWORD"),
+				From:     from,
+			}
+		case "file":
+			mailer = &FileMailer{Dir: GetEnvWithDefault("PTA_MAIL_DIR", "/tmp/pwnthemall-mails"), From: from}
+		default:
+			mailer = &LogMailer{}
+		}
+	})
+	return mailer
+}
+
+// SMTPMailer sends emails through an SMTP relay, STARTTLS is used when the server offers it
+type SMTPMailer struct {
+	Host     string
+	Port     string
+	Username string
+	Password string
+	From     string
+}
+
+func (m *SMTPMailer) Send(msg MailMessage) error {
+	if m.Host == "" {
+		return fmt.Errorf("PTA_SMTP_HOST is not set")
+	}
+	var auth smtp.Auth
+	if m.Username != "" {
+		auth = smtp.PlainAuth("", m.Username, m.Password, m.Host)
+	}
+	return smtp.SendMail(net.JoinHostPort(m.Host, m.Port), auth, m.From, []string{msg.To}, buildMail(m.From, msg))
+}
+
+// FileMailer writes each email as an .eml file, for local testing
+type FileMailer struct {
+	Dir  string
+	From string
+}
+
+func (m *FileMailer) Send(msg MailMessage) error {
+	if err := os.MkdirAll(m.Dir, 0o700); err != nil {
+		return err
+	}
+	suffix, err := GenerateRandomString(4)
+	if err != nil {
+		return err
+	}
+	name := fmt.Sprintf("%s-%s.eml", time.Now().Format("20060102-150405"), suffix)
+	return os.WriteFile(filepath.Join(m.Dir, name), buildMail(m.From, msg), 0o600)
+}
+
+// LogMailer prints emails to the debug log instead of sending them
+type LogMailer struct{}
+
+func (m *LogMailer) Send(msg MailMessage) error {
+	logger.Debugf("Mail to %s: %s\n%s", msg.To, msg.Subject, msg.Body)
+	return nil
+}
+
+// buildMail renders the message, header values are stripped of line breaks to prevent header injection
+func buildMail(from string, msg MailMessage) []byte {
+	clean := strings.NewReplacer("\r", "", "\n", "")
+	var b strings.Builder
+	fmt.Fprintf(&b, "From: %s\r\n", clean.Replace(from))
+	fmt.Fprintf(&b, "To: %s\r\n", clean.Replace(msg.To))
+	fmt.Fprintf(&b, "Subject: %s\r\n", clean.Replace(msg.Subject))
+	fmt.Fprintf(&b, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=UTF-8\r\n\r\n")
+	b.WriteString(strings.ReplaceAll(msg.Body, "\n", "\r\n"))
+	return []byte(b.String())
+}
@@ -3,11 +3,11 @@ package utils
 import (
 	"bytes"
 	"context"
-	"encoding/json"
 	"fmt"
 	"io"
 
-	"github.com/pwnthemall/pwnthemall/backend/debug"
+	"github.com/pwnthemall/pwnthemall/backend/events"
+	"github.com/pwnthemall/pwnthemall/backend/logger"
 
 	"os"
 	"path/filepath"
@@ -17,6 +17,7 @@ import (
 
 	"github.com/pwnthemall/pwnthemall/backend/config"
 	"github.com/pwnthemall/pwnthemall/backend/meta"
+	"github.com/pwnthemall/pwnthemall/backend/metrics"
 	"github.com/pwnthemall/pwnthemall/backend/models"
 
 	"github.com/lib/pq"
@@ -147,7 +148,7 @@ func parseChallengeByType(base meta.BaseChallengeMetadata, content []byte, objec
 
 // SyncAllChallengesFromMinIO syncs all challenges from MinIO on startup
 func SyncAllChallengesFromMinIO(ctx context.Context, updatesHub *Hub) error {
-	debug.Log("Starting initial sync of all challenges from MinIO bucket: %s", bucketNameChallenges)
+	logger.Infof("Starting initial sync of all challenges from MinIO bucket: %s", bucketNameChallenges)
 
 	// List all objects in the challenges bucket
 	objectCh := config.FS.ListObjects(ctx, bucketNameChallenges, minio.ListObjectsOptions{
@@ -159,7 +160,8 @@ func SyncAllChallengesFromMinIO(ctx context.Context, updatesHub *Hub) error {
 
 	for object := range objectCh {
 		if object.Err != nil {
-			debug.Log("Error listing object: %v", object.Err)
+			logger.Errorf("Error listing object: %v", object.Err)
+			metrics.MinioSyncErrors.WithLabelValues("challenge").Inc()
 			errorCount++
 			continue
 		}
@@ -172,31 +174,39 @@ func SyncAllChallengesFromMinIO(ctx context.Context, updatesHub *Hub) error {
 		// Sync this challenge
 		key := bucketNameChallenges + "/" + object.Key
 		if err := SyncChallengesFromMinIO(ctx, key, updatesHub); err != nil {
-			debug.Log("Error syncing %s: %v", object.Key, err)
+			logger.Errorf("Error syncing %s: %v", object.Key, err)
 			errorCount++
 		} else {
 			syncCount++
 		}
 	}
 
-	debug.Log("Initial sync completed: %d challenges synced, %d errors", syncCount, errorCount)
+	logger.Errorf("Initial sync completed: %d challenges synced, %d errors", syncCount, errorCount)
 	return nil
 }
 
+// SyncChallengesFromMinIO syncs a single challenge from MinIO and records the sync duration
 func SyncChallengesFromMinIO(ctx context.Context, key string, updatesHub *Hub) error {
+	start := time.Now()
+	err := syncChallengeFromMinIO(ctx, key, updatesHub)
+	metrics.ObserveMinioSync("challenge", start, err)
+	return err
+}
+
+func syncChallengeFromMinIO(ctx context.Context, key string, updatesHub *Hub) error {
 	objectKey := parseObjectKey(key)
-	debug.Log("SyncChallengesFromMinIO begin for bucket: %s, key: %s", bucketNameChallenges, objectKey)
+	logger.Debugf("SyncChallengesFromMinIO begin for bucket: %s, key: %s", bucketNameChallenges, objectKey)
 
 	// Try to retrieve and validate object
 	obj, err := retrieveAndValidateObject(ctx, bucketNameChallenges, objectKey)
 	if err != nil {
-		debug.Log("Object not found or error retrieving object %s: %v", objectKey, err)
+		logger.Errorf("Object not found or error retrieving object %s: %v", objectKey, err)
 		slug := strings.Split(objectKey, "/")[0]
 		if err := deleteChallengeFromDB(slug); err != nil {
-			debug.Log("Error deleting challenge from DB: %v", err)
+			logger.Errorf("Error deleting challenge from DB: %v", err)
 			return err
 		}
-		debug.Log("Deleted challenge with slug %s from DB", slug)
+		logger.Debugf("Deleted challenge with slug %s from DB", slug)
 		return nil
 	}
 	defer obj.Close()
@@ -204,28 +214,28 @@ func SyncChallengesFromMinIO(ctx context.Context, key string, updatesHub *Hub) e
 	// Read object content
 	buf, err := readObjectContent(obj)
 	if err != nil {
-		debug.Log("Error reading object %s: %v", objectKey, err)
+		logger.Errorf("Error reading object %s: %v", objectKey, err)
 		return err
 	}
 
 	// Parse base metadata to determine type
 	var base meta.BaseChallengeMetadata
 	if err := yaml.Unmarshal(buf.Bytes(), &base); err != nil {
-		debug.Log("Invalid YAML for %s: %v", objectKey, err)
+		logger.Errorf("Invalid YAML for %s: %v", objectKey, err)
 		return err
 	}
 
 	// Parse type-specific metadata
 	metaData, ports, geoMeta, err := parseChallengeByType(base, buf.Bytes(), objectKey)
 	if err != nil {
-		debug.Log("Error parsing challenge metadata: %v", err)
+		logger.Errorf("Error parsing challenge metadata: %v", err)
 		return err
 	}
 
 	// Update or create the challenge in the database
 	slug := strings.Split(objectKey, "/")[0]
 	if err := updateOrCreateChallengeInDB(metaData, slug, ports, updatesHub); err != nil {
-		debug.Log("Error updating or creating challenge in DB: %v", err)
+		logger.Errorf("Error updating or creating challenge in DB: %v", err)
 		return err
 	}
 
@@ -234,7 +244,7 @@ func SyncChallengesFromMinIO(ctx context.Context, key string, updatesHub *Hub) e
 		saveGeoSpecForChallenge(slug, *geoMeta)
 	}
 
-	debug.Log("Synced %s to DB", objectKey)
+	logger.Debugf("Synced %s to DB", objectKey)
 	return nil
 }
 
@@ -376,9 +386,12 @@ func syncFlags(challengeID uint, flags []string) error {
 	}
 
 	for _, flagValue := range flags {
-		hashed := HashFlag(flagValue)
+		stored, err := StoredFlagValue(flagValue)
+		if err != nil {
+			return err
+		}
 		newFlag := models.Flag{
-			Value:       hashed,
+			Value:       stored,
 			ChallengeID: challengeID,
 		}
 		if err := config.DB.Create(&newFlag).Error; err != nil {
@@ -493,6 +506,8 @@ func updateOrCreateChallengeInDB(metaData meta.BaseChallengeMetadata, slug strin
 		}
 	}
 
+	wasHidden := isNewChallenge || challenge.Hidden
+
 	// Populate challenge fields
 	populateBasicChallengeFields(&challenge, metaData, slug, categoryID, difficultyID, cType, decayFormula, isNewChallenge)
 	setChallengePorts(&challenge, ports)
@@ -510,11 +525,11 @@ func updateOrCreateChallengeInDB(metaData meta.BaseChallengeMetadata, slug strin
 	if metaData.CoverImg != "" {
 		ctx := context.Background()
 		if processedPath, err := ProcessChallengeCoverImage(ctx, slug, metaData.CoverImg); err != nil {
-			debug.Log("Warning: Failed to process cover image for %s: %v", slug, err)
+			logger.Warnf("Failed to process cover image for %s: %v", slug, err)
 			// Don't fail sync - challenge still works without cover image
 		} else {
 			coverImgPath = processedPath
-			debug.Log("Successfully processed cover image for %s: %s", slug, coverImgPath)
+			logger.Infof("Successfully processed cover image for %s: %s", slug, coverImgPath)
 		}
 	}
 	challenge.CoverImg = coverImgPath
@@ -524,16 +539,24 @@ func updateOrCreateChallengeInDB(metaData meta.BaseChallengeMetadata, slug strin
 		return err
 	}
 
-	// Broadcast update
+	// A nil hub marks a silent sync
 	if updatesHub != nil {
-		if payload, err := json.Marshal(map[string]interface{}{
-			"event":  "challenge-category",
-			"action": "minio_sync",
-		}); err == nil {
-			updatesHub.SendToAll(payload)
+		events.Publish(events.ChallengesChanged{Action: "minio_sync"})
+		if wasHidden && !challenge.Hidden {
+			events.Publish(events.ChallengeReleased{
+				ChallengeID: challenge.ID,
+				Name:        challenge.Name,
+				Slug:        challenge.Slug,
+				Category:    metaData.Category,
+				Points:      challenge.Points,
+			})
 		}
 	}
 
+	if err := SetChallengeTags(config.DB, &challenge, metaData.Tags); err != nil {
+		return err
+	}
+
 	// Sync flags and hints
 	if err := syncFlags(challenge.ID, metaData.Flags); err != nil {
 		return err
@@ -550,18 +573,18 @@ func RetrieveFileContentFromMinio(path string) ([]byte, error) {
 	const bucketName = bucketNameChallenges
 	object, err := config.FS.GetObject(context.Background(), bucketName, path, minio.GetObjectOptions{})
 	if err != nil {
-		debug.Println(err)
+		logger.Errorf("Failed to get %s from MinIO: %v", path, err)
 		return nil, err
 	}
 	defer object.Close()
 
 	content, err := io.ReadAll(object)
 	if err != nil {
-		debug.Println(err)
+		logger.Errorf("Failed to read %s from MinIO: %v", path, err)
 		return nil, err
 	}
 
-	debug.Log("File %s retrieved on MinIO", path)
+	logger.Debugf("File %s retrieved on MinIO", path)
 	return content, nil
 }
 
@@ -602,7 +625,7 @@ func DownloadChallengeContext(slug string, localDir string) error {
 
 // SyncAllPagesFromMinIO syncs all pages from MinIO on startup
 func SyncAllPagesFromMinIO(ctx context.Context) error {
-	debug.Log("Starting initial sync of all pages from MinIO bucket: %s", bucketNamePages)
+	logger.Infof("Starting initial sync of all pages from MinIO bucket: %s", bucketNamePages)
 
 	// List all objects in the pages bucket
 	objectCh := config.FS.ListObjects(ctx, bucketNamePages, minio.ListObjectsOptions{
@@ -614,7 +637,8 @@ func SyncAllPagesFromMinIO(ctx context.Context) error {
 
 	for object := range objectCh {
 		if object.Err != nil {
-			debug.Log("Error listing pages: %v", object.Err)
+			logger.Errorf("Error listing pages: %v", object.Err)
+			metrics.MinioSyncErrors.WithLabelValues("page").Inc()
 			errorCount++
 			continue
 		}
@@ -626,21 +650,28 @@ func SyncAllPagesFromMinIO(ctx context.Context) error {
 
 		// Sync this page
 		if err := SyncPagesFromMinIO(ctx, object.Key, nil); err != nil {
-			debug.Log("Failed to sync page %s: %v", object.Key, err)
+			logger.Errorf("Failed to sync page %s: %v", object.Key, err)
 			errorCount++
 		} else {
 			syncCount++
 		}
 	}
 
-	debug.Log("Initial page sync completed: %d pages synced, %d errors", syncCount, errorCount)
+	logger.Errorf("Initial page sync completed: %d pages synced, %d errors", syncCount, errorCount)
 	return nil
 }
 
 // SyncPagesFromMinIO syncs a single page from MinIO (triggered by webhook or manual sync)
 func SyncPagesFromMinIO(ctx context.Context, key string, updatesHub *Hub) error {
+	start := time.Now()
+	err := syncPageFromMinIO(ctx, key, updatesHub)
+	metrics.ObserveMinioSync("page", start, err)
+	return err
+}
+
+func syncPageFromMinIO(ctx context.Context, key string, updatesHub *Hub) error {
 	objectKey := parseObjectKey(key)
-	debug.Log("SyncPagesFromMinIO begin for bucket: %s, key: %s", bucketNamePages, objectKey)
+	logger.Debugf("SyncPagesFromMinIO begin for bucket: %s, key: %s", bucketNamePages, objectKey)
 
 	// Extract slug from path (e.g., "index/page.yml" -> "index")
 	parts := strings.Split(objectKey, "/")
@@ -735,18 +766,12 @@ func SyncPagesFromMinIO(ctx context.Context, key string, updatesHub *Hub) error
 		return fmt.Errorf("failed to sync page to database: %w", result.Error)
 	}
 
-	// Broadcast update to connected clients
+	// A nil hub marks a silent sync
 	if updatesHub != nil {
-		if payload, err := json.Marshal(map[string]interface{}{
-			"event":  "page",
-			"action": "synced",
-			"slug":   slug,
-		}); err == nil {
-			updatesHub.SendToAll(payload)
-		}
+		events.Publish(events.PageSynced{Slug: slug})
 	}
 
-	debug.Log("Successfully synced page: %s", slug)
+	logger.Infof("Successfully synced page: %s", slug)
 	return nil
 }
 
@@ -774,7 +799,7 @@ func validatePageMetadata(meta meta.PageMetadata, slug string) error {
 
 // logPageOverwrite logs page overwrites for audit trail
 func logPageOverwrite(existing models.Page, newMeta meta.PageMetadata) {
-	debug.Log("AUDIT: Overwriting page '%s' (id=%d) - Old: {title=%s, is_in_sidebar=%v, order=%d, source=%s} -> New: {title=%s, is_in_sidebar=%v, order=%d, source=minio}",
+	logger.Debugf("AUDIT: Overwriting page '%s' (id=%d) - Old: {title=%s, is_in_sidebar=%v, order=%d, source=%s} -> New: {title=%s, is_in_sidebar=%v, order=%d, source=minio}",
 		existing.Slug,
 		existing.ID,
 		existing.Title,
@@ -11,7 +11,7 @@ import (
 
 	"github.com/coreos/go-iptables/iptables"
 	"github.com/pwnthemall/pwnthemall/backend/config"
-	"github.com/pwnthemall/pwnthemall/backend/debug"
+	"github.com/pwnthemall/pwnthemall/backend/logger"
 	"github.com/pwnthemall/pwnthemall/backend/models"
 	"github.com/pwnthemall/pwnthemall/backend/shared"
 	"github.com/vishvananda/netlink"
@@ -110,7 +110,7 @@ func PushFirewallToAgent(teamID uint, ports []int, allowedIPs []string) error {
 
 		agentURL, err := getDefaultGateway()
 		if err != nil {
-			debug.Log("getDefaultGateway error: %v", err)
+			logger.Errorf("getDefaultGateway error: %v", err)
 			return fmt.Errorf("firewall agent push failed")
 		}
 		body := shared.FirewallRequest{
@@ -120,7 +120,7 @@ func PushFirewallToAgent(teamID uint, ports []int, allowedIPs []string) error {
 		}
 
 		data, _ := json.Marshal(body)
-		debug.Log("PushFirewallToAgent: agentURL %s", agentURL)
+		logger.Debugf("PushFirewallToAgent: agentURL %s", agentURL)
 		resp, err := http.Post("http://"+agentURL+":8383/team/firewall", "application/json", bytes.NewReader(data))
 		if err != nil {
 			return fmt.Errorf("firewall agent push failed: %w", err)
@@ -0,0 +1,444 @@
+package utils
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/pwnthemall/pwnthemall/backend/logger"
+	"golang.org/x/oauth2"
+)
+
+const (
+	oidcDiscoveryTTL   = time.Hour
+	oidcJWKSMinRefresh = time.Minute
+	oidcClockLeeway    = time.Minute
+)
+
+// ErrOIDCDisabled is returned when single sign-on is not configured
+var ErrOIDCDisabled = errors.New("oidc is not enabled")
+
+// OIDCConfig holds the OpenID Connect provider settings
+type OIDCConfig struct {
+	Enabled           bool
+	Name              string
+	Issuer            string
+	ClientID          string
+	ClientSecret      string
+	RedirectURL       string
+	Scopes            []string
+	AutoProvision     bool
+	GroupsClaim       string
+	RoleMapping       map[string]string // group -> role
+	TeamClaim         string
+	PostLoginRedirect string
+}
+
+// OIDCIdentity is the verified identity extracted from an ID token
+type OIDCIdentity struct {
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Username      string
+	Name          string
+	Groups        []string
+	Team          string
+	Claims        map[string]interface{}
+}
+
+// LoadOIDCConfig reads the OIDC settings from the PTA_OIDC_* environment variables
+func LoadOIDCConfig() OIDCConfig {
+	cfg := OIDCConfig{
+		Enabled:           os.Getenv("PTA_OIDC_ENABLED") == "true",
+		Name:              getEnvOrDefault("PTA_OIDC_NAME", "SSO"),
+		Issuer:            strings.TrimSuffix(os.Getenv("PTA_OIDC_ISSUER"), "/"),
+		ClientID:          os.Getenv("PTA_OIDC_CLIENT_ID"),
+		ClientSecret:      os.Getenv("PTA_OIDC_CLIENT_SECRET"),
+		RedirectURL:       os.Getenv("PTA_OIDC_REDIRECT_URL"),
+		Scopes:            strings.Fields(getEnvOrDefault("PTA_OIDC_SCOPES", "openid profile email")),
+		AutoProvision:     getEnvOrDefault("PTA_OIDC_AUTO_PROVISION", "true") == "true",
+		GroupsClaim:       getEnvOrDefault("PTA_OIDC_GROUPS_CLAIM", "groups"),
+		RoleMapping:       make(map[string]string),
+		TeamClaim:         os.Getenv("PTA_OIDC_TEAM_CLAIM"),
+		PostLoginRedirect: getEnvOrDefault("PTA_OIDC_POST_LOGIN_REDIRECT", "/"),
+	}
+
+	// PTA_OIDC_ROLE_MAPPING=ctf-admins:admin,staff:admin
+	for _, pair := range strings.Split(os.Getenv("PTA_OIDC_ROLE_MAPPING"), ",") {
+		group, role, found := strings.Cut(strings.TrimSpace(pair), ":")
+		if found && group != "" && role != "" {
+			cfg.RoleMapping[group] = role
+		}
+	}
+
+	hasOpenID := false
+	for _, scope := range cfg.Scopes {
+		if scope == "openid" {
+			hasOpenID = true
+		}
+	}
+	if !hasOpenID {
+		cfg.Scopes = append([]string{"openid"}, cfg.Scopes...)
+	}
+
+	if cfg.Enabled && (cfg.Issuer == "" || cfg.ClientID == "" || cfg.RedirectURL == "") {
+		logger.Debug("PTA_OIDC_ISSUER, PTA_OIDC_CLIENT_ID and PTA_OIDC_REDIRECT_URL are required, SSO disabled")
+		cfg.Enabled = false
+	}
+	return cfg
+}
+
+func getEnvOrDefault(key, defaultValue string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return defaultValue
+}
+
+// MapRole returns the role granted by the first mapped group, or "" when none matches
+func (cfg OIDCConfig) MapRole(groups []string) string {
+	for _, group := range groups {
+		if role, ok := cfg.RoleMapping[group]; ok {
+			return role
+		}
+	}
+	return ""
+}
+
+type oidcDiscovery struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+type oidcJWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// OIDCProvider performs the authorization code flow against an OpenID Connect issuer
+type OIDCProvider struct {
+	Config     OIDCConfig
+	httpClient *http.Client
+
+	mu            sync.Mutex
+	discovery     *oidcDiscovery
+	discoveredAt  time.Time
+	keys          map[string]interface{}
+	keysFetchedAt time.Time
+}
+
+var (
+	oidcProvider     *OIDCProvider
+	oidcProviderOnce sync.Once
+)
+
+// GetOIDCProvider returns the provider configured from the environment
+func GetOIDCProvider() (*OIDCProvider, error) {
+	oidcProviderOnce.Do(func() {
+		cfg := LoadOIDCConfig()
+		if cfg.Enabled {
+			oidcProvider = NewOIDCProvider(cfg, nil)
+		}
+	})
+	if oidcProvider == nil {
+		return nil, ErrOIDCDisabled
+	}
+	return oidcProvider, nil
+}
+
+// NewOIDCProvider creates a provider, a nil client uses a default HTTP client
+func NewOIDCProvider(cfg OIDCConfig, client *http.Client) *OIDCProvider {
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	return &OIDCProvider{Config: cfg, httpClient: client, keys: make(map[string]interface{})}
+}
+
+// AuthCodeURL returns the authorization endpoint URL for the given state, nonce and PKCE verifier
+func (p *OIDCProvider) AuthCodeURL(ctx context.Context, state, nonce, verifier string) (string, error) {
+	oauthConfig, err := p.oauth2Config(ctx)
+	if err != nil {
+		return "", err
+	}
+	return oauthConfig.AuthCodeURL(state,
+		oauth2.SetAuthURLParam("nonce", nonce),
+		oauth2.S256ChallengeOption(verifier),
+	), nil
+}
+
+// Exchange trades an authorization code for tokens and verifies the returned ID token
+func (p *OIDCProvider) Exchange(ctx context.Context, code, verifier, nonce string) (*OIDCIdentity, error) {
+	oauthConfig, err := p.oauth2Config(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := oauthConfig.Exchange(context.WithValue(ctx, oauth2.HTTPClient, p.httpClient), code, oauth2.VerifierOption(verifier))
+	if err != nil {
+		return nil, fmt.Errorf("code exchange failed: %w", err)
+	}
+
+	rawIDToken, ok := token.Extra("id_token").(string)
+	if !ok || rawIDToken == "" {
+		return nil, fmt.Errorf("no id_token in token response")
+	}
+	return p.VerifyIDToken(ctx, rawIDToken, nonce)
+}
+
+// VerifyIDToken checks the signature, issuer, audience, expiry and nonce of an ID token
+func (p *OIDCProvider) VerifyIDToken(ctx context.Context, rawIDToken, nonce string) (*OIDCIdentity, error) {
+	discovery, err := p.getDiscovery(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	claims := jwt.MapClaims{}
+	_, err = jwt.ParseWithClaims(rawIDToken, claims, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		return p.getKey(ctx, kid)
+	},
+		jwt.WithValidMethods([]string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "ES512"}),
+		jwt.WithIssuer(discovery.Issuer),
+		jwt.WithAudience(p.Config.ClientID),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+		jwt.WithLeeway(oidcClockLeeway),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("invalid id_token: %w", err)
+	}
+
+	// With several audiences the authorized party must be us
+	if aud, _ := claims.GetAudience(); len(aud) > 1 {
+		if azp, _ := claims["azp"].(string); azp != p.Config.ClientID {
+			return nil, fmt.Errorf("invalid id_token: unexpected authorized party")
+		}
+	}
+	if tokenNonce, _ := claims["nonce"].(string); tokenNonce == "" || tokenNonce != nonce {
+		return nil, fmt.Errorf("invalid id_token: nonce mismatch")
+	}
+
+	identity := &OIDCIdentity{
+		Subject:  claimString(claims, "sub"),
+		Email:    strings.ToLower(claimString(claims, "email")),
+		Username: claimString(claims, "preferred_username"),
+		Name:     claimString(claims, "name"),
+		Groups:   claimStrings(claims, p.Config.GroupsClaim),
+		Claims:   claims,
+	}
+	if identity.Subject == "" {
+		return nil, fmt.Errorf("invalid id_token: missing subject")
+	}
+	switch verified := claims["email_verified"].(type) {
+	case bool:
+		identity.EmailVerified = verified
+	case string:
+		identity.EmailVerified = verified == "true"
+	}
+	if p.Config.TeamClaim != "" {
+		identity.Team = strings.TrimSpace(claimString(claims, p.Config.TeamClaim))
+	}
+	return identity, nil
+}
+
+func (p *OIDCProvider) oauth2Config(ctx context.Context) (*oauth2.Config, error) {
+	discovery, err := p.getDiscovery(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &oauth2.Config{
+		ClientID:     p.Config.ClientID,
+		ClientSecret: p.Config.ClientSecret,
+		RedirectURL:  p.Config.RedirectURL,
+		Scopes:       p.Config.Scopes,
+		Endpoint: oauth2.Endpoint{
+			AuthURL:  discovery.AuthorizationEndpoint,
+			TokenURL: discovery.TokenEndpoint,
+		},
+	}, nil
+}
+
+// getDiscovery fetches and caches the issuer's .well-known/openid-configuration
+func (p *OIDCProvider) getDiscovery(ctx context.Context) (*oidcDiscovery, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.discovery != nil && time.Since(p.discoveredAt) < oidcDiscoveryTTL {
+		return p.discovery, nil
+	}
+
+	var discovery oidcDiscovery
+	if err := p.getJSON(ctx, p.Config.Issuer+"/.well-known/openid-configuration", &discovery); err != nil {
+		return nil, fmt.Errorf("oidc discovery failed: %w", err)
+	}
+	if strings.TrimSuffix(discovery.Issuer, "/") != p.Config.Issuer {
+		return nil, fmt.Errorf("oidc discovery failed: issuer mismatch (%s)", discovery.Issuer)
+	}
+	if discovery.AuthorizationEndpoint == "" || discovery.TokenEndpoint == "" || discovery.JWKSURI == "" {
+		return nil, fmt.Errorf("oidc discovery failed: missing endpoints")
+	}
+
+	p.discovery = &discovery
+	p.discoveredAt = time.Now()
+	return p.discovery, nil
+}
+
+// getKey returns the public key for a key ID, refreshing the JWKS when the key is unknown
+func (p *OIDCProvider) getKey(ctx context.Context, kid string) (interface{}, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if key, ok := p.lookupKey(kid); ok {
+		return key, nil
+	}
+	if time.Since(p.keysFetchedAt) < oidcJWKSMinRefresh && len(p.keys) > 0 {
+		return nil, fmt.Errorf("unknown signing key %q", kid)
+	}
+
+	var jwks struct {
+		Keys []oidcJWK `json:"keys"`
+	}
+	if err := p.getJSON(ctx, p.discovery.JWKSURI, &jwks); err != nil {
+		return nil, fmt.Errorf("failed to fetch jwks: %w", err)
+	}
+
+	keys := make(map[string]interface{})
+	for _, jwk := range jwks.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		key, err := parseJWK(jwk)
+		if err != nil {
+			continue
+		}
+		keys[jwk.Kid] = key
+	}
+	p.keys = keys
+	p.keysFetchedAt = time.Now()
+
+	if key, ok := p.lookupKey(kid); ok {
+		return key, nil
+	}
+	return nil, fmt.Errorf("unknown signing key %q", kid)
+}
+
+// lookupKey finds a cached key, a token without kid is accepted only when the JWKS has a single key
+func (p *OIDCProvider) lookupKey(kid string) (interface{}, bool) {
+	if kid == "" && len(p.keys) == 1 {
+		for _, key := range p.keys {
+			return key, true
+		}
+	}
+	key, ok := p.keys[kid]
+	return key, ok
+}
+
+func (p *OIDCProvider) getJSON(ctx context.Context, url string, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status %d from %s", resp.StatusCode, url)
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// parseJWK converts an RSA or EC JSON Web Key to a public key
+func parseJWK(jwk oidcJWK) (interface{}, error) {
+	switch jwk.Kty {
+	case "RSA":
+		n, err := base64.RawURLEncoding.DecodeString(jwk.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := base64.RawURLEncoding.DecodeString(jwk.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch jwk.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %s", jwk.Crv)
+		}
+		x, err := base64.RawURLEncoding.DecodeString(jwk.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := base64.RawURLEncoding.DecodeString(jwk.Y)
+		if err != nil {
+			return nil, err
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}, nil
+	}
+	return nil, fmt.Errorf("unsupported key type %s", jwk.Kty)
+}
+
+// claimValue resolves a claim by dotted path (e.g. realm_access.roles)
+func claimValue(claims map[string]interface{}, path string) interface{} {
+	var current interface{} = claims
+	for _, part := range strings.Split(path, ".") {
+		m, ok := current.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		current = m[part]
+	}
+	return current
+}
+
+func claimString(claims map[string]interface{}, path string) string {
+	value, _ := claimValue(claims, path).(string)
+	return value
+}
+
+// claimStrings reads a claim holding a string or a list of strings
+func claimStrings(claims map[string]interface{}, path string) []string {
+	switch value := claimValue(claims, path).(type) {
+	case string:
+		return []string{value}
+	case []interface{}:
+		values := make([]string, 0, len(value))
+		for _, v := range value {
+			if s, ok := v.(string); ok {
+				values = append(values, s)
+			}
+		}
+		return values
+	}
+	return nil
+}
@@ -0,0 +1,393 @@
+package utils
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pwnthemall/pwnthemall/backend/config"
+	"github.com/pwnthemall/pwnthemall/backend/events"
+	"github.com/pwnthemall/pwnthemall/backend/logger"
+	"github.com/pwnthemall/pwnthemall/backend/metrics"
+	"github.com/pwnthemall/pwnthemall/backend/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	webhookTimeout      = 10 * time.Second
+	webhookBatchSize    = 20
+	webhookPollInterval = 15 * time.Second
+	// webhookClaimLease outlasts a whole batch of timed out deliveries, so no other replica claims it again meanwhile
+	webhookClaimLease  = webhookBatchSize*webhookTimeout + time.Minute
+	webhookRetention   = 30 * 24 * time.Hour
+	webhookErrorLength = 512
+)
+
+// webhookBackoff is the delay before each retry, a delivery is failed once they are exhausted
+var webhookBackoff = []time.Duration{30 * time.Second, 2 * time.Minute, 10 * time.Minute, 30 * time.Minute, 2 * time.Hour}
+
+// WebhookFormats lists the payload formats of webhook targets
+var WebhookFormats = []string{"json", "discord", "slack"}
+
+// WebhookEvents lists the events webhook targets can subscribe to
+var WebhookEvents = []string{
+	events.SolveCreated{}.EventName(),
+	events.FirstBlood{}.EventName(),
+	events.ChallengeReleased{}.EventName(),
+	events.NotificationSent{}.EventName(),
+	events.TeamJoined{}.EventName(),
+	events.HintPurchased{}.EventName(),
+	events.InstanceStarted{}.EventName(),
+	events.InstanceStopped{}.EventName(),
+	events.TicketCreated{}.EventName(),
+	events.TicketResolved{}.EventName(),
+	events.UserBanned{}.EventName(),
+}
+
+var (
+	webhookClient = &http.Client{Timeout: webhookTimeout}
+	webhookWake   = make(chan struct{}, 1)
+)
+
+// webhookTest is the event sent by the test button of a target
+type webhookTest struct {
+	Target string `json:"target"`
+}
+
+func (webhookTest) EventName() string { return "webhook.test" }
+
+// SubscribeWebhooks queues a delivery of every published event to the targets subscribed to it
+func SubscribeWebhooks() {
+	events.Subscribe("webhooks", func(e events.Event) {
+		if !webhookDeliverable(e) {
+			return
+		}
+		var targets []models.WebhookTarget
+		if err := config.DB.Where("enabled = ? AND ? = ANY(events)", true, e.EventName()).Find(&targets).Error; err != nil {
+			logger.Errorf("Failed to load webhook targets for %s: %v", e.EventName(), err)
+			return
+		}
+		if len(targets) == 0 {
+			return
+		}
+
+		now := time.Now()
+		for _, target := range targets {
+			payload, err := renderWebhookPayload(target.Format, e)
+			if err != nil {
+				logger.Errorf("Failed to render %s payload of webhook %d: %v", e.EventName(), target.ID, err)
+				continue
+			}
+			delivery := models.WebhookDelivery{
+				TargetID:      target.ID,
+				Event:         e.EventName(),
+				Payload:       string(payload),
+				Status:        models.WebhookDeliveryPending,
+				NextAttemptAt: now,
+			}
+			if err := config.DB.Create(&delivery).Error; err != nil {
+				logger.Errorf("Failed to queue webhook delivery for target %d: %v", target.ID, err)
+			}
+		}
+		wakeWebhookWorker()
+	})
+}
+
+// webhookDeliverable keeps notifications sent to a user or team out of webhooks, only announcements are delivered
+func webhookDeliverable(e events.Event) bool {
+	if n, ok := e.(events.NotificationSent); ok {
+		return n.Global
+	}
+	return slices.Contains(WebhookEvents, e.EventName())
+}
+
+func wakeWebhookWorker() {
+	select {
+	case webhookWake <- struct{}{}:
+	default:
+	}
+}
+
+// StartWebhookWorker sends the due deliveries, checking every few seconds and whenever an event is queued
+func StartWebhookWorker() {
+	go func() {
+		ticker := time.NewTicker(webhookPollInterval)
+		defer ticker.Stop()
+		lastCleanup := time.Time{}
+		for {
+			ProcessDueWebhookDeliveries()
+			if time.Since(lastCleanup) > time.Hour {
+				cleanupWebhookDeliveries()
+				lastCleanup = time.Now()
+			}
+			select {
+			case <-ticker.C:
+			case <-webhookWake:
+			}
+		}
+	}()
+}
+
+// ProcessDueWebhookDeliveries sends the pending deliveries whose next attempt is due
+func ProcessDueWebhookDeliveries() {
+	for {
+		deliveries, err := claimWebhookDeliveries()
+		if err != nil {
+			logger.Errorf("Failed to claim webhook deliveries: %v", err)
+			return
+		}
+		for i := range deliveries {
+			attemptWebhookDelivery(&deliveries[i], true)
+		}
+		if len(deliveries) < webhookBatchSize {
+			return
+		}
+	}
+}
+
+// claimWebhookDeliveries locks a batch of due deliveries and pushes their next attempt back, so other replicas skip them
+func claimWebhookDeliveries() ([]models.WebhookDelivery, error) {
+	var deliveries []models.WebhookDelivery
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? AND next_attempt_at <= ?", models.WebhookDeliveryPending, time.Now()).
+			Order("next_attempt_at, id").Limit(webhookBatchSize).
+			Find(&deliveries).Error; err != nil {
+			return err
+		}
+		if len(deliveries) == 0 {
+			return nil
+		}
+		ids := make([]uint, len(deliveries))
+		for i, d := range deliveries {
+			ids[i] = d.ID
+		}
+		return tx.Model(&models.WebhookDelivery{}).Where("id IN ?", ids).
+			Update("next_attempt_at", time.Now().Add(webhookClaimLease)).Error
+	})
+	return deliveries, err
+}
+
+// attemptWebhookDelivery sends a delivery and records the result, failed deliveries are retried with backoff when retry is set
+func attemptWebhookDelivery(delivery *models.WebhookDelivery, retry bool) {
+	var target models.WebhookTarget
+	if err := config.DB.First(&target, delivery.TargetID).Error; err != nil {
+		// The target was deleted, its deliveries go with it
+		return
+	}
+	// Test deliveries go out even when the target is disabled
+	if !target.Enabled && retry {
+		delivery.Status = models.WebhookDeliveryFailed
+		delivery.Error = "target disabled"
+		config.DB.Save(delivery)
+		return
+	}
+
+	status, err := sendWebhook(target, delivery.ID, delivery.Event, []byte(delivery.Payload))
+	delivery.Attempts++
+	delivery.ResponseStatus = status
+	metrics.ObserveWebhookDelivery(target.Format, err)
+
+	if err == nil {
+		now := time.Now()
+		delivery.Status = models.WebhookDeliverySuccess
+		delivery.Error = ""
+		delivery.DeliveredAt = &now
+	} else {
+		delivery.Error = truncate(err.Error(), webhookErrorLength)
+		if retry && delivery.Attempts <= len(webhookBackoff) {
+			delivery.NextAttemptAt = time.Now().Add(webhookBackoff[delivery.Attempts-1])
+		} else {
+			delivery.Status = models.WebhookDeliveryFailed
+		}
+		logger.Warn("Webhook delivery failed", "target", target.ID, "delivery", delivery.ID, "event", delivery.Event, "attempt", delivery.Attempts, "error", err)
+	}
+
+	if err := config.DB.Save(delivery).Error; err != nil {
+		logger.Errorf("Failed to record webhook delivery %d: %v", delivery.ID, err)
+	}
+}
+
+// sendWebhook posts a payload to a target, json payloads are signed with the target secret
+func sendWebhook(target models.WebhookTarget, deliveryID uint, event string, payload []byte) (int, error) {
+	req, err := http.NewRequest(http.MethodPost, target.URL, bytes.NewReader(payload))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "pwnthemall-webhooks")
+	req.Header.Set("X-Pwnthemall-Event", event)
+	req.Header.Set("X-Pwnthemall-Delivery", strconv.FormatUint(uint64(deliveryID), 10))
+	if target.Format == "json" && target.Secret != "" {
+		timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+		req.Header.Set("X-Pwnthemall-Signature", "t="+timestamp+",v1="+SignWebhookPayload(target.Secret, timestamp, payload))
+	}
+
+	resp, err := webhookClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, webhookErrorLength))
+		return resp.StatusCode, fmt.Errorf("unexpected status %d: %s", resp.StatusCode, bytes.TrimSpace(body))
+	}
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+	return resp.StatusCode, nil
+}
+
+// SignWebhookPayload returns the hex HMAC-SHA256 of "timestamp.payload", receivers recompute it to authenticate deliveries
+func SignWebhookPayload(secret, timestamp string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// SendTestWebhook sends a test event to a target right away and records it in the delivery log
+func SendTestWebhook(target models.WebhookTarget) (*models.WebhookDelivery, error) {
+	e := webhookTest{Target: target.Name}
+	payload, err := renderWebhookPayload(target.Format, e)
+	if err != nil {
+		return nil, err
+	}
+	delivery := models.WebhookDelivery{
+		TargetID:      target.ID,
+		Event:         e.EventName(),
+		Payload:       string(payload),
+		Status:        models.WebhookDeliveryPending,
+		NextAttemptAt: time.Now().Add(webhookClaimLease),
+	}
+	if err := config.DB.Create(&delivery).Error; err != nil {
+		return nil, err
+	}
+	attemptWebhookDelivery(&delivery, false)
+	return &delivery, nil
+}
+
+// RedeliverWebhook queues a copy of a past delivery, the original entry is kept in the log
+func RedeliverWebhook(original models.WebhookDelivery) (*models.WebhookDelivery, error) {
+	delivery := models.WebhookDelivery{
+		TargetID:      original.TargetID,
+		Event:         original.Event,
+		Payload:       original.Payload,
+		Status:        models.WebhookDeliveryPending,
+		NextAttemptAt: time.Now(),
+	}
+	if err := config.DB.Create(&delivery).Error; err != nil {
+		return nil, err
+	}
+	wakeWebhookWorker()
+	return &delivery, nil
+}
+
+func cleanupWebhookDeliveries() {
+	if err := config.DB.Where("created_at < ? AND status <> ?", time.Now().Add(-webhookRetention), models.WebhookDeliveryPending).
+		Delete(&models.WebhookDelivery{}).Error; err != nil {
+		logger.Errorf("Failed to clean up webhook deliveries: %v", err)
+	}
+}
+
+// truncate shortens s to n bytes, the result stays valid UTF-8 for the database
+func truncate(s string, n int) string {
+	if len(s) > n {
+		s = s[:n]
+	}
+	return strings.ToValidUTF8(s, "")
+}
+
+// slackEscaper escapes the control characters of Slack messages, so names like <!channel> are shown as text
+var slackEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+// webhookMessage is the human readable form of an event used by the chat formats
+type webhookMessage struct {
+	Title       string
+	Description string
+	Color       int
+}
+
+// renderWebhookPayload encodes an event in the format of a target
+func renderWebhookPayload(format string, e events.Event) ([]byte, error) {
+	switch format {
+	case "discord":
+		msg := describeWebhookEvent(e)
+		return json.Marshal(map[string]interface{}{
+			"username": "pwnthemall",
+			// Team names like @everyone must not ping anybody
+			"allowed_mentions": map[string]interface{}{"parse": []string{}},
+			"embeds": []map[string]interface{}{{
+				"title":       msg.Title,
+				"description": msg.Description,
+				"color":       msg.Color,
+				"timestamp":   time.Now().UTC().Format(time.RFC3339),
+			}},
+		})
+	case "slack":
+		msg := describeWebhookEvent(e)
+		text := "*" + slackEscaper.Replace(msg.Title) + "*"
+		if msg.Description != "" {
+			text += "\n" + slackEscaper.Replace(msg.Description)
+		}
+		return json.Marshal(map[string]interface{}{"text": text})
+	default:
+		return events.Marshal(e)
+	}
+}
+
+func describeWebhookEvent(e events.Event) webhookMessage {
+	switch ev := e.(type) {
+	case events.FirstBlood:
+		return webhookMessage{
+			Title:       "🩸 First blood on " + ev.ChallengeName,
+			Description: fmt.Sprintf("%s (%s) solved it in position %d and earned a %d points bonus", ev.TeamName, ev.Username, ev.Position, ev.Bonus),
+			Color:       0xdc2626,
+		}
+	case events.SolveCreated:
+		return webhookMessage{
+			Title:       ev.TeamName + " solved " + ev.ChallengeName,
+			Description: fmt.Sprintf("Solved by %s for %d points", ev.Username, ev.Points),
+			Color:       0x16a34a,
+		}
+	case events.ChallengeReleased:
+		return webhookMessage{
+			Title:       "New challenge: " + ev.Name,
+			Description: fmt.Sprintf("%s, %d points", ev.Category, ev.Points),
+			Color:       0x2563eb,
+		}
+	case events.NotificationSent:
+		return webhookMessage{Title: ev.Title, Description: ev.Message, Color: 0xf59e0b}
+	case events.TeamJoined:
+		verb := "joined"
+		if ev.Created {
+			verb = "created"
+		}
+		return webhookMessage{Title: fmt.Sprintf("%s %s team %s", ev.Username, verb, ev.TeamName), Color: 0x6b7280}
+	case events.HintPurchased:
+		return webhookMessage{Title: fmt.Sprintf("%s bought hint %s", ev.Username, ev.HintTitle), Description: fmt.Sprintf("%d points", ev.Cost), Color: 0x6b7280}
+	case events.InstanceStarted:
+		return webhookMessage{Title: fmt.Sprintf("%s started instance %s", ev.Username, ev.Name), Color: 0x6b7280}
+	case events.InstanceStopped:
+		return webhookMessage{Title: fmt.Sprintf("%s's instance of challenge %d stopped", ev.Username, ev.ChallengeID), Color: 0x6b7280}
+	case events.TicketCreated:
+		return webhookMessage{Title: fmt.Sprintf("New ticket #%d", ev.TicketID), Description: fmt.Sprintf("%s by %s", ev.Subject, ev.Username), Color: 0x9333ea}
+	case events.TicketResolved:
+		return webhookMessage{Title: fmt.Sprintf("Ticket #%d resolved", ev.TicketID), Color: 0x9333ea}
+	case events.UserBanned:
+		return webhookMessage{Title: fmt.Sprintf("User %d banned", ev.UserID), Color: 0xdc2626}
+	case webhookTest:
+		return webhookMessage{Title: "Test delivery", Description: "Webhook " + ev.Target + " is working", Color: 0x16a34a}
+	}
+	return webhookMessage{Title: e.EventName()}
+}
@@ -0,0 +1,43 @@
+package utils
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/pmezard/go-difflib/difflib"
+	"github.com/pwnthemall/pwnthemall/backend/models"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/extension"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/renderer/html"
+)
+
+// pageMarkdown keeps raw HTML in the output, SanitizePageHTML strips what is not allowed afterwards
+var pageMarkdown = goldmark.New(
+	goldmark.WithExtensions(extension.GFM),
+	goldmark.WithParserOptions(parser.WithAutoHeadingID()),
+	goldmark.WithRendererOptions(html.WithUnsafe()),
+)
+
+// RenderPageContent converts the source of a page to sanitized HTML
+func RenderPageContent(format, source string) (string, error) {
+	if format == models.PageFormatMarkdown {
+		var buf bytes.Buffer
+		if err := pageMarkdown.Convert([]byte(source), &buf); err != nil {
+			return "", fmt.Errorf("failed to render markdown: %w", err)
+		}
+		source = buf.String()
+	}
+	return SanitizePageHTML(source), nil
+}
+
+// DiffPageRevisions returns the unified diff between the sources of two revisions
+func DiffPageRevisions(from, to models.PageRevision) (string, error) {
+	return difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        difflib.SplitLines(from.Content),
+		B:        difflib.SplitLines(to.Content),
+		FromFile: fmt.Sprintf("revision %d", from.Number),
+		ToFile:   fmt.Sprintf("revision %d", to.Number),
+		Context:  3,
+	})
+}
@@ -0,0 +1,198 @@
+package utils
+
+import (
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/pwnthemall/pwnthemall/backend/config"
+	"github.com/pwnthemall/pwnthemall/backend/logger"
+	"github.com/pwnthemall/pwnthemall/backend/models"
+)
+
+// PubSub fans messages out to the subscribers of every backend replica
+type PubSub interface {
+	Publish(channel string, payload []byte) error
+	Subscribe(channel string, handler func(payload []byte)) error
+	// NextID returns an ID of the channel greater than every previous one, on every replica
+	NextID(channel string) (uint64, error)
+}
+
+var (
+	pubSub     PubSub
+	pubSubOnce sync.Once
+)
+
+// GetPubSub returns the configured backend: postgres (default) so replicas share events, or memory for a single replica
+func GetPubSub() PubSub {
+	pubSubOnce.Do(func() {
+		switch strings.ToLower(os.Getenv("PTA_PUBSUB")) {
+		case "memory":
+			pubSub = NewMemoryPubSub()
+		default:
+			pubSub = NewPostgresPubSub(os.Getenv("DATABASE_URL"))
+		}
+	})
+	return pubSub
+}
+
+// MemoryPubSub delivers messages to the subscribers of the current process only
+type MemoryPubSub struct {
+	mu       sync.RWMutex
+	handlers map[string][]func([]byte)
+	ids      map[string]uint64
+}
+
+func NewMemoryPubSub() *MemoryPubSub {
+	return &MemoryPubSub{handlers: make(map[string][]func([]byte)), ids: make(map[string]uint64)}
+}
+
+func (m *MemoryPubSub) NextID(channel string) (uint64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.ids[channel]++
+	return m.ids[channel], nil
+}
+
+func (m *MemoryPubSub) Publish(channel string, payload []byte) error {
+	m.mu.RLock()
+	handlers := m.handlers[channel]
+	m.mu.RUnlock()
+	for _, handler := range handlers {
+		handler(payload)
+	}
+	return nil
+}
+
+func (m *MemoryPubSub) Subscribe(channel string, handler func([]byte)) error {
+	m.mu.Lock()
+	m.handlers[channel] = append(m.handlers[channel], handler)
+	m.mu.Unlock()
+	return nil
+}
+
+const (
+	// maxNotifyPayload keeps payloads under the 8000 bytes limit of NOTIFY
+	maxNotifyPayload = 7900
+	notifyInline     = 'm'
+	notifyStored     = 'r'
+)
+
+// PostgresPubSub relies on LISTEN/NOTIFY, larger payloads go through the pub_sub_messages table
+type PostgresPubSub struct {
+	listener  *pq.Listener
+	mu        sync.RWMutex
+	handlers  map[string][]func([]byte)
+	sequences sync.Map
+}
+
+// NewPostgresPubSub opens the listening connection and starts dispatching notifications
+func NewPostgresPubSub(dsn string) *PostgresPubSub {
+	p := &PostgresPubSub{handlers: make(map[string][]func([]byte))}
+	p.listener = pq.NewListener(dsn, time.Second, time.Minute, func(event pq.ListenerEventType, err error) {
+		switch event {
+		case pq.ListenerEventConnectionAttemptFailed, pq.ListenerEventDisconnected:
+			logger.Errorf("Pub/sub listener connection lost: %v", err)
+		case pq.ListenerEventReconnected:
+			logger.Warn("Pub/sub listener reconnected, events sent while disconnected were missed")
+		}
+	})
+	go p.run()
+	go p.cleanup()
+	return p
+}
+
+func (p *PostgresPubSub) Publish(channel string, payload []byte) error {
+	if len(payload) < maxNotifyPayload {
+		return config.DB.Exec("SELECT pg_notify(?, ?)", channel, string(notifyInline)+string(payload)).Error
+	}
+	message := models.PubSubMessage{Channel: channel, Payload: payload}
+	if err := config.DB.Create(&message).Error; err != nil {
+		return err
+	}
+	return config.DB.Exec("SELECT pg_notify(?, ?)", channel, string(notifyStored)+strconv.FormatUint(uint64(message.ID), 10)).Error
+}
+
+// NextID draws from a sequence of the channel, channel names are internal identifiers
+func (p *PostgresPubSub) NextID(channel string) (uint64, error) {
+	sequence := "pubsub_seq_" + channel
+	if _, created := p.sequences.Load(sequence); !created {
+		if err := config.DB.Exec("CREATE SEQUENCE IF NOT EXISTS " + sequence).Error; err != nil {
+			return 0, err
+		}
+		p.sequences.Store(sequence, true)
+	}
+	var id uint64
+	err := config.DB.Raw("SELECT nextval(?)", sequence).Scan(&id).Error
+	return id, err
+}
+
+func (p *PostgresPubSub) Subscribe(channel string, handler func([]byte)) error {
+	p.mu.Lock()
+	first := len(p.handlers[channel]) == 0
+	p.handlers[channel] = append(p.handlers[channel], handler)
+	p.mu.Unlock()
+	if !first {
+		return nil
+	}
+	if err := p.listener.Listen(channel); err != nil && err != pq.ErrChannelAlreadyOpen {
+		return err
+	}
+	return nil
+}
+
+func (p *PostgresPubSub) run() {
+	for {
+		select {
+		case n := <-p.listener.Notify:
+			// nil is sent after a reconnection
+			if n == nil {
+				continue
+			}
+			p.dispatch(n.Channel, n.Extra)
+		case <-time.After(90 * time.Second):
+			go p.listener.Ping()
+		}
+	}
+}
+
+func (p *PostgresPubSub) dispatch(channel, extra string) {
+	if extra == "" {
+		return
+	}
+	var payload []byte
+	switch extra[0] {
+	case notifyInline:
+		payload = []byte(extra[1:])
+	case notifyStored:
+		var message models.PubSubMessage
+		if err := config.DB.First(&message, extra[1:]).Error; err != nil {
+			logger.Errorf("Failed to load pub/sub message %s: %v", extra[1:], err)
+			return
+		}
+		payload = message.Payload
+	default:
+		return
+	}
+
+	p.mu.RLock()
+	handlers := p.handlers[channel]
+	p.mu.RUnlock()
+	for _, handler := range handlers {
+		handler(payload)
+	}
+}
+
+// cleanup removes stored payloads once every replica had time to read them
+func (p *PostgresPubSub) cleanup() {
+	ticker := time.NewTicker(5 * time.Minute)
+	defer ticker.Stop()
+	for range ticker.C {
+		if err := config.DB.Where("created_at < ?", time.Now().Add(-5*time.Minute)).Delete(&models.PubSubMessage{}).Error; err != nil {
+			logger.Errorf("Failed to clean up pub/sub messages: %v", err)
+		}
+	}
+}
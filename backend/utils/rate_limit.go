@@ -0,0 +1,235 @@
+package utils
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pwnthemall/pwnthemall/backend/config"
+	"github.com/pwnthemall/pwnthemall/backend/logger"
+)
+
+// RateLimitKey tells what a rate limit bucket is shared by
+type RateLimitKey string
+
+const (
+	RateLimitByIP   RateLimitKey = "ip"
+	RateLimitByUser RateLimitKey = "user" // falls back to the IP for anonymous requests
+	RateLimitByTeam RateLimitKey = "team" // falls back to the user, then to the IP
+)
+
+// RateLimitPolicy is a token bucket holding up to Burst requests, refilled at Burst per Period
+type RateLimitPolicy struct {
+	Name     string
+	Burst    int
+	Period   time.Duration
+	Key      RateLimitKey
+	ErrorKey string
+}
+
+// RateLimitResult is the state of a bucket after a request
+type RateLimitResult struct {
+	Allowed    bool
+	Limit      int
+	Remaining  int
+	Reset      time.Duration // until the bucket is full again
+	RetryAfter time.Duration // until the next request is allowed, zero when allowed
+}
+
+// RateLimitStore keeps the token buckets, the backend is chosen with PTA_RATE_LIMIT_STORE
+type RateLimitStore interface {
+	Take(bucket string, policy RateLimitPolicy) (RateLimitResult, error)
+}
+
+var (
+	rateLimitStore     RateLimitStore
+	rateLimitStoreOnce sync.Once
+)
+
+// GetRateLimitStore returns the configured store: postgres, to share limits between replicas, or memory (default)
+func GetRateLimitStore() RateLimitStore {
+	rateLimitStoreOnce.Do(func() {
+		switch strings.ToLower(os.Getenv("PTA_RATE_LIMIT_STORE")) {
+		case "postgres":
+			rateLimitStore = NewPostgresRateLimitStore()
+		default:
+			rateLimitStore = NewMemoryRateLimitStore()
+		}
+	})
+	return rateLimitStore
+}
+
+// rate returns the tokens added per second
+func (p RateLimitPolicy) rate() float64 {
+	return float64(p.Burst) / p.Period.Seconds()
+}
+
+// result turns the tokens left in a bucket into the values sent to the client
+func (p RateLimitPolicy) result(tokens float64, allowed bool) RateLimitResult {
+	res := RateLimitResult{
+		Allowed:   allowed,
+		Limit:     p.Burst,
+		Remaining: int(math.Floor(tokens)),
+		Reset:     time.Duration((float64(p.Burst) - tokens) / p.rate() * float64(time.Second)),
+	}
+	if !allowed {
+		res.RetryAfter = time.Duration((1 - tokens) / p.rate() * float64(time.Second))
+	}
+	return res
+}
+
+type memoryBucket struct {
+	tokens    float64
+	updatedAt time.Time
+	expiresAt time.Time
+}
+
+// MemoryRateLimitStore keeps buckets in process, limits reset on restart and are not shared between replicas
+type MemoryRateLimitStore struct {
+	mu      sync.Mutex
+	buckets map[string]*memoryBucket
+}
+
+// NewMemoryRateLimitStore creates a memory store and starts the cleanup of full buckets
+func NewMemoryRateLimitStore() *MemoryRateLimitStore {
+	store := &MemoryRateLimitStore{buckets: make(map[string]*memoryBucket)}
+	go func() {
+		ticker := time.NewTicker(5 * time.Minute)
+		defer ticker.Stop()
+		for range ticker.C {
+			store.cleanup()
+		}
+	}()
+	return store
+}
+
+func (s *MemoryRateLimitStore) Take(bucket string, policy RateLimitPolicy) (RateLimitResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	b, ok := s.buckets[bucket]
+	if !ok {
+		b = &memoryBucket{tokens: float64(policy.Burst), updatedAt: now}
+		s.buckets[bucket] = b
+	}
+	b.tokens = math.Min(float64(policy.Burst), b.tokens+now.Sub(b.updatedAt).Seconds()*policy.rate())
+	b.updatedAt = now
+	b.expiresAt = now.Add(policy.Period)
+
+	allowed := b.tokens >= 1
+	if allowed {
+		b.tokens--
+	}
+	return policy.result(b.tokens, allowed), nil
+}
+
+func (s *MemoryRateLimitStore) cleanup() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	for key, b := range s.buckets {
+		if now.After(b.expiresAt) {
+			delete(s.buckets, key)
+		}
+	}
+}
+
+// PostgresRateLimitStore keeps buckets in the rate_limit_buckets table so every replica shares them
+type PostgresRateLimitStore struct{}
+
+// NewPostgresRateLimitStore creates a Postgres store and starts the cleanup of full buckets
+func NewPostgresRateLimitStore() *PostgresRateLimitStore {
+	go func() {
+		ticker := time.NewTicker(5 * time.Minute)
+		defer ticker.Stop()
+		for range ticker.C {
+			if err := config.DB.Exec("DELETE FROM rate_limit_buckets WHERE expires_at < now()").Error; err != nil {
+				logger.Errorf("Failed to clean up rate limit buckets: %v", err)
+			}
+		}
+	}()
+	return &PostgresRateLimitStore{}
+}
+
+// postgresTakeQuery refills and takes a token in a single statement so concurrent requests cannot overspend
+const postgresTakeQuery = `
+INSERT INTO rate_limit_buckets AS b (key, tokens, allowed, updated_at, expires_at)
+VALUES (@key, @burst - 1, true, now(), now() + make_interval(secs => @period))
+ON CONFLICT (key) DO UPDATE SET
+	allowed = LEAST(@burst, b.tokens + EXTRACT(EPOCH FROM now() - b.updated_at) * @rate) >= 1,
+	tokens = LEAST(@burst, b.tokens + EXTRACT(EPOCH FROM now() - b.updated_at) * @rate)
+		- CASE WHEN LEAST(@burst, b.tokens + EXTRACT(EPOCH FROM now() - b.updated_at) * @rate) >= 1 THEN 1 ELSE 0 END,
+	updated_at = now(),
+	expires_at = now() + make_interval(secs => @period)
+RETURNING tokens, allowed`
+
+func (s *PostgresRateLimitStore) Take(bucket string, policy RateLimitPolicy) (RateLimitResult, error) {
+	var row struct {
+		Tokens  float64
+		Allowed bool
+	}
+	err := config.DB.Raw(postgresTakeQuery, map[string]interface{}{
+		"key":    bucket,
+		"burst":  float64(policy.Burst),
+		"rate":   policy.rate(),
+		"period": policy.Period.Seconds(),
+	}).Scan(&row).Error
+	if err != nil {
+		return RateLimitResult{}, err
+	}
+	return policy.result(row.Tokens, row.Allowed), nil
+}
+
+// ParseRateLimitPolicy reads a "burst/period[:key]" specification such as "10/1m:team"
+func ParseRateLimitPolicy(name, spec string) (RateLimitPolicy, error) {
+	policy := RateLimitPolicy{Name: name, Key: RateLimitByUser}
+	spec, key, hasKey := strings.Cut(strings.TrimSpace(spec), ":")
+	if hasKey {
+		switch RateLimitKey(key) {
+		case RateLimitByIP, RateLimitByUser, RateLimitByTeam:
+			policy.Key = RateLimitKey(key)
+		default:
+			return policy, fmt.Errorf("unknown rate limit key %q", key)
+		}
+	}
+
+	burst, period, ok := strings.Cut(spec, "/")
+	if !ok {
+		return policy, fmt.Errorf("rate limit %q must look like 10/1m", spec)
+	}
+	n, err := strconv.Atoi(burst)
+	if err != nil || n < 1 {
+		return policy, fmt.Errorf("invalid rate limit burst %q", burst)
+	}
+	d, err := time.ParseDuration(period)
+	if err != nil || d <= 0 {
+		return policy, fmt.Errorf("invalid rate limit period %q", period)
+	}
+	policy.Burst = n
+	policy.Period = d
+	return policy, nil
+}
+
+// RateLimitOverrides parses PTA_RATE_LIMITS, a list of name=burst/period[:key] separated by ";"
+func RateLimitOverrides() map[string]RateLimitPolicy {
+	overrides := map[string]RateLimitPolicy{}
+	for _, entry := range strings.Split(os.Getenv("PTA_RATE_LIMITS"), ";") {
+		name, spec, ok := strings.Cut(entry, "=")
+		name = strings.TrimSpace(name)
+		if !ok || name == "" {
+			continue
+		}
+		policy, err := ParseRateLimitPolicy(name, spec)
+		if err != nil {
+			logger.Warnf("Ignoring rate limit override %s: %v", name, err)
+			continue
+		}
+		overrides[name] = policy
+	}
+	return overrides
+}
@@ -0,0 +1,107 @@
+package utils
+
+import (
+	"time"
+
+	"github.com/pwnthemall/pwnthemall/backend/config"
+	"github.com/pwnthemall/pwnthemall/backend/logger"
+	"github.com/pwnthemall/pwnthemall/backend/models"
+	"gorm.io/gorm/clause"
+)
+
+// CreateSession records a new login and returns its access and refresh tokens
+func CreateSession(userID uint, role, userAgent, ip string) (string, string, error) {
+	refreshToken, jti, expiresAt, err := GenerateRefreshToken(userID)
+	if err != nil {
+		return "", "", err
+	}
+	if len(userAgent) > 512 {
+		userAgent = userAgent[:512]
+	}
+	session := models.UserSession{
+		UserID:     userID,
+		JTI:        jti,
+		UserAgent:  userAgent,
+		IPAddress:  ip,
+		LastSeenAt: time.Now(),
+		ExpiresAt:  expiresAt,
+	}
+	if err := config.DB.Create(&session).Error; err != nil {
+		return "", "", err
+	}
+
+	accessToken, err := GenerateAccessToken(userID, role, jti)
+	if err != nil {
+		return "", "", err
+	}
+	return accessToken, refreshToken, nil
+}
+
+// IsTokenRevoked reports whether any of the given jtis was revoked
+func IsTokenRevoked(jtis ...string) bool {
+	var count int64
+	if err := config.DB.Model(&models.RevokedToken{}).Where("jti IN ?", jtis).Count(&count).Error; err != nil {
+		logger.Errorf("Failed to check token revocation: %v", err)
+		return true
+	}
+	return count > 0
+}
+
+// RevokeToken blocks a jti until the token expires
+func RevokeToken(jti string, expiresAt time.Time) error {
+	if jti == "" {
+		return nil
+	}
+	return config.DB.Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&models.RevokedToken{JTI: jti, ExpiresAt: expiresAt}).Error
+}
+
+// RevokeSession ends a session, its access tokens are refused through their sid claim
+func RevokeSession(session *models.UserSession) error {
+	now := time.Now()
+	if err := config.DB.Model(session).Update("revoked_at", now).Error; err != nil {
+		return err
+	}
+	return RevokeToken(session.JTI, session.ExpiresAt)
+}
+
+// RevokeUserSessions ends every active session of a user except the one with keepJTI
+func RevokeUserSessions(userID uint, keepJTI string) (int, error) {
+	var sessions []models.UserSession
+	query := config.DB.Where("user_id = ? AND revoked_at IS NULL AND expires_at > ?", userID, time.Now())
+	if keepJTI != "" {
+		query = query.Where("jti <> ?", keepJTI)
+	}
+	if err := query.Find(&sessions).Error; err != nil {
+		return 0, err
+	}
+	for i := range sessions {
+		if err := RevokeSession(&sessions[i]); err != nil {
+			return i, err
+		}
+	}
+	return len(sessions), nil
+}
+
+// CleanupExpiredTokens drops revocations and sessions of tokens that expired anyway
+func CleanupExpiredTokens() {
+	now := time.Now()
+	if err := config.DB.Where("expires_at < ?", now).Delete(&models.RevokedToken{}).Error; err != nil {
+		logger.Errorf("Failed to clean up revoked tokens: %v", err)
+	}
+	if err := config.DB.Where("expires_at < ?", now).Delete(&models.UserSession{}).Error; err != nil {
+		logger.Errorf("Failed to clean up expired sessions: %v", err)
+	}
+}
+
+// StartTokenCleanup runs CleanupExpiredTokens every hour
+func StartTokenCleanup() {
+	go func() {
+		ticker := time.NewTicker(time.Hour)
+		defer ticker.Stop()
+		for {
+			CleanupExpiredTokens()
+			<-ticker.C
+		}
+	}()
+}
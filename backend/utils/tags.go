@@ -0,0 +1,53 @@
+package utils
+
+import (
+	"strings"
+
+	"github.com/pwnthemall/pwnthemall/backend/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const maxTagLength = 50
+
+// NormalizeTagNames trims, lowercases and deduplicates tag names, dropping empty or too long ones
+func NormalizeTagNames(names []string) []string {
+	seen := make(map[string]bool)
+	normalized := make([]string, 0, len(names))
+	for _, name := range names {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" || len(name) > maxTagLength || seen[name] {
+			continue
+		}
+		seen[name] = true
+		normalized = append(normalized, name)
+	}
+	return normalized
+}
+
+// SetChallengeTags replaces the tags of a challenge, creating missing tags
+func SetChallengeTags(db *gorm.DB, challenge *models.Challenge, names []string) error {
+	tags := []models.Tag{}
+	for _, name := range NormalizeTagNames(names) {
+		tag := models.Tag{Name: name}
+		if err := db.Clauses(clause.OnConflict{DoNothing: true}).Create(&tag).Error; err != nil {
+			return err
+		}
+		if tag.ID == 0 {
+			if err := db.Where("name = ?", name).First(&tag).Error; err != nil {
+				return err
+			}
+		}
+		tags = append(tags, tag)
+	}
+	return db.Model(challenge).Association("Tags").Replace(tags)
+}
+
+// TagNames returns the names of the given tags
+func TagNames(tags []models.Tag) []string {
+	names := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		names = append(names, tag.Name)
+	}
+	return names
+}
@@ -1,11 +1,14 @@
 package utils
 
 import (
+	"encoding/json"
 	"net/http"
+	"strconv"
+	"strings"
 	"sync"
 
 	"github.com/pwnthemall/pwnthemall/backend/config"
-	"github.com/pwnthemall/pwnthemall/backend/debug"
+	"github.com/pwnthemall/pwnthemall/backend/logger"
 	"github.com/pwnthemall/pwnthemall/backend/models"
 
 	"github.com/gorilla/websocket"
@@ -24,32 +27,83 @@ var WebSocketHub *Hub
 // (categories, challenges, CTF status, instances)
 var UpdatesHub *Hub
 
-// Client represents a connected WebSocket client
+// hubReplaySize is the number of recent events a hub keeps for reconnecting clients
+const hubReplaySize = 1000
+
+// Client represents a connected WebSocket client, a user may have several
 type Client struct {
-	ID   uint
-	Conn *websocket.Conn
-	Hub  *Hub
-	Send chan []byte
-	mu   sync.Mutex
+	ID    uint
+	Conn  *websocket.Conn
+	Hub   *Hub
+	Send  chan []byte
+	mu    sync.Mutex
+	since uint64 // last event received before reconnecting, zero for a fresh connection
 }
 
-// Hub manages all WebSocket connections
+// Hub manages the WebSocket connections of this replica, messages are published to every replica
 type Hub struct {
-	clients    map[uint]*Client
-	broadcast  chan []byte
+	name       string
+	clients    map[uint]map[*Client]struct{}
+	replay     []hubMessage // ring buffer of the last events, oldest at replayNext once full
+	replayNext int
 	register   chan *Client
 	unregister chan *Client
 	mu         sync.RWMutex
 }
 
-// NewHub creates a new WebSocket hub
-func NewHub() *Hub {
-	return &Hub{
-		clients:    make(map[uint]*Client),
-		broadcast:  make(chan []byte),
+// hubMessage is the envelope published to the replicas, a nil UserIDs targets every client
+type hubMessage struct {
+	ID      uint64 `json:"id"`
+	UserIDs []uint `json:"userIds,omitempty"`
+	Except  uint   `json:"except,omitempty"`
+	Message []byte `json:"message"`
+}
+
+// targets reports whether the message is meant for the user
+func (m hubMessage) targets(userID uint) bool {
+	if m.Except != 0 && m.Except == userID {
+		return false
+	}
+	if m.UserIDs == nil {
+		return true
+	}
+	for _, id := range m.UserIDs {
+		if id == userID {
+			return true
+		}
+	}
+	return false
+}
+
+// payload adds the event ID to JSON object messages so clients can resume with ?since=
+func (m hubMessage) payload() []byte {
+	if m.ID == 0 || len(m.Message) < 2 || m.Message[0] != '{' {
+		return m.Message
+	}
+	prefix := `{"eventId":` + strconv.FormatUint(m.ID, 10)
+	if strings.TrimSpace(string(m.Message[1:])) == "}" {
+		return []byte(prefix + "}")
+	}
+	return append([]byte(prefix+","), m.Message[1:]...)
+}
+
+// NewHub creates a new WebSocket hub and subscribes it to the messages of the other replicas
+func NewHub(name string) *Hub {
+	h := &Hub{
+		name:       name,
+		clients:    make(map[uint]map[*Client]struct{}),
+		replay:     make([]hubMessage, 0, hubReplaySize),
 		register:   make(chan *Client),
 		unregister: make(chan *Client),
 	}
+	if err := GetPubSub().Subscribe(h.channel(), h.receive); err != nil {
+		logger.Errorf("Failed to subscribe hub %s, messages will only reach this replica: %v", name, err)
+	}
+	return h
+}
+
+func (h *Hub) channel() string {
+	return "hub_" + h.name
 }
 
 // Run starts the hub's main loop
@@ -58,125 +112,191 @@ func (h *Hub) Run() {
 		select {
 		case client := <-h.register:
 			h.mu.Lock()
-			h.clients[client.ID] = client
+			if h.clients[client.ID] == nil {
+				h.clients[client.ID] = make(map[*Client]struct{})
+			}
+			h.clients[client.ID][client] = struct{}{}
+			if client.since > 0 {
+				h.replayTo(client)
+			}
 			h.mu.Unlock()
-			debug.Log("Client %d connected", client.ID)
+			logger.Debugf("Client %d connected", client.ID)
 
 		case client := <-h.unregister:
 			h.mu.Lock()
-			if _, ok := h.clients[client.ID]; ok {
-				delete(h.clients, client.ID)
-				close(client.Send)
-			}
+			h.remove(client)
 			h.mu.Unlock()
-			debug.Log("Client %d disconnected", client.ID)
-
-		case message := <-h.broadcast:
-			h.mu.RLock()
-			for _, client := range h.clients {
-				select {
-				case client.Send <- message:
-				default:
-					close(client.Send)
-					delete(h.clients, client.ID)
-				}
-			}
-			h.mu.RUnlock()
+			logger.Debugf("Client %d disconnected", client.ID)
 		}
 	}
 }
 
-// SendToUser sends a message to a specific user
-func (h *Hub) SendToUser(userID uint, message []byte) {
-	h.mu.RLock()
-	if client, exists := h.clients[userID]; exists {
-		select {
-		case client.Send <- message:
-		default:
-			close(client.Send)
-			delete(h.clients, userID)
+// remove closes a client, the caller holds the lock
+func (h *Hub) remove(client *Client) {
+	connections, ok := h.clients[client.ID]
+	if !ok {
+		return
+	}
+	if _, ok := connections[client]; !ok {
+		return
+	}
+	delete(connections, client)
+	close(client.Send)
+	if len(connections) == 0 {
+		delete(h.clients, client.ID)
+	}
+}
+
+// replayTo sends the buffered events after client.since, the caller holds the lock
+// A "replay-gap" event tells the client to reload when the events it missed are no longer buffered
+func (h *Hub) replayTo(client *Client) {
+	events := h.bufferedEvents()
+	if len(events) == 0 || events[0].ID > client.since+1 {
+		h.sendGap(client)
+	}
+
+	missed := make([][]byte, 0)
+	for _, event := range events {
+		if event.ID > client.since && event.targets(client.ID) {
+			missed = append(missed, event.payload())
 		}
 	}
-	h.mu.RUnlock()
+	if len(missed) >= cap(client.Send) {
+		h.sendGap(client)
+		return
+	}
+	for _, message := range missed {
+		client.Send <- message
+	}
 }
 
-// SendToAll sends a message to all connected clients
-func (h *Hub) SendToAll(message []byte) {
-	h.broadcast <- message
+func (h *Hub) sendGap(client *Client) {
+	select {
+	case client.Send <- []byte(`{"event":"replay-gap"}`):
+	default:
+	}
 }
 
-// SendToAllExcept sends a message to all connected clients except the specified user
-func (h *Hub) SendToAllExcept(message []byte, excludeUserID uint) {
-	h.mu.RLock()
-	for userID, client := range h.clients {
-		if userID != excludeUserID {
-			select {
-			case client.Send <- message:
-			default:
-				close(client.Send)
-				delete(h.clients, userID)
-			}
-		}
+// bufferedEvents returns the replay buffer from the oldest event, the caller holds the lock
+func (h *Hub) bufferedEvents() []hubMessage {
+	if len(h.replay) < hubReplaySize {
+		return h.replay
 	}
-	h.mu.RUnlock()
+	return append(append([]hubMessage{}, h.replay[h.replayNext:]...), h.replay[:h.replayNext]...)
 }
 
-// SendToTeam sends a message to all connected clients in a specific team
-func (h *Hub) SendToTeam(teamID uint, message []byte) {
-	// Get all users in the team from the database
-	var userIDs []uint
-	if err := config.DB.Model(&models.User{}).Where("team_id = ?", teamID).Pluck("id", &userIDs).Error; err != nil {
-		debug.Log("Failed to get team members for team %d: %v", teamID, err)
+// buffer keeps an event for reconnecting clients, the caller holds the lock
+func (h *Hub) buffer(msg hubMessage) {
+	if len(h.replay) < hubReplaySize {
+		h.replay = append(h.replay, msg)
 		return
 	}
+	h.replay[h.replayNext] = msg
+	h.replayNext = (h.replayNext + 1) % hubReplaySize
+}
 
-	h.mu.RLock()
-	for _, userID := range userIDs {
-		if client, exists := h.clients[userID]; exists {
-			select {
-			case client.Send <- message:
-			default:
-				close(client.Send)
-				delete(h.clients, userID)
-			}
-		}
+// publish sends a message to every replica, it is delivered locally when the pub/sub is unavailable
+func (h *Hub) publish(msg hubMessage) {
+	id, err := GetPubSub().NextID(h.channel())
+	if err != nil {
+		logger.Errorf("Failed to number event on hub %s: %v", h.name, err)
+	}
+	msg.ID = id
+
+	payload, err := json.Marshal(msg)
+	if err == nil {
+		err = GetPubSub().Publish(h.channel(), payload)
+	}
+	if err != nil {
+		logger.Errorf("Failed to publish on hub %s, delivering locally: %v", h.name, err)
+		h.deliver(msg)
 	}
-	h.mu.RUnlock()
 }
 
-// SendToTeamExcept sends a message to all connected clients in a specific team except one user
-func (h *Hub) SendToTeamExcept(teamID uint, excludeUserID uint, message []byte) {
-	var userIDs []uint
-	if err := config.DB.Model(&models.User{}).Where("team_id = ?", teamID).Pluck("id", &userIDs).Error; err != nil {
-		debug.Log("Failed to get team members for team %d: %v", teamID, err)
+func (h *Hub) receive(payload []byte) {
+	var msg hubMessage
+	if err := json.Unmarshal(payload, &msg); err != nil {
+		logger.Errorf("Invalid message on hub %s: %v", h.name, err)
 		return
 	}
+	h.deliver(msg)
+}
 
-	debug.Log("[WebSocket] SendToTeamExcept: team=%d, exclude=%d, team_members=%v", teamID, excludeUserID, userIDs)
+// deliver sends a message to the targeted clients connected to this replica
+func (h *Hub) deliver(msg hubMessage) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
 
-	h.mu.RLock()
-	sentCount := 0
-	for _, userID := range userIDs {
-		if userID == excludeUserID {
-			debug.Log("[WebSocket] Skipping excluded user %d", userID)
-			continue
+	if msg.ID != 0 {
+		h.buffer(msg)
+	}
+	message := msg.payload()
+
+	send := func(userID uint) {
+		if !msg.targets(userID) {
+			return
 		}
-		if client, exists := h.clients[userID]; exists {
+		for client := range h.clients[userID] {
 			select {
 			case client.Send <- message:
-				sentCount++
-				debug.Log("[WebSocket] Sent message to user %d", userID)
 			default:
-				close(client.Send)
-				delete(h.clients, userID)
-				debug.Log("[WebSocket] Failed to send to user %d (channel full), closed connection", userID)
+				h.remove(client)
+				logger.Warnf("[WebSocket] Failed to send to user %d (channel full), closed connection", userID)
 			}
-		} else {
-			debug.Log("[WebSocket] User %d not connected to websocket", userID)
 		}
 	}
-	h.mu.RUnlock()
-	debug.Log("[WebSocket] Sent message to %d users (excluding user %d)", sentCount, excludeUserID)
+
+	if msg.UserIDs == nil {
+		for userID := range h.clients {
+			send(userID)
+		}
+		return
+	}
+	for _, userID := range msg.UserIDs {
+		send(userID)
+	}
+}
+
+// SendToUser sends a message to every connection of a specific user
+func (h *Hub) SendToUser(userID uint, message []byte) {
+	h.publish(hubMessage{UserIDs: []uint{userID}, Message: message})
+}
+
+// SendToUsers sends a message to every connection of the given users except one
+func (h *Hub) SendToUsers(userIDs []uint, excludeUserID uint, message []byte) {
+	if len(userIDs) == 0 {
+		return
+	}
+	h.publish(hubMessage{UserIDs: userIDs, Except: excludeUserID, Message: message})
+}
+
+// SendToAll sends a message to all connected clients
+func (h *Hub) SendToAll(message []byte) {
+	h.publish(hubMessage{Message: message})
+}
+
+// SendToAllExcept sends a message to all connected clients except the specified user
+func (h *Hub) SendToAllExcept(message []byte, excludeUserID uint) {
+	h.publish(hubMessage{Except: excludeUserID, Message: message})
+}
+
+// SendToTeam sends a message to all connected clients in a specific team
+func (h *Hub) SendToTeam(teamID uint, message []byte) {
+	h.SendToTeamExcept(teamID, 0, message)
+}
+
+// SendToTeamExcept sends a message to all connected clients in a specific team except one user
+func (h *Hub) SendToTeamExcept(teamID uint, excludeUserID uint, message []byte) {
+	userIDs := []uint{}
+	if err := config.DB.Model(&models.User{}).Where("team_id = ?", teamID).Pluck("id", &userIDs).Error; err != nil {
+		logger.Errorf("Failed to get team members for team %d: %v", teamID, err)
+		return
+	}
+	if len(userIDs) == 0 {
+		return
+	}
+	logger.Debugf("[WebSocket] SendToTeamExcept: team=%d, exclude=%d, team_members=%v", teamID, excludeUserID, userIDs)
+	h.publish(hubMessage{UserIDs: userIDs, Except: excludeUserID, Message: message})
 }
 
 // GetConnectedUsers returns a list of connected user IDs
@@ -202,11 +322,11 @@ func (c *Client) readPump() {
 		_, message, err := c.Conn.ReadMessage()
 		if err != nil {
 			if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseAbnormalClosure) {
-				debug.Log("error: %v", err)
+				logger.Errorf("error: %v", err)
 			}
 			break
 		}
-		debug.Log("Received message from client %d: %s", c.ID, string(message))
+		logger.Debugf("Received message from client %d: %s", c.ID, string(message))
 	}
 }
 
@@ -238,7 +358,7 @@ func (c *Client) writePump() {
 func ServeWs(hub *Hub, userID uint, w http.ResponseWriter, r *http.Request) {
 	conn, err := upgrader.Upgrade(w, r, nil)
 	if err != nil {
-		debug.Log("WebSocket upgrade error: %v", err)
+		logger.Errorf("WebSocket upgrade error: %v", err)
 		return
 	}
 
@@ -248,6 +368,10 @@ func ServeWs(hub *Hub, userID uint, w http.ResponseWriter, r *http.Request) {
 		Conn: conn,
 		Send: make(chan []byte, 256),
 	}
+	// Clients resuming after a disconnection get the events they missed
+	if since, err := strconv.ParseUint(r.URL.Query().Get("since"), 10, 64); err == nil {
+		client.since = since
+	}
 
 	client.Hub.register <- client
 
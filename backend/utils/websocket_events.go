@@ -0,0 +1,251 @@
+package utils
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/pwnthemall/pwnthemall/backend/config"
+	"github.com/pwnthemall/pwnthemall/backend/dto"
+	"github.com/pwnthemall/pwnthemall/backend/events"
+	"github.com/pwnthemall/pwnthemall/backend/logger"
+	"github.com/pwnthemall/pwnthemall/backend/models"
+)
+
+// SubscribeWebSocketHubs turns platform events into the messages sent on the WebSocket hubs
+func SubscribeWebSocketHubs() {
+	events.Subscribe("websocket", func(e events.Event) {
+		if WebSocketHub == nil || UpdatesHub == nil {
+			return
+		}
+		switch ev := e.(type) {
+		case events.SolveCreated:
+			sendJSON(ev, func(p []byte) { WebSocketHub.SendToTeamExcept(ev.TeamID, ev.UserID, p) }, dto.TeamSolveEvent{
+				Event:         "team_solve",
+				TeamID:        ev.TeamID,
+				ChallengeID:   ev.ChallengeID,
+				ChallengeName: ev.ChallengeName,
+				ChallengeSlug: ev.ChallengeSlug,
+				Points:        ev.Points,
+				UserID:        ev.UserID,
+				Username:      ev.Username,
+				Timestamp:     ev.SolvedAt.UTC().Unix(),
+			})
+		case events.InstanceStarted:
+			sendJSON(ev, func(p []byte) { WebSocketHub.SendToTeamExcept(ev.TeamID, ev.UserID, p) }, dto.InstanceEvent{
+				Event:          "instance_update",
+				TeamID:         ev.TeamID,
+				UserID:         ev.UserID,
+				Username:       ev.Username,
+				ChallengeID:    ev.ChallengeID,
+				Status:         "running",
+				Name:           ev.Name,
+				CreatedAt:      ev.CreatedAt.UTC().Unix(),
+				ExpiresAt:      ev.ExpiresAt.UTC().Unix(),
+				Ports:          ev.Ports,
+				ConnectionInfo: ev.ConnectionInfo,
+			})
+		case events.InstanceStopped:
+			// The solver already knows the instance stopped from the submit response
+			var except uint
+			if ev.Reason == "solve" {
+				except = ev.UserID
+			}
+			sendJSON(ev, func(p []byte) { WebSocketHub.SendToTeamExcept(ev.TeamID, except, p) }, dto.InstanceEvent{
+				Event:       "instance_update",
+				TeamID:      ev.TeamID,
+				UserID:      ev.UserID,
+				Username:    ev.Username,
+				ChallengeID: ev.ChallengeID,
+				Status:      "stopped",
+				UpdatedAt:   time.Now().UTC().Unix(),
+			})
+		case events.HintPurchased:
+			sendJSON(ev, func(p []byte) { WebSocketHub.SendToTeam(ev.TeamID, p) }, dto.HintPurchaseEvent{
+				Event:       "hint_purchase",
+				TeamID:      ev.TeamID,
+				ChallengeID: ev.ChallengeID,
+				HintID:      ev.HintID,
+				UserID:      ev.UserID,
+				Username:    ev.Username,
+				HintTitle:   ev.HintTitle,
+				HintContent: ev.HintContent,
+				Cost:        ev.Cost,
+				Timestamp:   time.Now().UTC().Unix(),
+			})
+		case events.TicketCreated:
+			sendTicketEvent(ev.UserID, ev.TeamID, ev.UserID, false, dto.TicketWebSocketEvent{
+				Event:    "ticket_created",
+				TicketID: ev.TicketID,
+				Subject:  ev.Subject,
+				UserID:   ev.UserID,
+				Username: ev.Username,
+				TeamID:   ev.TeamID,
+			})
+		case events.TicketMessageAdded:
+			// Internal notes only reach the staff
+			sendTicketEvent(ev.TicketUserID, ev.TeamID, ev.UserID, ev.Internal, dto.TicketWebSocketEvent{
+				Event:       "ticket_message",
+				TicketID:    ev.TicketID,
+				MessageID:   ev.MessageID,
+				UserID:      ev.UserID,
+				Username:    ev.Username,
+				Message:     ev.Message,
+				Attachments: ev.Attachments,
+				CreatedAt:   ev.CreatedAt.Format(time.RFC3339),
+				IsAdmin:     ev.IsAdmin,
+				IsInternal:  ev.Internal,
+			})
+		case events.TicketClaimed:
+			event := dto.TicketWebSocketEvent{
+				Event:       "ticket_claimed",
+				TicketID:    ev.TicketID,
+				UserID:      ev.UserID,
+				ClaimedByID: ev.ClaimedByID,
+			}
+			if ev.ClaimedByID != nil {
+				event.ClaimedByName = &ev.ClaimedByName
+			}
+			sendTicketEvent(ev.TicketUserID, ev.TeamID, 0, false, event)
+		case events.TicketResolved:
+			// Nobody is excluded, everyone needs to see the status change
+			sendTicketEvent(ev.TicketUserID, ev.TeamID, 0, false, dto.TicketWebSocketEvent{
+				Event:    "ticket_resolved",
+				TicketID: ev.TicketID,
+				UserID:   ev.UserID,
+			})
+		case events.TicketReopened:
+			sendTicketEvent(ev.TicketUserID, ev.TeamID, 0, false, dto.TicketWebSocketEvent{
+				Event:    "ticket_reopened",
+				TicketID: ev.TicketID,
+				UserID:   ev.UserID,
+			})
+		case events.NotificationSent:
+			sendJSON(ev, notificationAudience(ev.UserID, ev.TeamID, ev.Global, ev.Recipients, ev.SenderID), dto.NotificationResponse{
+				ID:        ev.NotificationID,
+				Title:     ev.Title,
+				Message:   ev.Message,
+				Type:      ev.Type,
+				CreatedAt: ev.CreatedAt,
+			})
+		case events.NotificationUpdated:
+			sendJSON(ev, notificationAudience(ev.UserID, ev.TeamID, ev.Global, ev.Recipients, 0), dto.NotificationUpdateEvent{
+				Event: "notification_update",
+				NotificationResponse: dto.NotificationResponse{
+					ID:        ev.NotificationID,
+					Title:     ev.Title,
+					Message:   ev.Message,
+					Type:      ev.Type,
+					CreatedAt: ev.CreatedAt,
+					EditedAt:  &ev.EditedAt,
+				},
+			})
+		case events.UserBanned:
+			sendJSON(ev, func(p []byte) { UpdatesHub.SendToUser(ev.UserID, p) }, map[string]interface{}{
+				"event":   "user-banned",
+				"user_id": ev.UserID,
+			})
+		case events.ChallengesChanged:
+			sendJSON(ev, UpdatesHub.SendToAll, map[string]interface{}{
+				"event":  "challenge-category",
+				"action": ev.Action,
+			})
+		case events.PageSynced:
+			sendJSON(ev, UpdatesHub.SendToAll, map[string]interface{}{
+				"event":  "page",
+				"action": "synced",
+				"slug":   ev.Slug,
+			})
+		case events.ConfigChanged:
+			event, action := configEventAction(ev)
+			if event == "" {
+				return
+			}
+			sendJSON(ev, UpdatesHub.SendToAll, map[string]interface{}{
+				"event":  event,
+				"action": action,
+				"key":    ev.Key,
+				"value":  ev.Value,
+			})
+		}
+	})
+}
+
+// configEventAction tells which hub event a config change triggers, private configs other than the CTF timing are not broadcast
+func configEventAction(ev events.ConfigChanged) (string, string) {
+	switch ev.Key {
+	case "CTF_START_TIME", "CTF_END_TIME":
+		return "ctf-status", "config_update"
+	case "TICKETS_ENABLED":
+		return "config-update", "tickets_enabled"
+	}
+	if ev.Public {
+		return "config-update", "public_config"
+	}
+	return "", ""
+}
+
+// sendJSON encodes the hub message of an event and hands it to send
+func sendJSON(e events.Event, send func([]byte), message interface{}) {
+	payload, err := json.Marshal(message)
+	if err != nil {
+		logger.Errorf("Failed to marshal WebSocket message for %s: %v", e.EventName(), err)
+		return
+	}
+	send(payload)
+}
+
+// sendTicketEvent sends a ticket event to the ticket owner, their team and the staff allowed to read tickets, or to the staff alone
+func sendTicketEvent(ownerID uint, teamID *uint, excludeUserID uint, staffOnly bool, event dto.TicketWebSocketEvent) {
+	recipients := map[uint]struct{}{}
+	if !staffOnly {
+		recipients[ownerID] = struct{}{}
+	}
+
+	if teamID != nil && !staffOnly {
+		var memberIDs []uint
+		if err := config.DB.Model(&models.User{}).Where("team_id = ?", *teamID).Pluck("id", &memberIDs).Error; err == nil {
+			for _, id := range memberIDs {
+				recipients[id] = struct{}{}
+			}
+		}
+	}
+
+	var staffIDs []uint
+	if err := config.DB.Model(&models.User{}).
+		Where("role IN ?", config.RolesWithPermission("/admin/tickets", "read")).
+		Pluck("id", &staffIDs).Error; err == nil {
+		for _, id := range staffIDs {
+			recipients[id] = struct{}{}
+		}
+	}
+
+	userIDs := make([]uint, 0, len(recipients))
+	for id := range recipients {
+		userIDs = append(userIDs, id)
+	}
+
+	logger.Debugf("Sending ticket WebSocket event: %s for ticket %d to %d authorized users (excluding %d)",
+		event.Event, event.TicketID, len(userIDs), excludeUserID)
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		logger.Errorf("Failed to marshal ticket WebSocket event: %v", err)
+		return
+	}
+	WebSocketHub.SendToUsers(userIDs, excludeUserID, payload)
+	UpdatesHub.SendToUsers(userIDs, excludeUserID, payload)
+}
+
+// notificationAudience returns a sender reaching the user, team, resolved recipients or everyone targeted by a notification
+func notificationAudience(userID, teamID *uint, global bool, recipients []uint, excludeUserID uint) func([]byte) {
+	switch {
+	case userID != nil:
+		return func(p []byte) { WebSocketHub.SendToUser(*userID, p) }
+	case teamID != nil:
+		return func(p []byte) { WebSocketHub.SendToTeam(*teamID, p) }
+	case global:
+		return func(p []byte) { WebSocketHub.SendToAllExcept(p, excludeUserID) }
+	default:
+		return func(p []byte) { WebSocketHub.SendToUsers(recipients, excludeUserID, p) }
+	}
+}
@@ -0,0 +1,240 @@
+import React, { useState, FormEvent } from "react";
+import { Copy, Check, Trash2 } from "lucide-react";
+import { Button } from "@/components/ui/button";
+import { Input } from "@/components/ui/input";
+import { Checkbox } from "@/components/ui/checkbox";
+import { Badge } from "@/components/ui/badge";
+import {
+  Select,
+  SelectContent,
+  SelectItem,
+  SelectTrigger,
+  SelectValue,
+} from "@/components/ui/select";
+import {
+  Dialog,
+  DialogContent,
+  DialogDescription,
+  DialogFooter,
+  DialogHeader,
+  DialogTitle,
+} from "@/components/ui/dialog";
+import {
+  AlertDialog,
+  AlertDialogContent,
+  AlertDialogHeader,
+  AlertDialogTitle,
+  AlertDialogDescription,
+  AlertDialogFooter,
+  AlertDialogCancel,
+  AlertDialogAction,
+} from "@/components/ui/alert-dialog";
+import { useLanguage } from "@/context/LanguageContext";
+import { useAPITokens } from "@/hooks/use-api-tokens";
+import { APIToken, APITokenScope } from "@/models/APIToken";
+import { toast } from "sonner";
+
+// Days offered for the expiration, 0 means the token never expires
+const EXPIRATION_OPTIONS = [7, 30, 90, 365, 0];
+
+const scopeKey = (scope: APITokenScope) => `api_tokens.scope_${scope.replace(":", "_")}`;
+
+export function APITokensSection() {
+  const { t } = useLanguage();
+  const { tokens, availableScopes, loading, createToken, revokeToken } = useAPITokens();
+  const [name, setName] = useState("");
+  const [scopes, setScopes] = useState<APITokenScope[]>([]);
+  const [expiresInDays, setExpiresInDays] = useState(30);
+  const [creating, setCreating] = useState(false);
+  const [createdToken, setCreatedToken] = useState<string | null>(null);
+  const [copied, setCopied] = useState(false);
+  const [tokenToRevoke, setTokenToRevoke] = useState<APIToken | null>(null);
+
+  const toggleScope = (scope: APITokenScope, checked: boolean) => {
+    setScopes(prev => (checked ? [...prev, scope] : prev.filter(s => s !== scope)));
+  };
+
+  const handleCreate = async (e: FormEvent) => {
+    e.preventDefault();
+    setCreating(true);
+    try {
+      const raw = await createToken({ name: name.trim(), scopes, expiresInDays });
+      setCreatedToken(raw);
+      setName("");
+      setScopes([]);
+    } catch (err: any) {
+      toast.error(t(err?.response?.data?.error || "api_tokens.create_failed"), { className: "bg-red-600 text-white" });
+    } finally {
+      setCreating(false);
+    }
+  };
+
+  const handleRevoke = async () => {
+    if (!tokenToRevoke) return;
+    try {
+      await revokeToken(tokenToRevoke.id);
+      toast.success(t("api_tokens.token_revoked"));
+    } catch (err: any) {
+      toast.error(t(err?.response?.data?.error || "api_tokens.revoke_failed"), { className: "bg-red-600 text-white" });
+    } finally {
+      setTokenToRevoke(null);
+    }
+  };
+
+  const copyToken = async () => {
+    if (!createdToken) return;
+    try {
+      await navigator.clipboard.writeText(createdToken);
+      setCopied(true);
+      toast.success(t("copied_to_clipboard") || "Copied to clipboard!");
+      setTimeout(() => setCopied(false), 2000);
+    } catch (error) {
+      toast.error(t("copy_failed") || "Failed to copy to clipboard");
+    }
+  };
+
+  const closeCreatedToken = () => {
+    setCreatedToken(null);
+    setCopied(false);
+  };
+
+  const isExpired = (token: APIToken) => !!token.expiresAt && new Date(token.expiresAt) < new Date();
+
+  return (
+    <div className="space-y-6">
+      <div>
+        <h2 className="text-lg font-semibold">{t("api_tokens.api_tokens")}</h2>
+        <p className="text-sm text-muted-foreground">{t("api_tokens.description")}</p>
+      </div>
+
+      <form className="space-y-4 max-w-md" onSubmit={handleCreate}>
+        <div>
+          <label className="block text-sm font-medium mb-1" htmlFor="token-name">{t("api_tokens.name")}</label>
+          <Input
+            id="token-name"
+            value={name}
+            onChange={(e) => setName(e.target.value)}
+            required
+            maxLength={64}
+            disabled={creating}
+          />
+        </div>
+        <div>
+          <span className="block text-sm font-medium mb-2">{t("api_tokens.scopes")}</span>
+          <div className="space-y-2">
+            {availableScopes.map((scope) => (
+              <label key={scope} className="flex items-center gap-2 text-sm">
+                <Checkbox
+                  checked={scopes.includes(scope)}
+                  onCheckedChange={(checked) => toggleScope(scope, checked as boolean)}
+                  disabled={creating}
+                />
+                <code className="text-xs">{scope}</code>
+                <span className="text-muted-foreground">{t(scopeKey(scope))}</span>
+              </label>
+            ))}
+          </div>
+        </div>
+        <div>
+          <span className="block text-sm font-medium mb-1">{t("api_tokens.expiration")}</span>
+          <Select value={String(expiresInDays)} onValueChange={(value) => setExpiresInDays(Number(value))}>
+            <SelectTrigger>
+              <SelectValue />
+            </SelectTrigger>
+            <SelectContent>
+              {EXPIRATION_OPTIONS.map((days) => (
+                <SelectItem key={days} value={String(days)}>
+                  {days === 0 ? t("api_tokens.never_expires") : t("api_tokens.expires_in_days", { days })}
+                </SelectItem>
+              ))}
+            </SelectContent>
+          </Select>
+        </div>
+        <Button type="submit" className="w-full" disabled={creating || !name.trim() || scopes.length === 0}>
+          {t("api_tokens.create_token")}
+        </Button>
+      </form>
+
+      <div className="space-y-2">
+        {loading ? null : tokens.length === 0 ? (
+          <p className="text-sm text-muted-foreground">{t("api_tokens.no_tokens")}</p>
+        ) : (
+          tokens.map((token) => (
+            <div key={token.id} className="flex items-start justify-between gap-4 rounded-lg border p-3">
+              <div className="space-y-1 min-w-0">
+                <div className="flex items-center gap-2">
+                  <span className="font-medium truncate">{token.name}</span>
+                  <code className="text-xs text-muted-foreground">{token.prefix}…</code>
+                  {isExpired(token) && <Badge variant="destructive">{t("api_tokens.expired")}</Badge>}
+                </div>
+                <div className="flex flex-wrap gap-1">
+                  {token.scopes.map((scope) => (
+                    <Badge key={scope} variant="secondary">{scope}</Badge>
+                  ))}
+                </div>
+                <div className="text-xs text-muted-foreground">
+                  {t("api_tokens.created_at", { date: new Date(token.createdAt).toLocaleString() })}
+                  {" · "}
+                  {token.lastUsedAt
+                    ? t("api_tokens.last_used_at", { date: new Date(token.lastUsedAt).toLocaleString() })
+                    : t("api_tokens.never_used")}
+                  {" · "}
+                  {token.expiresAt
+                    ? t("api_tokens.expires_at", { date: new Date(token.expiresAt).toLocaleString() })
+                    : t("api_tokens.never_expires")}
+                </div>
+              </div>
+              <Button
+                type="button"
+                variant="ghost"
+                size="icon"
+                aria-label={t("api_tokens.revoke")}
+                onClick={() => setTokenToRevoke(token)}
+              >
+                <Trash2 className="h-4 w-4 text-destructive" />
+              </Button>
+            </div>
+          ))
+        )}
+      </div>
+
+      <Dialog open={createdToken !== null} onOpenChange={(open) => { if (!open) closeCreatedToken(); }}>
+        <DialogContent>
+          <DialogHeader>
+            <DialogTitle>{t("api_tokens.token_created")}</DialogTitle>
+            <DialogDescription>{t("api_tokens.copy_warning")}</DialogDescription>
+          </DialogHeader>
+          <div className="flex items-center gap-2">
+            <code className="flex-1 break-all rounded-md bg-muted p-2 text-sm">{createdToken}</code>
+            <Button type="button" variant="outline" size="icon" onClick={copyToken}>
+              {copied ? <Check className="h-4 w-4" /> : <Copy className="h-4 w-4" />}
+            </Button>
+          </div>
+          <DialogFooter>
+            <Button type="button" onClick={closeCreatedToken}>{t("api_tokens.done")}</Button>
+          </DialogFooter>
+        </DialogContent>
+      </Dialog>
+
+      <AlertDialog open={tokenToRevoke !== null} onOpenChange={(open) => { if (!open) setTokenToRevoke(null); }}>
+        <AlertDialogContent>
+          <AlertDialogHeader>
+            <AlertDialogTitle>{t("api_tokens.revoke")}</AlertDialogTitle>
+            <AlertDialogDescription>
+              {t("api_tokens.revoke_confirm", { name: tokenToRevoke?.name || "" })}
+            </AlertDialogDescription>
+          </AlertDialogHeader>
+          <AlertDialogFooter>
+            <AlertDialogCancel>{t("cancel")}</AlertDialogCancel>
+            <AlertDialogAction
+              onClick={handleRevoke}
+              className="bg-destructive text-destructive-foreground hover:bg-destructive/90"
+            >
+              {t("api_tokens.revoke")}
+            </AlertDialogAction>
+          </AlertDialogFooter>
+        </AlertDialogContent>
+      </AlertDialog>
+    </div>
+  );
+}
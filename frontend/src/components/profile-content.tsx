@@ -23,6 +23,7 @@ import { useLanguage } from "@/context/LanguageContext";
 import { Team } from "@/models/Team";
 import { User } from "@/models/User";
 import { TeamManagementSection } from "@/components/TeamManagementSection";
+import { APITokensSection } from "@/components/APITokensSection";
 import { toast } from "sonner";
 
 const TABS = ["Account", "Security", "Appearance", "Team"] as const;
@@ -332,6 +333,7 @@ function ProfileContentInner() {
           </form>
         )}
         {activeTab === "Security" && (
+          <>
           <form className="space-y-4 max-w-md" onSubmit={handlePasswordChange}>
             <div>
               <label className="block text-sm font-medium mb-1" htmlFor="current">{t('current_password')}</label>
@@ -369,6 +371,9 @@ function ProfileContentInner() {
               </AlertDialogContent>
             </AlertDialog>
           </form>
+          <Separator className="my-6" />
+          <APITokensSection />
+          </>
         )}
         {activeTab === "Appearance" && (
           <div className="space-y-4">
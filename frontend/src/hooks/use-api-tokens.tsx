@@ -0,0 +1,63 @@
+import { useEffect, useState, useCallback } from 'react';
+import { APIToken, APITokenCreateResponse, APITokenInput, APITokenListResponse, APITokenScope } from '@/models/APIToken';
+import axios from '@/lib/axios';
+import { debugError } from '@/lib/debug';
+
+interface UseAPITokensReturn {
+  tokens: APIToken[];
+  availableScopes: APITokenScope[];
+  loading: boolean;
+  error: string | null;
+  refreshTokens: () => Promise<void>;
+  createToken: (input: APITokenInput) => Promise<string>;
+  revokeToken: (id: number) => Promise<void>;
+}
+
+export function useAPITokens(): UseAPITokensReturn {
+  const [tokens, setTokens] = useState<APIToken[]>([]);
+  const [availableScopes, setAvailableScopes] = useState<APITokenScope[]>([]);
+  const [loading, setLoading] = useState(true);
+  const [error, setError] = useState<string | null>(null);
+
+  const fetchTokens = useCallback(async () => {
+    setLoading(true);
+    setError(null);
+    try {
+      const response = await axios.get<APITokenListResponse>('/api/me/tokens');
+      setTokens(response.data.tokens || []);
+      setAvailableScopes(response.data.availableScopes || []);
+    } catch (err: any) {
+      debugError('Failed to fetch API tokens:', err);
+      setError(err.response?.data?.error || 'Failed to fetch API tokens');
+      setTokens([]);
+    } finally {
+      setLoading(false);
+    }
+  }, []);
+
+  useEffect(() => {
+    fetchTokens();
+  }, [fetchTokens]);
+
+  // The raw token is only returned by this call, the list only keeps its prefix
+  const createToken = async (input: APITokenInput): Promise<string> => {
+    const response = await axios.post<APITokenCreateResponse>('/api/me/tokens', input);
+    setTokens(prev => [response.data.apiToken, ...prev]);
+    return response.data.token;
+  };
+
+  const revokeToken = async (id: number): Promise<void> => {
+    await axios.delete(`/api/me/tokens/${id}`);
+    setTokens(prev => prev.filter(token => token.id !== id));
+  };
+
+  return {
+    tokens,
+    availableScopes,
+    loading,
+    error,
+    refreshTokens: fetchTokens,
+    createToken,
+    revokeToken,
+  };
+}
@@ -205,7 +205,13 @@ export const useNotifications = (isAuthenticated: boolean = false): UseNotificat
                   debugLog('[NOTIFICATIONS WS] Event dispatched successfully');
                   return; // Not a Notification object
                 }
-                if (parsed && (parsed.event === 'ticket_created' || parsed.event === 'ticket_message' || parsed.event === 'ticket_resolved')) {
+                if (parsed && parsed.event === 'notification_update') {
+                  debugLog('[WS] notification_update event', parsed);
+                  const { event: _event, ...updated } = parsed;
+                  setNotifications(prev => prev.map(n => (n.id === updated.id ? { ...n, ...updated, readAt: n.readAt } : n)));
+                  return;
+                }
+                if (parsed && (parsed.event === 'ticket_created' || parsed.event === 'ticket_message' || parsed.event === 'ticket_resolved' || parsed.event === 'ticket_reopened' || parsed.event === 'ticket_claimed')) {
                   debugLog('[WS] Ticket event:', parsed.event);
                   window.dispatchEvent(new CustomEvent('realtime-update', { detail: parsed }));
                   return;
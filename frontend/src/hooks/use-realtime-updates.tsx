@@ -2,7 +2,8 @@
 import { useEffect, useRef, useState } from 'react';
 
 export type UpdateEvent = {
-  event: 'challenge-category' | 'ctf-status' | 'instance' | 'user-banned' | 'ticket_created' | 'ticket_message' | 'ticket_resolved' | 'config-update';
+  event: 'challenge-category' | 'ctf-status' | 'instance' | 'user-banned' | 'ticket_created' | 'ticket_message' | 'ticket_resolved' | 'ticket_reopened' | 'ticket_claimed' | 'config-update' | 'replay-gap';
+  eventId?: number;
   action?: string;
   data?: any;
   key?: string;
@@ -19,6 +20,9 @@ export type UpdateEvent = {
   attachments?: string[];
   createdAt?: string;
   isAdmin?: boolean;
+  isInternal?: boolean;
+  claimedById?: number;
+  claimedByName?: string;
 };
 
 type UpdateCallback = (event: UpdateEvent) => void;
@@ -32,6 +36,8 @@ let closeTimeout: NodeJS.Timeout | undefined;
 const callbacks = new Map<number, UpdateCallback>();
 const connectionListeners = new Set<(connected: boolean) => void>();
 let callbackIdCounter = 0;
+// Last event received, sent on reconnection to get the missed events
+let lastEventId = 0;
 
 export function useRealtimeUpdates(onUpdate?: UpdateCallback, enabled: boolean = true, requireAuth: boolean = true) {
   const [isConnected, setIsConnected] = useState(globalIsConnected);
@@ -96,7 +102,8 @@ export function useRealtimeUpdates(onUpdate?: UpdateCallback, enabled: boolean =
       const connect = () => {
         try {
           const protocol = window.location.protocol === 'https:' ? 'wss:' : 'ws:';
-          const wsUrl = `${protocol}//${window.location.host}/ws/updates`;
+          const since = lastEventId > 0 ? `?since=${lastEventId}` : '';
+          const wsUrl = `${protocol}//${window.location.host}/ws/updates${since}`;
 
           const ws = new WebSocket(wsUrl);
           globalWs = ws;
@@ -111,6 +118,9 @@ export function useRealtimeUpdates(onUpdate?: UpdateCallback, enabled: boolean =
           ws.onmessage = (event) => {
             try {
               const data: UpdateEvent = JSON.parse(event.data);
+              if (data.eventId && data.eventId > lastEventId) {
+                lastEventId = data.eventId;
+              }
               // Process update silently
               
               // Handle user-banned event specially - dispatch to window
@@ -78,6 +78,18 @@ export function useTickets(options: UseTicketsOptions = {}): UseTicketsReturn {
         debugLog('Tickets after update:', updated.filter(t => t.id === event.ticketId));
         return updated;
       });
+    } else if (event.event === 'ticket_reopened' && event.ticketId) {
+      setTickets(prev => prev.map(ticket =>
+        ticket.id === event.ticketId
+          ? { ...ticket, status: 'open' as const, resolvedAt: undefined, reopenedAt: new Date().toISOString() }
+          : ticket
+      ));
+    } else if (event.event === 'ticket_claimed' && event.ticketId) {
+      setTickets(prev => prev.map(ticket =>
+        ticket.id === event.ticketId
+          ? { ...ticket, claimedById: event.claimedById, claimedByName: event.claimedByName }
+          : ticket
+      ));
     } else if (event.event === 'ticket_message' && event.ticketId) {
       // Update message count and last message preview
       setTickets(prev => prev.map(ticket => 
@@ -166,6 +178,10 @@ export function useTicketDetail(ticketId: number | string, isAdmin: boolean = fa
     if (event.event === 'ticket_resolved') {
       // Update status in place
       setTicket(prev => prev ? { ...prev, status: 'resolved' } : null);
+    } else if (event.event === 'ticket_reopened') {
+      setTicket(prev => prev ? { ...prev, status: 'open', resolvedAt: undefined } : null);
+    } else if (event.event === 'ticket_claimed') {
+      setTicket(prev => prev ? { ...prev, claimedById: event.claimedById, claimedByName: event.claimedByName } : null);
     } else if (event.event === 'ticket_message' && event.messageId && event.createdAt) {
       // Append new message directly from WebSocket event data
       const newMessage: TicketMessage = {
@@ -175,6 +191,7 @@ export function useTicketDetail(ticketId: number | string, isAdmin: boolean = fa
         username: event.username || '',
         message: event.message || '',
         isAdmin: event.isAdmin || false,
+        isInternal: event.isInternal,
         attachments: event.attachments || [],
         createdAt: event.createdAt,
       };
@@ -0,0 +1,27 @@
+export type APITokenScope = 'submit' | 'instances' | 'admin:read' | 'challenges:sync';
+
+export interface APIToken {
+  id: number;
+  name: string;
+  prefix: string;
+  scopes: APITokenScope[];
+  expiresAt?: string;
+  lastUsedAt?: string;
+  createdAt: string;
+}
+
+export interface APITokenInput {
+  name: string;
+  scopes: APITokenScope[];
+  expiresInDays: number;
+}
+
+export interface APITokenListResponse {
+  tokens: APIToken[];
+  availableScopes: APITokenScope[];
+}
+
+export interface APITokenCreateResponse {
+  token: string;
+  apiToken: APIToken;
+}
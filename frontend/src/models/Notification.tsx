@@ -4,6 +4,8 @@ export interface Notification {
   message: string;
   type: 'info' | 'warning' | 'error';
   readAt?: string;
+  editedAt?: string;
+  pinned?: boolean;
   createdAt: string;
 }
 
@@ -13,6 +15,11 @@ export interface NotificationInput {
   type: 'info' | 'warning' | 'error';
   userId?: number;
   teamId?: number;
+  targetRole?: string;
+  unsolvedChallengeId?: number;
+  targetBracketId?: number;
+  sendAt?: string;
+  pinned?: boolean;
 }
 
 export interface SentNotification {
@@ -1,3 +1,5 @@
+export type PageFormat = 'html' | 'markdown';
+
 export interface Page {
   id: number;
   slug: string;
@@ -6,6 +8,10 @@ export interface Page {
   is_in_sidebar: boolean;
   order: number;
   source: 'ui' | 'minio';
+  format: PageFormat;
+  draft: boolean;
+  publish_at?: string;
+  unpublish_at?: string;
   last_synced_at?: string;
   created_at: string;
   updated_at: string;
@@ -26,8 +32,25 @@ export interface PageFormData {
   slug: string;
   title: string;
   html: string;
+  format?: PageFormat;
+  markdown?: string;
+  draft?: boolean;
+  publish_at?: string;
+  unpublish_at?: string;
 }
 
 export interface PageWithContent extends Page {
   html: string;
 }
+
+export interface PageRevision {
+  id: number;
+  number: number;
+  title: string;
+  format: PageFormat;
+  content?: string;
+  author_id?: number;
+  author_name?: string;
+  restored_from?: number;
+  created_at: string;
+}
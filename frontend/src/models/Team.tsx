@@ -4,6 +4,7 @@ export interface Team {
   id: number;
   name: string;
   creatorId: number;
+  bracketId?: number;
   users: User[];
   createdAt?: string;
   updatedAt?: string;
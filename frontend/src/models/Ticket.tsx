@@ -11,12 +11,30 @@ export interface Ticket {
   challengeId?: number;
   challengeName?: string;
   challengeSlug?: string;
+  claimedById?: number;
+  claimedByName?: string;
   attachments: string[];
   createdAt: string;
   updatedAt: string;
+  claimedAt?: string;
   resolvedAt?: string;
+  reopenedAt?: string;
   messageCount: number;
   lastMessage?: string;
+  // Admin only
+  priority?: 'low' | 'normal' | 'high' | 'urgent';
+  tags?: string[];
+  firstResponseAt?: string;
+  slaDueAt?: string;
+  slaBreached?: boolean;
+}
+
+export interface TicketCannedResponse {
+  id: number;
+  title: string;
+  message: string;
+  createdAt: string;
+  updatedAt: string;
 }
 
 export interface TicketMessage {
@@ -26,6 +44,7 @@ export interface TicketMessage {
   username: string;
   message: string;
   isAdmin: boolean;
+  isInternal?: boolean;
   attachments: string[];
   createdAt: string;
 }
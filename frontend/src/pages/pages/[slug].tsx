@@ -1,3 +1,4 @@
+import { useEffect } from 'react';
 import { GetServerSideProps } from 'next';
 import Head from 'next/head';
 import { useRouter } from 'next/router';
@@ -15,9 +16,28 @@ interface PageProps {
   error?: string;
 }
 
+// formatCountdown matches the countdown widget rendered by the backend
+function formatCountdown(target: string): string {
+  const remaining = Math.max(0, Math.floor((new Date(target).getTime() - Date.now()) / 1000));
+  const pad = (n: number) => String(n).padStart(2, '0');
+  return `${Math.floor(remaining / 86400)}d ${pad(Math.floor(remaining / 3600) % 24)}:${pad(Math.floor(remaining / 60) % 60)}:${pad(remaining % 60)}`;
+}
+
 export default function CustomPage({ page, error }: PageProps) {
   const router = useRouter();
 
+  // Keep the countdown widgets ticking after the server-side render
+  useEffect(() => {
+    const countdowns = document.querySelectorAll<HTMLTimeElement>('.custom-page-content time.pta-countdown');
+    if (countdowns.length === 0) return;
+    const interval = setInterval(() => {
+      countdowns.forEach((el) => {
+        el.textContent = formatCountdown(el.dateTime);
+      });
+    }, 1000);
+    return () => clearInterval(interval);
+  }, [page?.html]);
+
   if (error || !page) {
     return (
       <div className="flex min-h-screen items-center justify-center">